@@ -9,9 +9,10 @@ type City struct {
 	CityName        string `nubes:"Id" dynamodbav:"Id"`
 	Region          string
 	Description     string
-	Hotels          lib.ReferenceNavigationList[Hotel] `nubes:"hasOne-City" dynamodbav:"-"`
+	Hotels          lib.ReferenceNavigationList[Hotel] `nubes:"hasOne-City,sortedBy-Rate" dynamodbav:"-"`
 	isInitialized   bool
 	invocationDepth int
+	stateSnapshot   lib.Snapshot
 }
 
 func (o City) GetTypeName() string {
@@ -27,7 +28,7 @@ type CloseToParams struct {
 func (c City) GetHotelsCloseTo(param CloseToParams) ([]Hotel, error) {
 	c.invocationDepth++
 	if c.isInitialized && c.invocationDepth == 1 {
-		_libError := lib.GetStub(c.CityName, &c)
+		_libError := lib.GetStubWithSnapshot(c.CityName, &c, &c.stateSnapshot)
 		if _libError != nil {
 			c.invocationDepth--
 			return *new([]Hotel), _libError
@@ -70,33 +71,15 @@ func (c City) GetHotelsCloseTo(param CloseToParams) ([]Hotel, error) {
 func (c City) GetHotelsWithBestRates(count int) ([]Hotel, error) {
 	c.invocationDepth++
 	if c.isInitialized && c.invocationDepth == 1 {
-		_libError := lib.GetStub(c.CityName, &c)
+		_libError := lib.GetStubWithSnapshot(c.CityName, &c, &c.stateSnapshot)
 		if _libError != nil {
 			c.invocationDepth--
 			return *new([]Hotel), _libError
 		}
 	}
-	hotels, err := c.Hotels.GetStubs()
-	result := make([]Hotel, count)
-
-	if err != nil {
-		c.invocationDepth--
-		return nil, err
-	}
-
-	if len(hotels) <= count {
-		c.invocationDepth--
-		return result, err
-	}
-
-	quickSortRate(hotels, 0, len(hotels))
-
-	for i := 0; i < count; i++ {
-		result[i] = hotels[len(hotels)-i-1]
-	}
+	hotels, err := c.Hotels.GetSortedStubs(lib.SortedQuery{SortedBy: "Rate", Descending: true, Limit: count})
 	c.invocationDepth--
-
-	return result, nil
+	return hotels, err
 }
 
 type hotelDist struct {
@@ -135,37 +118,6 @@ func partitionHotelDist(arr []hotelDist, from int, to int) int {
 	return pivotPos
 }
 
-func quickSortRate(arr []Hotel, from int, to int) {
-
-	if from < 0 || from >= to {
-		return
-	}
-	pivot := partitionRate(arr, from, to)
-
-	quickSortRate(arr, from, pivot)
-	quickSortRate(arr, pivot+1, to)
-}
-
-func partitionRate(arr []Hotel, from int, to int) int {
-
-	pivot := arr[from]
-	pivotPos := from
-
-	for j := from + 1; j < to; j++ {
-		if arr[j].Rate < pivot.Rate {
-			pivotPos++
-
-			temp := arr[pivotPos]
-			arr[pivotPos] = arr[j]
-			arr[j] = temp
-		}
-
-	}
-
-	arr[from] = arr[pivotPos]
-	arr[pivotPos] = pivot
-	return pivotPos
-}
 func (receiver City) GetId() string {
 	return receiver.CityName
 }
@@ -175,7 +127,7 @@ func (receiver *City) Init() {
 }
 func (receiver *City) saveChangesIfInitialized() error {
 	if receiver.isInitialized && receiver.invocationDepth == 1 {
-		_libError := lib.Upsert(receiver, receiver.CityName)
+		_libError := lib.SaveChanges(receiver, receiver.CityName, receiver.stateSnapshot)
 		if _libError != nil {
 			return _libError
 		}
@@ -15,6 +15,7 @@ type Hotel struct {
 	City            lib.Reference[City]               `dynamodbav:",omitempty"`
 	isInitialized   bool
 	invocationDepth int
+	stateSnapshot   lib.Snapshot
 }
 
 func (o Hotel) GetTypeName() string {
@@ -29,7 +30,7 @@ func (receiver *Hotel) Init() {
 }
 func (receiver *Hotel) saveChangesIfInitialized() error {
 	if receiver.isInitialized && receiver.invocationDepth == 1 {
-		_libError := lib.Upsert(receiver, receiver.HName)
+		_libError := lib.SaveChanges(receiver, receiver.HName, receiver.stateSnapshot)
 		if _libError != nil {
 			return _libError
 		}
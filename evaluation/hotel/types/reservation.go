@@ -16,6 +16,7 @@ type Reservation struct {
 	DateOut         time.Time
 	isInitialized   bool
 	invocationDepth int
+	stateSnapshot   lib.Snapshot
 }
 
 func (o Reservation) GetTypeName() string {
@@ -126,7 +127,7 @@ func (receiver *Reservation) Init() {
 }
 func (receiver *Reservation) saveChangesIfInitialized() error {
 	if receiver.isInitialized && receiver.invocationDepth == 1 {
-		_libError := lib.Upsert(receiver, receiver.Id)
+		_libError := lib.SaveChanges(receiver, receiver.Id, receiver.stateSnapshot)
 		if _libError != nil {
 			return _libError
 		}
@@ -13,6 +13,7 @@ type Room struct {
 	Price           float32
 	isInitialized   bool
 	invocationDepth int
+	stateSnapshot   lib.Snapshot
 }
 
 type ReservationInOut struct {
@@ -52,7 +53,7 @@ func (receiver *Room) Init() {
 }
 func (receiver *Room) saveChangesIfInitialized() error {
 	if receiver.isInitialized && receiver.invocationDepth == 1 {
-		_libError := lib.Upsert(receiver, receiver.Id)
+		_libError := lib.SaveChanges(receiver, receiver.Id, receiver.stateSnapshot)
 		if _libError != nil {
 			return _libError
 		}
@@ -14,6 +14,7 @@ type User struct {
 	Reservations    lib.ReferenceNavigationList[Reservation] `nubes:"hasMany-Users" dynamodbav:"-"`
 	isInitialized   bool
 	invocationDepth int
+	stateSnapshot   lib.Snapshot
 }
 
 func (o User) GetTypeName() string {
@@ -23,7 +24,7 @@ func (o User) GetTypeName() string {
 func (u User) VerifyPassword(password string) (bool, error) {
 	u.invocationDepth++
 	if u.isInitialized && u.invocationDepth == 1 {
-		_libError := lib.GetStub(u.Email, &u)
+		_libError := lib.GetStubWithSnapshot(u.Email, &u, &u.stateSnapshot)
 		if _libError != nil {
 			u.invocationDepth--
 			return *new(bool), _libError
@@ -68,7 +69,7 @@ func (receiver *User) Init() {
 }
 func (receiver *User) saveChangesIfInitialized() error {
 	if receiver.isInitialized && receiver.invocationDepth == 1 {
-		_libError := lib.Upsert(receiver, receiver.Email)
+		_libError := lib.SaveChanges(receiver, receiver.Email, receiver.stateSnapshot)
 		if _libError != nil {
 			return _libError
 		}
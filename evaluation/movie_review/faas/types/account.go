@@ -8,6 +8,7 @@ type Account struct {
 	Password        string `nubes:"readonly"`
 	isInitialized   bool
 	invocationDepth int
+	stateSnapshot   lib.Snapshot
 }
 
 func (Account) GetTypeName() string {
@@ -21,7 +22,7 @@ func (u Account) GetId() string {
 func (u Account) VerifyPassword(password string) (bool, error) {
 	u.invocationDepth++
 	if u.isInitialized && u.invocationDepth == 1 {
-		_libError := lib.GetStub(u.Email, &u)
+		_libError := lib.GetStubWithSnapshot(u.Email, &u, &u.stateSnapshot)
 		if _libError != nil {
 			u.invocationDepth--
 			return *new(bool), _libError
@@ -49,7 +50,7 @@ func (receiver *Account) Init() {
 }
 func (receiver *Account) saveChangesIfInitialized() error {
 	if receiver.isInitialized && receiver.invocationDepth == 1 {
-		_libError := lib.Upsert(receiver, receiver.Email)
+		_libError := lib.SaveChanges(receiver, receiver.Email, receiver.stateSnapshot)
 		if _libError != nil {
 			return _libError
 		}
@@ -7,6 +7,7 @@ type Category struct {
 	Movies          lib.ReferenceNavigationList[Movie] `nubes:"hasOne-Category" dynamodbav:"-"`
 	isInitialized   bool
 	invocationDepth int
+	stateSnapshot   lib.Snapshot
 }
 
 func (Category) GetTypeName() string {
@@ -21,7 +22,7 @@ func (receiver *Category) Init() {
 }
 func (receiver *Category) saveChangesIfInitialized() error {
 	if receiver.isInitialized && receiver.invocationDepth == 1 {
-		_libError := lib.Upsert(receiver, receiver.CName)
+		_libError := lib.SaveChanges(receiver, receiver.CName, receiver.stateSnapshot)
 		if _libError != nil {
 			return _libError
 		}
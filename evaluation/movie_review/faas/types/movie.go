@@ -10,6 +10,7 @@ type Movie struct {
 	Reviews         lib.ReferenceNavigationList[Review] `nubes:"hasOne-Movie" dynamodbav:"-"`
 	isInitialized   bool
 	invocationDepth int
+	stateSnapshot   lib.Snapshot
 }
 
 func (Movie) GetTypeName() string {
@@ -21,7 +22,7 @@ func (receiver *Movie) Init() {
 }
 func (receiver *Movie) saveChangesIfInitialized() error {
 	if receiver.isInitialized && receiver.invocationDepth == 1 {
-		_libError := lib.Upsert(receiver, receiver.Id)
+		_libError := lib.SaveChanges(receiver, receiver.Id, receiver.stateSnapshot)
 		if _libError != nil {
 			return _libError
 		}
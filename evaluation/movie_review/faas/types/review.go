@@ -17,6 +17,7 @@ type Review struct {
 	MapField        map[string]string
 	isInitialized   bool
 	invocationDepth int
+	stateSnapshot   lib.Snapshot
 }
 
 func (Review) GetTypeName() string {
@@ -26,7 +27,7 @@ func (Review) GetTypeName() string {
 func (m *Review) Downvote(account Account) (int, error) {
 	m.invocationDepth++
 	if m.isInitialized && m.invocationDepth == 1 {
-		_libError := lib.GetStub(m.Id, m)
+		_libError := lib.GetStubWithSnapshot(m.Id, m, &m.stateSnapshot)
 		if _libError != nil {
 			m.invocationDepth--
 			return *new(int), _libError
@@ -55,7 +56,7 @@ func (m *Review) Downvote(account Account) (int, error) {
 func (m *Review) Upvote(account Account) (int, error) {
 	m.invocationDepth++
 	if m.isInitialized && m.invocationDepth == 1 {
-		_libError := lib.GetStub(m.Id, m)
+		_libError := lib.GetStubWithSnapshot(m.Id, m, &m.stateSnapshot)
 		if _libError != nil {
 			m.invocationDepth--
 			return *new(int), _libError
@@ -83,7 +84,7 @@ func (receiver *Review) Init() {
 }
 func (receiver *Review) saveChangesIfInitialized() error {
 	if receiver.isInitialized && receiver.invocationDepth == 1 {
-		_libError := lib.Upsert(receiver, receiver.Id)
+		_libError := lib.SaveChanges(receiver, receiver.Id, receiver.stateSnapshot)
 		if _libError != nil {
 			return _libError
 		}
@@ -4,18 +4,19 @@ import (
 	"encoding/json"
 	"fmt"
 
+	"github.com/Astenna/Nubes/lib"
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/lambda"
 )
 
 func NewDiscount() (*DiscountStub, error) {
 
-	out, _err := LambdaClient.Invoke(&lambda.InvokeInput{FunctionName: aws.String("NewDiscount")})
+	out, _err := LambdaClient.Invoke(&lambda.InvokeInput{FunctionName: aws.String(FunctionNamePrefix + "NewDiscount")})
 	if _err != nil {
 		return nil, _err
 	}
 	if out.FunctionError != nil {
-		return nil, fmt.Errorf("lambda function invocation failed. Error: %s", string(out.Payload))
+		return nil, fmt.Errorf("lambda function invocation failed. Error: %w", lib.DecodeFunctionError(out.Payload))
 	}
 
 	result := new(DiscountStub)
@@ -35,12 +35,12 @@ func LoadDiscount(id string) (*discount, error) {
 		return nil, err
 	}
 
-	out, _err := LambdaClient.Invoke(&lambda.InvokeInput{FunctionName: aws.String("Load"), Payload: jsonParam})
+	out, _err := LambdaClient.Invoke(&lambda.InvokeInput{FunctionName: aws.String(FunctionNamePrefix + "Load"), Payload: jsonParam})
 	if _err != nil {
 		return nil, _err
 	}
 	if out.FunctionError != nil {
-		return nil, fmt.Errorf("lambda function designed to verify if instance exists failed. Error: %s", string(out.Payload))
+		return nil, fmt.Errorf("lambda function designed to verify if instance exists failed. Error: %w", lib.DecodeFunctionError(out.Payload))
 	}
 
 	newInstance.id = id
@@ -66,6 +66,11 @@ func (r *discount) init() {
 func ExportDiscount(input DiscountStub) (*discount, error) {
 	newInstance := new(discount)
 
+	// the same rules are checked by the library before the object is exported
+	if err := lib.Validate(input); err != nil {
+		return nil, err
+	}
+
 	params := lib.HandlerParameters{
 		TypeName:  newInstance.GetTypeName(),
 		Parameter: input,
@@ -75,12 +80,12 @@ func ExportDiscount(input DiscountStub) (*discount, error) {
 		return nil, err
 	}
 
-	out, _err := LambdaClient.Invoke(&lambda.InvokeInput{FunctionName: aws.String("Export"), Payload: jsonParam})
+	out, _err := LambdaClient.Invoke(&lambda.InvokeInput{FunctionName: aws.String(FunctionNamePrefix + "Export"), Payload: jsonParam})
 	if _err != nil {
 		return nil, _err
 	}
 	if out.FunctionError != nil {
-		return nil, fmt.Errorf("lambda function designed to export an object failed. Error: %s", string(out.Payload[:]))
+		return nil, fmt.Errorf("lambda function designed to export an object failed. Error: %w", lib.DecodeFunctionError(out.Payload))
 	}
 
 	newInstance.id, err = strconv.Unquote(string(out.Payload[:]))
@@ -103,17 +108,19 @@ func DeleteDiscount(id string) error {
 		return err
 	}
 
-	out, _err := LambdaClient.Invoke(&lambda.InvokeInput{FunctionName: aws.String("Delete"), Payload: jsonParam})
+	out, _err := LambdaClient.Invoke(&lambda.InvokeInput{FunctionName: aws.String(FunctionNamePrefix + "Delete"), Payload: jsonParam})
 	if _err != nil {
 		return _err
 	}
 	if out.FunctionError != nil {
-		return fmt.Errorf("lambda function designed to delete an object failed. Error: %s", string(out.Payload))
+		return fmt.Errorf("lambda function designed to delete an object failed. Error: %w", lib.DecodeFunctionError(out.Payload))
 	}
 
 	return nil
 }
 
+// FIND
+
 // GETID
 
 func (s discount) GetId() string {
@@ -143,12 +150,12 @@ func (s discount) GetPercentage() (string, error) {
 		return *new(string), err
 	}
 
-	out, _err := LambdaClient.Invoke(&lambda.InvokeInput{FunctionName: aws.String("GetState"), Payload: jsonParam})
+	out, _err := LambdaClient.Invoke(&lambda.InvokeInput{FunctionName: aws.String(FunctionNamePrefix + "GetState"), Payload: jsonParam})
 	if _err != nil {
 		return *new(string), _err
 	}
 	if out.FunctionError != nil {
-		return *new(string), fmt.Errorf(string(out.Payload[:]))
+		return *new(string), lib.DecodeFunctionError(out.Payload)
 	}
 
 	result := new(string)
@@ -176,12 +183,12 @@ func (s discount) SetPercentage(newValue string) error {
 		return err
 	}
 
-	out, _err := LambdaClient.Invoke(&lambda.InvokeInput{FunctionName: aws.String("SetField"), Payload: jsonParam})
+	out, _err := LambdaClient.Invoke(&lambda.InvokeInput{FunctionName: aws.String(FunctionNamePrefix + "SetField"), Payload: jsonParam})
 	if _err != nil {
 		return _err
 	}
 	if out.FunctionError != nil {
-		return fmt.Errorf(string(out.Payload[:]))
+		return lib.DecodeFunctionError(out.Payload)
 	}
 	return nil
 }
@@ -201,12 +208,12 @@ func (s discount) GetValidFrom() (time.Time, error) {
 		return *new(time.Time), err
 	}
 
-	out, _err := LambdaClient.Invoke(&lambda.InvokeInput{FunctionName: aws.String("GetState"), Payload: jsonParam})
+	out, _err := LambdaClient.Invoke(&lambda.InvokeInput{FunctionName: aws.String(FunctionNamePrefix + "GetState"), Payload: jsonParam})
 	if _err != nil {
 		return *new(time.Time), _err
 	}
 	if out.FunctionError != nil {
-		return *new(time.Time), fmt.Errorf(string(out.Payload[:]))
+		return *new(time.Time), lib.DecodeFunctionError(out.Payload)
 	}
 
 	result := new(time.Time)
@@ -234,12 +241,12 @@ func (s discount) SetValidFrom(newValue time.Time) error {
 		return err
 	}
 
-	out, _err := LambdaClient.Invoke(&lambda.InvokeInput{FunctionName: aws.String("SetField"), Payload: jsonParam})
+	out, _err := LambdaClient.Invoke(&lambda.InvokeInput{FunctionName: aws.String(FunctionNamePrefix + "SetField"), Payload: jsonParam})
 	if _err != nil {
 		return _err
 	}
 	if out.FunctionError != nil {
-		return fmt.Errorf(string(out.Payload[:]))
+		return lib.DecodeFunctionError(out.Payload)
 	}
 	return nil
 }
@@ -259,12 +266,12 @@ func (s discount) GetValidUntil() (time.Time, error) {
 		return *new(time.Time), err
 	}
 
-	out, _err := LambdaClient.Invoke(&lambda.InvokeInput{FunctionName: aws.String("GetState"), Payload: jsonParam})
+	out, _err := LambdaClient.Invoke(&lambda.InvokeInput{FunctionName: aws.String(FunctionNamePrefix + "GetState"), Payload: jsonParam})
 	if _err != nil {
 		return *new(time.Time), _err
 	}
 	if out.FunctionError != nil {
-		return *new(time.Time), fmt.Errorf(string(out.Payload[:]))
+		return *new(time.Time), lib.DecodeFunctionError(out.Payload)
 	}
 
 	result := new(time.Time)
@@ -292,12 +299,12 @@ func (s discount) SetValidUntil(newValue time.Time) error {
 		return err
 	}
 
-	out, _err := LambdaClient.Invoke(&lambda.InvokeInput{FunctionName: aws.String("SetField"), Payload: jsonParam})
+	out, _err := LambdaClient.Invoke(&lambda.InvokeInput{FunctionName: aws.String(FunctionNamePrefix + "SetField"), Payload: jsonParam})
 	if _err != nil {
 		return _err
 	}
 	if out.FunctionError != nil {
-		return fmt.Errorf(string(out.Payload[:]))
+		return lib.DecodeFunctionError(out.Payload)
 	}
 	return nil
 }
@@ -319,12 +326,12 @@ func (r discount) GetStub() (DiscountStub, error) {
 		return *new(DiscountStub), err
 	}
 
-	out, _err := LambdaClient.Invoke(&lambda.InvokeInput{FunctionName: aws.String("GetState"), Payload: jsonParam})
+	out, _err := LambdaClient.Invoke(&lambda.InvokeInput{FunctionName: aws.String(FunctionNamePrefix + "GetState"), Payload: jsonParam})
 	if _err != nil {
 		return *new(DiscountStub), _err
 	}
 	if out.FunctionError != nil {
-		return *new(DiscountStub), fmt.Errorf(string(out.Payload[:]))
+		return *new(DiscountStub), lib.DecodeFunctionError(out.Payload)
 	}
 
 	result := new(DiscountStub)
@@ -5,6 +5,10 @@ import (
 	"github.com/aws/aws-sdk-go/service/lambda"
 )
 
+// FunctionNamePrefix precedes the names of the invoked lambda functions,
+// it is set to the prefix of the namespace the handlers were generated with
+const FunctionNamePrefix = ""
+
 var sess = session.Must(session.NewSessionWithOptions(session.Options{
 	SharedConfigState: session.SharedConfigEnable,
 }))
@@ -34,12 +34,12 @@ func LoadOrder(id string) (*order, error) {
 		return nil, err
 	}
 
-	out, _err := LambdaClient.Invoke(&lambda.InvokeInput{FunctionName: aws.String("Load"), Payload: jsonParam})
+	out, _err := LambdaClient.Invoke(&lambda.InvokeInput{FunctionName: aws.String(FunctionNamePrefix + "Load"), Payload: jsonParam})
 	if _err != nil {
 		return nil, _err
 	}
 	if out.FunctionError != nil {
-		return nil, fmt.Errorf("lambda function designed to verify if instance exists failed. Error: %s", string(out.Payload))
+		return nil, fmt.Errorf("lambda function designed to verify if instance exists failed. Error: %w", lib.DecodeFunctionError(out.Payload))
 	}
 
 	newInstance.id = id
@@ -74,12 +74,12 @@ func ExportOrder(input OrderStub) (*order, error) {
 		return nil, err
 	}
 
-	out, _err := LambdaClient.Invoke(&lambda.InvokeInput{FunctionName: aws.String("Export"), Payload: jsonParam})
+	out, _err := LambdaClient.Invoke(&lambda.InvokeInput{FunctionName: aws.String(FunctionNamePrefix + "Export"), Payload: jsonParam})
 	if _err != nil {
 		return nil, _err
 	}
 	if out.FunctionError != nil {
-		return nil, fmt.Errorf("lambda function designed to export an object failed. Error: %s", string(out.Payload[:]))
+		return nil, fmt.Errorf("lambda function designed to export an object failed. Error: %w", lib.DecodeFunctionError(out.Payload))
 	}
 
 	newInstance.id, err = strconv.Unquote(string(out.Payload[:]))
@@ -102,17 +102,19 @@ func DeleteOrder(id string) error {
 		return err
 	}
 
-	out, _err := LambdaClient.Invoke(&lambda.InvokeInput{FunctionName: aws.String("Delete"), Payload: jsonParam})
+	out, _err := LambdaClient.Invoke(&lambda.InvokeInput{FunctionName: aws.String(FunctionNamePrefix + "Delete"), Payload: jsonParam})
 	if _err != nil {
 		return _err
 	}
 	if out.FunctionError != nil {
-		return fmt.Errorf("lambda function designed to delete an object failed. Error: %s", string(out.Payload))
+		return fmt.Errorf("lambda function designed to delete an object failed. Error: %w", lib.DecodeFunctionError(out.Payload))
 	}
 
 	return nil
 }
 
+// FIND
+
 // GETID
 
 func (s order) GetId() string {
@@ -142,12 +144,12 @@ func (s order) GetProducts() ([]OrderedProduct, error) {
 		return *new([]OrderedProduct), err
 	}
 
-	out, _err := LambdaClient.Invoke(&lambda.InvokeInput{FunctionName: aws.String("GetState"), Payload: jsonParam})
+	out, _err := LambdaClient.Invoke(&lambda.InvokeInput{FunctionName: aws.String(FunctionNamePrefix + "GetState"), Payload: jsonParam})
 	if _err != nil {
 		return *new([]OrderedProduct), _err
 	}
 	if out.FunctionError != nil {
-		return *new([]OrderedProduct), fmt.Errorf(string(out.Payload[:]))
+		return *new([]OrderedProduct), lib.DecodeFunctionError(out.Payload)
 	}
 
 	result := new([]OrderedProduct)
@@ -175,12 +177,12 @@ func (s order) SetProducts(newValue []OrderedProduct) error {
 		return err
 	}
 
-	out, _err := LambdaClient.Invoke(&lambda.InvokeInput{FunctionName: aws.String("SetField"), Payload: jsonParam})
+	out, _err := LambdaClient.Invoke(&lambda.InvokeInput{FunctionName: aws.String(FunctionNamePrefix + "SetField"), Payload: jsonParam})
 	if _err != nil {
 		return _err
 	}
 	if out.FunctionError != nil {
-		return fmt.Errorf(string(out.Payload[:]))
+		return lib.DecodeFunctionError(out.Payload)
 	}
 	return nil
 }
@@ -200,12 +202,12 @@ func (s order) GetBuyer() (user, error) {
 		return *new(user), err
 	}
 
-	out, _err := LambdaClient.Invoke(&lambda.InvokeInput{FunctionName: aws.String("GetState"), Payload: jsonParam})
+	out, _err := LambdaClient.Invoke(&lambda.InvokeInput{FunctionName: aws.String(FunctionNamePrefix + "GetState"), Payload: jsonParam})
 	if _err != nil {
 		return *new(user), _err
 	}
 	if out.FunctionError != nil {
-		return *new(user), fmt.Errorf(string(out.Payload[:]))
+		return *new(user), lib.DecodeFunctionError(out.Payload)
 	}
 
 	result := new(lib.Reference[user])
@@ -233,12 +235,12 @@ func (s order) GetBuyerId() (string, error) {
 		return "", err
 	}
 
-	out, _err := LambdaClient.Invoke(&lambda.InvokeInput{FunctionName: aws.String("GetState"), Payload: jsonParam})
+	out, _err := LambdaClient.Invoke(&lambda.InvokeInput{FunctionName: aws.String(FunctionNamePrefix + "GetState"), Payload: jsonParam})
 	if _err != nil {
 		return "", _err
 	}
 	if out.FunctionError != nil {
-		return "", fmt.Errorf(string(out.Payload[:]))
+		return "", lib.DecodeFunctionError(out.Payload)
 	}
 
 	result := new(lib.Reference[user])
@@ -266,12 +268,12 @@ func (s order) SetBuyer(newValue string) error {
 		return err
 	}
 
-	out, _err := LambdaClient.Invoke(&lambda.InvokeInput{FunctionName: aws.String("SetField"), Payload: jsonParam})
+	out, _err := LambdaClient.Invoke(&lambda.InvokeInput{FunctionName: aws.String(FunctionNamePrefix + "SetField"), Payload: jsonParam})
 	if _err != nil {
 		return _err
 	}
 	if out.FunctionError != nil {
-		return fmt.Errorf(string(out.Payload[:]))
+		return lib.DecodeFunctionError(out.Payload)
 	}
 	return nil
 }
@@ -291,12 +293,12 @@ func (s order) GetShipping() (shipping, error) {
 		return *new(shipping), err
 	}
 
-	out, _err := LambdaClient.Invoke(&lambda.InvokeInput{FunctionName: aws.String("GetState"), Payload: jsonParam})
+	out, _err := LambdaClient.Invoke(&lambda.InvokeInput{FunctionName: aws.String(FunctionNamePrefix + "GetState"), Payload: jsonParam})
 	if _err != nil {
 		return *new(shipping), _err
 	}
 	if out.FunctionError != nil {
-		return *new(shipping), fmt.Errorf(string(out.Payload[:]))
+		return *new(shipping), lib.DecodeFunctionError(out.Payload)
 	}
 
 	result := new(lib.Reference[shipping])
@@ -324,12 +326,12 @@ func (s order) GetShippingId() (string, error) {
 		return "", err
 	}
 
-	out, _err := LambdaClient.Invoke(&lambda.InvokeInput{FunctionName: aws.String("GetState"), Payload: jsonParam})
+	out, _err := LambdaClient.Invoke(&lambda.InvokeInput{FunctionName: aws.String(FunctionNamePrefix + "GetState"), Payload: jsonParam})
 	if _err != nil {
 		return "", _err
 	}
 	if out.FunctionError != nil {
-		return "", fmt.Errorf(string(out.Payload[:]))
+		return "", lib.DecodeFunctionError(out.Payload)
 	}
 
 	result := new(lib.Reference[shipping])
@@ -357,12 +359,12 @@ func (s order) SetShipping(newValue string) error {
 		return err
 	}
 
-	out, _err := LambdaClient.Invoke(&lambda.InvokeInput{FunctionName: aws.String("SetField"), Payload: jsonParam})
+	out, _err := LambdaClient.Invoke(&lambda.InvokeInput{FunctionName: aws.String(FunctionNamePrefix + "SetField"), Payload: jsonParam})
 	if _err != nil {
 		return _err
 	}
 	if out.FunctionError != nil {
-		return fmt.Errorf(string(out.Payload[:]))
+		return lib.DecodeFunctionError(out.Payload)
 	}
 	return nil
 }
@@ -384,12 +386,12 @@ func (r order) GetStub() (OrderStub, error) {
 		return *new(OrderStub), err
 	}
 
-	out, _err := LambdaClient.Invoke(&lambda.InvokeInput{FunctionName: aws.String("GetState"), Payload: jsonParam})
+	out, _err := LambdaClient.Invoke(&lambda.InvokeInput{FunctionName: aws.String(FunctionNamePrefix + "GetState"), Payload: jsonParam})
 	if _err != nil {
 		return *new(OrderStub), _err
 	}
 	if out.FunctionError != nil {
-		return *new(OrderStub), fmt.Errorf(string(out.Payload[:]))
+		return *new(OrderStub), lib.DecodeFunctionError(out.Payload)
 	}
 
 	result := new(OrderStub)
@@ -34,12 +34,12 @@ func LoadProduct(id string) (*product, error) {
 		return nil, err
 	}
 
-	out, _err := LambdaClient.Invoke(&lambda.InvokeInput{FunctionName: aws.String("Load"), Payload: jsonParam})
+	out, _err := LambdaClient.Invoke(&lambda.InvokeInput{FunctionName: aws.String(FunctionNamePrefix + "Load"), Payload: jsonParam})
 	if _err != nil {
 		return nil, _err
 	}
 	if out.FunctionError != nil {
-		return nil, fmt.Errorf("lambda function designed to verify if instance exists failed. Error: %s", string(out.Payload))
+		return nil, fmt.Errorf("lambda function designed to verify if instance exists failed. Error: %w", lib.DecodeFunctionError(out.Payload))
 	}
 
 	newInstance.id = id
@@ -65,6 +65,11 @@ func (r *product) init() {
 func ExportProduct(input ProductStub) (*product, error) {
 	newInstance := new(product)
 
+	// the same rules are checked by the library before the object is exported
+	if err := lib.Validate(input); err != nil {
+		return nil, err
+	}
+
 	params := lib.HandlerParameters{
 		TypeName:  newInstance.GetTypeName(),
 		Parameter: input,
@@ -74,12 +79,12 @@ func ExportProduct(input ProductStub) (*product, error) {
 		return nil, err
 	}
 
-	out, _err := LambdaClient.Invoke(&lambda.InvokeInput{FunctionName: aws.String("Export"), Payload: jsonParam})
+	out, _err := LambdaClient.Invoke(&lambda.InvokeInput{FunctionName: aws.String(FunctionNamePrefix + "Export"), Payload: jsonParam})
 	if _err != nil {
 		return nil, _err
 	}
 	if out.FunctionError != nil {
-		return nil, fmt.Errorf("lambda function designed to export an object failed. Error: %s", string(out.Payload[:]))
+		return nil, fmt.Errorf("lambda function designed to export an object failed. Error: %w", lib.DecodeFunctionError(out.Payload))
 	}
 
 	newInstance.id, err = strconv.Unquote(string(out.Payload[:]))
@@ -102,17 +107,63 @@ func DeleteProduct(id string) error {
 		return err
 	}
 
-	out, _err := LambdaClient.Invoke(&lambda.InvokeInput{FunctionName: aws.String("Delete"), Payload: jsonParam})
+	out, _err := LambdaClient.Invoke(&lambda.InvokeInput{FunctionName: aws.String(FunctionNamePrefix + "Delete"), Payload: jsonParam})
 	if _err != nil {
 		return _err
 	}
 	if out.FunctionError != nil {
-		return fmt.Errorf("lambda function designed to delete an object failed. Error: %s", string(out.Payload))
+		return fmt.Errorf("lambda function designed to delete an object failed. Error: %w", lib.DecodeFunctionError(out.Payload))
 	}
 
 	return nil
 }
 
+// FIND
+
+func FindProductsByName(value string) ([]product, error) {
+	params := lib.FindByParam{
+		TypeName:  (*new(product)).GetTypeName(),
+		FieldName: "Name",
+		Value:     value,
+	}
+	jsonParam, err := json.Marshal(params)
+	if err != nil {
+		return nil, err
+	}
+
+	out, _err := LambdaClient.Invoke(&lambda.InvokeInput{FunctionName: aws.String(FunctionNamePrefix + "FindBy"), Payload: jsonParam})
+	if _err != nil {
+		return nil, _err
+	}
+	if out.FunctionError != nil {
+		return nil, fmt.Errorf("lambda function designed to find objects failed. Error: %w", lib.DecodeFunctionError(out.Payload))
+	}
+
+	var ids []string
+	err = json.Unmarshal(out.Payload, &ids)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]product, len(ids))
+	for i, id := range ids {
+		result[i].id = id
+		result[i].init()
+	}
+	return result, nil
+}
+
+func FindProductByName(value string) (*product, error) {
+	found, err := FindProductsByName(value)
+	if err != nil {
+		return nil, err
+	}
+	if len(found) == 0 {
+		return nil, fmt.Errorf("Product with Name %v not found", value)
+	}
+	return &found[0], nil
+}
+
 // GETID
 
 func (s product) GetId() string {
@@ -142,12 +193,12 @@ func (s product) GetName() (string, error) {
 		return *new(string), err
 	}
 
-	out, _err := LambdaClient.Invoke(&lambda.InvokeInput{FunctionName: aws.String("GetState"), Payload: jsonParam})
+	out, _err := LambdaClient.Invoke(&lambda.InvokeInput{FunctionName: aws.String(FunctionNamePrefix + "GetState"), Payload: jsonParam})
 	if _err != nil {
 		return *new(string), _err
 	}
 	if out.FunctionError != nil {
-		return *new(string), fmt.Errorf(string(out.Payload[:]))
+		return *new(string), lib.DecodeFunctionError(out.Payload)
 	}
 
 	result := new(string)
@@ -175,12 +226,12 @@ func (s product) SetName(newValue string) error {
 		return err
 	}
 
-	out, _err := LambdaClient.Invoke(&lambda.InvokeInput{FunctionName: aws.String("SetField"), Payload: jsonParam})
+	out, _err := LambdaClient.Invoke(&lambda.InvokeInput{FunctionName: aws.String(FunctionNamePrefix + "SetField"), Payload: jsonParam})
 	if _err != nil {
 		return _err
 	}
 	if out.FunctionError != nil {
-		return fmt.Errorf(string(out.Payload[:]))
+		return lib.DecodeFunctionError(out.Payload)
 	}
 	return nil
 }
@@ -200,12 +251,12 @@ func (s product) GetQuantityAvailable() (int, error) {
 		return *new(int), err
 	}
 
-	out, _err := LambdaClient.Invoke(&lambda.InvokeInput{FunctionName: aws.String("GetState"), Payload: jsonParam})
+	out, _err := LambdaClient.Invoke(&lambda.InvokeInput{FunctionName: aws.String(FunctionNamePrefix + "GetState"), Payload: jsonParam})
 	if _err != nil {
 		return *new(int), _err
 	}
 	if out.FunctionError != nil {
-		return *new(int), fmt.Errorf(string(out.Payload[:]))
+		return *new(int), lib.DecodeFunctionError(out.Payload)
 	}
 
 	result := new(int)
@@ -222,6 +273,10 @@ func (s product) SetQuantityAvailable(newValue int) error {
 		return errors.New("id of the type not set, use LoadProduct or ExportProduct to create new instance of the type")
 	}
 
+	if err := lib.ValidateField(ProductStub{}, "QuantityAvailable", newValue); err != nil {
+		return err
+	}
+
 	params := lib.SetFieldParam{
 		Id:        s.GetId(),
 		TypeName:  s.GetTypeName(),
@@ -233,12 +288,12 @@ func (s product) SetQuantityAvailable(newValue int) error {
 		return err
 	}
 
-	out, _err := LambdaClient.Invoke(&lambda.InvokeInput{FunctionName: aws.String("SetField"), Payload: jsonParam})
+	out, _err := LambdaClient.Invoke(&lambda.InvokeInput{FunctionName: aws.String(FunctionNamePrefix + "SetField"), Payload: jsonParam})
 	if _err != nil {
 		return _err
 	}
 	if out.FunctionError != nil {
-		return fmt.Errorf(string(out.Payload[:]))
+		return lib.DecodeFunctionError(out.Payload)
 	}
 	return nil
 }
@@ -258,12 +313,12 @@ func (s product) GetSoldBy() (shop, error) {
 		return *new(shop), err
 	}
 
-	out, _err := LambdaClient.Invoke(&lambda.InvokeInput{FunctionName: aws.String("GetState"), Payload: jsonParam})
+	out, _err := LambdaClient.Invoke(&lambda.InvokeInput{FunctionName: aws.String(FunctionNamePrefix + "GetState"), Payload: jsonParam})
 	if _err != nil {
 		return *new(shop), _err
 	}
 	if out.FunctionError != nil {
-		return *new(shop), fmt.Errorf(string(out.Payload[:]))
+		return *new(shop), lib.DecodeFunctionError(out.Payload)
 	}
 
 	result := new(lib.Reference[shop])
@@ -291,12 +346,12 @@ func (s product) GetSoldById() (string, error) {
 		return "", err
 	}
 
-	out, _err := LambdaClient.Invoke(&lambda.InvokeInput{FunctionName: aws.String("GetState"), Payload: jsonParam})
+	out, _err := LambdaClient.Invoke(&lambda.InvokeInput{FunctionName: aws.String(FunctionNamePrefix + "GetState"), Payload: jsonParam})
 	if _err != nil {
 		return "", _err
 	}
 	if out.FunctionError != nil {
-		return "", fmt.Errorf(string(out.Payload[:]))
+		return "", lib.DecodeFunctionError(out.Payload)
 	}
 
 	result := new(lib.Reference[shop])
@@ -324,12 +379,12 @@ func (s product) SetSoldBy(newValue string) error {
 		return err
 	}
 
-	out, _err := LambdaClient.Invoke(&lambda.InvokeInput{FunctionName: aws.String("SetField"), Payload: jsonParam})
+	out, _err := LambdaClient.Invoke(&lambda.InvokeInput{FunctionName: aws.String(FunctionNamePrefix + "SetField"), Payload: jsonParam})
 	if _err != nil {
 		return _err
 	}
 	if out.FunctionError != nil {
-		return fmt.Errorf(string(out.Payload[:]))
+		return lib.DecodeFunctionError(out.Payload)
 	}
 	return nil
 }
@@ -350,12 +405,12 @@ func (s product) GetDiscountIds() ([]string, error) {
 		return nil, err
 	}
 
-	out, _err := LambdaClient.Invoke(&lambda.InvokeInput{FunctionName: aws.String("GetState"), Payload: jsonParam})
+	out, _err := LambdaClient.Invoke(&lambda.InvokeInput{FunctionName: aws.String(FunctionNamePrefix + "GetState"), Payload: jsonParam})
 	if _err != nil {
 		return nil, _err
 	}
 	if out.FunctionError != nil {
-		return nil, fmt.Errorf(string(out.Payload[:]))
+		return nil, lib.DecodeFunctionError(out.Payload)
 	}
 
 	var result []string
@@ -382,12 +437,12 @@ func (s product) GetDiscount() ([]discount, error) {
 		return nil, err
 	}
 
-	out, _err := LambdaClient.Invoke(&lambda.InvokeInput{FunctionName: aws.String("GetState"), Payload: jsonParam})
+	out, _err := LambdaClient.Invoke(&lambda.InvokeInput{FunctionName: aws.String(FunctionNamePrefix + "GetState"), Payload: jsonParam})
 	if _err != nil {
 		return nil, _err
 	}
 	if out.FunctionError != nil {
-		return nil, fmt.Errorf(string(out.Payload[:]))
+		return nil, lib.DecodeFunctionError(out.Payload)
 	}
 
 	var ids []string
@@ -404,6 +459,7 @@ func (s product) GetDiscount() ([]discount, error) {
 
 	return result, nil
 }
+
 func (s product) SetDiscount(ids []string) error {
 	if s.id == "" {
 		return errors.New("id of the type not set, use LoadProduct or ExportProduct to create new instance of the type")
@@ -420,12 +476,12 @@ func (s product) SetDiscount(ids []string) error {
 		return err
 	}
 
-	out, _err := LambdaClient.Invoke(&lambda.InvokeInput{FunctionName: aws.String("SetField"), Payload: jsonParam})
+	out, _err := LambdaClient.Invoke(&lambda.InvokeInput{FunctionName: aws.String(FunctionNamePrefix + "SetField"), Payload: jsonParam})
 	if _err != nil {
 		return _err
 	}
 	if out.FunctionError != nil {
-		return fmt.Errorf(string(out.Payload[:]))
+		return lib.DecodeFunctionError(out.Payload)
 	}
 	return nil
 }
@@ -445,12 +501,12 @@ func (s product) GetPrice() (float64, error) {
 		return *new(float64), err
 	}
 
-	out, _err := LambdaClient.Invoke(&lambda.InvokeInput{FunctionName: aws.String("GetState"), Payload: jsonParam})
+	out, _err := LambdaClient.Invoke(&lambda.InvokeInput{FunctionName: aws.String(FunctionNamePrefix + "GetState"), Payload: jsonParam})
 	if _err != nil {
 		return *new(float64), _err
 	}
 	if out.FunctionError != nil {
-		return *new(float64), fmt.Errorf(string(out.Payload[:]))
+		return *new(float64), lib.DecodeFunctionError(out.Payload)
 	}
 
 	result := new(float64)
@@ -467,6 +523,10 @@ func (s product) SetPrice(newValue float64) error {
 		return errors.New("id of the type not set, use LoadProduct or ExportProduct to create new instance of the type")
 	}
 
+	if err := lib.ValidateField(ProductStub{}, "Price", newValue); err != nil {
+		return err
+	}
+
 	params := lib.SetFieldParam{
 		Id:        s.GetId(),
 		TypeName:  s.GetTypeName(),
@@ -478,16 +538,48 @@ func (s product) SetPrice(newValue float64) error {
 		return err
 	}
 
-	out, _err := LambdaClient.Invoke(&lambda.InvokeInput{FunctionName: aws.String("SetField"), Payload: jsonParam})
+	out, _err := LambdaClient.Invoke(&lambda.InvokeInput{FunctionName: aws.String(FunctionNamePrefix + "SetField"), Payload: jsonParam})
 	if _err != nil {
 		return _err
 	}
 	if out.FunctionError != nil {
-		return fmt.Errorf(string(out.Payload[:]))
+		return lib.DecodeFunctionError(out.Payload)
 	}
 	return nil
 }
 
+func (s product) GetVersion() (int, error) {
+	if s.id == "" {
+		return *new(int), errors.New("id of the type not set, use  LoadProduct or ExportProduct to create new instance of the type")
+	}
+
+	params := lib.GetStateParam{
+		Id:        s.GetId(),
+		TypeName:  s.GetTypeName(),
+		FieldName: "Version",
+	}
+	jsonParam, err := json.Marshal(params)
+	if err != nil {
+		return *new(int), err
+	}
+
+	out, _err := LambdaClient.Invoke(&lambda.InvokeInput{FunctionName: aws.String(FunctionNamePrefix + "GetState"), Payload: jsonParam})
+	if _err != nil {
+		return *new(int), _err
+	}
+	if out.FunctionError != nil {
+		return *new(int), lib.DecodeFunctionError(out.Payload)
+	}
+
+	result := new(int)
+	err = json.Unmarshal(out.Payload, result)
+	if err != nil {
+		return *new(int), err
+	}
+	return *result, err
+
+}
+
 // (STATE-CHANGING) METHODS
 
 func (p product) DecreaseAvailabilityBy(input int) error {
@@ -504,12 +596,12 @@ func (p product) DecreaseAvailabilityBy(input int) error {
 		return err
 	}
 
-	out, _err := LambdaClient.Invoke(&lambda.InvokeInput{FunctionName: aws.String("ProductDecreaseAvailabilityBy"), Payload: jsonParam})
+	out, _err := LambdaClient.Invoke(&lambda.InvokeInput{FunctionName: aws.String(FunctionNamePrefix + "ProductDecreaseAvailabilityBy"), Payload: jsonParam})
 	if _err != nil {
 		return _err
 	}
 	if out.FunctionError != nil {
-		return fmt.Errorf(string(out.Payload[:]))
+		return lib.DecodeFunctionError(out.Payload)
 	}
 
 	return _err
@@ -529,12 +621,12 @@ func (p product) AddNewDiscountByCopy(input DiscountStub) error {
 		return err
 	}
 
-	out, _err := LambdaClient.Invoke(&lambda.InvokeInput{FunctionName: aws.String("ProductAddNewDiscountByCopy"), Payload: jsonParam})
+	out, _err := LambdaClient.Invoke(&lambda.InvokeInput{FunctionName: aws.String(FunctionNamePrefix + "ProductAddNewDiscountByCopy"), Payload: jsonParam})
 	if _err != nil {
 		return _err
 	}
 	if out.FunctionError != nil {
-		return fmt.Errorf(string(out.Payload[:]))
+		return lib.DecodeFunctionError(out.Payload)
 	}
 
 	return _err
@@ -554,17 +646,55 @@ func (p product) AddNewDiscountByReference(input lib.Reference[discount]) error
 		return err
 	}
 
-	out, _err := LambdaClient.Invoke(&lambda.InvokeInput{FunctionName: aws.String("ProductAddNewDiscountByReference"), Payload: jsonParam})
+	out, _err := LambdaClient.Invoke(&lambda.InvokeInput{FunctionName: aws.String(FunctionNamePrefix + "ProductAddNewDiscountByReference"), Payload: jsonParam})
 	if _err != nil {
 		return _err
 	}
 	if out.FunctionError != nil {
-		return fmt.Errorf(string(out.Payload[:]))
+		return lib.DecodeFunctionError(out.Payload)
 	}
 
 	return _err
 }
 
+func (p product) Restock(quantity int, price float64) (int, float64, error) {
+	if p.id == "" {
+		return *new(int), *new(float64), errors.New("id of the type not set, use  LoadProduct or ExportProduct to create new instance of the type")
+	}
+
+	params := new(lib.HandlerParameters)
+	params.Id = p.id
+
+	params.Parameter = struct {
+		Quantity int
+		Price    float64
+	}{quantity, price}
+
+	jsonParam, err := json.Marshal(params)
+	if err != nil {
+		return *new(int), *new(float64), err
+	}
+
+	out, _err := LambdaClient.Invoke(&lambda.InvokeInput{FunctionName: aws.String(FunctionNamePrefix + "ProductRestock"), Payload: jsonParam})
+	if _err != nil {
+		return *new(int), *new(float64), _err
+	}
+	if out.FunctionError != nil {
+		return *new(int), *new(float64), lib.DecodeFunctionError(out.Payload)
+	}
+
+	result := new(struct {
+		QuantityAvailable int
+		NewPrice          float64
+	})
+	_err = json.Unmarshal(out.Payload, result)
+	if _err != nil {
+		return *new(int), *new(float64), _err
+	}
+
+	return result.QuantityAvailable, result.NewPrice, _err
+}
+
 func (r product) GetStub() (ProductStub, error) {
 	if r.id == "" {
 		return *new(ProductStub), errors.New("id of the type not set, use  LoadProduct or ExportProduct to create new instance of the type")
@@ -580,12 +710,12 @@ func (r product) GetStub() (ProductStub, error) {
 		return *new(ProductStub), err
 	}
 
-	out, _err := LambdaClient.Invoke(&lambda.InvokeInput{FunctionName: aws.String("GetState"), Payload: jsonParam})
+	out, _err := LambdaClient.Invoke(&lambda.InvokeInput{FunctionName: aws.String(FunctionNamePrefix + "GetState"), Payload: jsonParam})
 	if _err != nil {
 		return *new(ProductStub), _err
 	}
 	if out.FunctionError != nil {
-		return *new(ProductStub), fmt.Errorf(string(out.Payload[:]))
+		return *new(ProductStub), lib.DecodeFunctionError(out.Payload)
 	}
 
 	result := new(ProductStub)
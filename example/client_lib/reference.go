@@ -36,12 +36,12 @@ func (r Reference[T]) Get() (*T, error) {
 		return nil, err
 	}
 
-	out, _err := LambdaClient.Invoke(&lambda.InvokeInput{FunctionName: aws.String("Load"), Payload: jsonParam})
+	out, _err := LambdaClient.Invoke(&lambda.InvokeInput{FunctionName: aws.String(FunctionNamePrefix + "Load"), Payload: jsonParam})
 	if _err != nil {
 		return nil, _err
 	}
 	if out.FunctionError != nil {
-		return nil, fmt.Errorf("lambda function designed to verify if instance exists failed. Error: %s", string(out.Payload))
+		return nil, fmt.Errorf("lambda function designed to verify if instance exists failed. Error: %w", lib.DecodeFunctionError(out.Payload))
 	}
 
 	casted := any(newInstance)
@@ -67,12 +67,12 @@ func GetStub[T lib.Nobject](id string) (T, error) {
 		return *new(T), err
 	}
 
-	out, _err := LambdaClient.Invoke(&lambda.InvokeInput{FunctionName: aws.String("GetState"), Payload: jsonParam})
+	out, _err := LambdaClient.Invoke(&lambda.InvokeInput{FunctionName: aws.String(FunctionNamePrefix + "GetState"), Payload: jsonParam})
 	if _err != nil {
 		return *new(T), _err
 	}
 	if out.FunctionError != nil {
-		return *new(T), fmt.Errorf(string(out.Payload[:]))
+		return *new(T), lib.DecodeFunctionError(out.Payload)
 	}
 
 	err = json.Unmarshal(out.Payload, result)
@@ -95,12 +95,12 @@ func GetStubs[T lib.Nobject](ids []string) ([]T, error) {
 	if err != nil {
 		return nil, err
 	}
-	out, _err := LambdaClient.Invoke(&lambda.InvokeInput{FunctionName: aws.String("GetBatch"), Payload: jsonParam})
+	out, _err := LambdaClient.Invoke(&lambda.InvokeInput{FunctionName: aws.String(FunctionNamePrefix + "GetBatch"), Payload: jsonParam})
 	if _err != nil {
 		return nil, _err
 	}
 	if out.FunctionError != nil {
-		return nil, fmt.Errorf("lambda function designed retrieve the objects' states failed. Error: %s", string(out.Payload))
+		return nil, fmt.Errorf("lambda function designed retrieve the objects' states failed. Error: %w", lib.DecodeFunctionError(out.Payload))
 	}
 
 	stubs := make([]T, len(ids))
@@ -2,6 +2,7 @@ package client_lib
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 
 	"github.com/Astenna/Nubes/lib"
@@ -38,17 +39,18 @@ func loadBatch[T lib.Nobject](ids []string) ([]T, error) {
 		return nil, err
 	}
 
-	out, err := LambdaClient.Invoke(&lambda.InvokeInput{FunctionName: aws.String("Load"), Payload: jsonParam})
-	if out.FunctionError != nil {
-		return nil, fmt.Errorf("lambda function designed to verify if instance exists failed. Error: %s", string(out.Payload))
+	out, err := LambdaClient.Invoke(&lambda.InvokeInput{FunctionName: aws.String(FunctionNamePrefix + "Load"), Payload: jsonParam})
+	if err != nil {
+		return nil, err
 	}
 	foundIds := ids
-	if err != nil {
-		if notFound, casted := err.(lib.NotFoundError); casted {
-			foundIds = difference(ids, notFound.Ids)
-		} else {
-			return nil, err
+	if out.FunctionError != nil {
+		err = lib.DecodeFunctionError(out.Payload)
+		var notFound lib.NotFoundError
+		if !errors.As(err, &notFound) {
+			return nil, fmt.Errorf("lambda function designed to verify if instance exists failed. Error: %w", err)
 		}
+		foundIds = difference(ids, notFound.Ids)
 	}
 
 	result := make([]T, len(foundIds))
@@ -2,6 +2,7 @@ package client_lib
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 
 	"github.com/Astenna/Nubes/lib"
@@ -30,12 +31,12 @@ func (r referenceNavigationList[T, Stub]) GetIds() ([]string, error) {
 		return nil, err
 	}
 
-	out, _err := LambdaClient.Invoke(&lambda.InvokeInput{FunctionName: aws.String("ReferenceGetIds"), Payload: jsonParam})
+	out, _err := LambdaClient.Invoke(&lambda.InvokeInput{FunctionName: aws.String(FunctionNamePrefix + "ReferenceGetIds"), Payload: jsonParam})
 	if _err != nil {
 		return nil, _err
 	}
 	if out.FunctionError != nil {
-		return nil, fmt.Errorf(string(out.Payload[:]))
+		return nil, lib.DecodeFunctionError(out.Payload)
 	}
 
 	var result []string
@@ -53,16 +54,16 @@ func (r referenceNavigationList[T, Stub]) Get() ([]T, error) {
 		return nil, err
 	}
 
-	out, _err := LambdaClient.Invoke(&lambda.InvokeInput{FunctionName: aws.String("ReferenceGet"), Payload: jsonParam})
-	if out.FunctionError != nil {
-		return nil, fmt.Errorf("lambda function designed to verify if instance exists failed. Error: %s", string(out.Payload))
+	out, _err := LambdaClient.Invoke(&lambda.InvokeInput{FunctionName: aws.String(FunctionNamePrefix + "ReferenceGet"), Payload: jsonParam})
+	if _err != nil {
+		return nil, _err
 	}
 
 	var notFoundError lib.NotFoundError
-	if _err != nil {
-		casted := false
-		if notFoundError, casted = err.(lib.NotFoundError); !casted {
-			return nil, err
+	if out.FunctionError != nil {
+		err = lib.DecodeFunctionError(out.Payload)
+		if !errors.As(err, &notFoundError) {
+			return nil, fmt.Errorf("lambda function designed to verify if instance exists failed. Error: %w", err)
 		}
 	}
 
@@ -94,12 +95,12 @@ func (r referenceNavigationList[T, Stub]) GetStubs() ([]Stub, error) {
 		return nil, err
 	}
 
-	out, _err := LambdaClient.Invoke(&lambda.InvokeInput{FunctionName: aws.String("ReferenceGetStubs"), Payload: jsonParam})
+	out, _err := LambdaClient.Invoke(&lambda.InvokeInput{FunctionName: aws.String(FunctionNamePrefix + "ReferenceGetStubs"), Payload: jsonParam})
 	if _err != nil {
 		return nil, _err
 	}
 	if out.FunctionError != nil {
-		return nil, fmt.Errorf("lambda function designed to the retrieve objects' states failed. Error: %s", string(out.Payload))
+		return nil, fmt.Errorf("lambda function designed to the retrieve objects' states failed. Error: %w", lib.DecodeFunctionError(out.Payload))
 	}
 
 	var stubs []Stub
@@ -111,6 +112,45 @@ func (r referenceNavigationList[T, Stub]) GetStubs() ([]Stub, error) {
 	return stubs, err
 }
 
+// GetPage returns at most limit objects of the relationship starting from the
+// cursor returned with the previous page, the empty cursor denotes the first page.
+// The returned cursor is empty if there are no more objects.
+func (r referenceNavigationList[T, Stub]) GetPage(limit int, cursor string) ([]T, string, error) {
+	jsonParam, err := json.Marshal(lib.ReferenceGetPageParam{
+		RefNavListParam: r.param,
+		Limit:           limit,
+		Cursor:          cursor,
+	})
+	if err != nil {
+		return nil, "", err
+	}
+
+	out, _err := LambdaClient.Invoke(&lambda.InvokeInput{FunctionName: aws.String(FunctionNamePrefix + "ReferenceGetPage"), Payload: jsonParam})
+	if _err != nil {
+		return nil, "", _err
+	}
+	if out.FunctionError != nil {
+		return nil, "", lib.DecodeFunctionError(out.Payload)
+	}
+
+	var page lib.IdsPage
+	err = json.Unmarshal(out.Payload, &page)
+	if err != nil {
+		return nil, "", err
+	}
+
+	result := make([]T, len(page.Ids))
+	for i, id := range page.Ids {
+		newInstance := new(T)
+		casted := any(newInstance)
+		setIdInterf, _ := casted.(setId)
+		setIdInterf.setId(id)
+		setIdInterf.init()
+		result[i] = *newInstance
+	}
+	return result, page.Cursor, nil
+}
+
 func (r referenceNavigationList[T, Stub]) AddToManyToMany(newId string) error {
 	if newId == "" {
 		return fmt.Errorf("missing id")
@@ -127,12 +167,12 @@ func (r referenceNavigationList[T, Stub]) AddToManyToMany(newId string) error {
 			return err
 		}
 
-		out, _err := LambdaClient.Invoke(&lambda.InvokeInput{FunctionName: aws.String("ReferenceAddToManyToMany"), Payload: jsonParam})
+		out, _err := LambdaClient.Invoke(&lambda.InvokeInput{FunctionName: aws.String(FunctionNamePrefix + "ReferenceAddToManyToMany"), Payload: jsonParam})
 		if _err != nil {
 			return _err
 		}
 		if out.FunctionError != nil {
-			return fmt.Errorf(string(out.Payload[:]))
+			return lib.DecodeFunctionError(out.Payload)
 		}
 
 		return nil
@@ -156,14 +196,14 @@ func (r referenceNavigationList[T, Stub]) DeleteFromManyToMany(ids []string) err
 		}
 
 		out, _err := LambdaClient.Invoke(&lambda.InvokeInput{
-			FunctionName: aws.String("ReferenceDeleteFromManyToMany"),
+			FunctionName: aws.String(FunctionNamePrefix + "ReferenceDeleteFromManyToMany"),
 			Payload:      jsonParam,
 		})
 		if _err != nil {
 			return _err
 		}
 		if out.FunctionError != nil {
-			return fmt.Errorf(string(out.Payload[:]))
+			return lib.DecodeFunctionError(out.Payload)
 		}
 
 		return nil
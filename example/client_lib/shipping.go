@@ -35,12 +35,12 @@ func LoadShipping(id string) (*shipping, error) {
 		return nil, err
 	}
 
-	out, _err := LambdaClient.Invoke(&lambda.InvokeInput{FunctionName: aws.String("Load"), Payload: jsonParam})
+	out, _err := LambdaClient.Invoke(&lambda.InvokeInput{FunctionName: aws.String(FunctionNamePrefix + "Load"), Payload: jsonParam})
 	if _err != nil {
 		return nil, _err
 	}
 	if out.FunctionError != nil {
-		return nil, fmt.Errorf("lambda function designed to verify if instance exists failed. Error: %s", string(out.Payload))
+		return nil, fmt.Errorf("lambda function designed to verify if instance exists failed. Error: %w", lib.DecodeFunctionError(out.Payload))
 	}
 
 	newInstance.id = id
@@ -75,12 +75,12 @@ func ExportShipping(input string) (*shipping, error) {
 		return nil, err
 	}
 
-	out, _err := LambdaClient.Invoke(&lambda.InvokeInput{FunctionName: aws.String("Export"), Payload: jsonParam})
+	out, _err := LambdaClient.Invoke(&lambda.InvokeInput{FunctionName: aws.String(FunctionNamePrefix + "Export"), Payload: jsonParam})
 	if _err != nil {
 		return nil, _err
 	}
 	if out.FunctionError != nil {
-		return nil, fmt.Errorf("lambda function designed to export an object failed. Error: %s", string(out.Payload[:]))
+		return nil, fmt.Errorf("lambda function designed to export an object failed. Error: %w", lib.DecodeFunctionError(out.Payload))
 	}
 
 	newInstance.id, err = strconv.Unquote(string(out.Payload[:]))
@@ -103,17 +103,19 @@ func DeleteShipping(id string) error {
 		return err
 	}
 
-	out, _err := LambdaClient.Invoke(&lambda.InvokeInput{FunctionName: aws.String("Delete"), Payload: jsonParam})
+	out, _err := LambdaClient.Invoke(&lambda.InvokeInput{FunctionName: aws.String(FunctionNamePrefix + "Delete"), Payload: jsonParam})
 	if _err != nil {
 		return _err
 	}
 	if out.FunctionError != nil {
-		return fmt.Errorf("lambda function designed to delete an object failed. Error: %s", string(out.Payload))
+		return fmt.Errorf("lambda function designed to delete an object failed. Error: %w", lib.DecodeFunctionError(out.Payload))
 	}
 
 	return nil
 }
 
+// FIND
+
 // GETID
 
 func (s shipping) GetId() string {
@@ -143,12 +145,12 @@ func (s shipping) GetAddress() (string, error) {
 		return *new(string), err
 	}
 
-	out, _err := LambdaClient.Invoke(&lambda.InvokeInput{FunctionName: aws.String("GetState"), Payload: jsonParam})
+	out, _err := LambdaClient.Invoke(&lambda.InvokeInput{FunctionName: aws.String(FunctionNamePrefix + "GetState"), Payload: jsonParam})
 	if _err != nil {
 		return *new(string), _err
 	}
 	if out.FunctionError != nil {
-		return *new(string), fmt.Errorf(string(out.Payload[:]))
+		return *new(string), lib.DecodeFunctionError(out.Payload)
 	}
 
 	result := new(string)
@@ -176,12 +178,12 @@ func (s shipping) SetAddress(newValue string) error {
 		return err
 	}
 
-	out, _err := LambdaClient.Invoke(&lambda.InvokeInput{FunctionName: aws.String("SetField"), Payload: jsonParam})
+	out, _err := LambdaClient.Invoke(&lambda.InvokeInput{FunctionName: aws.String(FunctionNamePrefix + "SetField"), Payload: jsonParam})
 	if _err != nil {
 		return _err
 	}
 	if out.FunctionError != nil {
-		return fmt.Errorf(string(out.Payload[:]))
+		return lib.DecodeFunctionError(out.Payload)
 	}
 	return nil
 }
@@ -201,12 +203,12 @@ func (s shipping) GetState() (ShippingState, error) {
 		return *new(ShippingState), err
 	}
 
-	out, _err := LambdaClient.Invoke(&lambda.InvokeInput{FunctionName: aws.String("GetState"), Payload: jsonParam})
+	out, _err := LambdaClient.Invoke(&lambda.InvokeInput{FunctionName: aws.String(FunctionNamePrefix + "GetState"), Payload: jsonParam})
 	if _err != nil {
 		return *new(ShippingState), _err
 	}
 	if out.FunctionError != nil {
-		return *new(ShippingState), fmt.Errorf(string(out.Payload[:]))
+		return *new(ShippingState), lib.DecodeFunctionError(out.Payload)
 	}
 
 	result := new(ShippingState)
@@ -234,12 +236,12 @@ func (s shipping) SetState(newValue ShippingState) error {
 		return err
 	}
 
-	out, _err := LambdaClient.Invoke(&lambda.InvokeInput{FunctionName: aws.String("SetField"), Payload: jsonParam})
+	out, _err := LambdaClient.Invoke(&lambda.InvokeInput{FunctionName: aws.String(FunctionNamePrefix + "SetField"), Payload: jsonParam})
 	if _err != nil {
 		return _err
 	}
 	if out.FunctionError != nil {
-		return fmt.Errorf(string(out.Payload[:]))
+		return lib.DecodeFunctionError(out.Payload)
 	}
 	return nil
 }
@@ -259,12 +261,12 @@ func (s shipping) GetCreationDate() (time.Time, error) {
 		return *new(time.Time), err
 	}
 
-	out, _err := LambdaClient.Invoke(&lambda.InvokeInput{FunctionName: aws.String("GetState"), Payload: jsonParam})
+	out, _err := LambdaClient.Invoke(&lambda.InvokeInput{FunctionName: aws.String(FunctionNamePrefix + "GetState"), Payload: jsonParam})
 	if _err != nil {
 		return *new(time.Time), _err
 	}
 	if out.FunctionError != nil {
-		return *new(time.Time), fmt.Errorf(string(out.Payload[:]))
+		return *new(time.Time), lib.DecodeFunctionError(out.Payload)
 	}
 
 	result := new(time.Time)
@@ -292,12 +294,12 @@ func (s shipping) SetCreationDate(newValue time.Time) error {
 		return err
 	}
 
-	out, _err := LambdaClient.Invoke(&lambda.InvokeInput{FunctionName: aws.String("SetField"), Payload: jsonParam})
+	out, _err := LambdaClient.Invoke(&lambda.InvokeInput{FunctionName: aws.String(FunctionNamePrefix + "SetField"), Payload: jsonParam})
 	if _err != nil {
 		return _err
 	}
 	if out.FunctionError != nil {
-		return fmt.Errorf(string(out.Payload[:]))
+		return lib.DecodeFunctionError(out.Payload)
 	}
 	return nil
 }
@@ -319,12 +321,12 @@ func (r shipping) GetStub() (ShippingStub, error) {
 		return *new(ShippingStub), err
 	}
 
-	out, _err := LambdaClient.Invoke(&lambda.InvokeInput{FunctionName: aws.String("GetState"), Payload: jsonParam})
+	out, _err := LambdaClient.Invoke(&lambda.InvokeInput{FunctionName: aws.String(FunctionNamePrefix + "GetState"), Payload: jsonParam})
 	if _err != nil {
 		return *new(ShippingStub), _err
 	}
 	if out.FunctionError != nil {
-		return *new(ShippingStub), fmt.Errorf(string(out.Payload[:]))
+		return *new(ShippingStub), lib.DecodeFunctionError(out.Payload)
 	}
 
 	result := new(ShippingStub)
@@ -38,12 +38,12 @@ func LoadShop(id string) (*shop, error) {
 		return nil, err
 	}
 
-	out, _err := LambdaClient.Invoke(&lambda.InvokeInput{FunctionName: aws.String("Load"), Payload: jsonParam})
+	out, _err := LambdaClient.Invoke(&lambda.InvokeInput{FunctionName: aws.String(FunctionNamePrefix + "Load"), Payload: jsonParam})
 	if _err != nil {
 		return nil, _err
 	}
 	if out.FunctionError != nil {
-		return nil, fmt.Errorf("lambda function designed to verify if instance exists failed. Error: %s", string(out.Payload))
+		return nil, fmt.Errorf("lambda function designed to verify if instance exists failed. Error: %w", lib.DecodeFunctionError(out.Payload))
 	}
 
 	newInstance.id = id
@@ -85,6 +85,11 @@ func (r *shop) init() {
 func ExportShop(input ShopStub) (*shop, error) {
 	newInstance := new(shop)
 
+	// the same rules are checked by the library before the object is exported
+	if err := lib.Validate(input); err != nil {
+		return nil, err
+	}
+
 	params := lib.HandlerParameters{
 		TypeName:  newInstance.GetTypeName(),
 		Parameter: input,
@@ -94,12 +99,12 @@ func ExportShop(input ShopStub) (*shop, error) {
 		return nil, err
 	}
 
-	out, _err := LambdaClient.Invoke(&lambda.InvokeInput{FunctionName: aws.String("Export"), Payload: jsonParam})
+	out, _err := LambdaClient.Invoke(&lambda.InvokeInput{FunctionName: aws.String(FunctionNamePrefix + "Export"), Payload: jsonParam})
 	if _err != nil {
 		return nil, _err
 	}
 	if out.FunctionError != nil {
-		return nil, fmt.Errorf("lambda function designed to export an object failed. Error: %s", string(out.Payload[:]))
+		return nil, fmt.Errorf("lambda function designed to export an object failed. Error: %w", lib.DecodeFunctionError(out.Payload))
 	}
 
 	newInstance.id, err = strconv.Unquote(string(out.Payload[:]))
@@ -121,17 +126,19 @@ func DeleteShop(id string) error {
 		return err
 	}
 
-	out, _err := LambdaClient.Invoke(&lambda.InvokeInput{FunctionName: aws.String("Delete"), Payload: jsonParam})
+	out, _err := LambdaClient.Invoke(&lambda.InvokeInput{FunctionName: aws.String(FunctionNamePrefix + "Delete"), Payload: jsonParam})
 	if _err != nil {
 		return _err
 	}
 	if out.FunctionError != nil {
-		return fmt.Errorf("lambda function designed to delete an object failed. Error: %s", string(out.Payload))
+		return fmt.Errorf("lambda function designed to delete an object failed. Error: %w", lib.DecodeFunctionError(out.Payload))
 	}
 
 	return nil
 }
 
+// FIND
+
 // GETID
 
 func (s shop) GetId() string {
@@ -161,12 +168,12 @@ func (s shop) GetName() (string, error) {
 		return *new(string), err
 	}
 
-	out, _err := LambdaClient.Invoke(&lambda.InvokeInput{FunctionName: aws.String("GetState"), Payload: jsonParam})
+	out, _err := LambdaClient.Invoke(&lambda.InvokeInput{FunctionName: aws.String(FunctionNamePrefix + "GetState"), Payload: jsonParam})
 	if _err != nil {
 		return *new(string), _err
 	}
 	if out.FunctionError != nil {
-		return *new(string), fmt.Errorf(string(out.Payload[:]))
+		return *new(string), lib.DecodeFunctionError(out.Payload)
 	}
 
 	result := new(string)
@@ -194,12 +201,12 @@ func (s shop) SetName(newValue string) error {
 		return err
 	}
 
-	out, _err := LambdaClient.Invoke(&lambda.InvokeInput{FunctionName: aws.String("SetField"), Payload: jsonParam})
+	out, _err := LambdaClient.Invoke(&lambda.InvokeInput{FunctionName: aws.String(FunctionNamePrefix + "SetField"), Payload: jsonParam})
 	if _err != nil {
 		return _err
 	}
 	if out.FunctionError != nil {
-		return fmt.Errorf(string(out.Payload[:]))
+		return lib.DecodeFunctionError(out.Payload)
 	}
 	return nil
 }
@@ -220,12 +227,12 @@ func (s shop) GetNearestOwnerCopy(input Coordinates) (UserStub, error) {
 		return *new(UserStub), err
 	}
 
-	out, _err := LambdaClient.Invoke(&lambda.InvokeInput{FunctionName: aws.String("ShopGetNearestOwnerCopy"), Payload: jsonParam})
+	out, _err := LambdaClient.Invoke(&lambda.InvokeInput{FunctionName: aws.String(FunctionNamePrefix + "ShopGetNearestOwnerCopy"), Payload: jsonParam})
 	if _err != nil {
 		return *new(UserStub), _err
 	}
 	if out.FunctionError != nil {
-		return *new(UserStub), fmt.Errorf(string(out.Payload[:]))
+		return *new(UserStub), lib.DecodeFunctionError(out.Payload)
 	}
 
 	result := new(UserStub)
@@ -251,12 +258,12 @@ func (s shop) GetNearestOwnerReference(input Coordinates) (lib.Reference[user],
 		return *new(lib.Reference[user]), err
 	}
 
-	out, _err := LambdaClient.Invoke(&lambda.InvokeInput{FunctionName: aws.String("ShopGetNearestOwnerReference"), Payload: jsonParam})
+	out, _err := LambdaClient.Invoke(&lambda.InvokeInput{FunctionName: aws.String(FunctionNamePrefix + "ShopGetNearestOwnerReference"), Payload: jsonParam})
 	if _err != nil {
 		return *new(lib.Reference[user]), _err
 	}
 	if out.FunctionError != nil {
-		return *new(lib.Reference[user]), fmt.Errorf(string(out.Payload[:]))
+		return *new(lib.Reference[user]), lib.DecodeFunctionError(out.Payload)
 	}
 
 	result := new(lib.Reference[user])
@@ -283,12 +290,12 @@ func (r shop) GetStub() (ShopStub, error) {
 		return *new(ShopStub), err
 	}
 
-	out, _err := LambdaClient.Invoke(&lambda.InvokeInput{FunctionName: aws.String("GetState"), Payload: jsonParam})
+	out, _err := LambdaClient.Invoke(&lambda.InvokeInput{FunctionName: aws.String(FunctionNamePrefix + "GetState"), Payload: jsonParam})
 	if _err != nil {
 		return *new(ShopStub), _err
 	}
 	if out.FunctionError != nil {
-		return *new(ShopStub), fmt.Errorf(string(out.Payload[:]))
+		return *new(ShopStub), lib.DecodeFunctionError(out.Payload)
 	}
 
 	result := new(ShopStub)
@@ -43,15 +43,17 @@ func (ShopStub) GetTypeName() string {
 type ProductStub struct {
 	Id string
 
-	Name string
+	Name string `nubes:"index"`
 
-	QuantityAvailable int
+	QuantityAvailable int `nubes:"min=0"`
 
-	SoldBy Reference[shop] `dynamodbav:",omitempty"`
+	SoldBy Reference[shop]
 
 	Discount ReferenceList[discount]
 
-	Price float64
+	Price float64 `nubes:"min=0"`
+
+	Version int `nubes:"version"`
 }
 
 func (ProductStub) GetTypeName() string {
@@ -77,7 +79,7 @@ type UserStub struct {
 
 	LastName string
 
-	Email string `nubes:"id,readonly" dynamodbav:"Id"`
+	Email string `nubes:"id,readonly"`
 
 	Password string `nubes:"readonly"`
 
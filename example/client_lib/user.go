@@ -36,12 +36,12 @@ func LoadUser(id string) (*user, error) {
 		return nil, err
 	}
 
-	out, _err := LambdaClient.Invoke(&lambda.InvokeInput{FunctionName: aws.String("Load"), Payload: jsonParam})
+	out, _err := LambdaClient.Invoke(&lambda.InvokeInput{FunctionName: aws.String(FunctionNamePrefix + "Load"), Payload: jsonParam})
 	if _err != nil {
 		return nil, _err
 	}
 	if out.FunctionError != nil {
-		return nil, fmt.Errorf("lambda function designed to verify if instance exists failed. Error: %s", string(out.Payload))
+		return nil, fmt.Errorf("lambda function designed to verify if instance exists failed. Error: %w", lib.DecodeFunctionError(out.Payload))
 	}
 
 	newInstance.id = id
@@ -75,6 +75,11 @@ func (r *user) init() {
 func ExportUser(input UserStub) (*user, error) {
 	newInstance := new(user)
 
+	// the same rules are checked by the library before the object is exported
+	if err := lib.Validate(input); err != nil {
+		return nil, err
+	}
+
 	params := lib.HandlerParameters{
 		TypeName:  newInstance.GetTypeName(),
 		Parameter: input,
@@ -84,12 +89,12 @@ func ExportUser(input UserStub) (*user, error) {
 		return nil, err
 	}
 
-	out, _err := LambdaClient.Invoke(&lambda.InvokeInput{FunctionName: aws.String("Export"), Payload: jsonParam})
+	out, _err := LambdaClient.Invoke(&lambda.InvokeInput{FunctionName: aws.String(FunctionNamePrefix + "Export"), Payload: jsonParam})
 	if _err != nil {
 		return nil, _err
 	}
 	if out.FunctionError != nil {
-		return nil, fmt.Errorf("lambda function designed to export an object failed. Error: %s", string(out.Payload[:]))
+		return nil, fmt.Errorf("lambda function designed to export an object failed. Error: %w", lib.DecodeFunctionError(out.Payload))
 	}
 
 	newInstance.id, err = strconv.Unquote(string(out.Payload[:]))
@@ -111,17 +116,19 @@ func DeleteUser(id DeleteParam) error {
 		return err
 	}
 
-	out, _err := LambdaClient.Invoke(&lambda.InvokeInput{FunctionName: aws.String("Delete"), Payload: jsonParam})
+	out, _err := LambdaClient.Invoke(&lambda.InvokeInput{FunctionName: aws.String(FunctionNamePrefix + "Delete"), Payload: jsonParam})
 	if _err != nil {
 		return _err
 	}
 	if out.FunctionError != nil {
-		return fmt.Errorf("lambda function designed to delete an object failed. Error: %s", string(out.Payload))
+		return fmt.Errorf("lambda function designed to delete an object failed. Error: %w", lib.DecodeFunctionError(out.Payload))
 	}
 
 	return nil
 }
 
+// FIND
+
 // GETID
 
 func (s user) GetId() string {
@@ -151,12 +158,12 @@ func (s user) GetFirstName() (string, error) {
 		return *new(string), err
 	}
 
-	out, _err := LambdaClient.Invoke(&lambda.InvokeInput{FunctionName: aws.String("GetState"), Payload: jsonParam})
+	out, _err := LambdaClient.Invoke(&lambda.InvokeInput{FunctionName: aws.String(FunctionNamePrefix + "GetState"), Payload: jsonParam})
 	if _err != nil {
 		return *new(string), _err
 	}
 	if out.FunctionError != nil {
-		return *new(string), fmt.Errorf(string(out.Payload[:]))
+		return *new(string), lib.DecodeFunctionError(out.Payload)
 	}
 
 	result := new(string)
@@ -184,12 +191,12 @@ func (s user) SetFirstName(newValue string) error {
 		return err
 	}
 
-	out, _err := LambdaClient.Invoke(&lambda.InvokeInput{FunctionName: aws.String("SetField"), Payload: jsonParam})
+	out, _err := LambdaClient.Invoke(&lambda.InvokeInput{FunctionName: aws.String(FunctionNamePrefix + "SetField"), Payload: jsonParam})
 	if _err != nil {
 		return _err
 	}
 	if out.FunctionError != nil {
-		return fmt.Errorf(string(out.Payload[:]))
+		return lib.DecodeFunctionError(out.Payload)
 	}
 	return nil
 }
@@ -209,12 +216,12 @@ func (s user) GetLastName() (string, error) {
 		return *new(string), err
 	}
 
-	out, _err := LambdaClient.Invoke(&lambda.InvokeInput{FunctionName: aws.String("GetState"), Payload: jsonParam})
+	out, _err := LambdaClient.Invoke(&lambda.InvokeInput{FunctionName: aws.String(FunctionNamePrefix + "GetState"), Payload: jsonParam})
 	if _err != nil {
 		return *new(string), _err
 	}
 	if out.FunctionError != nil {
-		return *new(string), fmt.Errorf(string(out.Payload[:]))
+		return *new(string), lib.DecodeFunctionError(out.Payload)
 	}
 
 	result := new(string)
@@ -242,12 +249,12 @@ func (s user) SetLastName(newValue string) error {
 		return err
 	}
 
-	out, _err := LambdaClient.Invoke(&lambda.InvokeInput{FunctionName: aws.String("SetField"), Payload: jsonParam})
+	out, _err := LambdaClient.Invoke(&lambda.InvokeInput{FunctionName: aws.String(FunctionNamePrefix + "SetField"), Payload: jsonParam})
 	if _err != nil {
 		return _err
 	}
 	if out.FunctionError != nil {
-		return fmt.Errorf(string(out.Payload[:]))
+		return lib.DecodeFunctionError(out.Payload)
 	}
 	return nil
 }
@@ -267,12 +274,12 @@ func (s user) GetEmail() (string, error) {
 		return *new(string), err
 	}
 
-	out, _err := LambdaClient.Invoke(&lambda.InvokeInput{FunctionName: aws.String("GetState"), Payload: jsonParam})
+	out, _err := LambdaClient.Invoke(&lambda.InvokeInput{FunctionName: aws.String(FunctionNamePrefix + "GetState"), Payload: jsonParam})
 	if _err != nil {
 		return *new(string), _err
 	}
 	if out.FunctionError != nil {
-		return *new(string), fmt.Errorf(string(out.Payload[:]))
+		return *new(string), lib.DecodeFunctionError(out.Payload)
 	}
 
 	result := new(string)
@@ -299,12 +306,12 @@ func (s user) GetPassword() (string, error) {
 		return *new(string), err
 	}
 
-	out, _err := LambdaClient.Invoke(&lambda.InvokeInput{FunctionName: aws.String("GetState"), Payload: jsonParam})
+	out, _err := LambdaClient.Invoke(&lambda.InvokeInput{FunctionName: aws.String(FunctionNamePrefix + "GetState"), Payload: jsonParam})
 	if _err != nil {
 		return *new(string), _err
 	}
 	if out.FunctionError != nil {
-		return *new(string), fmt.Errorf(string(out.Payload[:]))
+		return *new(string), lib.DecodeFunctionError(out.Payload)
 	}
 
 	result := new(string)
@@ -331,12 +338,12 @@ func (s user) GetAddressText() (string, error) {
 		return *new(string), err
 	}
 
-	out, _err := LambdaClient.Invoke(&lambda.InvokeInput{FunctionName: aws.String("GetState"), Payload: jsonParam})
+	out, _err := LambdaClient.Invoke(&lambda.InvokeInput{FunctionName: aws.String(FunctionNamePrefix + "GetState"), Payload: jsonParam})
 	if _err != nil {
 		return *new(string), _err
 	}
 	if out.FunctionError != nil {
-		return *new(string), fmt.Errorf(string(out.Payload[:]))
+		return *new(string), lib.DecodeFunctionError(out.Payload)
 	}
 
 	result := new(string)
@@ -364,12 +371,12 @@ func (s user) SetAddressText(newValue string) error {
 		return err
 	}
 
-	out, _err := LambdaClient.Invoke(&lambda.InvokeInput{FunctionName: aws.String("SetField"), Payload: jsonParam})
+	out, _err := LambdaClient.Invoke(&lambda.InvokeInput{FunctionName: aws.String(FunctionNamePrefix + "SetField"), Payload: jsonParam})
 	if _err != nil {
 		return _err
 	}
 	if out.FunctionError != nil {
-		return fmt.Errorf(string(out.Payload[:]))
+		return lib.DecodeFunctionError(out.Payload)
 	}
 	return nil
 }
@@ -389,12 +396,12 @@ func (s user) GetAddressCoordinates() (Coordinates, error) {
 		return *new(Coordinates), err
 	}
 
-	out, _err := LambdaClient.Invoke(&lambda.InvokeInput{FunctionName: aws.String("GetState"), Payload: jsonParam})
+	out, _err := LambdaClient.Invoke(&lambda.InvokeInput{FunctionName: aws.String(FunctionNamePrefix + "GetState"), Payload: jsonParam})
 	if _err != nil {
 		return *new(Coordinates), _err
 	}
 	if out.FunctionError != nil {
-		return *new(Coordinates), fmt.Errorf(string(out.Payload[:]))
+		return *new(Coordinates), lib.DecodeFunctionError(out.Payload)
 	}
 
 	result := new(Coordinates)
@@ -422,12 +429,12 @@ func (s user) SetAddressCoordinates(newValue Coordinates) error {
 		return err
 	}
 
-	out, _err := LambdaClient.Invoke(&lambda.InvokeInput{FunctionName: aws.String("SetField"), Payload: jsonParam})
+	out, _err := LambdaClient.Invoke(&lambda.InvokeInput{FunctionName: aws.String(FunctionNamePrefix + "SetField"), Payload: jsonParam})
 	if _err != nil {
 		return _err
 	}
 	if out.FunctionError != nil {
-		return fmt.Errorf(string(out.Payload[:]))
+		return lib.DecodeFunctionError(out.Payload)
 	}
 	return nil
 }
@@ -448,12 +455,12 @@ func (s user) GetOrdersIds() ([]string, error) {
 		return nil, err
 	}
 
-	out, _err := LambdaClient.Invoke(&lambda.InvokeInput{FunctionName: aws.String("GetState"), Payload: jsonParam})
+	out, _err := LambdaClient.Invoke(&lambda.InvokeInput{FunctionName: aws.String(FunctionNamePrefix + "GetState"), Payload: jsonParam})
 	if _err != nil {
 		return nil, _err
 	}
 	if out.FunctionError != nil {
-		return nil, fmt.Errorf(string(out.Payload[:]))
+		return nil, lib.DecodeFunctionError(out.Payload)
 	}
 
 	var result []string
@@ -480,12 +487,12 @@ func (s user) GetOrders() ([]order, error) {
 		return nil, err
 	}
 
-	out, _err := LambdaClient.Invoke(&lambda.InvokeInput{FunctionName: aws.String("GetState"), Payload: jsonParam})
+	out, _err := LambdaClient.Invoke(&lambda.InvokeInput{FunctionName: aws.String(FunctionNamePrefix + "GetState"), Payload: jsonParam})
 	if _err != nil {
 		return nil, _err
 	}
 	if out.FunctionError != nil {
-		return nil, fmt.Errorf(string(out.Payload[:]))
+		return nil, lib.DecodeFunctionError(out.Payload)
 	}
 
 	var ids []string
@@ -502,6 +509,7 @@ func (s user) GetOrders() ([]order, error) {
 
 	return result, nil
 }
+
 func (s user) SetOrders(ids []string) error {
 	if s.id == "" {
 		return errors.New("id of the type not set, use LoadUser or ExportUser to create new instance of the type")
@@ -518,12 +526,12 @@ func (s user) SetOrders(ids []string) error {
 		return err
 	}
 
-	out, _err := LambdaClient.Invoke(&lambda.InvokeInput{FunctionName: aws.String("SetField"), Payload: jsonParam})
+	out, _err := LambdaClient.Invoke(&lambda.InvokeInput{FunctionName: aws.String(FunctionNamePrefix + "SetField"), Payload: jsonParam})
 	if _err != nil {
 		return _err
 	}
 	if out.FunctionError != nil {
-		return fmt.Errorf(string(out.Payload[:]))
+		return lib.DecodeFunctionError(out.Payload)
 	}
 	return nil
 }
@@ -544,12 +552,12 @@ func (u user) VerifyPassword(input string) (bool, error) {
 		return *new(bool), err
 	}
 
-	out, _err := LambdaClient.Invoke(&lambda.InvokeInput{FunctionName: aws.String("UserVerifyPassword"), Payload: jsonParam})
+	out, _err := LambdaClient.Invoke(&lambda.InvokeInput{FunctionName: aws.String(FunctionNamePrefix + "UserVerifyPassword"), Payload: jsonParam})
 	if _err != nil {
 		return *new(bool), _err
 	}
 	if out.FunctionError != nil {
-		return *new(bool), fmt.Errorf(string(out.Payload[:]))
+		return *new(bool), lib.DecodeFunctionError(out.Payload)
 	}
 
 	result := new(bool)
@@ -576,12 +584,12 @@ func (r user) GetStub() (UserStub, error) {
 		return *new(UserStub), err
 	}
 
-	out, _err := LambdaClient.Invoke(&lambda.InvokeInput{FunctionName: aws.String("GetState"), Payload: jsonParam})
+	out, _err := LambdaClient.Invoke(&lambda.InvokeInput{FunctionName: aws.String(FunctionNamePrefix + "GetState"), Payload: jsonParam})
 	if _err != nil {
 		return *new(UserStub), _err
 	}
 	if out.FunctionError != nil {
-		return *new(UserStub), fmt.Errorf(string(out.Payload[:]))
+		return *new(UserStub), lib.DecodeFunctionError(out.Payload)
 	}
 
 	result := new(UserStub)
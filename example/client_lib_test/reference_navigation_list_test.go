@@ -4,7 +4,7 @@ import (
 	"testing"
 
 	clib "github.com/Astenna/Nubes/example/client_lib"
-	"github.com/Astenna/Nubes/example/faas/types"
+	"github.com/Astenna/Nubes/example/faas/nubes/types"
 	"github.com/Astenna/Nubes/lib"
 	"github.com/stretchr/testify/require"
 
@@ -7,12 +7,10 @@ import (
 )
 
 type Order struct {
-	Id              string
-	Products        []OrderedProduct
-	Buyer           lib.Reference[User]
-	Shipping        lib.Reference[Shipping]
-	isInitialized   bool
-	invocationDepth int
+	Id       string
+	Products []OrderedProduct
+	Buyer    lib.Reference[User]
+	Shipping lib.Reference[Shipping]
 }
 
 type OrderedProduct struct {
@@ -20,45 +18,47 @@ type OrderedProduct struct {
 	Quantity int
 }
 
+// ExportOrder decreases the availability of the ordered products, creates
+// the shipping and the order in a transaction, so that either all or none
+// of the changes are saved
 func ExportOrder(order Order) (string, error) {
+	var orderId string
 
-	for _, orderedProduct := range order.Products {
-		product, err := orderedProduct.Product.Get()
+	err := lib.RunInTransaction(func(tx *lib.Tx) error {
+		for _, orderedProduct := range order.Products {
+			product, err := orderedProduct.Product.Get()
+			if err != nil {
+				return errors.New("item " + orderedProduct.Product.Id() + " not available")
+			}
+			if err = product.DecreaseAvailabilityBy(orderedProduct.Quantity); err != nil {
+				return errors.New("item " + orderedProduct.Product.Id() + " not available: " + err.Error())
+			}
+		}
+
+		buyer, err := order.Buyer.Get()
 		if err != nil {
-			return "", errors.New("item " + orderedProduct.Product.Id() + " not available")
+			return errors.New("unable to retrieve user's address for shipping")
+		}
+		shipping, err := lib.Export[Shipping](Shipping{
+			State:   InPreparation,
+			Address: buyer.AddressText,
+		})
+		if err != nil {
+			return errors.New("failed to create shipping for the order: " + err.Error())
 		}
-		product.DecreaseAvailabilityBy(orderedProduct.Quantity)
-	}
 
-	buyer, err := order.Buyer.Get()
-	if err != nil {
-		return "", errors.New("unable to retrieve user's address for shipping")
-	}
-	shipping, err := lib.Export[Shipping](Shipping{
-		State:   InPreparation,
-		Address: buyer.AddressText,
+		order.Shipping = lib.Reference[Shipping](shipping.Id)
+		exportedOrder, err := lib.Export[Order](order)
+		if err != nil {
+			return err
+		}
+		orderId = exportedOrder.Id
+		return nil
 	})
-	if err != nil {
-		return "", errors.New("failed to create shipping for the order: " + err.Error())
-	}
 
-	order.Shipping = lib.Reference[Shipping](shipping.Id)
-	exportedOrder, err := lib.Export[Order](order)
-	return exportedOrder.Id, err
+	return orderId, err
 }
 
 func (o Order) GetTypeName() string {
 	return "Order"
 }
-func (receiver *Order) Init() {
-	receiver.isInitialized = true
-}
-func (receiver *Order) saveChangesIfInitialized() error {
-	if receiver.isInitialized && receiver.invocationDepth == 1 {
-		_libError := lib.Upsert(receiver, receiver.Id)
-		if _libError != nil {
-			return _libError
-		}
-	}
-	return nil
-}
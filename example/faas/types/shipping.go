@@ -16,12 +16,10 @@ const (
 )
 
 type Shipping struct {
-	Id              string
-	Address         string
-	State           ShippingState
-	CreationDate    time.Time
-	isInitialized   bool
-	invocationDepth int
+	Id           string
+	Address      string
+	State        ShippingState
+	CreationDate time.Time
 }
 
 func (s Shipping) GetTypeName() string {
@@ -43,16 +41,3 @@ func ExportShipping(addr string) (string, error) {
 	exported, err := lib.Export[Shipping](newShipping)
 	return exported.Id, err
 }
-
-func (receiver *Shipping) Init() {
-	receiver.isInitialized = true
-}
-func (receiver *Shipping) saveChangesIfInitialized() error {
-	if receiver.isInitialized && receiver.invocationDepth == 1 {
-		_libError := lib.Upsert(receiver, receiver.Id)
-		if _libError != nil {
-			return _libError
-		}
-	}
-	return nil
-}
@@ -1,7 +1,6 @@
 package types
 
 import (
-	"fmt"
 	"math"
 
 	"github.com/Astenna/Nubes/lib"
@@ -9,12 +8,10 @@ import (
 )
 
 type Shop struct {
-	Id              string
-	Name            string
-	Owners          lib.ReferenceNavigationList[User]    `nubes:"hasMany-Shops" dynamodbav:"-"`
-	Products        lib.ReferenceNavigationList[Product] `nubes:"hasOne-SoldBy,readonly" dynamodbav:"-"`
-	isInitialized   bool
-	invocationDepth int
+	Id       string
+	Name     string
+	Owners   lib.ReferenceNavigationList[User]    `nubes:"hasMany-Shops" dynamodbav:"-"`
+	Products lib.ReferenceNavigationList[Product] `nubes:"hasOne-SoldBy,readonly,sortedBy-Price" dynamodbav:"-"`
 }
 
 func (Shop) GetTypeName() string {
@@ -22,27 +19,13 @@ func (Shop) GetTypeName() string {
 }
 
 func (s Shop) GetOwners() ([]string, error) {
-	if !s.isInitialized {
-		return nil, fmt.Errorf(`fields of type ReferenceNavigationList can be used only after instance initialization. 
-			Use lib.Load or lib.Export from the Nubes library to create initialized instances`)
-	}
 	return s.Owners.GetIds()
 }
 
 // Example of a method returning a Nobject
 func (s Shop) GetNearestOwnerCopy(point Coordinates) (User, error) {
-	s.invocationDepth++
-	if s.isInitialized && s.invocationDepth == 1 {
-		_libError := lib.GetStub(s.Id, &s)
-		if _libError != nil {
-			s.invocationDepth--
-			return *new(User), _libError
-		}
-	}
-
 	owners, err := s.Owners.GetStubs()
 	if err != nil {
-		s.invocationDepth--
 		return *new(User), err
 	}
 
@@ -63,24 +46,14 @@ func (s Shop) GetNearestOwnerCopy(point Coordinates) (User, error) {
 			closestOwner = owner
 		}
 	}
-	s.invocationDepth--
 
 	return closestOwner, nil
 }
 
 // Example of a method returning a Nobject's reference
 func (s Shop) GetNearestOwnerReference(point Coordinates) (lib.Reference[User], error) {
-	s.invocationDepth++
-	if s.isInitialized && s.invocationDepth == 1 {
-		_libError := lib.GetStub(s.Id, &s)
-		if _libError != nil {
-			s.invocationDepth--
-			return *new(lib.Reference[User]), _libError
-		}
-	}
 	owners, err := s.Owners.GetStubs()
 	if err != nil {
-		s.invocationDepth--
 		return *new(lib.Reference[User]), err
 	}
 
@@ -101,7 +74,6 @@ func (s Shop) GetNearestOwnerReference(point Coordinates) (lib.Reference[User],
 			closestOwner = owner
 		}
 	}
-	s.invocationDepth--
 
 	return *lib.NewReference[User](closestOwner.Email), nil
 }
@@ -128,17 +100,3 @@ func DeleteShop(id string) error {
 
 	return lib.Delete[Shop](id)
 }
-func (receiver *Shop) Init() {
-	receiver.isInitialized = true
-	receiver.Products = *lib.NewReferenceNavigationList[Product](lib.ReferenceNavigationListParam{OwnerId: receiver.Id, OwnerTypeName: receiver.GetTypeName(), OtherTypeName: (*new(Product)).GetTypeName(), ReferringFieldName: "SoldBy", IsManyToMany: false})
-	receiver.Owners = *lib.NewReferenceNavigationList[User](lib.ReferenceNavigationListParam{OwnerId: receiver.Id, OwnerTypeName: receiver.GetTypeName(), OtherTypeName: (*new(User)).GetTypeName(), ReferringFieldName: "Owners", IsManyToMany: true})
-}
-func (receiver *Shop) saveChangesIfInitialized() error {
-	if receiver.isInitialized && receiver.invocationDepth == 1 {
-		_libError := lib.Upsert(receiver, receiver.Id)
-		if _libError != nil {
-			return _libError
-		}
-	}
-	return nil
-}
@@ -14,14 +14,12 @@ type Coordinates struct {
 type User struct {
 	FirstName          string
 	LastName           string
-	Email              string `nubes:"id,readonly" dynamodbav:"Id"`
+	Email              string `nubes:"id,readonly"`
 	Password           string `nubes:"readonly"`
 	AddressText        string
 	AddressCoordinates Coordinates
 	Shops              lib.ReferenceNavigationList[Shop] `nubes:"hasMany-Owners" dynamodbav:"-"`
 	Orders             lib.ReferenceList[Order]
-	isInitialized      bool
-	invocationDepth    int
 }
 
 type DeleteParam struct {
@@ -59,62 +57,20 @@ func (u User) GetId() string {
 
 func (u *User) SetLastName(lastName string) error {
 	u.LastName = lastName
-	if u.isInitialized {
-		_libError := lib.SetField(lib.SetFieldParam{Id: u.Email, TypeName: "User", FieldName: "LastName", Value: u.LastName})
-		if _libError != nil {
-			return _libError
-		}
-	}
 	return nil
 }
 
 func (u *User) GetLastName() (string, error) {
-	if u.isInitialized {
-		fieldValue := *new(string)
-		_libError := lib.GetFieldOfType(lib.GetStateParam{Id: u.Email, TypeName: "User", FieldName: "LastName"}, &fieldValue)
-		if _libError != nil {
-			return *new(string), _libError
-		}
-		u.LastName = fieldValue
-	}
 	return u.LastName, nil
 }
 
 func (u User) GetShops() ([]string, error) {
-	if !u.isInitialized {
-		return nil, fmt.Errorf(`fields of type ReferenceNavigationList can be used only after instance initialization. 
-			Use lib.Load or lib.Export from the Nubes library to create initialized instances`)
-	}
 	return u.Shops.GetIds()
 }
 
 func (u User) VerifyPassword(password string) (bool, error) {
-	u.invocationDepth++
-	if u.isInitialized && u.invocationDepth == 1 {
-		_libError := lib.GetStub(u.Email, &u)
-		if _libError != nil {
-			u.invocationDepth--
-			return *new(bool), _libError
-		}
-	}
-
 	if u.Password == password {
-		u.invocationDepth--
 		return true, nil
 	}
-	u.invocationDepth--
 	return false, nil
 }
-func (receiver *User) Init() {
-	receiver.isInitialized = true
-	receiver.Shops = *lib.NewReferenceNavigationList[Shop](lib.ReferenceNavigationListParam{OwnerId: receiver.Email, OwnerTypeName: receiver.GetTypeName(), OtherTypeName: (*new(Shop)).GetTypeName(), ReferringFieldName: "Shops", IsManyToMany: true})
-}
-func (receiver *User) saveChangesIfInitialized() error {
-	if receiver.isInitialized && receiver.invocationDepth == 1 {
-		_libError := lib.Upsert(receiver, receiver.Email)
-		if _libError != nil {
-			return _libError
-		}
-	}
-	return nil
-}
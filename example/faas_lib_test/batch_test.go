@@ -0,0 +1,149 @@
+package faas_lib_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/Astenna/Nubes/example/faas/nubes/types"
+	"github.com/Astenna/Nubes/lib"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+// throttlingStore processes at most limit keys or writes of
+// each batch request, the rest is returned as unprocessed
+type throttlingStore struct {
+	lib.Store
+	limit int
+}
+
+func (s throttlingStore) BatchGetItemWithContext(ctx aws.Context, input *dynamodb.BatchGetItemInput, opts ...request.Option) (*dynamodb.BatchGetItemOutput, error) {
+	processed := map[string]*dynamodb.KeysAndAttributes{}
+	unprocessed := map[string]*dynamodb.KeysAndAttributes{}
+	for tableName, request := range input.RequestItems {
+		keys := request.Keys
+		if len(keys) > s.limit {
+			unprocessed[tableName] = &dynamodb.KeysAndAttributes{Keys: keys[s.limit:], ProjectionExpression: request.ProjectionExpression}
+			keys = keys[:s.limit]
+		}
+		processed[tableName] = &dynamodb.KeysAndAttributes{Keys: keys, ProjectionExpression: request.ProjectionExpression}
+	}
+
+	output, err := s.Store.BatchGetItemWithContext(ctx, &dynamodb.BatchGetItemInput{RequestItems: processed}, opts...)
+	if err != nil {
+		return nil, err
+	}
+	output.UnprocessedKeys = unprocessed
+	return output, nil
+}
+
+func (s throttlingStore) BatchWriteItemWithContext(ctx aws.Context, input *dynamodb.BatchWriteItemInput, opts ...request.Option) (*dynamodb.BatchWriteItemOutput, error) {
+	processed := map[string][]*dynamodb.WriteRequest{}
+	unprocessed := map[string][]*dynamodb.WriteRequest{}
+	for tableName, requests := range input.RequestItems {
+		if len(requests) > s.limit {
+			unprocessed[tableName] = requests[s.limit:]
+			requests = requests[:s.limit]
+		}
+		processed[tableName] = requests
+	}
+
+	if _, err := s.Store.BatchWriteItemWithContext(ctx, &dynamodb.BatchWriteItemInput{RequestItems: processed}, opts...); err != nil {
+		return nil, err
+	}
+	return &dynamodb.BatchWriteItemOutput{UnprocessedItems: unprocessed}, nil
+}
+
+func useThrottlingStore(t *testing.T, limit int) {
+	if testStore == nil {
+		t.Skip("unprocessed items can not be simulated on dynamodb")
+	}
+	lib.SetStore(throttlingStore{Store: testStore, limit: limit})
+	t.Cleanup(func() { lib.SetStore(testStore) })
+}
+
+func exportProducts(t *testing.T, count int, name string) []string {
+	ids := make([]string, count)
+	for i := range ids {
+		exported, err := lib.Export[types.Product](types.Product{Name: fmt.Sprintf("%s%d", name, i), QuantityAvailable: i})
+		require.Equal(t, nil, err, "error occurred in Export invocation", err)
+		ids[i] = exported.Id
+	}
+	return ids
+}
+
+func TestGetStubsInBatchReturnsObjectsInOrderOfIdsAboveBatchLimit(t *testing.T) {
+	// Arrange
+	ids := exportProducts(t, 230, "TestBatchLimit")
+	for i, j := 0, len(ids)-1; i < j; i, j = i+1, j-1 {
+		ids[i], ids[j] = ids[j], ids[i]
+	}
+
+	// Act
+	products, err := lib.GetStubsInBatch[types.Product](ids)
+
+	// Assert
+	require.Equal(t, nil, err, "error occurred in GetStubsInBatch invocation", err)
+	require.Len(t, *products, len(ids))
+	for i, product := range *products {
+		require.Equal(t, ids[i], product.Id)
+	}
+}
+
+func TestGetStubsInBatchRetriesUnprocessedKeys(t *testing.T) {
+	// Arrange
+	ids := exportProducts(t, 10, "TestBatchUnprocessedKeys")
+	ids = append(ids, uuid.NewString(), ids[0])
+	useThrottlingStore(t, 3)
+
+	// Act
+	products, err := lib.GetStubsInBatch[types.Product](ids)
+
+	// Assert
+	require.Equal(t, nil, err, "error occurred in GetStubsInBatch invocation", err)
+	require.Len(t, *products, 11)
+	for i, product := range (*products)[:10] {
+		require.Equal(t, ids[i], product.Id)
+		require.Equal(t, i, product.QuantityAvailable)
+	}
+	require.Equal(t, ids[0], (*products)[10].Id)
+}
+
+func TestAreInstancesAlreadyCreatedReportsMissingIdsAboveBatchLimit(t *testing.T) {
+	// Arrange
+	ids := exportProducts(t, 120, "TestBatchMissing")
+	missingId := uuid.NewString()
+	useThrottlingStore(t, 50)
+
+	// Act
+	err := lib.AreInstancesAlreadyCreated(lib.LoadBatchParam{TypeName: "Product", Ids: append(ids, missingId)})
+
+	// Assert
+	require.Equal(t, lib.NotFoundError{TypeName: "Product", Ids: []string{missingId}}, err)
+}
+
+func TestDeleteBatchFromManyToManyRetriesUnprocessedItems(t *testing.T) {
+	// Arrange
+	exportedUser, err := lib.Export[types.User](types.User{Email: uuid.NewString(), FirstName: "TestBatchDelete"})
+	require.Equal(t, nil, err, "error occurred in Export[types.User]", err)
+	shopIds := make([]string, 30)
+	for i := range shopIds {
+		exportedShop, err := lib.Export[types.Shop](types.Shop{Name: "ShopTestBatchDelete"})
+		require.Equal(t, nil, err, "error occurred in Export[types.Shop] invocation", err)
+		require.Equal(t, nil, exportedUser.Shops.AddToManyToMany(exportedShop.Id))
+		shopIds[i] = exportedShop.Id
+	}
+	useThrottlingStore(t, 10)
+
+	// Act
+	err = exportedUser.Shops.DeleteBatchFromManyToMany(shopIds)
+
+	// Assert
+	require.Equal(t, nil, err, "error occurred in exportedUser.Shops.DeleteBatchFromManyToMany", err)
+	ids, err := exportedUser.Shops.GetIds()
+	require.Equal(t, nil, err, "error occurred in exportedUser.Shops.GetIds", err)
+	require.Empty(t, ids)
+}
@@ -0,0 +1,69 @@
+package faas_lib_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Astenna/Nubes/example/faas/nubes/types"
+	"github.com/Astenna/Nubes/lib"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/stretchr/testify/require"
+)
+
+// usePrefixedTables sets the store with the tables of Shop and Product whose
+// names start with the prefix, the configuration is reset in the cleanup
+func usePrefixedTables(t *testing.T, tablePrefix string) *lib.MemoryStore {
+	store := lib.NewMemoryStore()
+	for _, table := range []*dynamodb.CreateTableInput{nobjectTable("Shop"), nobjectTable("Product", "SoldBy")} {
+		table.TableName = aws.String(tablePrefix + *table.TableName)
+		_, err := store.CreateTable(table)
+		require.Equal(t, nil, err, "error occurred in CreateTable", err)
+	}
+
+	lib.SetStore(store)
+	t.Cleanup(func() {
+		lib.Configure(lib.Config{})
+		lib.SetStore(testStore)
+	})
+	return store
+}
+
+func TestTablePrefixIsUsedForAllTables(t *testing.T) {
+	// Arrange
+	store := usePrefixedTables(t, "dev-")
+	lib.Configure(lib.Config{TablePrefix: "dev-"})
+
+	// Act
+	exportedShop, err := lib.Export[types.Shop](types.Shop{Name: "ShopTestTablePrefix"})
+	require.Equal(t, nil, err, "error occurred in Export[types.Shop] invocation", err)
+	exportedProduct, err := lib.Export[types.Product](types.Product{Name: "TestTablePrefix", SoldBy: lib.Reference[types.Shop](exportedShop.Id)})
+	require.Equal(t, nil, err, "error occurred in Export[types.Product] invocation", err)
+	ids, err := exportedShop.Products.GetIds()
+
+	// Assert
+	require.Equal(t, nil, err, "error occurred in exportedShop.Products.GetIds", err)
+	require.Equal(t, []string{exportedProduct.Id}, ids)
+	output, err := store.GetItemWithContext(context.Background(), &dynamodb.GetItemInput{
+		TableName: aws.String("dev-Product"),
+		Key:       map[string]*dynamodb.AttributeValue{"Id": {S: aws.String(exportedProduct.Id)}},
+	})
+	require.Equal(t, nil, err, "error occurred in GetItem", err)
+	require.NotNil(t, output.Item)
+}
+
+func TestTablePrefixDefaultsToEnvironmentVariable(t *testing.T) {
+	// Arrange
+	usePrefixedTables(t, "prod-")
+	t.Setenv(lib.TablePrefixEnvVariable, "prod-")
+	lib.Configure(lib.Config{})
+
+	// Act
+	exported, err := lib.Export[types.Shop](types.Shop{Name: "ShopTestTablePrefix"})
+	require.Equal(t, nil, err, "error occurred in Export[types.Shop] invocation", err)
+	loaded, err := lib.Load[types.Shop](exported.Id)
+
+	// Assert
+	require.Equal(t, nil, err, "error occurred in Load[types.Shop] invocation", err)
+	require.Equal(t, exported.Id, loaded.Id)
+}
@@ -0,0 +1,60 @@
+package faas_lib_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Astenna/Nubes/example/faas/nubes/types"
+	"github.com/Astenna/Nubes/lib"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadWithCancelledContextReturnsError(t *testing.T) {
+	// Arrange
+	exported, err := lib.Export[types.Product](types.Product{Name: "TestContext"})
+	require.Equal(t, nil, err, "error occurred in Export invocation", err)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// Act
+	_, err = lib.LoadWithContext[types.Product](ctx, exported.Id)
+
+	// Assert
+	require.Error(t, err)
+}
+
+func TestOperationsWithoutContextUseInvocationContext(t *testing.T) {
+	// Arrange
+	exportedShop, _ := exportShopWithProducts(t, 2)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	lib.SetInvocationContext(ctx)
+	t.Cleanup(func() { lib.SetInvocationContext(nil) })
+
+	// Act
+	_, errCancelled := exportedShop.Products.GetStubs()
+	lib.SetInvocationContext(nil)
+	products, err := exportedShop.Products.GetStubs()
+
+	// Assert
+	require.Error(t, errCancelled)
+	require.Equal(t, nil, err, "error occurred in exportedShop.Products.GetStubs", err)
+	require.Len(t, products, 2)
+}
+
+func TestContextDeadlineStopsRetriesOfUnprocessedKeys(t *testing.T) {
+	// Arrange
+	ids := exportProducts(t, 10, "TestContextRetries")
+	useThrottlingStore(t, 1)
+	// the deadline is exceeded before the first retry,
+	// which is delayed by at least half of the base delay
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	// Act
+	_, err := lib.GetStubsInBatchWithContext[types.Product](ctx, ids)
+
+	// Assert
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+}
@@ -0,0 +1,94 @@
+package faas_lib_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/Astenna/Nubes/example/faas/nubes/types"
+	"github.com/Astenna/Nubes/lib"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+var errOutOfStock = errors.New("product out of stock")
+
+func init() {
+	lib.RegisterError("OutOfStock", errOutOfStock)
+}
+
+// invokeWithErrorEnvelope invokes the handler wrapped as in the generated
+// handlers and returns the payload of the failed lambda function invocation
+func invokeWithErrorEnvelope(t *testing.T, handler func(context.Context, string) (string, error)) []byte {
+	wrapped := lib.WithErrorEnvelope(handler).(func(context.Context, string) (string, error))
+	_, err := wrapped(context.Background(), "input")
+	require.Error(t, err)
+
+	payload, marshalErr := json.Marshal(map[string]string{"errorMessage": err.Error(), "errorType": "envelopedError"})
+	require.NoError(t, marshalErr)
+	return payload
+}
+
+func TestDecodedFunctionErrorIsNotFoundError(t *testing.T) {
+	// Arrange
+	missingId := uuid.New().String()
+	payload := invokeWithErrorEnvelope(t, func(ctx context.Context, _ string) (string, error) {
+		_, err := lib.LoadWithContext[types.Product](ctx, missingId)
+		return "", err
+	})
+
+	// Act
+	err := lib.DecodeFunctionError(payload)
+
+	// Assert
+	require.Equal(t, lib.NotFoundError{TypeName: "Product", Ids: []string{missingId}}, err)
+}
+
+func TestDecodedFunctionErrorIsAlreadyExistsError(t *testing.T) {
+	// Arrange
+	exported, err := lib.Export[types.User](types.User{Email: uuid.New().String()})
+	require.NoError(t, err)
+	payload := invokeWithErrorEnvelope(t, func(ctx context.Context, _ string) (string, error) {
+		_, err := lib.ExportWithContext[types.User](ctx, types.User{Email: exported.Email})
+		return "", fmt.Errorf("export failed: %w", err)
+	})
+
+	// Act
+	err = lib.DecodeFunctionError(payload)
+
+	// Assert
+	var alreadyExists lib.AlreadyExistsError
+	require.ErrorAs(t, err, &alreadyExists)
+	require.Equal(t, exported.Email, alreadyExists.Id)
+	require.Contains(t, err.Error(), "export failed: ")
+}
+
+func TestDecodedFunctionErrorMatchesRegisteredSentinelError(t *testing.T) {
+	// Arrange
+	payload := invokeWithErrorEnvelope(t, func(context.Context, string) (string, error) {
+		return "", fmt.Errorf("cannot sell the product: %w", errOutOfStock)
+	})
+
+	// Act
+	err := lib.DecodeFunctionError(payload)
+
+	// Assert
+	require.ErrorIs(t, err, errOutOfStock)
+	require.Equal(t, "cannot sell the product: product out of stock", err.Error())
+}
+
+func TestDecodedFunctionErrorKeepsMessageOfNotRegisteredError(t *testing.T) {
+	// Arrange
+	payload := invokeWithErrorEnvelope(t, func(context.Context, string) (string, error) {
+		return "", errors.New("something went wrong")
+	})
+
+	// Act
+	err := lib.DecodeFunctionError(payload)
+
+	// Assert
+	require.EqualError(t, err, "something went wrong")
+	require.False(t, lib.IsConflictError(err))
+}
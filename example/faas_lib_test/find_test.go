@@ -0,0 +1,52 @@
+package faas_lib_test
+
+import (
+	"testing"
+
+	"github.com/Astenna/Nubes/example/faas/nubes/types"
+	"github.com/Astenna/Nubes/lib"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindByReturnsObjectsWithIndexedFieldEqualToValue(t *testing.T) {
+	// Arrange
+	name := "TestFindBy" + uuid.NewString()
+	first, err := lib.Export[types.Product](types.Product{Name: name})
+	require.Equal(t, nil, err, "error occurred in Export invocation", err)
+	second, err := lib.Export[types.Product](types.Product{Name: name})
+	require.Equal(t, nil, err, "error occurred in Export invocation", err)
+	_, err = lib.Export[types.Product](types.Product{Name: name + "Other"})
+	require.Equal(t, nil, err, "error occurred in Export invocation", err)
+
+	// Act
+	found, err := lib.FindBy[types.Product]("Name", name)
+
+	// Assert
+	require.Equal(t, nil, err, "error occurred in FindBy invocation", err)
+	foundIds := make([]string, len(found))
+	for i, product := range found {
+		foundIds[i] = product.Id
+		foundName, err := product.GetName()
+		require.Equal(t, nil, err, "error occurred in GetName invocation", err)
+		require.Equal(t, name, foundName)
+	}
+	require.ElementsMatch(t, []string{first.Id, second.Id}, foundIds)
+}
+
+func TestFindIdsByReturnsEmptyListIfNothingMatches(t *testing.T) {
+	// Act
+	ids, err := lib.FindIdsBy(lib.FindByParam{TypeName: "Product", FieldName: "Name", Value: uuid.NewString()})
+
+	// Assert
+	require.Equal(t, nil, err, "error occurred in FindIdsBy invocation", err)
+	require.Empty(t, ids)
+}
+
+func TestFindIdsByReturnsErrorIfValueIsMissing(t *testing.T) {
+	// Act
+	_, err := lib.FindIdsBy(lib.FindByParam{TypeName: "Product", FieldName: "Name"})
+
+	// Assert
+	require.Error(t, err)
+}
@@ -0,0 +1,162 @@
+package faas_lib_test
+
+import (
+	"testing"
+
+	"github.com/Astenna/Nubes/lib"
+	"github.com/stretchr/testify/require"
+)
+
+type country struct {
+	Id       string
+	Name     string
+	Regions  lib.ReferenceNavigationList[region]  `nubes:"hasOne-Country,onDelete=cascade" dynamodbav:"-"`
+	Tourists lib.ReferenceNavigationList[tourist] `nubes:"hasMany-Countries,onDelete=nullify" dynamodbav:"-"`
+}
+
+func (country) GetTypeName() string {
+	return "Country"
+}
+
+type region struct {
+	Id        string
+	Country   lib.Reference[country]                `dynamodbav:",omitempty"`
+	Landmarks lib.ReferenceNavigationList[landmark] `nubes:"hasOne-Region,onDelete=nullify" dynamodbav:"-"`
+	Cities    lib.ReferenceNavigationList[city]     `nubes:"hasOne-Region,onDelete=restrict" dynamodbav:"-"`
+}
+
+func (region) GetTypeName() string {
+	return "Region"
+}
+
+type landmark struct {
+	Id     string
+	Region lib.Reference[region] `dynamodbav:",omitempty"`
+}
+
+func (landmark) GetTypeName() string {
+	return "Landmark"
+}
+
+type city struct {
+	Id     string
+	Region lib.Reference[region] `dynamodbav:",omitempty"`
+}
+
+func (city) GetTypeName() string {
+	return "City"
+}
+
+type tourist struct {
+	Id        string
+	Countries lib.ReferenceNavigationList[country] `nubes:"hasMany-Tourists" dynamodbav:"-"`
+}
+
+func (tourist) GetTypeName() string {
+	return "Tourist"
+}
+
+func exists(t *testing.T, typeName, id string) bool {
+	exists, err := lib.IsInstanceAlreadyCreated(lib.IsInstanceAlreadyCreatedParam{Id: id, TypeName: typeName})
+	require.NoError(t, err)
+	return exists
+}
+
+func exportRegions(t *testing.T, countryId string, count int) []string {
+	ids := make([]string, count)
+	for i := range ids {
+		exported, err := lib.Export[region](region{Country: lib.Reference[country](countryId)})
+		require.NoError(t, err)
+		ids[i] = exported.Id
+	}
+	return ids
+}
+
+func TestDeleteCascadesToRelatedObjects(t *testing.T) {
+	// Arrange
+	exportedCountry, err := lib.Export[country](country{Name: "TestDeleteCascade"})
+	require.NoError(t, err)
+	regionIds := exportRegions(t, exportedCountry.Id, 2)
+	exportedTourist, err := lib.Export[tourist](tourist{})
+	require.NoError(t, err)
+	tourists := lib.NewReferenceNavigationList[tourist](lib.ReferenceNavigationListParam{OwnerId: exportedCountry.Id,
+		OwnerTypeName: "Country", OtherTypeName: "Tourist", ReferringFieldName: "Tourists", IsManyToMany: true})
+	require.NoError(t, tourists.AddToManyToMany(exportedTourist.Id))
+
+	// Act
+	err = lib.Delete[country](exportedCountry.Id)
+
+	// Assert
+	require.NoError(t, err)
+	require.False(t, exists(t, "Country", exportedCountry.Id))
+	for _, id := range regionIds {
+		require.False(t, exists(t, "Region", id))
+	}
+	require.True(t, exists(t, "Tourist", exportedTourist.Id))
+	countries := lib.NewReferenceNavigationList[country](lib.ReferenceNavigationListParam{OwnerId: exportedTourist.Id,
+		OwnerTypeName: "Tourist", OtherTypeName: "Country", ReferringFieldName: "Countries", IsManyToMany: true})
+	countryIds, err := countries.GetIds()
+	require.NoError(t, err)
+	require.Empty(t, countryIds)
+}
+
+func TestDeleteNullifiesReferencesOfRelatedObjects(t *testing.T) {
+	// Arrange
+	exportedRegion, err := lib.Export[region](region{})
+	require.NoError(t, err)
+	exportedLandmark, err := lib.Export[landmark](landmark{Region: lib.Reference[region](exportedRegion.Id)})
+	require.NoError(t, err)
+
+	// Act
+	err = lib.Delete[region](exportedRegion.Id)
+
+	// Assert
+	require.NoError(t, err)
+	require.False(t, exists(t, "Region", exportedRegion.Id))
+	stored := landmark{}
+	require.NoError(t, lib.GetStub(exportedLandmark.Id, &stored))
+	require.Empty(t, stored.Region)
+}
+
+func TestDeleteRestrictedByRelatedObjectsDeletesNothing(t *testing.T) {
+	// Arrange
+	exportedCountry, err := lib.Export[country](country{Name: "TestDeleteRestrict"})
+	require.NoError(t, err)
+	regionId := exportRegions(t, exportedCountry.Id, 1)[0]
+	_, err = lib.Export[city](city{Region: lib.Reference[region](regionId)})
+	require.NoError(t, err)
+
+	// Act
+	err = lib.Delete[country](exportedCountry.Id)
+
+	// Assert
+	require.Equal(t, lib.DeleteRestrictedError{Id: regionId, TypeName: "Region", FieldName: "Cities"}, err)
+	require.True(t, exists(t, "Country", exportedCountry.Id))
+	require.True(t, exists(t, "Region", regionId))
+}
+
+func TestDeleteCascadesBeyondTransactionWritesLimit(t *testing.T) {
+	// Arrange
+	exportedCountry, err := lib.Export[country](country{Name: "TestDeleteCascadeLimit"})
+	require.NoError(t, err)
+	regionIds := exportRegions(t, exportedCountry.Id, lib.TransactionWritesLimit+10)
+
+	// Act
+	err = lib.Delete[country](exportedCountry.Id)
+
+	// Assert
+	require.NoError(t, err)
+	require.False(t, exists(t, "Country", exportedCountry.Id))
+	for _, id := range regionIds {
+		require.False(t, exists(t, "Region", id))
+	}
+}
+
+func TestDeleteWithPoliciesOfMissingObjectFails(t *testing.T) {
+	// Arrange
+	// Act
+	err := lib.Delete[country]("missing-country-id")
+
+	// Assert
+	require.EqualError(t, err, "delete failed. Instance of Country with id: missing-country-id not found")
+}
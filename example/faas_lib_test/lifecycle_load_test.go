@@ -3,7 +3,7 @@ package faas_lib_test
 import (
 	"testing"
 
-	"github.com/Astenna/Nubes/example/faas/types"
+	"github.com/Astenna/Nubes/example/faas/nubes/types"
 	"github.com/Astenna/Nubes/lib"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/require"
@@ -80,3 +80,53 @@ func TestLoadStateChangingMethodsShouldSaveChanges(t *testing.T) {
 	// Assert
 	require.Equal(t, modifiedQuantity, initialQuantityAvailable-decreaseBy, "QuantityAvailable was not modified")
 }
+
+func TestLoadStateChangingMethodsShouldNotSaveChangesOnError(t *testing.T) {
+	// Arrange
+	initialQuantityAvailable := 3
+	product := types.Product{
+		Name:              "Product1",
+		QuantityAvailable: initialQuantityAvailable,
+		Price:             88.88,
+	}
+	exportedProduct, exportError := lib.Export[types.Product](product)
+	require.Equal(t, nil, exportError, "error occurred while exporting the product in arrange step", exportError)
+
+	// Act
+	failedInvocationError := exportedProduct.DecreaseAvailabilityBy(initialQuantityAvailable + 1)
+	invocationError := exportedProduct.DecreaseAvailabilityBy(1)
+	require.Equal(t, nil, invocationError, "error occurred while invoking method after the failed invocation", invocationError)
+	modifiedQuantity, quantityRetrievalError := exportedProduct.GetQuantityAvailable()
+	require.Equal(t, nil, quantityRetrievalError, "error occurred while exucting GetQuantityAvailable", quantityRetrievalError)
+
+	// Assert
+	require.NotNil(t, failedInvocationError, "expected the error returned by the method")
+	require.Equal(t, initialQuantityAvailable-1, modifiedQuantity, "the failed invocation should not leave the object in an inconsistent state")
+}
+
+func TestLoadStateChangingMethodsWithSeveralResultsShouldSaveChanges(t *testing.T) {
+	// Arrange
+	initialQuantityAvailable := 10
+	restockBy := 5
+	newPrice := 99.99
+	product := types.Product{
+		Name:              "Product1",
+		QuantityAvailable: initialQuantityAvailable,
+		Price:             88.88,
+	}
+	exportedProduct, exportError := lib.Export[types.Product](product)
+	require.Equal(t, nil, exportError, "error occurred while exporting the product in arrange step", exportError)
+
+	// Act
+	loadedProduct, loadExistingProductError := lib.Load[types.Product](exportedProduct.Id)
+	require.Equal(t, nil, loadExistingProductError, "error occurred while loading existing product", loadExistingProductError)
+	quantity, price, methodInvocationError := loadedProduct.Restock(restockBy, newPrice)
+	require.Equal(t, nil, methodInvocationError, "error occurred while invoking method on product instances", methodInvocationError)
+	modifiedQuantity, quantityRetrievalError := exportedProduct.GetQuantityAvailable()
+	require.Equal(t, nil, quantityRetrievalError, "error occurred while exucting GetQuantityAvailable", quantityRetrievalError)
+
+	// Assert
+	require.Equal(t, initialQuantityAvailable+restockBy, quantity, "the first result of the method was not returned")
+	require.Equal(t, newPrice, price, "the second result of the method was not returned")
+	require.Equal(t, initialQuantityAvailable+restockBy, modifiedQuantity, "QuantityAvailable was not modified")
+}
@@ -0,0 +1,212 @@
+package faas_lib_test
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/Astenna/Nubes/lib"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunMigrationTransformsItems(t *testing.T) {
+	// Arrange
+	putMigrationTestItem(t, "Subscriber", map[string]*dynamodb.AttributeValue{
+		"Id":     {S: aws.String("subscriber-1")},
+		"Mail":   {S: aws.String("subscriber@nubes.com")},
+		"Points": {S: aws.String("12")},
+	})
+	putMigrationTestItem(t, "Subscriber", map[string]*dynamodb.AttributeValue{
+		"Id":     {S: aws.String("subscriber-2")},
+		"Email":  {S: aws.String("migrated@nubes.com")},
+		"Points": {N: aws.String("3")},
+		"Active": {BOOL: aws.Bool(false)},
+	})
+
+	// Act
+	report, err := lib.RunMigration([]lib.MigrationStep{
+		lib.RenameAttribute("Subscriber", "Mail", "Email"),
+		lib.ConvertAttribute("Subscriber", "Points", lib.NumberAttributeType),
+		lib.FillDefault("Subscriber", "Active", true),
+	}, lib.MigrationOptions{})
+
+	// Assert
+	require.NoError(t, err)
+	require.Equal(t, 2, report.Scanned["Subscriber"])
+	require.Equal(t, 1, report.Changed["Subscriber"])
+	migrated := getMigrationTestItem(t, "Subscriber", "subscriber-1")
+	require.Nil(t, migrated["Mail"])
+	require.Equal(t, "subscriber@nubes.com", aws.StringValue(migrated["Email"].S))
+	require.Equal(t, "12", aws.StringValue(migrated["Points"].N))
+	require.True(t, aws.BoolValue(migrated["Active"].BOOL))
+	unchanged := getMigrationTestItem(t, "Subscriber", "subscriber-2")
+	require.False(t, aws.BoolValue(unchanged["Active"].BOOL))
+}
+
+func TestRunMigrationInDryRunDoesNotWriteItems(t *testing.T) {
+	// Arrange
+	putMigrationTestItem(t, "Invoice", map[string]*dynamodb.AttributeValue{
+		"Id":    {S: aws.String("invoice-1")},
+		"Total": {N: aws.String("10")},
+	})
+
+	// Act
+	report, err := lib.RunMigration([]lib.MigrationStep{
+		lib.RenameAttribute("Invoice", "Total", "Amount"),
+	}, lib.MigrationOptions{DryRun: true})
+
+	// Assert
+	require.NoError(t, err)
+	require.Equal(t, 1, report.Changed["Invoice"])
+	item := getMigrationTestItem(t, "Invoice", "invoice-1")
+	require.Equal(t, "10", aws.StringValue(item["Total"].N))
+	require.Nil(t, item["Amount"])
+}
+
+func TestRunMigrationResumesFromRecordedProgress(t *testing.T) {
+	// Arrange
+	for i := 0; i < 5; i++ {
+		putMigrationTestItem(t, "Ticket", map[string]*dynamodb.AttributeValue{
+			"Id":    {S: aws.String("ticket-" + strconv.Itoa(i))},
+			"Price": {N: aws.String(strconv.Itoa(i))},
+		})
+	}
+	options := lib.MigrationOptions{ProgressPath: filepath.Join(t.TempDir(), "progress.json"), PageSize: 2}
+	interrupted := lib.TransformItems("Ticket", "fail on ticket-3", func(item map[string]*dynamodb.AttributeValue) (bool, error) {
+		if aws.StringValue(item["Id"].S) == "ticket-3" {
+			return false, errors.New("interrupted")
+		}
+		return false, nil
+	})
+	steps := []lib.MigrationStep{lib.ConvertAttribute("Ticket", "Price", lib.StringAttributeType)}
+
+	// Act
+	_, interruptedErr := lib.RunMigration(append(steps, interrupted), options)
+	report, err := lib.RunMigration(steps, options)
+	completedReport, completedErr := lib.RunMigration(steps, options)
+
+	// Assert
+	require.Error(t, interruptedErr)
+	require.NoError(t, err)
+	require.Equal(t, 3, report.Scanned["Ticket"])
+	require.NoError(t, completedErr)
+	require.Equal(t, 0, completedReport.Scanned["Ticket"])
+	for i := 0; i < 5; i++ {
+		item := getMigrationTestItem(t, "Ticket", "ticket-"+strconv.Itoa(i))
+		require.Equal(t, strconv.Itoa(i), aws.StringValue(item["Price"].S))
+	}
+}
+
+func TestRunMigrationTransformsAgainItemOfNotVersionedTypeModifiedAfterScan(t *testing.T) {
+	// Arrange
+	putMigrationTestItem(t, "Receipt", map[string]*dynamodb.AttributeValue{
+		"Id":    {S: aws.String("receipt-1")},
+		"Total": {N: aws.String("10")},
+		"Note":  {S: aws.String("initial")},
+	})
+	modified := false
+	rename := lib.RenameAttribute("Receipt", "Total", "Amount")
+	modifyAfterScan := lib.TransformItems("Receipt", "modify the item after the scan", func(item map[string]*dynamodb.AttributeValue) (bool, error) {
+		if !modified {
+			modified = true
+			putMigrationTestItem(t, "Receipt", map[string]*dynamodb.AttributeValue{
+				"Id":    {S: aws.String("receipt-1")},
+				"Total": {N: aws.String("10")},
+				"Note":  {S: aws.String("modified")},
+			})
+		}
+		return false, nil
+	})
+
+	// Act
+	report, err := lib.RunMigration([]lib.MigrationStep{modifyAfterScan, rename}, lib.MigrationOptions{})
+
+	// Assert
+	require.NoError(t, err)
+	require.Equal(t, 1, report.Changed["Receipt"])
+	item := getMigrationTestItem(t, "Receipt", "receipt-1")
+	require.Equal(t, "modified", aws.StringValue(item["Note"].S))
+	require.Equal(t, "10", aws.StringValue(item["Amount"].N))
+	require.Nil(t, item["Total"])
+}
+
+func TestRunMigrationWithOtherStepsMigratesTypesCompletedByPreviousMigration(t *testing.T) {
+	// Arrange
+	putMigrationTestItem(t, "Voucher", map[string]*dynamodb.AttributeValue{
+		"Id":   {S: aws.String("voucher-1")},
+		"Code": {S: aws.String("SPRING")},
+	})
+	options := lib.MigrationOptions{ProgressPath: filepath.Join(t.TempDir(), "progress.json")}
+	_, err := lib.RunMigration([]lib.MigrationStep{lib.RenameAttribute("Voucher", "Code", "Name")}, options)
+	require.NoError(t, err)
+
+	// Act
+	report, err := lib.RunMigration([]lib.MigrationStep{lib.RenameAttribute("Voucher", "Name", "Title")}, options)
+
+	// Assert
+	require.NoError(t, err)
+	require.Equal(t, 1, report.Changed["Voucher"])
+	item := getMigrationTestItem(t, "Voucher", "voucher-1")
+	require.Nil(t, item["Name"])
+	require.Equal(t, "SPRING", aws.StringValue(item["Title"].S))
+}
+
+func TestRunMigrationIncrementsVersionOfMigratedItems(t *testing.T) {
+	// Arrange
+	putMigrationTestItem(t, "Coupon", map[string]*dynamodb.AttributeValue{
+		"Id":      {S: aws.String("coupon-1")},
+		"Code":    {S: aws.String("SUMMER")},
+		"Version": {N: aws.String("3")},
+	})
+
+	// Act
+	_, err := lib.RunMigration([]lib.MigrationStep{lib.RenameAttribute("Coupon", "Code", "Name")}, lib.MigrationOptions{})
+
+	// Assert
+	require.NoError(t, err)
+	item := getMigrationTestItem(t, "Coupon", "coupon-1")
+	require.Equal(t, "SUMMER", aws.StringValue(item["Name"].S))
+	require.Equal(t, "4", aws.StringValue(item["Version"].N))
+}
+
+func TestRunMigrationWithUnknownAttributeTypeFails(t *testing.T) {
+	// Arrange
+	putMigrationTestItem(t, "Invoice", map[string]*dynamodb.AttributeValue{
+		"Id":     {S: aws.String("invoice-2")},
+		"Number": {S: aws.String("2")},
+	})
+
+	// Act
+	_, err := lib.RunMigration([]lib.MigrationStep{
+		lib.ConvertAttribute("Invoice", "Number", "SS"),
+	}, lib.MigrationOptions{DryRun: true})
+
+	// Assert
+	require.Error(t, err)
+}
+
+func putMigrationTestItem(t *testing.T, tableName string, item map[string]*dynamodb.AttributeValue) {
+	if testStore == nil {
+		t.Skip("the items of the previous schema are put directly into the test store")
+	}
+	_, err := testStore.PutItemWithContext(context.Background(), &dynamodb.PutItemInput{
+		TableName: aws.String(tableName),
+		Item:      item,
+	})
+	require.NoError(t, err)
+}
+
+func getMigrationTestItem(t *testing.T, tableName, id string) map[string]*dynamodb.AttributeValue {
+	output, err := testStore.GetItemWithContext(context.Background(), &dynamodb.GetItemInput{
+		TableName:      aws.String(tableName),
+		Key:            map[string]*dynamodb.AttributeValue{"Id": {S: aws.String(id)}},
+		ConsistentRead: aws.Bool(true),
+	})
+	require.NoError(t, err)
+	require.NotNil(t, output.Item)
+	return output.Item
+}
@@ -0,0 +1,133 @@
+package faas_lib_test
+
+import (
+	"testing"
+
+	"github.com/Astenna/Nubes/example/faas/nubes/types"
+	"github.com/Astenna/Nubes/lib"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+// pagingStore returns at most pageSize items in each page of
+// the query results, like DynamoDB does for the results above 1 MB
+type pagingStore struct {
+	lib.Store
+	pageSize int64
+}
+
+func (s pagingStore) QueryWithContext(ctx aws.Context, input *dynamodb.QueryInput, opts ...request.Option) (*dynamodb.QueryOutput, error) {
+	paged := *input
+	if paged.Limit == nil || *paged.Limit > s.pageSize {
+		paged.Limit = aws.Int64(s.pageSize)
+	}
+	return s.Store.QueryWithContext(ctx, &paged, opts...)
+}
+
+func usePagingStore(t *testing.T, pageSize int64) {
+	if testStore == nil {
+		t.Skip("pages of query results can not be simulated on dynamodb")
+	}
+	lib.SetStore(pagingStore{Store: testStore, pageSize: pageSize})
+	t.Cleanup(func() { lib.SetStore(testStore) })
+}
+
+func exportShopWithProducts(t *testing.T, productsCount int) (*types.Shop, []string) {
+	exportedShop, err := lib.Export[types.Shop](types.Shop{Name: "ShopTestPagination"})
+	require.Equal(t, nil, err, "error occurred in Export[types.Shop] invocation", err)
+
+	productIds := make([]string, productsCount)
+	for i := range productIds {
+		exported, err := lib.Export[types.Product](types.Product{Name: "TestPagination", SoldBy: lib.Reference[types.Shop](exportedShop.Id)})
+		require.Equal(t, nil, err, "error occurred in Export[types.Product]", err)
+		productIds[i] = exported.Id
+	}
+	return exportedShop, productIds
+}
+
+func TestGetIdsFollowsAllPages(t *testing.T) {
+	// Arrange
+	exportedShop, productIds := exportShopWithProducts(t, 7)
+	usePagingStore(t, 2)
+
+	// Act
+	ids, err := exportedShop.Products.GetIds()
+
+	// Assert
+	require.Equal(t, nil, err, "error occurred in exportedShop.Products.GetIds", err)
+	require.ElementsMatch(t, productIds, ids)
+}
+
+func TestGetPageIteratesOverOneToManyRelationship(t *testing.T) {
+	// Arrange
+	exportedShop, productIds := exportShopWithProducts(t, 7)
+	usePagingStore(t, 2)
+	var retrievedIds []string
+	var pageSizes []int
+
+	// Act
+	cursor := ""
+	for {
+		page, nextCursor, err := exportedShop.Products.GetPage(3, cursor)
+		require.Equal(t, nil, err, "error occurred in exportedShop.Products.GetPage", err)
+		pageSizes = append(pageSizes, len(page))
+		for _, product := range page {
+			retrievedIds = append(retrievedIds, product.Id)
+		}
+		if nextCursor == "" {
+			break
+		}
+		cursor = nextCursor
+	}
+
+	// Assert
+	require.Equal(t, []int{3, 3, 1}, pageSizes)
+	require.ElementsMatch(t, productIds, retrievedIds)
+}
+
+func TestGetPageIteratesOverManyToManyRelationship(t *testing.T) {
+	// Arrange
+	exportedShop, err := lib.Export[types.Shop](types.Shop{Name: "ShopTestPaginationOwners"})
+	require.Equal(t, nil, err, "error occurred in Export[types.Shop] invocation", err)
+	ownerIds := make([]string, 5)
+	for i := range ownerIds {
+		exportedUser, err := lib.Export[types.User](types.User{Email: uuid.NewString(), FirstName: "TestPagination"})
+		require.Equal(t, nil, err, "error occurred in Export[types.User]", err)
+		require.Equal(t, nil, exportedShop.Owners.AddToManyToMany(exportedUser.Email))
+		ownerIds[i] = exportedUser.Email
+	}
+	handlers := lib.NewReferenceNavigationListHandlers(lib.ReferenceNavigationListParam{
+		OwnerId:            exportedShop.Id,
+		OwnerTypeName:      "Shop",
+		OtherTypeName:      "User",
+		ReferringFieldName: "Shops",
+		IsManyToMany:       true,
+	})
+
+	// Act
+	firstPage, err := handlers.GetPage(3, "")
+	require.Equal(t, nil, err, "error occurred in handlers.GetPage", err)
+	secondPage, err := handlers.GetPage(3, firstPage.Cursor)
+	require.Equal(t, nil, err, "error occurred in handlers.GetPage", err)
+
+	// Assert
+	require.Len(t, firstPage.Ids, 3)
+	require.NotEmpty(t, firstPage.Cursor)
+	require.Len(t, secondPage.Ids, 2)
+	require.Empty(t, secondPage.Cursor)
+	require.ElementsMatch(t, ownerIds, append(firstPage.Ids, secondPage.Ids...))
+}
+
+func TestGetPageReturnsErrorIfCursorIsInvalid(t *testing.T) {
+	// Arrange
+	exportedShop, _ := exportShopWithProducts(t, 1)
+
+	// Act
+	_, _, err := exportedShop.Products.GetPage(3, "invalid")
+
+	// Assert
+	require.Error(t, err)
+}
@@ -0,0 +1,95 @@
+package faas_lib_test
+
+import (
+	"testing"
+
+	"github.com/Astenna/Nubes/example/faas/nubes/types"
+	"github.com/Astenna/Nubes/lib"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpsertPreservesReadonlyFields(t *testing.T) {
+	// Arrange
+	email := uuid.NewString()
+	_, err := lib.Export[types.User](types.User{Email: email, Password: "initial", FirstName: "Kinga"})
+	require.NoError(t, err)
+	user := types.User{}
+	require.NoError(t, lib.GetStub(email, &user))
+
+	// Act
+	user.Password = "overwritten"
+	user.FirstName = "Marek"
+	err = lib.Upsert(&user, email)
+
+	// Assert
+	require.NoError(t, err)
+	stored := types.User{}
+	require.NoError(t, lib.GetStub(email, &stored))
+	require.Equal(t, "initial", stored.Password)
+	require.Equal(t, "Marek", stored.FirstName)
+}
+
+func TestUpsertSetsReadonlyFieldsOfNewObject(t *testing.T) {
+	// Arrange
+	email := uuid.NewString()
+
+	// Act
+	err := lib.Upsert(&types.User{Email: email, Password: "initial", LastName: "Marek"}, email)
+
+	// Assert
+	require.NoError(t, err)
+	stored := types.User{}
+	require.NoError(t, lib.GetStub(email, &stored))
+	require.Equal(t, "initial", stored.Password)
+	require.Equal(t, "Marek", stored.LastName)
+}
+
+func TestSaveChangesPreservesReadonlyFields(t *testing.T) {
+	// Arrange
+	email := uuid.NewString()
+	_, err := lib.Export[types.User](types.User{Email: email, Password: "initial"})
+	require.NoError(t, err)
+	user := types.User{}
+	var snapshot lib.Snapshot
+	require.NoError(t, lib.GetStubWithSnapshot(email, &user, &snapshot))
+
+	// Act
+	user.Password = ""
+	user.AddressText = "Amsterdam"
+	err = lib.SaveChanges(&user, email, snapshot)
+
+	// Assert
+	require.NoError(t, err)
+	stored := types.User{}
+	require.NoError(t, lib.GetStub(email, &stored))
+	require.Equal(t, "initial", stored.Password)
+	require.Equal(t, "Amsterdam", stored.AddressText)
+}
+
+func TestSetFieldOfReadonlyFieldIsRejected(t *testing.T) {
+	// Arrange
+	email := uuid.NewString()
+	_, err := lib.Export[types.User](types.User{Email: email, Password: "initial"})
+	require.NoError(t, err)
+
+	// Act
+	err = lib.SetField(lib.SetFieldParam{Id: email, TypeName: "User", FieldName: "Password", Value: "overwritten"})
+
+	// Assert
+	require.Equal(t, lib.ReadonlyFieldError{TypeName: "User", FieldName: "Password"}, err)
+	stored := types.User{}
+	require.NoError(t, lib.GetStub(email, &stored))
+	require.Equal(t, "initial", stored.Password)
+}
+
+func TestCheckFieldIsWritableRejectsReadonlyFields(t *testing.T) {
+	// Arrange
+	// Act
+	readonlyErr := lib.CheckFieldIsWritable(types.User{}, "Password")
+	writableErr := lib.CheckFieldIsWritable(types.User{}, "LastName")
+
+	// Assert
+	require.Equal(t, lib.ReadonlyFieldError{TypeName: "User", FieldName: "Password"}, readonlyErr)
+	require.NoError(t, writableErr)
+}
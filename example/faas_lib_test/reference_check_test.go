@@ -0,0 +1,123 @@
+package faas_lib_test
+
+import (
+	"testing"
+
+	"github.com/Astenna/Nubes/example/faas/nubes/types"
+	"github.com/Astenna/Nubes/lib"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+type promotion struct {
+	Shop      lib.Reference[types.Shop] `nubes:"noReferenceCheck"`
+	Discounts lib.ReferenceList[types.Discount]
+}
+
+func TestExportWithMissingReferenceReturnsReferenceNotFoundError(t *testing.T) {
+	// Arrange
+	missingShopId := uuid.NewString()
+	product := types.Product{Name: "TestMissingReference", SoldBy: lib.Reference[types.Shop](missingShopId)}
+
+	// Act
+	exported, err := lib.Export[types.Product](product)
+
+	// Assert
+	require.Equal(t, lib.ReferenceNotFoundError{TypeName: "Product", FieldName: "SoldBy", ReferencedTypeName: "Shop", Ids: []string{missingShopId}}, err)
+	require.Empty(t, exported.Id)
+}
+
+func TestUpsertWithMissingReferencesNamesOnlyMissingIds(t *testing.T) {
+	// Arrange
+	exportedDiscount, err := lib.Export[types.Discount](types.Discount{Percentage: "10"})
+	require.NoError(t, err)
+	missingIds := []string{uuid.NewString(), uuid.NewString()}
+	product := types.Product{Name: "TestMissingReferences", Discount: lib.NewReferenceList[types.Discount](append([]string{exportedDiscount.Id}, missingIds...))}
+	productId := uuid.NewString()
+
+	// Act
+	err = lib.Upsert(&product, productId)
+
+	// Assert
+	var referenceErr lib.ReferenceNotFoundError
+	require.ErrorAs(t, err, &referenceErr)
+	require.Equal(t, "Discount", referenceErr.FieldName)
+	require.Equal(t, missingIds, referenceErr.Ids)
+	exists, err := lib.IsInstanceAlreadyCreated(lib.IsInstanceAlreadyCreatedParam{Id: productId, TypeName: "Product"})
+	require.NoError(t, err)
+	require.False(t, exists)
+}
+
+func TestSetterWithMissingReferenceDoesNotSaveIt(t *testing.T) {
+	// Arrange
+	exportedShop, err := lib.Export[types.Shop](types.Shop{Name: "TestSetterReference"})
+	require.NoError(t, err)
+	exportedProduct, err := lib.Export[types.Product](types.Product{Name: "TestSetterReference", SoldBy: lib.Reference[types.Shop](exportedShop.Id)})
+	require.NoError(t, err)
+	product, err := lib.Load[types.Product](exportedProduct.Id)
+	require.NoError(t, err)
+
+	// Act
+	err = product.SetSoldBy(uuid.NewString())
+
+	// Assert
+	require.IsType(t, lib.ReferenceNotFoundError{}, err)
+	stored := types.Product{}
+	require.NoError(t, lib.GetStub(exportedProduct.Id, &stored))
+	require.Equal(t, exportedShop.Id, stored.SoldBy.Id())
+}
+
+func TestReferenceToObjectExportedInTransactionIsAccepted(t *testing.T) {
+	// Arrange
+	var productId string
+
+	// Act
+	err := lib.RunInTransaction(func(tx *lib.Tx) error {
+		exportedShop, err := lib.Export[types.Shop](types.Shop{Name: "TestTransactionReference"})
+		if err != nil {
+			return err
+		}
+		exportedProduct, err := lib.Export[types.Product](types.Product{Name: "TestTransactionReference", SoldBy: lib.Reference[types.Shop](exportedShop.Id)})
+		if err != nil {
+			return err
+		}
+		productId = exportedProduct.Id
+		return nil
+	})
+
+	// Assert
+	require.NoError(t, err)
+	stored := types.Product{}
+	require.NoError(t, lib.GetStub(productId, &stored))
+	require.NotEmpty(t, stored.SoldBy.Id())
+}
+
+func TestCheckReferencesSkipsFieldsTaggedWithNoReferenceCheck(t *testing.T) {
+	// Arrange
+	missingDiscountId := uuid.NewString()
+	unchecked := promotion{Shop: lib.Reference[types.Shop](uuid.NewString())}
+	checked := promotion{Discounts: lib.NewReferenceList[types.Discount]([]string{missingDiscountId})}
+
+	// Act
+	uncheckedErr := lib.CheckReferences(unchecked)
+	checkedErr := lib.CheckReferences(&checked)
+
+	// Assert
+	require.NoError(t, uncheckedErr)
+	require.EqualError(t, checkedErr, "field Discounts of promotion refers to instances of Discount that do not exist, ids: "+missingDiscountId)
+}
+
+func TestCheckFieldReferencesChecksValueDecodedFromJSON(t *testing.T) {
+	// Arrange
+	exportedDiscount, err := lib.Export[types.Discount](types.Discount{Percentage: "5"})
+	require.NoError(t, err)
+	missingDiscountId := uuid.NewString()
+	// the values set by the clients are decoded from JSON
+	var value interface{} = []interface{}{exportedDiscount.Id, missingDiscountId}
+
+	// Act
+	err = lib.CheckFieldReferences(types.Product{}, "Discount", value)
+
+	// Assert
+	require.Equal(t, lib.ReferenceNotFoundError{TypeName: "Product", FieldName: "Discount", ReferencedTypeName: "Discount", Ids: []string{missingDiscountId}}, err)
+}
@@ -0,0 +1,74 @@
+package faas_lib_test
+
+import (
+	"testing"
+
+	"github.com/Astenna/Nubes/example/faas/nubes/types"
+	"github.com/Astenna/Nubes/lib"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSaveChangesWritesOnlyModifiedFields(t *testing.T) {
+	// Arrange
+	exported, err := lib.Export[types.Product](types.Product{Name: "TestSnapshotProduct", QuantityAvailable: 10, Price: 5})
+	require.Equal(t, nil, err, "error occurred in Export invocation", err)
+	product := types.Product{}
+	var snapshot lib.Snapshot
+	require.Equal(t, nil, lib.GetStubWithSnapshot(exported.Id, &product, &snapshot))
+	err = lib.SetField(lib.SetFieldParam{Id: exported.Id, TypeName: "Product", FieldName: "Name", Value: "TestSnapshotProductRenamed"})
+	require.Equal(t, nil, err, "error occurred in SetField invocation", err)
+
+	// Act
+	product.QuantityAvailable = 3
+	err = lib.SaveChanges(&product, exported.Id, snapshot)
+
+	// Assert
+	require.Equal(t, nil, err, "error occurred in SaveChanges invocation", err)
+	require.Equal(t, 1, product.Version)
+	stored := types.Product{}
+	require.Equal(t, nil, lib.GetStub(exported.Id, &stored))
+	require.Equal(t, "TestSnapshotProductRenamed", stored.Name)
+	require.Equal(t, 3, stored.QuantityAvailable)
+	require.Equal(t, 5.0, stored.Price)
+	require.Equal(t, 1, stored.Version)
+}
+
+func TestSaveChangesDoesNotWriteIfNothingModified(t *testing.T) {
+	// Arrange
+	exported, err := lib.Export[types.Product](types.Product{Name: "TestSnapshotNotModified"})
+	require.Equal(t, nil, err, "error occurred in Export invocation", err)
+	product := types.Product{}
+	var snapshot lib.Snapshot
+	require.Equal(t, nil, lib.GetStubWithSnapshot(exported.Id, &product, &snapshot))
+
+	// Act
+	err = lib.SaveChanges(&product, exported.Id, snapshot)
+
+	// Assert
+	require.Equal(t, nil, err, "error occurred in SaveChanges invocation", err)
+	stored := types.Product{}
+	require.Equal(t, nil, lib.GetStub(exported.Id, &stored))
+	require.Equal(t, 0, stored.Version)
+}
+
+func TestSaveChangesReturnsNotFoundErrorIfObjectWasDeleted(t *testing.T) {
+	// Arrange
+	email := uuid.NewString()
+	_, err := lib.Export[types.User](types.User{Email: email, FirstName: "Kinga"})
+	require.Equal(t, nil, err, "error occurred in Export invocation", err)
+	user := types.User{}
+	var snapshot lib.Snapshot
+	require.Equal(t, nil, lib.GetStubWithSnapshot(email, &user, &snapshot))
+	require.Equal(t, nil, lib.Delete[types.User](email))
+
+	// Act
+	user.FirstName = "Marek"
+	err = lib.SaveChanges(&user, email, snapshot)
+
+	// Assert
+	require.IsType(t, lib.NotFoundError{}, err)
+	exists, err := lib.IsInstanceAlreadyCreated(lib.IsInstanceAlreadyCreatedParam{Id: email, TypeName: user.GetTypeName()})
+	require.Equal(t, nil, err, "error occurred in IsInstanceAlreadyCreated invocation", err)
+	require.False(t, exists, "deleted user should not be recreated")
+}
@@ -0,0 +1,79 @@
+package faas_lib_test
+
+import (
+	"testing"
+
+	"github.com/Astenna/Nubes/example/faas/nubes/types"
+	"github.com/Astenna/Nubes/lib"
+	"github.com/stretchr/testify/require"
+)
+
+func exportShopWithPricedProducts(t *testing.T, prices []float64) *types.Shop {
+	exportedShop, err := lib.Export[types.Shop](types.Shop{Name: "ShopTestSortedQuery"})
+	require.Equal(t, nil, err, "error occurred in Export[types.Shop] invocation", err)
+
+	for _, price := range prices {
+		_, err := lib.Export[types.Product](types.Product{Name: "TestSortedQuery", Price: price, SoldBy: lib.Reference[types.Shop](exportedShop.Id)})
+		require.Equal(t, nil, err, "error occurred in Export[types.Product]", err)
+	}
+	return exportedShop
+}
+
+func getPrices(products []types.Product) []float64 {
+	prices := make([]float64, len(products))
+	for i, product := range products {
+		prices[i] = product.Price
+	}
+	return prices
+}
+
+func TestGetSortedStubsReturnsTopNInDescendingOrder(t *testing.T) {
+	// Arrange
+	exportedShop := exportShopWithPricedProducts(t, []float64{30, 5, 120, 7.5, 60})
+
+	// Act
+	products, err := exportedShop.Products.GetSortedStubs(lib.SortedQuery{SortedBy: "Price", Descending: true, Limit: 3})
+
+	// Assert
+	require.Equal(t, nil, err, "error occurred in exportedShop.Products.GetSortedStubs", err)
+	require.Equal(t, []float64{120, 60, 30}, getPrices(products))
+}
+
+func TestGetSortedStubsReturnsObjectsBetweenBounds(t *testing.T) {
+	// Arrange
+	exportedShop := exportShopWithPricedProducts(t, []float64{30, 5, 120, 7.5, 60})
+
+	// Act
+	products, err := exportedShop.Products.GetSortedStubs(lib.SortedQuery{SortedBy: "Price", From: 7.5, To: 60})
+
+	// Assert
+	require.Equal(t, nil, err, "error occurred in exportedShop.Products.GetSortedStubs", err)
+	require.Equal(t, []float64{7.5, 30, 60}, getPrices(products))
+}
+
+func TestGetSortedIdsFollowsPagesUpToLimit(t *testing.T) {
+	// Arrange
+	exportedShop := exportShopWithPricedProducts(t, []float64{4, 1, 3, 5, 2})
+	usePagingStore(t, 2)
+
+	// Act
+	ids, err := exportedShop.Products.GetSortedIds(lib.SortedQuery{SortedBy: "Price", Limit: 3})
+
+	// Assert
+	require.Equal(t, nil, err, "error occurred in exportedShop.Products.GetSortedIds", err)
+	products, err := lib.GetStubsInBatch[types.Product](ids)
+	require.Equal(t, nil, err, "error occurred in GetStubsInBatch invocation", err)
+	require.Equal(t, []float64{1, 2, 3}, getPrices(*products))
+}
+
+func TestGetSortedIdsReturnsErrorForManyToManyRelationship(t *testing.T) {
+	// Arrange
+	exportedShop, err := lib.Export[types.Shop](types.Shop{Name: "ShopTestSortedQuery"})
+	require.Equal(t, nil, err, "error occurred in Export[types.Shop] invocation", err)
+
+	// Act
+	_, err = exportedShop.Owners.GetSortedIds(lib.SortedQuery{SortedBy: "Email"})
+
+	// Assert
+	require.Error(t, err)
+}
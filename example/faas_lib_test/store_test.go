@@ -0,0 +1,139 @@
+package faas_lib_test
+
+import (
+	"os"
+
+	"github.com/Astenna/Nubes/lib"
+	"github.com/Astenna/Nubes/lib/sqlite"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+// the store must be set before the init functions of the package are run,
+// that's why it is done in a package-level variable initialization.
+// NUBES_TEST_STORE selects the store the tests are run against:
+// memory (default), sqlite or dynamodb (tables must already exist).
+var _ = useTestStore()
+
+// testStore is the store selected by NUBES_TEST_STORE, nil for dynamodb
+var testStore lib.Store
+
+func useTestStore() bool {
+	var store interface {
+		lib.Store
+		CreateTable(*dynamodb.CreateTableInput) (*dynamodb.CreateTableOutput, error)
+	}
+
+	switch os.Getenv("NUBES_TEST_STORE") {
+	case "dynamodb":
+		return false
+	case "sqlite":
+		sqliteStore, err := sqlite.Open(":memory:")
+		if err != nil {
+			panic(err)
+		}
+		store = sqliteStore
+	default:
+		store = lib.NewMemoryStore()
+	}
+
+	tables := []*dynamodb.CreateTableInput{
+		nobjectTable("User"),
+		nobjectTable("Shop"),
+		withSortedIndex(nobjectTable("Product", "SoldBy", "Name"), "SoldBy", "Price", dynamodb.ScalarAttributeTypeN),
+		nobjectTable("Order"),
+		nobjectTable("Discount"),
+		nobjectTable("Shipping"),
+		joinTable("Shop", "User"),
+		// the types of the delete policies tests
+		nobjectTable("Country"),
+		nobjectTable("Region", "Country"),
+		nobjectTable("Landmark", "Region"),
+		nobjectTable("City", "Region"),
+		nobjectTable("Tourist"),
+		joinTable("Country", "Tourist"),
+		// the types of the unique constraints tests
+		nobjectTable("Member"),
+		nobjectTable("Hotel"),
+		nobjectTable(lib.UniqueValuesTableName),
+		// the tables of the migration tests
+		nobjectTable("Subscriber"),
+		nobjectTable("Invoice"),
+		nobjectTable("Ticket"),
+		nobjectTable("Receipt"),
+		nobjectTable("Voucher"),
+		nobjectTable("Coupon"),
+	}
+	for _, table := range tables {
+		if _, err := store.CreateTable(table); err != nil {
+			panic(err)
+		}
+	}
+
+	testStore = store
+	lib.SetStore(store)
+	return true
+}
+
+func nobjectTable(typeName string, indexedAttributes ...string) *dynamodb.CreateTableInput {
+	input := &dynamodb.CreateTableInput{
+		TableName:            aws.String(typeName),
+		AttributeDefinitions: []*dynamodb.AttributeDefinition{stringAttribute("Id")},
+		KeySchema:            []*dynamodb.KeySchemaElement{keyElement("Id", dynamodb.KeyTypeHash)},
+	}
+
+	for _, attributeName := range indexedAttributes {
+		input.AttributeDefinitions = append(input.AttributeDefinitions, stringAttribute(attributeName))
+		input.GlobalSecondaryIndexes = append(input.GlobalSecondaryIndexes, &dynamodb.GlobalSecondaryIndex{
+			IndexName:  aws.String(typeName + attributeName),
+			KeySchema:  []*dynamodb.KeySchemaElement{keyElement(attributeName, dynamodb.KeyTypeHash)},
+			Projection: &dynamodb.Projection{ProjectionType: aws.String(dynamodb.ProjectionTypeKeysOnly)},
+		})
+	}
+	return input
+}
+
+// withSortedIndex adds the index created by the generator for
+// the sortedBy tag, the partition key must be already defined
+func withSortedIndex(input *dynamodb.CreateTableInput, partitionKeyName, sortKeyName, sortKeyType string) *dynamodb.CreateTableInput {
+	input.AttributeDefinitions = append(input.AttributeDefinitions, &dynamodb.AttributeDefinition{AttributeName: aws.String(sortKeyName), AttributeType: aws.String(sortKeyType)})
+	input.GlobalSecondaryIndexes = append(input.GlobalSecondaryIndexes, &dynamodb.GlobalSecondaryIndex{
+		IndexName: aws.String(*input.TableName + partitionKeyName + sortKeyName),
+		KeySchema: []*dynamodb.KeySchemaElement{
+			keyElement(partitionKeyName, dynamodb.KeyTypeHash),
+			keyElement(sortKeyName, dynamodb.KeyTypeRange),
+		},
+		Projection: &dynamodb.Projection{ProjectionType: aws.String(dynamodb.ProjectionTypeKeysOnly)},
+	})
+	return input
+}
+
+func joinTable(partitionKeyName, sortKeyName string) *dynamodb.CreateTableInput {
+	tableName := partitionKeyName + sortKeyName
+	return &dynamodb.CreateTableInput{
+		TableName: aws.String(tableName),
+		AttributeDefinitions: []*dynamodb.AttributeDefinition{
+			stringAttribute(partitionKeyName),
+			stringAttribute(sortKeyName),
+		},
+		KeySchema: []*dynamodb.KeySchemaElement{
+			keyElement(partitionKeyName, dynamodb.KeyTypeHash),
+			keyElement(sortKeyName, dynamodb.KeyTypeRange),
+		},
+		GlobalSecondaryIndexes: []*dynamodb.GlobalSecondaryIndex{
+			{
+				IndexName:  aws.String(tableName + "Reversed"),
+				KeySchema:  []*dynamodb.KeySchemaElement{keyElement(sortKeyName, dynamodb.KeyTypeHash)},
+				Projection: &dynamodb.Projection{ProjectionType: aws.String(dynamodb.ProjectionTypeKeysOnly)},
+			},
+		},
+	}
+}
+
+func stringAttribute(name string) *dynamodb.AttributeDefinition {
+	return &dynamodb.AttributeDefinition{AttributeName: aws.String(name), AttributeType: aws.String(dynamodb.ScalarAttributeTypeS)}
+}
+
+func keyElement(name, keyType string) *dynamodb.KeySchemaElement {
+	return &dynamodb.KeySchemaElement{AttributeName: aws.String(name), KeyType: aws.String(keyType)}
+}
@@ -0,0 +1,151 @@
+package faas_lib_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/Astenna/Nubes/example/faas/nubes/types"
+	"github.com/Astenna/Nubes/lib"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportOrderDecreasesAvailabilityAndCreatesOrder(t *testing.T) {
+	// Arrange
+	product, err := lib.Export[types.Product](types.Product{Name: "TestTransactionProduct", QuantityAvailable: 5})
+	require.Equal(t, nil, err, "error occurred in Export invocation", err)
+	order := types.Order{
+		Products: []types.OrderedProduct{{Product: lib.Reference[types.Product](product.Id), Quantity: 2}},
+		Buyer:    lib.Reference[types.User](existingUserId),
+	}
+
+	// Act
+	orderId, err := types.ExportOrder(order)
+
+	// Assert
+	require.Equal(t, nil, err, "error occurred in ExportOrder invocation", err)
+	storedOrder := types.Order{}
+	require.Equal(t, nil, lib.GetStub(orderId, &storedOrder))
+	require.NotEmpty(t, storedOrder.Shipping.Id())
+	storedShipping := types.Shipping{}
+	require.Equal(t, nil, lib.GetStub(storedOrder.Shipping.Id(), &storedShipping))
+	storedProduct := types.Product{}
+	require.Equal(t, nil, lib.GetStub(product.Id, &storedProduct))
+	require.Equal(t, 3, storedProduct.QuantityAvailable)
+}
+
+func TestExportOrderSavesNothingIfOneOfProductsIsNotAvailable(t *testing.T) {
+	// Arrange
+	available, err := lib.Export[types.Product](types.Product{Name: "TestTransactionAvailable", QuantityAvailable: 5})
+	require.Equal(t, nil, err, "error occurred in Export invocation", err)
+	notAvailable, err := lib.Export[types.Product](types.Product{Name: "TestTransactionNotAvailable", QuantityAvailable: 1})
+	require.Equal(t, nil, err, "error occurred in Export invocation", err)
+	order := types.Order{
+		Products: []types.OrderedProduct{
+			{Product: lib.Reference[types.Product](available.Id), Quantity: 2},
+			{Product: lib.Reference[types.Product](notAvailable.Id), Quantity: 2},
+		},
+		Buyer: lib.Reference[types.User](existingUserId),
+	}
+
+	// Act
+	_, err = types.ExportOrder(order)
+
+	// Assert
+	require.NotEqual(t, nil, err, "ExportOrder should fail")
+	storedProduct := types.Product{}
+	require.Equal(t, nil, lib.GetStub(available.Id, &storedProduct))
+	require.Equal(t, 5, storedProduct.QuantityAvailable)
+	require.Equal(t, 0, storedProduct.Version)
+}
+
+func TestRunInTransactionReturnsTransactionCancelledErrorIfConditionFails(t *testing.T) {
+	// Arrange
+	newUser := types.User{Email: uuid.NewString(), FirstName: "Kinga"}
+
+	// Act
+	err := lib.RunInTransaction(func(tx *lib.Tx) error {
+		if _, err := lib.Export[types.User](newUser); err != nil {
+			return err
+		}
+		return tx.CheckExists(newUser.GetTypeName(), uuid.NewString())
+	})
+
+	// Assert
+	require.IsType(t, lib.TransactionCancelledError{}, err)
+	require.Equal(t, []string{"None", "ConditionalCheckFailed"}, err.(lib.TransactionCancelledError).Reasons)
+	exists, err := lib.IsInstanceAlreadyCreated(lib.IsInstanceAlreadyCreatedParam{Id: newUser.Email, TypeName: newUser.GetTypeName()})
+	require.Equal(t, nil, err, "error occurred in IsInstanceAlreadyCreated invocation", err)
+	require.False(t, exists, "user exported in cancelled transaction should not exist")
+}
+
+func TestRunInTransactionWithContextIsolatesConcurrentTransactions(t *testing.T) {
+	// Arrange
+	committedUser := types.User{Email: uuid.NewString(), FirstName: "Kinga"}
+	cancelledUser := types.User{Email: uuid.NewString(), FirstName: "Kinga"}
+	outsideUser := types.User{Email: uuid.NewString(), FirstName: "Kinga"}
+	bothExported := sync.WaitGroup{}
+	bothExported.Add(2)
+	errs := make([]error, 2)
+
+	// Act
+	wg := sync.WaitGroup{}
+	for i, user := range []types.User{committedUser, cancelledUser} {
+		wg.Add(1)
+		go func(i int, user types.User) {
+			defer wg.Done()
+			errs[i] = lib.RunInTransactionWithContext(context.Background(), func(tx *lib.Tx) error {
+				_, err := lib.ExportWithContext[types.User](tx.Context(), user)
+				bothExported.Done()
+				// both transactions are in progress when the writes are issued
+				bothExported.Wait()
+				if err == nil && i == 1 {
+					_, err = lib.ExportWithContext[types.User](context.Background(), outsideUser)
+					if err == nil {
+						err = errors.New("cancelled")
+					}
+				}
+				return err
+			})
+		}(i, user)
+	}
+	wg.Wait()
+
+	// Assert
+	require.Equal(t, nil, errs[0], "error occurred in the committed transaction", errs[0])
+	require.EqualError(t, errs[1], "cancelled")
+	for _, expected := range []struct {
+		user   types.User
+		exists bool
+	}{{committedUser, true}, {cancelledUser, false}, {outsideUser, true}} {
+		exists, err := lib.IsInstanceAlreadyCreated(lib.IsInstanceAlreadyCreatedParam{Id: expected.user.Email, TypeName: expected.user.GetTypeName()})
+		require.Equal(t, nil, err, "error occurred in IsInstanceAlreadyCreated invocation", err)
+		require.Equal(t, expected.exists, exists, "unexpected existence of the user "+expected.user.Email)
+	}
+}
+
+func TestNestedRunInTransactionReturnsTransactionInProgressError(t *testing.T) {
+	// Arrange
+	newUser := types.User{Email: uuid.NewString(), FirstName: "Kinga"}
+	var nestedErr, nestedWithContextErr error
+
+	// Act
+	err := lib.RunInTransaction(func(tx *lib.Tx) error {
+		nestedErr = lib.RunInTransaction(func(*lib.Tx) error {
+			_, err := lib.Export[types.User](newUser)
+			return err
+		})
+		nestedWithContextErr = lib.RunInTransactionWithContext(tx.Context(), func(*lib.Tx) error { return nil })
+		return nil
+	})
+
+	// Assert
+	require.Equal(t, nil, err, "error occurred in RunInTransaction invocation", err)
+	require.IsType(t, lib.TransactionInProgressError{}, nestedErr)
+	require.IsType(t, lib.TransactionInProgressError{}, nestedWithContextErr)
+	exists, err := lib.IsInstanceAlreadyCreated(lib.IsInstanceAlreadyCreatedParam{Id: newUser.Email, TypeName: newUser.GetTypeName()})
+	require.Equal(t, nil, err, "error occurred in IsInstanceAlreadyCreated invocation", err)
+	require.False(t, exists, "user exported in the nested transaction should not exist")
+}
@@ -0,0 +1,188 @@
+package faas_lib_test
+
+import (
+	"testing"
+
+	"github.com/Astenna/Nubes/lib"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+type member struct {
+	Id       string
+	Email    string `nubes:"unique"`
+	Nickname string
+}
+
+func (member) GetTypeName() string {
+	return "Member"
+}
+
+func init() {
+	// like the types of the shadow packages, so that lib.SetField knows the unique fields
+	lib.RegisterType[member]()
+}
+
+type hotel struct {
+	Id   string
+	Name string `nubes:"unique-City"`
+	City string
+}
+
+func (hotel) GetTypeName() string {
+	return "Hotel"
+}
+
+func TestExportWithValueOfUniqueFieldHeldByAnotherInstanceFails(t *testing.T) {
+	// Arrange
+	email := uuid.NewString() + "@nubes.com"
+	_, err := lib.Export[member](member{Email: email})
+	require.NoError(t, err)
+
+	// Act
+	exported, err := lib.Export[member](member{Email: email})
+
+	// Assert
+	require.Equal(t, lib.DuplicateValueError{TypeName: "Member", FieldName: "Email", Value: email}, err)
+	require.Nil(t, exported)
+}
+
+func TestUniqueFieldWithScopeAllowsTheSameValueInOtherScopes(t *testing.T) {
+	// Arrange
+	name := "TestUniqueScope" + uuid.NewString()
+	_, err := lib.Export[hotel](hotel{Name: name, City: "Amsterdam"})
+	require.NoError(t, err)
+
+	// Act
+	_, otherCityErr := lib.Export[hotel](hotel{Name: name, City: "Warsaw"})
+	_, sameCityErr := lib.Export[hotel](hotel{Name: name, City: "Amsterdam"})
+
+	// Assert
+	require.NoError(t, otherCityErr)
+	require.IsType(t, lib.DuplicateValueError{}, sameCityErr)
+}
+
+func TestSetUniqueFieldReleasesPreviousValue(t *testing.T) {
+	// Arrange
+	oldEmail, newEmail := uuid.NewString()+"@nubes.com", uuid.NewString()+"@nubes.com"
+	exported, err := lib.Export[member](member{Email: oldEmail})
+	require.NoError(t, err)
+
+	// Act
+	err = lib.SetUniqueField[member](lib.SetFieldParam{Id: exported.Id, TypeName: "Member", FieldName: "Email", Value: newEmail})
+
+	// Assert
+	require.NoError(t, err)
+	_, err = lib.Export[member](member{Email: oldEmail})
+	require.NoError(t, err)
+	_, err = lib.Export[member](member{Email: newEmail})
+	require.IsType(t, lib.DuplicateValueError{}, err)
+}
+
+func TestSetUniqueFieldWithValueHeldByAnotherInstanceDoesNotSaveIt(t *testing.T) {
+	// Arrange
+	email := uuid.NewString() + "@nubes.com"
+	_, err := lib.Export[member](member{Email: email})
+	require.NoError(t, err)
+	other, err := lib.Export[member](member{Email: uuid.NewString() + "@nubes.com"})
+	require.NoError(t, err)
+
+	// Act
+	err = lib.SetUniqueField[member](lib.SetFieldParam{Id: other.Id, TypeName: "Member", FieldName: "Email", Value: email})
+
+	// Assert
+	require.Equal(t, lib.DuplicateValueError{TypeName: "Member", FieldName: "Email", Value: email}, err)
+	stored := member{}
+	require.NoError(t, lib.GetStub(other.Id, &stored))
+	require.Equal(t, other.Email, stored.Email)
+}
+
+func TestSetFieldWithValueHeldByAnotherInstanceOfRegisteredTypeFails(t *testing.T) {
+	// Arrange
+	email := uuid.NewString() + "@nubes.com"
+	_, err := lib.Export[member](member{Email: email})
+	require.NoError(t, err)
+	other, err := lib.Export[member](member{Email: uuid.NewString() + "@nubes.com"})
+	require.NoError(t, err)
+
+	// Act
+	err = lib.SetField(lib.SetFieldParam{Id: other.Id, TypeName: "Member", FieldName: "Email", Value: email})
+
+	// Assert
+	require.Equal(t, lib.DuplicateValueError{TypeName: "Member", FieldName: "Email", Value: email}, err)
+	stored := member{}
+	require.NoError(t, lib.GetStub(other.Id, &stored))
+	require.Equal(t, other.Email, stored.Email)
+}
+
+func TestSetFieldOfRegisteredTypeUpdatesGuardItems(t *testing.T) {
+	// Arrange
+	oldEmail, newEmail := uuid.NewString()+"@nubes.com", uuid.NewString()+"@nubes.com"
+	exported, err := lib.Export[member](member{Email: oldEmail})
+	require.NoError(t, err)
+
+	// Act
+	err = lib.SetField(lib.SetFieldParam{Id: exported.Id, TypeName: "Member", FieldName: "Email", Value: newEmail})
+
+	// Assert
+	require.NoError(t, err)
+	_, err = lib.Export[member](member{Email: oldEmail})
+	require.NoError(t, err)
+	_, err = lib.Export[member](member{Email: newEmail})
+	require.IsType(t, lib.DuplicateValueError{}, err)
+}
+
+func TestUpsertAndSaveChangesKeepValueOfTheSameInstance(t *testing.T) {
+	// Arrange
+	email := uuid.NewString() + "@nubes.com"
+	exported, err := lib.Export[member](member{Email: email})
+	require.NoError(t, err)
+	stored := member{}
+	var snapshot lib.Snapshot
+	require.NoError(t, lib.GetStubWithSnapshot(exported.Id, &stored, &snapshot))
+
+	// Act
+	upsertErr := lib.Upsert(&member{Email: email, Nickname: "upserted"}, exported.Id)
+	stored.Nickname = "saved"
+	saveErr := lib.SaveChanges(&stored, exported.Id, snapshot)
+
+	// Assert
+	require.NoError(t, upsertErr)
+	require.NoError(t, saveErr)
+}
+
+func TestSaveChangesWithValueHeldByAnotherInstanceFails(t *testing.T) {
+	// Arrange
+	email := uuid.NewString() + "@nubes.com"
+	_, err := lib.Export[member](member{Email: email})
+	require.NoError(t, err)
+	other, err := lib.Export[member](member{Email: uuid.NewString() + "@nubes.com"})
+	require.NoError(t, err)
+	stored := member{}
+	var snapshot lib.Snapshot
+	require.NoError(t, lib.GetStubWithSnapshot(other.Id, &stored, &snapshot))
+
+	// Act
+	stored.Email = email
+	err = lib.SaveChanges(&stored, other.Id, snapshot)
+
+	// Assert
+	require.IsType(t, lib.DuplicateValueError{}, err)
+	require.NoError(t, lib.GetStub(other.Id, &stored))
+	require.Equal(t, other.Email, stored.Email)
+}
+
+func TestDeleteReleasesValuesOfUniqueFields(t *testing.T) {
+	// Arrange
+	email := uuid.NewString() + "@nubes.com"
+	exported, err := lib.Export[member](member{Email: email})
+	require.NoError(t, err)
+
+	// Act
+	err = lib.Delete[member](exported.Id)
+
+	// Assert
+	require.NoError(t, err)
+	_, err = lib.Export[member](member{Email: email})
+	require.NoError(t, err)
+}
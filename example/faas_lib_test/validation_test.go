@@ -0,0 +1,119 @@
+package faas_lib_test
+
+import (
+	"testing"
+
+	"github.com/Astenna/Nubes/example/faas/nubes/types"
+	"github.com/Astenna/Nubes/lib"
+	"github.com/stretchr/testify/require"
+)
+
+type validatedOrder struct {
+	Customer string   `nubes:"required,regex=^[a-z]+$"`
+	Size     string   `nubes:"oneof=S M L"`
+	Items    []string `nubes:"min=1,max=3"`
+	Discount float64  `nubes:"min=0,max=50"`
+}
+
+func TestExportOfInvalidObjectReturnsValidationErrorWithAllFailingFields(t *testing.T) {
+	// Arrange
+	invalid := types.Product{Name: "TestInvalidProduct", QuantityAvailable: -1, Price: -10}
+
+	// Act
+	exported, err := lib.Export[types.Product](invalid)
+
+	// Assert
+	var validationErr lib.ValidationError
+	require.ErrorAs(t, err, &validationErr)
+	require.Equal(t, "Product", validationErr.TypeName)
+	require.ElementsMatch(t, []lib.FieldValidationError{
+		{FieldName: "QuantityAvailable", Rule: "min=0"},
+		{FieldName: "Price", Rule: "min=0"},
+	}, validationErr.Fields)
+	require.Empty(t, exported.Id)
+}
+
+func TestUpsertOfInvalidStateIsRejected(t *testing.T) {
+	// Arrange
+	exported, err := lib.Export[types.Product](types.Product{Name: "TestUpsertValidationProduct", QuantityAvailable: 1})
+	require.NoError(t, err)
+	stored := types.Product{}
+	require.NoError(t, lib.GetStub(exported.Id, &stored))
+
+	// Act
+	stored.QuantityAvailable = -5
+	err = lib.Upsert(&stored, exported.Id)
+
+	// Assert
+	require.IsType(t, lib.ValidationError{}, err)
+	require.NoError(t, lib.GetStub(exported.Id, &stored))
+	require.Equal(t, 1, stored.QuantityAvailable)
+}
+
+func TestSetFieldWithInvalidValueIsRejected(t *testing.T) {
+	// Arrange
+	exported, err := lib.Export[types.Product](types.Product{Name: "TestSetFieldValidationProduct", QuantityAvailable: 1})
+	require.NoError(t, err)
+
+	// Act
+	err = lib.SetField(lib.SetFieldParam{Id: exported.Id, TypeName: "Product", FieldName: "QuantityAvailable", Value: -5})
+
+	// Assert
+	var validationErr lib.ValidationError
+	require.ErrorAs(t, err, &validationErr)
+	require.Equal(t, []lib.FieldValidationError{{FieldName: "QuantityAvailable", Rule: "min=0"}}, validationErr.Fields)
+	stored := types.Product{}
+	require.NoError(t, lib.GetStub(exported.Id, &stored))
+	require.Equal(t, 1, stored.QuantityAvailable)
+}
+
+func TestValidateChecksAllRules(t *testing.T) {
+	// Arrange
+	invalid := validatedOrder{Customer: "John1", Size: "XL", Items: []string{}, Discount: 60}
+	valid := validatedOrder{Customer: "john", Size: "M", Items: []string{"book"}, Discount: 10}
+
+	// Act
+	invalidErr := lib.Validate(invalid)
+	validErr := lib.Validate(&valid)
+
+	// Assert
+	require.NoError(t, validErr)
+	require.Equal(t, lib.ValidationError{TypeName: "validatedOrder", Fields: []lib.FieldValidationError{
+		{FieldName: "Customer", Rule: "regex=^[a-z]+$"},
+		{FieldName: "Size", Rule: "oneof=S M L"},
+		{FieldName: "Items", Rule: "min=1"},
+		{FieldName: "Discount", Rule: "max=50"},
+	}}, invalidErr)
+}
+
+func TestRegexRuleWithInvalidExpressionIsNotSatisfied(t *testing.T) {
+	// Arrange
+	type invalidRegex struct {
+		Code string `nubes:"regex=[a-z"`
+	}
+
+	// Act
+	err := lib.Validate(invalidRegex{Code: "abc"})
+
+	// Assert
+	require.Equal(t, lib.ValidationError{TypeName: "invalidRegex", Fields: []lib.FieldValidationError{
+		{FieldName: "Code", Rule: "regex=[a-z"},
+	}}, err)
+}
+
+func TestValidateFieldChecksValueDecodedFromJSON(t *testing.T) {
+	// Arrange
+	// the values set by the clients are decoded from JSON, hence the numbers are float64
+	var invalidQuantity interface{} = float64(-3)
+	var validQuantity interface{} = float64(3)
+
+	// Act
+	invalidErr := lib.ValidateField(types.Product{}, "QuantityAvailable", invalidQuantity)
+	validErr := lib.ValidateField(types.Product{}, "QuantityAvailable", validQuantity)
+	missingErr := lib.ValidateField(validatedOrder{}, "Customer", nil)
+
+	// Assert
+	require.NoError(t, validErr)
+	require.EqualError(t, invalidErr, "validation of Product failed: QuantityAvailable does not satisfy min=0")
+	require.EqualError(t, missingErr, "validation of validatedOrder failed: Customer does not satisfy required")
+}
@@ -0,0 +1,78 @@
+package faas_lib_test
+
+import (
+	"testing"
+
+	"github.com/Astenna/Nubes/example/faas/nubes/types"
+	"github.com/Astenna/Nubes/lib"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMethodOfVersionedTypeIncrementsVersion(t *testing.T) {
+	// Arrange
+	exported, err := lib.Export[types.Product](types.Product{Name: "TestVersionProduct", QuantityAvailable: 10})
+	require.Equal(t, nil, err, "error occurred in Export invocation", err)
+
+	// Act
+	err = exported.DecreaseAvailabilityBy(2)
+	require.Equal(t, nil, err, "error occurred in DecreaseAvailabilityBy invocation", err)
+	err = exported.DecreaseAvailabilityBy(3)
+	require.Equal(t, nil, err, "error occurred in DecreaseAvailabilityBy invocation", err)
+
+	// Assert
+	stored := types.Product{}
+	err = lib.GetStub(exported.Id, &stored)
+	require.Equal(t, nil, err, "error occurred in GetStub invocation", err)
+	require.Equal(t, 2, stored.Version)
+	require.Equal(t, 5, stored.QuantityAvailable)
+}
+
+func TestUpsertOfStaleVersionReturnsConflictError(t *testing.T) {
+	// Arrange
+	exported, err := lib.Export[types.Product](types.Product{Name: "TestConflictProduct", QuantityAvailable: 10})
+	require.Equal(t, nil, err, "error occurred in Export invocation", err)
+	first, second := types.Product{}, types.Product{}
+	require.Equal(t, nil, lib.GetStub(exported.Id, &first))
+	require.Equal(t, nil, lib.GetStub(exported.Id, &second))
+
+	// Act
+	first.QuantityAvailable = 8
+	errFirst := lib.Upsert(&first, exported.Id)
+	second.QuantityAvailable = 7
+	errSecond := lib.Upsert(&second, exported.Id)
+
+	// Assert
+	require.Equal(t, nil, errFirst, "error occurred in Upsert invocation", errFirst)
+	require.Equal(t, 1, first.Version)
+	require.True(t, lib.IsConflictError(errSecond), "expected ConflictError, returned", errSecond)
+	require.Equal(t, lib.ConflictError{Id: exported.Id, TypeName: "Product", ExpectedVersion: 0}, errSecond)
+
+	stored := types.Product{}
+	require.Equal(t, nil, lib.GetStub(exported.Id, &stored))
+	require.Equal(t, 8, stored.QuantityAvailable)
+}
+
+func TestSetFieldOfVersionedTypeIncrementsVersion(t *testing.T) {
+	// Arrange
+	exported, err := lib.Export[types.Product](types.Product{Name: "TestSetFieldVersionProduct"})
+	require.Equal(t, nil, err, "error occurred in Export invocation", err)
+	stale := types.Product{}
+	require.Equal(t, nil, lib.GetStub(exported.Id, &stale))
+	shop, err := lib.Export[types.Shop](types.Shop{Name: "TestSetFieldVersionShop"})
+	require.Equal(t, nil, err, "error occurred in Export invocation", err)
+
+	// Act
+	err = exported.SetSoldBy(shop.Id)
+	require.Equal(t, nil, err, "error occurred in SetSoldBy invocation", err)
+	expectedVersion := 0
+	errExpected := lib.SetField(lib.SetFieldParam{Id: exported.Id, TypeName: "Product", FieldName: "Name", Value: "New", ExpectedVersion: &expectedVersion})
+	errUpsert := lib.Upsert(&stale, exported.Id)
+
+	// Assert
+	require.True(t, lib.IsConflictError(errExpected), "expected ConflictError, returned", errExpected)
+	require.True(t, lib.IsConflictError(errUpsert), "expected ConflictError, returned", errUpsert)
+	stored := types.Product{}
+	require.Equal(t, nil, lib.GetStub(exported.Id, &stored))
+	require.Equal(t, 1, stored.Version)
+	require.Equal(t, "TestSetFieldVersionProduct", stored.Name)
+}
@@ -9,6 +9,7 @@ require github.com/Astenna/Nubes/lib v0.0.0
 require (
 	github.com/davecgh/go-spew v1.1.1 // indirect
 	github.com/jftuga/geodist v1.0.0 // indirect
+	github.com/mattn/go-sqlite3 v1.14.16 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
 	golang.org/x/mod v0.9.0 // indirect
 	golang.org/x/sys v0.6.0 // indirect
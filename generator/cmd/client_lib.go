@@ -18,11 +18,15 @@ var clientCmd = &cobra.Command{
 	Long:  `Generates client project based on types and repositories.`,
 
 	Run: func(cmd *cobra.Command, _ []string) {
-		typesPath, _ := cmd.Flags().GetString("types")
+		typesPaths, _ := cmd.Flags().GetStringSlice("types")
 		output, _ := cmd.Flags().GetString("output")
 		projectName, _ := cmd.Flags().GetString("project-name")
+		namePrefix := getNamePrefixOrExitOnError(cmd)
 
-		typesParser, err := parser.NewClientTypesParser(templ.MakePathAbosoluteOrExitOnError(typesPath))
+		for i := range typesPaths {
+			typesPaths[i] = templ.MakePathAbosoluteOrExitOnError(typesPaths[i])
+		}
+		typesParser, err := parser.NewClientTypesParser(typesPaths...)
 		if err != nil {
 			fmt.Println("Fatal error occurred initialising type spec parser: %w", err)
 			os.Exit(1)
@@ -80,7 +84,10 @@ var clientCmd = &cobra.Command{
 			Types       []*parser.StructTypeDefinition
 		}{PackageName: projectName, Types: definedTypes}, filePath)
 
-		lambdaClientTemplInput := struct{ PackageName string }{PackageName: projectName}
+		lambdaClientTemplInput := struct {
+			PackageName string
+			NamePrefix  string
+		}{PackageName: projectName, NamePrefix: namePrefix}
 		templ.CreateFile("template/client_lib/lambda_client.go.tmpl", lambdaClientTemplInput, filepath.Join(outputDirectoryPath, "lambda_client.go"))
 	},
 }
@@ -88,13 +95,15 @@ var clientCmd = &cobra.Command{
 func init() {
 	rootCmd.AddCommand(clientCmd)
 
-	var typesPath string
+	var typesPaths []string
 	var outputPath string
 	var projectName string
+	var namespace string
 
-	clientCmd.Flags().StringVarP(&typesPath, "types", "t", ".", "path to package with types definitions")
+	clientCmd.Flags().StringSliceVarP(&typesPaths, "types", "t", []string{"."}, "paths to packages with types definitions, a path ending with /... includes the nested packages, e.g. ./types/...")
 	clientCmd.Flags().StringVarP(&outputPath, "output", "o", ".", "path where the directory with the client library will be created")
 	clientCmd.Flags().StringVarP(&projectName, "project-name", "p", "client_lib", "name of the generated package")
+	clientCmd.Flags().StringVarP(&namespace, "namespace", "n", "", "namespace the handlers were generated with, prepended to the names of the invoked lambda functions")
 
 	cmd.Execute()
 }
@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+// TestGeneratedClientLibCompiles generates the client library of the example types
+// and builds it in the example module, which provides the dependencies of the library
+func TestGeneratedClientLibCompiles(t *testing.T) {
+	// Arrange
+	generatorDir, err := filepath.Abs("..")
+	if err != nil {
+		t.Fatal(err)
+	}
+	exampleDir := filepath.Join(filepath.Dir(generatorDir), "example")
+
+	// the templates are read relative to the executable of the generator
+	binDir := t.TempDir()
+	generator := filepath.Join(binDir, "generator")
+	run(t, generatorDir, "go", "build", "-o", generator, ".")
+	if err := os.Symlink(filepath.Join(generatorDir, "template"), filepath.Join(binDir, "template")); err != nil {
+		t.Fatal(err)
+	}
+
+	// the directories starting with _ are ignored by the ./... patterns of the example module
+	outputDir, err := os.MkdirTemp(exampleDir, "_client_lib_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(outputDir) })
+
+	// Act
+	run(t, exampleDir, generator, "client", "-t", filepath.Join(exampleDir, "faas", "types"), "-o", outputDir, "-p", "client_lib")
+
+	// Assert
+	if _, err := os.Stat(filepath.Join(outputDir, "client_lib", "stubs.go")); err != nil {
+		t.Fatalf("client library was not generated: %v", err)
+	}
+	run(t, exampleDir, "go", "vet", "./"+filepath.Base(outputDir)+"/client_lib")
+}
+
+func run(t *testing.T, dir string, name string, args ...string) {
+	t.Helper()
+	cmd := exec.Command(name, args...)
+	cmd.Dir = dir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("%s %v failed: %v\n%s", name, args, err, out)
+	}
+}
@@ -0,0 +1,149 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/Astenna/Nubes/generator/migration"
+	"github.com/Astenna/Nubes/generator/parser"
+	tp "github.com/Astenna/Nubes/generator/template"
+	typespec "github.com/Astenna/Nubes/generator/template/type_spec"
+	"github.com/spf13/cobra"
+)
+
+// the default name of the file with the schema snapshot, saved in the types directory
+const schemaFileName = "nubes_schema.json"
+
+var migrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Generates program migrating the items stored with the previous schema of the types",
+	Long: `Compares the types indicated by the path with the schema snapshot recorded before and generates
+the program migrating the existing items to the current schema. The snapshot is recorded on the first run
+and updated by the generated program once the migration completes, so the program is generated from the same
+snapshot until the migration is applied. The renamed fields must be given with the rename flag.`,
+
+	Run: func(cmd *cobra.Command, _ []string) {
+		typesPath, _ := cmd.Flags().GetString("types")
+		generationDestination, _ := cmd.Flags().GetString("output")
+		moduleName, _ := cmd.Flags().GetString("module")
+		schemaPath, _ := cmd.Flags().GetString("schema")
+		renameFlags, _ := cmd.Flags().GetStringArray("rename")
+		namePrefix := getNamePrefixOrExitOnError(cmd)
+
+		typesPath = tp.MakePathAbosoluteOrExitOnError(typesPath)
+		if schemaPath == "" {
+			schemaPath = filepath.Join(typesPath, schemaFileName)
+		}
+		renames := parseRenamesOrExitOnError(renameFlags)
+
+		typeSpecParser, err := parser.NewTypeSpecParser(typesPath)
+		if err != nil {
+			fmt.Println("Fatal error occurred initialising type spec parser: ", err)
+			os.Exit(1)
+		}
+		typeSpecParser.Parse(moduleName)
+		currentSchema := migration.NewSchema(typeSpecParser.Output)
+
+		previousSchema, exists, err := migration.LoadSchema(schemaPath)
+		if err != nil {
+			fmt.Println("Fatal error occurred loading the schema snapshot: ", err)
+			os.Exit(1)
+		}
+		if !exists {
+			saveSchemaOrExitOnError(currentSchema, schemaPath)
+			fmt.Println("Schema snapshot recorded in", schemaPath, ", the migration is generated once the types change")
+			return
+		}
+
+		diff := migration.Diff(previousSchema, currentSchema, renames)
+		for _, note := range diff.Notes {
+			fmt.Println("NOTE:", note)
+		}
+		if len(diff.Steps) == 0 {
+			fmt.Println("No items need to be migrated")
+			saveSchemaOrExitOnError(currentSchema, schemaPath)
+			return
+		}
+
+		generationDestPath := tp.MakePathAbosoluteOrExitOnError(filepath.Join(generationDestination, "generated", "migration"))
+		if err := os.MkdirAll(generationDestPath, 0777); err != nil {
+			fmt.Println("Fatal error occurred creating the directory of the migration program: ", err)
+			os.Exit(1)
+		}
+		migrationPath := filepath.Join(generationDestPath, "Migration.go")
+		schemaJSON, err := currentSchema.Marshal()
+		if err != nil {
+			fmt.Println("Fatal error occurred marshalling the schema snapshot: ", err)
+			os.Exit(1)
+		}
+		absoluteSchemaPath := tp.MakePathAbosoluteOrExitOnError(schemaPath)
+		tp.CreateFile("template/type_spec/migration.go.tmpl", typespec.MigrationTemplateInput{
+			Steps:      diff.Steps,
+			Notes:      diff.Notes,
+			NamePrefix: namePrefix,
+			Schema:     getStringLiteral(string(schemaJSON)),
+			SchemaPath: absoluteSchemaPath,
+		}, migrationPath)
+		tp.RunGoimportsOnFile(migrationPath)
+
+		// the snapshot is left unchanged until the migration is applied
+		fmt.Println("Migration generated in", migrationPath, ", run it with -dryRun first to see the number of items to be changed")
+		fmt.Println("The schema snapshot in", absoluteSchemaPath, "is updated by the program once the migration completes")
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(migrateCmd)
+
+	var typesPath string
+	var outputPath string
+	var moduleName string
+	var schemaPath string
+	var renames []string
+	var namespace string
+
+	migrateCmd.Flags().StringVarP(&typesPath, "types", "t", ".", "path to package with types definitions")
+	migrateCmd.Flags().StringVarP(&outputPath, "output", "o", ".", "path where directory with the migration program will be created")
+	migrateCmd.Flags().StringVarP(&moduleName, "module", "m", "MISSING_MODULE_NAME", "module name of the source project")
+	migrateCmd.Flags().StringVarP(&schemaPath, "schema", "s", "", "path to the schema snapshot, "+schemaFileName+" in the types directory by default")
+	migrateCmd.Flags().StringArrayVar(&renames, "rename", nil, "renamed field in the form Type.OldField=NewField, can be repeated")
+	migrateCmd.Flags().StringVarP(&namespace, "namespace", "n", "", "service or stage namespace, e.g. shop-dev, prepended to the names of the tables")
+}
+
+// parseRenamesOrExitOnError maps the types to their renamed
+// fields given in the form Type.OldField=NewField
+func parseRenamesOrExitOnError(renameFlags []string) map[string]map[string]string {
+	renames := map[string]map[string]string{}
+	for _, rename := range renameFlags {
+		field, newName, found := strings.Cut(rename, "=")
+		typeName, oldName, isQualified := strings.Cut(field, ".")
+		if !found || !isQualified || typeName == "" || oldName == "" || newName == "" {
+			fmt.Printf("Invalid rename %q, it must be in the form Type.OldField=NewField\n", rename)
+			os.Exit(1)
+		}
+		if _, exists := renames[typeName]; !exists {
+			renames[typeName] = map[string]string{}
+		}
+		renames[typeName][oldName] = newName
+	}
+	return renames
+}
+
+// getStringLiteral returns the raw string literal of the value,
+// unless it contains backquotes, which only the quoted literal can
+func getStringLiteral(value string) string {
+	if strings.Contains(value, "`") {
+		return strconv.Quote(value)
+	}
+	return "`" + value + "`"
+}
+
+func saveSchemaOrExitOnError(schema migration.Schema, path string) {
+	if err := schema.Save(path); err != nil {
+		fmt.Println("Fatal error occurred saving the schema snapshot: ", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,29 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+
+	"github.com/spf13/cobra"
+)
+
+// the names of lambda functions and DynamoDB tables can
+// both contain letters, digits, hyphens and underscores
+var validNamespace = regexp.MustCompile(`^[a-zA-Z0-9_-]*$`)
+
+// getNamePrefixOrExitOnError returns the prefix of the names of the lambda
+// functions and tables of the namespace given with the namespace flag,
+// e.g. shop-dev- for shop-dev. It is empty if no namespace is given.
+func getNamePrefixOrExitOnError(cmd *cobra.Command) string {
+	namespace, _ := cmd.Flags().GetString("namespace")
+	if !validNamespace.MatchString(namespace) {
+		fmt.Printf("Invalid namespace %q, it can contain only letters, digits, hyphens and underscores\n", namespace)
+		os.Exit(1)
+	}
+
+	if namespace == "" {
+		return ""
+	}
+	return namespace + "-"
+}
@@ -12,6 +12,7 @@ import (
 	typespec "github.com/Astenna/Nubes/generator/template/type_spec"
 	"github.com/spf13/cobra"
 	"github.com/spf13/cobra-cli/cmd"
+	"golang.org/x/exp/maps"
 )
 
 var ssfSpecCmd = &cobra.Command{
@@ -20,20 +21,27 @@ var ssfSpecCmd = &cobra.Command{
 	Long:  `Generates handlers' definitions for AWS lambda deployment based on types indicated by the path`,
 
 	Run: func(cmd *cobra.Command, _ []string) {
-		typesPath, _ := cmd.Flags().GetString("types")
+		typesPaths, _ := cmd.Flags().GetStringSlice("types")
 		generationDestination, _ := cmd.Flags().GetString("output")
 		moduleName, _ := cmd.Flags().GetString("module")
 		dbInit, _ := cmd.Flags().GetBool("dbInit")
+		dbType, _ := cmd.Flags().GetString("dbType")
+		dbPath, _ := cmd.Flags().GetString("dbPath")
 		generateDeploymentFilesOn, _ := cmd.Flags().GetBool("deplFiles")
+		conflictRetries, _ := cmd.Flags().GetInt("conflictRetries")
+		namePrefix := getNamePrefixOrExitOnError(cmd)
 
-		typesPath = tp.MakePathAbosoluteOrExitOnError(typesPath)
+		for i := range typesPaths {
+			typesPaths[i] = tp.MakePathAbosoluteOrExitOnError(typesPaths[i])
+		}
 
-		typeSpecParser, err := parser.NewTypeSpecParser(typesPath)
+		typeSpecParser, err := parser.NewTypeSpecParser(typesPaths...)
 		if err != nil {
 			fmt.Println("Fatal error occurred initialising type spec parser: %w", err)
 			os.Exit(1)
 		}
 		typeSpecParser.Run(moduleName)
+		setConflictRetries(typeSpecParser.Handlers, typeSpecParser.Output.TypesWithVersion, conflictRetries)
 
 		generateStateChangingHandlers(generationDestination, typeSpecParser.Handlers)
 		generateGenericHandlers(generationDestination, typeSpecParser.Output)
@@ -46,12 +54,14 @@ var ssfSpecCmd = &cobra.Command{
 				StateFuncs:    typeSpecParser.Handlers,
 				CustomCtors:   typeSpecParser.CustomCtors,
 				ManyToManyRel: len(typeSpecParser.Output.ManyToManyRelationships) > 0,
+				FindBy:        len(typeSpecParser.Output.IndexedFields) > 0,
+				NamePrefix:    namePrefix,
 			}
 			generateDeploymentFiles(generationDestination, serverlessInput)
 		}
 
 		if dbInit {
-			database.CreateTypeTables(typeSpecParser.Output)
+			initializeDatabase(dbType, dbPath, namePrefix, typeSpecParser.Output)
 		}
 	},
 }
@@ -59,17 +69,25 @@ var ssfSpecCmd = &cobra.Command{
 func init() {
 	rootCmd.AddCommand(ssfSpecCmd)
 
-	var typesPath string
+	var typesPaths []string
 	var handlersPath string
 	var moduleName string
 	var dbInit bool
+	var dbType string
+	var dbPath string
 	var generateDeploymentFiles bool
+	var conflictRetries int
+	var namespace string
 
-	ssfSpecCmd.Flags().StringVarP(&typesPath, "types", "t", ".", "path to package with types definitions")
+	ssfSpecCmd.Flags().StringSliceVarP(&typesPaths, "types", "t", []string{"."}, "paths to packages with types definitions, a path ending with /... includes the nested packages, e.g. ./types/...")
 	ssfSpecCmd.Flags().StringVarP(&handlersPath, "output", "o", ".", "path where directory with handlers will be created")
 	ssfSpecCmd.Flags().StringVarP(&moduleName, "module", "m", "MISSING_MODULE_NAME", "module name of the source project")
 	ssfSpecCmd.Flags().BoolVarP(&dbInit, "dbInit", "i", false, "boolean, indicates whether database tables should be initialized")
+	ssfSpecCmd.Flags().StringVarP(&dbType, "dbType", "d", "dynamodb", "type of the database initialized with dbInit, dynamodb or sqlite")
+	ssfSpecCmd.Flags().StringVar(&dbPath, "dbPath", "nubes.db", "path to the SQLite database file, used if dbType is sqlite")
 	ssfSpecCmd.Flags().BoolVarP(&generateDeploymentFiles, "deplFiles", "g", true, "boolean, indicates whether deployment files for AWS lambdas are to be created")
+	ssfSpecCmd.Flags().IntVarP(&conflictRetries, "conflictRetries", "r", 0, "number of times the methods of versioned types are retried after a concurrent modification is detected")
+	ssfSpecCmd.Flags().StringVarP(&namespace, "namespace", "n", "", "service or stage namespace, e.g. shop-dev, prepended to the names of all the lambda functions and tables")
 
 	cmd.Execute()
 }
@@ -79,6 +97,37 @@ type ServerlessTemplateInput struct {
 	StateFuncs    []parser.StateChangingHandler
 	CustomCtors   []parser.CustomCtorDefinition
 	ManyToManyRel bool
+	FindBy        bool
+	// NamePrefix precedes the names of the functions and the tables
+	NamePrefix string
+}
+
+func initializeDatabase(dbType, dbPath, tablePrefix string, parsedPkg parser.ParsedPackage) {
+	switch dbType {
+	case "dynamodb":
+		database.CreateTypeTables(parsedPkg, database.NewDynamoDBTableCreator(), tablePrefix)
+	case "sqlite":
+		creator, closeDb, err := database.NewSQLiteTableCreator(dbPath)
+		if err != nil {
+			fmt.Println("Fatal error occurred opening SQLite database: ", err)
+			os.Exit(1)
+		}
+		defer closeDb()
+		database.CreateTypeTables(parsedPkg, creator, tablePrefix)
+	default:
+		fmt.Println("Unknown database type: ", dbType, ". Supported types are dynamodb and sqlite")
+		os.Exit(1)
+	}
+}
+
+// setConflictRetries sets the number of retries of the state changing
+// handlers whose receivers are versioned types
+func setConflictRetries(handlers []parser.StateChangingHandler, typesWithVersion map[string]string, retries int) {
+	for i := range handlers {
+		if _, isVersioned := typesWithVersion[handlers[i].ReceiverType]; isVersioned {
+			handlers[i].MaxRetries = retries
+		}
+	}
 }
 
 func generateDeploymentFiles(path string, templateInput ServerlessTemplateInput) {
@@ -118,7 +167,24 @@ func generateGenericHandlers(path string, parsedPkg parser.ParsedPackage) {
 	generationDestPath = tp.MakePathAbosoluteOrExitOnError(filepath.Join(path, "generated", "generics", "SetField"))
 	os.MkdirAll(generationDestPath, 0777)
 	setPath := filepath.Join(generationDestPath, "SetField.go")
-	tp.CreateFile("template/type_spec/set_field_template.go.tmpl", nil, setPath)
+	setFieldTemplInput := typespec.SetFieldTemplateInput{OrginalPackages: map[string]string{},
+		TypeAliases:                typeAliases(parsedPkg),
+		IsNobjectInOrginalPackage:  parsedPkg.IsNobjectInOrginalPackage,
+		TypesWithVersion:           parsedPkg.TypesWithVersion,
+		TypesWithValidation:        parsedPkg.TypesWithValidation,
+		TypesWithReadonlyFields:    parsedPkg.TypesWithReadonlyFields,
+		TypesWithCheckedReferences: parsedPkg.TypesWithCheckedReferences,
+		TypesWithUniqueFields:      parsedPkg.TypesWithUniqueFields,
+	}
+	for _, typesWithChecks := range []map[string][]string{parsedPkg.TypesWithValidation, parsedPkg.TypesWithReadonlyFields,
+		parsedPkg.TypesWithCheckedReferences, parsedPkg.TypesWithUniqueFields} {
+		for typeName := range typesWithChecks {
+			if parsedPkg.IsNobjectInOrginalPackage[typeName] {
+				maps.Copy(setFieldTemplInput.OrginalPackages, parsedPkg.ImportsOf(parsedPkg.QualifiedType(typeName)))
+			}
+		}
+	}
+	tp.CreateFile("template/type_spec/set_field_template.go.tmpl", setFieldTemplInput, setPath)
 
 	generationDestPath = tp.MakePathAbosoluteOrExitOnError(filepath.Join(path, "generated", "generics", "Load"))
 	os.MkdirAll(generationDestPath, 0777)
@@ -129,18 +195,36 @@ func generateGenericHandlers(path string, parsedPkg parser.ParsedPackage) {
 	os.MkdirAll(generationDestPath, 0777)
 	exportPath := filepath.Join(generationDestPath, "Export.go")
 	input := typespec.ExportTemplateInput{IsNobjectInOrginalPackage: parsedPkg.IsNobjectInOrginalPackage,
-		OrginalPackageAlias: parser.OrginalPackageAlias, OrginalPackage: parsedPkg.ImportPath,
+		OrginalPackages: map[string]string{}, TypeAliases: typeAliases(parsedPkg),
 		TypesWithCustomExport: parsedPkg.TypesWithCustomExport,
 	}
+	for typeName, isNobject := range parsedPkg.IsNobjectInOrginalPackage {
+		if isNobject {
+			maps.Copy(input.OrginalPackages, parsedPkg.ImportsOf(parsedPkg.QualifiedType(typeName), parsedPkg.TypesWithCustomExport[typeName].InputParameterType))
+		}
+	}
 	tp.CreateFile("template/type_spec/export_template.go.tmpl", input, exportPath)
 	tp.RunGoimportsOnFile(generationDestPath)
 
 	generationDestPath = tp.MakePathAbosoluteOrExitOnError(filepath.Join(path, "generated", "generics", "Delete"))
 	os.MkdirAll(generationDestPath, 0777)
 	deletePath := filepath.Join(generationDestPath, "Delete.go")
-	deleteTemplInput := typespec.DeleteTemplateInput{OrginalPackageAlias: parser.OrginalPackageAlias,
-		OrginalPackage:        parsedPkg.ImportPath,
+	deleteTemplInput := typespec.DeleteTemplateInput{OrginalPackages: map[string]string{},
+		TypeAliases:           typeAliases(parsedPkg),
 		TypesWithCustomDelete: parsedPkg.TypesWithCustomDelete,
+		TypesDeletedWithLib:   map[string]bool{},
+	}
+	for typeName, customDelete := range parsedPkg.TypesWithCustomDelete {
+		maps.Copy(deleteTemplInput.OrginalPackages, parsedPkg.ImportsOf(parsedPkg.QualifiedType(typeName), customDelete.InputParameterType))
+	}
+	for _, typesDeletedWithLib := range []map[string][]string{parsedPkg.TypesWithDeletePolicies, parsedPkg.TypesWithUniqueFields} {
+		for typeName := range typesDeletedWithLib {
+			// the custom delete functions are expected to use lib.Delete
+			if _, isCustom := parsedPkg.TypesWithCustomDelete[typeName]; !isCustom {
+				deleteTemplInput.TypesDeletedWithLib[typeName] = true
+				maps.Copy(deleteTemplInput.OrginalPackages, parsedPkg.ImportsOf(parsedPkg.QualifiedType(typeName)))
+			}
+		}
 	}
 	tp.CreateFile("template/type_spec/delete_template.go.tmpl", deleteTemplInput, deletePath)
 	tp.RunGoimportsOnFile(generationDestPath)
@@ -160,6 +244,18 @@ func generateGenericHandlers(path string, parsedPkg parser.ParsedPackage) {
 	referenceGetStubsPath := filepath.Join(generationDestPath, "ReferenceGetStubs.go")
 	tp.CreateFile("template/type_spec/reference_get_stubs.go.tmpl", nil, referenceGetStubsPath)
 
+	generationDestPath = tp.MakePathAbosoluteOrExitOnError(filepath.Join(path, "generated", "reference", "GetPage"))
+	os.MkdirAll(generationDestPath, 0777)
+	referenceGetPagePath := filepath.Join(generationDestPath, "ReferenceGetPage.go")
+	tp.CreateFile("template/type_spec/reference_get_page.go.tmpl", nil, referenceGetPagePath)
+
+	if len(parsedPkg.IndexedFields) > 0 {
+		generationDestPath = tp.MakePathAbosoluteOrExitOnError(filepath.Join(path, "generated", "generics", "FindBy"))
+		os.MkdirAll(generationDestPath, 0777)
+		findByPath := filepath.Join(generationDestPath, "FindBy.go")
+		tp.CreateFile("template/type_spec/find_by.go.tmpl", nil, findByPath)
+	}
+
 	if len(parsedPkg.ManyToManyRelationships) > 0 {
 
 		generationDestPath = tp.MakePathAbosoluteOrExitOnError(filepath.Join(path, "generated", "reference", "AddToManyToMany"))
@@ -189,6 +285,16 @@ func generateCustomConstructorsHandlers(path string, customCtor []parser.CustomC
 	}
 }
 
+// typeAliases returns the aliases of the packages of the
+// types the generic handlers are generated for
+func typeAliases(parsedPkg parser.ParsedPackage) map[string]string {
+	aliases := make(map[string]string, len(parsedPkg.IsNobjectInOrginalPackage))
+	for typeName := range parsedPkg.IsNobjectInOrginalPackage {
+		aliases[typeName] = parsedPkg.TypeAlias(typeName)
+	}
+	return aliases
+}
+
 func lastElem(ss []string) string {
 	return ss[len(ss)-1]
 }
@@ -4,17 +4,42 @@ import (
 	"fmt"
 
 	"github.com/Astenna/Nubes/generator/parser"
+	"github.com/Astenna/Nubes/lib"
+	"github.com/Astenna/Nubes/lib/sqlite"
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/dynamodb"
 )
 
-func CreateTypeTables(parsedPackage parser.ParsedPackage) {
+// TableCreator creates the tables of the types. It is implemented
+// by the DynamoDB client as well as by the SQLite store of the lib.
+type TableCreator interface {
+	CreateTable(input *dynamodb.CreateTableInput) (*dynamodb.CreateTableOutput, error)
+}
+
+func NewDynamoDBTableCreator() TableCreator {
 	var _session = session.Must(session.NewSessionWithOptions(session.Options{
 		SharedConfigState: session.SharedConfigEnable,
 	}))
 
-	var dblient = dynamodb.New(_session)
+	return dynamodb.New(_session)
+}
+
+// NewSQLiteTableCreator opens the SQLite database, which must
+// be closed with the returned function once tables are created
+func NewSQLiteTableCreator(path string) (TableCreator, func() error, error) {
+	store, err := sqlite.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	return store, store.Close, nil
+}
+
+// CreateTypeTables creates the tables of the types, the join tables of the many-to-many
+// relationships and the table guarding the values of the unique fields, if needed.
+// The names of the tables start with the tablePrefix.
+func CreateTypeTables(parsedPackage parser.ParsedPackage, dblient TableCreator, tablePrefix string) {
+	createUniqueValuesTable(parsedPackage, dblient, tablePrefix)
 
 	for typeName, isNobjectType := range parsedPackage.IsNobjectInOrginalPackage {
 		if isNobjectType {
@@ -32,33 +57,63 @@ func CreateTypeTables(parsedPackage parser.ParsedPackage) {
 						KeyType:       aws.String("HASH"),
 					},
 				},
-				TableName: aws.String(typeName),
+				TableName: aws.String(tablePrefix + typeName),
 			}
 
-			if typeIndexes, ok := parsedPackage.TypeAttributesIndexes[typeName]; ok {
-				for _, attributeName := range typeIndexes {
-					createTableInput.GlobalSecondaryIndexes = []*dynamodb.GlobalSecondaryIndex{
-						{
-							IndexName: aws.String(typeName + attributeName),
-							KeySchema: []*dynamodb.KeySchemaElement{
-								{
-									AttributeName: aws.String(attributeName),
-									KeyType:       aws.String("HASH"),
-								},
+			indexedAttributes := map[string]struct{}{}
+			for _, attributeName := range parsedPackage.TypeAttributesIndexes[typeName] {
+				if _, exists := indexedAttributes[attributeName]; exists {
+					continue
+				}
+				indexedAttributes[attributeName] = struct{}{}
+
+				// the attributes referring to other types are strings,
+				// the type of attributes tagged as index depends on the field type
+				attributeType := "S"
+				if indexedFieldType, ok := parsedPackage.IndexedFields[typeName][attributeName]; ok {
+					attributeType = indexedFieldType
+				}
+
+				addAttributeDefinition(createTableInput, attributeName, attributeType)
+				createTableInput.GlobalSecondaryIndexes = append(createTableInput.GlobalSecondaryIndexes,
+					&dynamodb.GlobalSecondaryIndex{
+						IndexName: aws.String(typeName + attributeName),
+						KeySchema: []*dynamodb.KeySchemaElement{
+							{
+								AttributeName: aws.String(attributeName),
+								KeyType:       aws.String("HASH"),
 							},
-							Projection: &dynamodb.Projection{
-								ProjectionType: aws.String("KEYS_ONLY"),
+						},
+						Projection: &dynamodb.Projection{
+							ProjectionType: aws.String("KEYS_ONLY"),
+						},
+					},
+				)
+			}
+
+			for _, index := range parsedPackage.TypeSortedIndexes[typeName] {
+				addAttributeDefinition(createTableInput, index.PartitionAttributeName, "S")
+				addAttributeDefinition(createTableInput, index.SortAttributeName, index.SortAttributeType)
+				createTableInput.GlobalSecondaryIndexes = append(createTableInput.GlobalSecondaryIndexes,
+					&dynamodb.GlobalSecondaryIndex{
+						IndexName: aws.String(typeName + index.PartitionAttributeName + index.SortAttributeName),
+						KeySchema: []*dynamodb.KeySchemaElement{
+							{
+								AttributeName: aws.String(index.PartitionAttributeName),
+								KeyType:       aws.String("HASH"),
+							},
+							{
+								AttributeName: aws.String(index.SortAttributeName),
+								KeyType:       aws.String("RANGE"),
 							},
 						},
-					}
-					createTableInput.AttributeDefinitions = append(createTableInput.AttributeDefinitions,
-						&dynamodb.AttributeDefinition{
-							AttributeName: aws.String(attributeName),
-							AttributeType: aws.String("S"),
+						Projection: &dynamodb.Projection{
+							ProjectionType: aws.String("KEYS_ONLY"),
 						},
-					)
-				}
+					},
+				)
 			}
+
 			_, err := dblient.CreateTable(createTableInput)
 
 			if err != nil {
@@ -98,7 +153,7 @@ func CreateTypeTables(parsedPackage parser.ParsedPackage) {
 							KeyType:       aws.String("RANGE"),
 						},
 					},
-					TableName: aws.String(relationship.TableName),
+					TableName: aws.String(tablePrefix + relationship.TableName),
 					GlobalSecondaryIndexes: []*dynamodb.GlobalSecondaryIndex{
 						{
 							IndexName: aws.String(relationship.TableName + "Reversed"),
@@ -129,3 +184,50 @@ func CreateTypeTables(parsedPackage parser.ParsedPackage) {
 		}
 	}
 }
+
+// createUniqueValuesTable creates the table with the guard items of the
+// values of the unique fields, if any type has the fields tagged with unique
+func createUniqueValuesTable(parsedPackage parser.ParsedPackage, dblient TableCreator, tablePrefix string) {
+	if len(parsedPackage.TypesWithUniqueFields) == 0 {
+		return
+	}
+
+	_, err := dblient.CreateTable(&dynamodb.CreateTableInput{
+		BillingMode: aws.String("PAY_PER_REQUEST"),
+		AttributeDefinitions: []*dynamodb.AttributeDefinition{
+			{
+				AttributeName: aws.String("Id"),
+				AttributeType: aws.String("S"),
+			},
+		},
+		KeySchema: []*dynamodb.KeySchemaElement{
+			{
+				AttributeName: aws.String("Id"),
+				KeyType:       aws.String("HASH"),
+			},
+		},
+		TableName: aws.String(tablePrefix + lib.UniqueValuesTableName),
+	})
+
+	if err != nil {
+		if _, ok := err.(*dynamodb.ResourceInUseException); ok {
+			fmt.Println("Table of the values of the unique fields already created")
+			return
+		}
+		fmt.Println(err)
+	}
+}
+
+// addAttributeDefinition defines the attribute unless it is already defined,
+// the attributes used as keys of several indexes must be defined once
+func addAttributeDefinition(input *dynamodb.CreateTableInput, attributeName, attributeType string) {
+	for _, definition := range input.AttributeDefinitions {
+		if aws.StringValue(definition.AttributeName) == attributeName {
+			return
+		}
+	}
+	input.AttributeDefinitions = append(input.AttributeDefinitions, &dynamodb.AttributeDefinition{
+		AttributeName: aws.String(attributeName),
+		AttributeType: aws.String(attributeType),
+	})
+}
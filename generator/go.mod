@@ -2,16 +2,22 @@ module github.com/Astenna/Nubes/generator
 
 go 1.19
 
+replace github.com/Astenna/Nubes/lib v0.0.0 => ../lib
+
 require (
+	github.com/Astenna/Nubes/lib v0.0.0
 	github.com/aws/aws-sdk-go v1.44.184
 	github.com/spf13/cobra v1.3.0
 	github.com/spf13/cobra-cli v1.3.0
 	golang.org/x/exp v0.0.0-20221217163422-3c43f8badb15
 )
 
+require github.com/google/uuid v1.3.0 // indirect
+
 require (
 	github.com/fatih/structtag v1.2.0
 	github.com/jmespath/go-jmespath v0.4.0 // indirect
+	github.com/mattn/go-sqlite3 v1.14.16 // indirect
 	github.com/mitchellh/mapstructure v1.4.3 // indirect
 )
 
@@ -27,6 +33,7 @@ require (
 	github.com/spf13/pflag v1.0.5 // indirect
 	github.com/spf13/viper v1.10.1 // indirect
 	github.com/subosito/gotenv v1.2.0 // indirect
+	golang.org/x/mod v0.9.0 // indirect
 	golang.org/x/sys v0.5.0 // indirect
 	golang.org/x/text v0.4.0 // indirect
 	golang.org/x/tools v0.6.0
@@ -0,0 +1,161 @@
+package migration
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Astenna/Nubes/generator/parser"
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
+)
+
+// the names of the types of the index keys used in the names of the lib constants
+var indexKeyTypeNames = map[string]string{"S": "String", "N": "Number"}
+
+// Migration holds the steps transforming the items stored with the previous
+// schema, and the notes on the changes that can not be migrated automatically
+type Migration struct {
+	// Steps are the Go expressions creating lib.MigrationStep
+	Steps []string
+	Notes []string
+}
+
+// Diff compares the schemas and returns the migration of the items of the types present in both.
+// The renames map the types to their renamed attributes, from the previous to the current name,
+// as the rename can not be told apart from the removal of one field and the addition of another.
+// The added fields of strings, numbers and booleans are filled with the zero values, unless indexed,
+// the fields changed from and to such types are converted and the newly indexed fields get the indexes.
+func Diff(previous, current Schema, renames map[string]map[string]string) Migration {
+	var migration Migration
+
+	for _, typeName := range sortedKeys(previous.Types) {
+		if _, exists := current.Types[typeName]; !exists {
+			migration.note("%s was removed, its table is left unchanged", typeName)
+		}
+	}
+
+	for _, typeName := range sortedKeys(current.Types) {
+		currentType := current.Types[typeName]
+		previousType, exists := previous.Types[typeName]
+		if !exists {
+			migration.note("%s was added, its table is created with the dbInit flag of the handlers command", typeName)
+			continue
+		}
+
+		// previousNames maps the current attributes to their names in the previous schema
+		previousNames := map[string]string{}
+		for _, from := range sortedKeys(renames[typeName]) {
+			to := renames[typeName][from]
+			_, fromExists := previousType.Fields[from]
+			_, toExists := currentType.Fields[to]
+			if !fromExists || !toExists {
+				fmt.Println("ERROR: The renamed field", from, "of type", typeName, "must exist in the previous schema and", to, "in the current one")
+				continue
+			}
+			previousNames[to] = from
+			migration.step("lib.RenameAttribute(%q, %q, %q)", typeName, from, to)
+		}
+
+		for _, attribute := range sortedKeys(currentType.Fields) {
+			previousName, isRenamed := previousNames[attribute]
+			if !isRenamed {
+				previousName = attribute
+			}
+			migration.addFieldSteps(typeName, attribute, previousType.Fields[previousName], currentType.Fields[attribute], currentType.IndexedFields[attribute] != "")
+		}
+
+		for _, attribute := range sortedKeys(previousType.Fields) {
+			_, exists := currentType.Fields[attribute]
+			if !exists && !isRenamedFrom(renames[typeName], attribute) {
+				migration.note("%s.%s was removed, the attribute is left in the items", typeName, attribute)
+			}
+		}
+
+		for _, attribute := range sortedKeys(currentType.IndexedFields) {
+			previousKeyType, wasIndexed := previousType.IndexedFields[attribute]
+			keyType := currentType.IndexedFields[attribute]
+			if !wasIndexed {
+				migration.step("lib.AddIndex(%q, %q, lib.%sAttributeType)", typeName, attribute, indexKeyTypeNames[keyType])
+			} else if previousKeyType != keyType {
+				migration.note("the key of the index of %s.%s changed its type, the index must be deleted and added again", typeName, attribute)
+			}
+		}
+		for _, attribute := range sortedKeys(previousType.IndexedFields) {
+			if _, isIndexed := currentType.IndexedFields[attribute]; !isIndexed {
+				migration.note("the index of %s.%s is no longer used, it can be deleted from the table", typeName, attribute)
+			}
+		}
+	}
+	return migration
+}
+
+func (m *Migration) addFieldSteps(typeName, attribute, previousFieldType, fieldType string, isIndexed bool) {
+	attributeType, isScalar := getAttributeType(fieldType)
+
+	if previousFieldType == "" {
+		if !isScalar || isIndexed || strings.HasPrefix(fieldType, parser.ReferenceType+"[") {
+			// the empty values of the keys of the indexes and the references are not stored
+			m.note("%s.%s was added, the items without it can be changed with lib.TransformItems", typeName, attribute)
+			return
+		}
+		m.step("lib.FillDefault(%q, %q, %s)", typeName, attribute, getZeroValue(attributeType))
+		return
+	}
+	if previousFieldType == fieldType {
+		return
+	}
+
+	previousAttributeType, wasScalar := getAttributeType(previousFieldType)
+	switch {
+	case !isScalar || !wasScalar:
+		m.note("%s.%s changed its type from %s to %s, the items can be changed with lib.TransformItems", typeName, attribute, previousFieldType, fieldType)
+	case previousAttributeType != attributeType:
+		m.step("lib.ConvertAttribute(%q, %q, lib.%sAttributeType)", typeName, attribute, attributeType)
+	}
+}
+
+func (m *Migration) step(format string, args ...interface{}) {
+	m.Steps = append(m.Steps, fmt.Sprintf(format, args...))
+}
+
+func (m *Migration) note(format string, args ...interface{}) {
+	m.Notes = append(m.Notes, fmt.Sprintf(format, args...))
+}
+
+// getAttributeType returns the name of the type of the attributes
+// of the strings, numbers and booleans and the references
+func getAttributeType(fieldType string) (string, bool) {
+	switch fieldType {
+	case "string":
+		return "String", true
+	case "int", "int8", "int16", "int32", "int64", "uint", "uint8", "uint16", "uint32", "uint64", "float32", "float64":
+		return "Number", true
+	case "bool":
+		return "Bool", true
+	}
+	if strings.HasPrefix(fieldType, parser.ReferenceType+"[") {
+		return "String", true
+	}
+	return "", false
+}
+
+func getZeroValue(attributeType string) string {
+	switch attributeType {
+	case "Number":
+		return "0"
+	case "Bool":
+		return "false"
+	}
+	return `""`
+}
+
+func isRenamedFrom(renames map[string]string, attribute string) bool {
+	_, isRenamed := renames[attribute]
+	return isRenamed
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := maps.Keys(m)
+	slices.Sort(keys)
+	return keys
+}
@@ -0,0 +1,100 @@
+package migration
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"unicode"
+
+	"github.com/Astenna/Nubes/generator/parser"
+	"github.com/Astenna/Nubes/lib"
+)
+
+// Schema is the snapshot of the attributes of the Nobject types stored in the DB,
+// recorded by the migrate command and compared with the types when it's run again
+type Schema struct {
+	Types map[string]TypeSchema
+}
+
+type TypeSchema struct {
+	// Fields maps the attributes to the Go types of their fields
+	Fields map[string]string
+	// IndexedFields maps the attributes with the secondary indexes to the types of the index keys
+	IndexedFields map[string]string
+}
+
+// NewSchema returns the schema of the Nobject types of the parsed package. The exported
+// fields are stored with their names, except for the custom id and version fields, which
+// are stored as the Id and Version attributes. ReferenceNavigationList fields are not stored.
+func NewSchema(parsedPkg parser.ParsedPackage) Schema {
+	schema := Schema{Types: map[string]TypeSchema{}}
+
+	for typeName, isNobject := range parsedPkg.IsNobjectInOrginalPackage {
+		if !isNobject {
+			continue
+		}
+
+		typeSchema := TypeSchema{Fields: map[string]string{}, IndexedFields: map[string]string{}}
+		for fieldName, fieldType := range parsedPkg.TypeFields[typeName] {
+			if !isExported(fieldName) || strings.HasPrefix(fieldType, parser.LibraryReferenceNavigationList) {
+				continue
+			}
+			typeSchema.Fields[getAttributeName(parsedPkg, typeName, fieldName)] = fieldType
+		}
+		for fieldName, attributeType := range parsedPkg.IndexedFields[typeName] {
+			typeSchema.IndexedFields[getAttributeName(parsedPkg, typeName, fieldName)] = attributeType
+		}
+		schema.Types[typeName] = typeSchema
+	}
+	return schema
+}
+
+// LoadSchema reads the schema saved in the file, the second
+// return value is false if the file does not exist
+func LoadSchema(path string) (Schema, bool, error) {
+	var schema Schema
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return schema, false, nil
+	}
+	if err != nil {
+		return schema, false, err
+	}
+	if err = json.Unmarshal(data, &schema); err != nil {
+		return schema, false, fmt.Errorf("failed to read the schema from %s. Error: %w", path, err)
+	}
+	return schema, true, nil
+}
+
+// Save writes the schema to the file
+func (s Schema) Save(path string) error {
+	data, err := s.Marshal()
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// Marshal returns the JSON of the schema, as written by Save
+func (s Schema) Marshal() ([]byte, error) {
+	return json.MarshalIndent(s, "", "  ")
+}
+
+func getAttributeName(parsedPkg parser.ParsedPackage, typeName, fieldName string) string {
+	if parsedPkg.TypesWithCustomId[typeName] == fieldName {
+		return "Id"
+	}
+	if parsedPkg.TypesWithVersion[typeName] == fieldName {
+		return lib.VersionAttributeName
+	}
+	return fieldName
+}
+
+func isExported(fieldName string) bool {
+	for _, r := range fieldName {
+		return unicode.IsUpper(r)
+	}
+	return false
+}
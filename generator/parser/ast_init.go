@@ -230,3 +230,26 @@ func getInitFunctionForType(typeName, idFieldName string, oneToMany []OneToManyR
 
 	return function
 }
+
+// getRegisterTypeFunctionForType returns the init function registering the type
+// in the library, so that the library enforces the rules of its fields when
+// the objects are identified by the type name only, e.g. in lib.SetField
+func getRegisterTypeFunctionForType(typeName string) *ast.FuncDecl {
+	return &ast.FuncDecl{
+		Name: &ast.Ident{Name: "init"},
+		Type: &ast.FuncType{Params: &ast.FieldList{}},
+		Body: &ast.BlockStmt{
+			List: []ast.Stmt{
+				&ast.ExprStmt{X: &ast.CallExpr{
+					Fun: &ast.IndexExpr{
+						X: &ast.SelectorExpr{
+							X:   &ast.Ident{Name: "lib"},
+							Sel: &ast.Ident{Name: RegisterType},
+						},
+						Index: &ast.Ident{Name: typeName},
+					},
+				}},
+			},
+		},
+	}
+}
@@ -63,6 +63,10 @@ type getDBStmtsParam struct {
 	receiverVariableName string
 	fieldName            string
 	fieldType            string
+	isVersioned          bool
+	isValidated          bool
+	isReferenceChecked   bool
+	isUnique             bool
 }
 
 func getGetterDBStmts(fn *ast.FuncDecl, input getDBStmtsParam) ast.IfStmt {
@@ -309,25 +313,125 @@ func getSetterDBStmts(fn *ast.FuncDecl, input getDBStmtsParam) ast.IfStmt {
 					},
 				},
 			}}}
+	if input.isUnique {
+		// the guard items of the unique values are updated together with the field
+		setFieldCall := getFieldFromLib.Rhs[0].(*ast.CallExpr)
+		setFieldCall.Fun = &ast.IndexExpr{
+			X: &ast.SelectorExpr{
+				X:   &ast.Ident{Name: "lib"},
+				Sel: &ast.Ident{Name: SetUniqueField},
+			},
+			Index: &ast.Ident{Name: input.typeName},
+		}
+	}
+	if input.isVersioned {
+		setFieldParam := getFieldFromLib.Rhs[0].(*ast.CallExpr).Args[0].(*ast.CompositeLit)
+		setFieldParam.Elts = append(setFieldParam.Elts, &ast.KeyValueExpr{
+			Key:   &ast.Ident{Name: SetFieldParamVersionedField},
+			Value: &ast.Ident{Name: "true"},
+		})
+	}
 	errorCheck := getErrorCheckExpr(fn, LibErrorVariableName)
 
-	isInitializedCheck.Body.List = []ast.Stmt{&getFieldFromLib, &errorCheck}
+	// the new value is checked against the validation rules of the field
+	// and its referenced objects are checked to exist before it is saved
+	var checks []ast.Stmt
+	if input.isValidated {
+		checks = append(checks, getFieldCheckStmts(fn, input, ValidateField)...)
+	}
+	if input.isReferenceChecked {
+		checks = append(checks, getFieldCheckStmts(fn, input, CheckFieldReferences)...)
+	}
+	if len(checks) > 0 {
+		checks[0].(*ast.AssignStmt).Tok = token.DEFINE
+		getFieldFromLib.Tok = token.ASSIGN
+	}
+	isInitializedCheck.Body.List = append(checks, &getFieldFromLib, &errorCheck)
 	return isInitializedCheck
 }
 
-func getNobjectFunctionProlog(fn *ast.FuncDecl, parsedPackage ParsedPackage) []ast.Stmt {
-	invocationDepthInc := getInvocationDepthInceremntStmt(fn.Recv.List[0].Names[0].Name)
-	isInitializedCheck := getIsInitializedAndInvocationDepthEqOneCheck(fn.Recv.List[0].Names[0].Name)
-	readFromLibExpr := getReadFromLibExpr(fn, parsedPackage.TypesWithCustomId)
+// getFieldCheckStmts returns the invocation of the library function checking
+// the new value of the field, e.g. ValidateField, followed by the error check
+func getFieldCheckStmts(fn *ast.FuncDecl, input getDBStmtsParam, libFunctionName string) []ast.Stmt {
 	errorCheck := getErrorCheckExpr(fn, LibErrorVariableName)
+	return []ast.Stmt{getFieldCheckStmt(input, libFunctionName), &errorCheck}
+}
+
+func getFieldCheckStmt(input getDBStmtsParam, libFunctionName string) *ast.AssignStmt {
+	return &ast.AssignStmt{
+		Tok: token.ASSIGN,
+		Lhs: []ast.Expr{
+			&ast.Ident{Name: LibErrorVariableName},
+		},
+		Rhs: []ast.Expr{
+			&ast.CallExpr{
+				Fun: &ast.SelectorExpr{
+					X:   &ast.Ident{Name: "lib"},
+					Sel: &ast.Ident{Name: libFunctionName},
+				},
+				Args: []ast.Expr{
+					&ast.Ident{Name: input.receiverVariableName},
+					&ast.BasicLit{
+						Kind:  token.STRING,
+						Value: "\"" + input.fieldName + "\"",
+					},
+					&ast.SelectorExpr{
+						X:   &ast.Ident{Name: input.receiverVariableName},
+						Sel: &ast.Ident{Name: input.fieldName},
+					},
+				},
+			},
+		},
+	}
+}
+
+// getNobjectFunctionProlog returns the statements prepended to the state changing
+// methods. The state of the object is retrieved on the first invocation and the
+// deferred epilogue saves the changes when the method returns without an error
+func getNobjectFunctionProlog(fn *ast.FuncDecl, resultErrorName string, parsedPackage ParsedPackage) []ast.Stmt {
+	receiverVariableName := fn.Recv.List[0].Names[0].Name
+	invocationDepthInc := getInvocationDepthInceremntStmt(receiverVariableName)
+	endInvocation := getEndInvocationDeferStmt(fn, resultErrorName)
+	isInitializedCheck := getIsInitializedAndInvocationDepthEqOneCheck(receiverVariableName)
+	readFromLibExpr := getReadFromLibExpr(fn, resultErrorName, parsedPackage.TypesWithCustomId)
+	errorCheck := ast.IfStmt{
+		Cond: &ast.BinaryExpr{
+			X:  &ast.Ident{Name: resultErrorName},
+			Op: token.NEQ,
+			Y:  &ast.Ident{Name: "nil"},
+		},
+		Body: &ast.BlockStmt{
+			List: []ast.Stmt{&ast.ReturnStmt{}},
+		},
+	}
 
 	isInitializedCheck.Body.List = []ast.Stmt{&readFromLibExpr, &errorCheck}
-	return []ast.Stmt{&invocationDepthInc, &isInitializedCheck}
+	return []ast.Stmt{&invocationDepthInc, &endInvocation, &isInitializedCheck}
+}
+
+// getEndInvocationDeferStmt returns the deferred invocation of the epilogue,
+// the methods with value receivers can not modify the state, so their
+// result error is not passed
+func getEndInvocationDeferStmt(fn *ast.FuncDecl, resultErrorName string) ast.DeferStmt {
+	var resultError ast.Expr = &ast.Ident{Name: "nil"}
+	if !isFuncReadonly(fn.Recv) {
+		resultError = &ast.UnaryExpr{Op: token.AND, X: &ast.Ident{Name: resultErrorName}}
+	}
+
+	return ast.DeferStmt{
+		Call: &ast.CallExpr{
+			Fun: &ast.SelectorExpr{
+				X:   &ast.Ident{Name: fn.Recv.List[0].Names[0].Name},
+				Sel: &ast.Ident{Name: EndInvocationMethod},
+			},
+			Args: []ast.Expr{resultError},
+		},
+	}
 }
 
 func getNobjectStateConditionalUpsert(typeName, receiverVarName string, parsedPackage ParsedPackage) ast.IfStmt {
 	isInitializedCheck := getIsInitializedAndInvocationDepthEqOneCheck(receiverVarName)
-	saveExpr := getUpsertInLibExpr(typeName, receiverVarName, parsedPackage.TypesWithCustomId)
+	saveExpr := getSaveChangesInLibExpr(typeName, receiverVarName, parsedPackage.TypesWithCustomId)
 	erorCheck := ast.IfStmt{
 		Cond: &ast.BinaryExpr{
 			X:  &ast.Ident{Name: LibErrorVariableName},
@@ -384,24 +488,98 @@ func getSaveChangesMethodForType(typeName string, parsedPackage ParsedPackage) *
 	return function
 }
 
-func invokeSaveChangesMethodForType(fn *ast.FuncDecl, parsedPackage ParsedPackage) *ast.AssignStmt {
-	return &ast.AssignStmt{
-		Tok: token.DEFINE,
-		Rhs: []ast.Expr{
-			&ast.CallExpr{
-				Fun: &ast.SelectorExpr{
-					X:   &ast.Ident{Name: fn.Recv.List[0].Names[0].Name},
-					Sel: &ast.Ident{Name: SaveChangesIfInitialized},
+// getEndInvocationMethodForType returns the epilogue of the state changing methods.
+// If the method panics, the panic is propagated without saving the changes.
+// Otherwise, the changes are saved if the error returned is nil,
+// and the error of the save replaces it if the save fails
+func getEndInvocationMethodForType(typeName string) *ast.FuncDecl {
+	receiverVarName := "receiver"
+	errorParamName := "err"
+	panicValueName := "panicValue"
+
+	isPanicking := ast.IfStmt{
+		Init: &ast.AssignStmt{
+			Tok: token.DEFINE,
+			Lhs: []ast.Expr{&ast.Ident{Name: panicValueName}},
+			Rhs: []ast.Expr{&ast.CallExpr{Fun: &ast.Ident{Name: "recover"}}},
+		},
+		Cond: &ast.BinaryExpr{
+			X:  &ast.Ident{Name: panicValueName},
+			Op: token.NEQ,
+			Y:  &ast.Ident{Name: "nil"},
+		},
+		Body: &ast.BlockStmt{
+			List: []ast.Stmt{
+				getInvocationDepthDecremntStmt(receiverVarName),
+				&ast.ExprStmt{X: &ast.CallExpr{
+					Fun:  &ast.Ident{Name: "panic"},
+					Args: []ast.Expr{&ast.Ident{Name: panicValueName}},
+				}},
+			},
+		},
+	}
+
+	isResultErrorNil := ast.IfStmt{
+		Cond: &ast.BinaryExpr{
+			Op: token.LAND,
+			X: &ast.BinaryExpr{
+				X:  &ast.Ident{Name: errorParamName},
+				Op: token.NEQ,
+				Y:  &ast.Ident{Name: "nil"},
+			},
+			Y: &ast.BinaryExpr{
+				X:  &ast.StarExpr{X: &ast.Ident{Name: errorParamName}},
+				Op: token.EQL,
+				Y:  &ast.Ident{Name: "nil"},
+			},
+		},
+		Body: &ast.BlockStmt{
+			List: []ast.Stmt{
+				&ast.AssignStmt{
+					Tok: token.ASSIGN,
+					Lhs: []ast.Expr{&ast.StarExpr{X: &ast.Ident{Name: errorParamName}}},
+					Rhs: []ast.Expr{&ast.CallExpr{
+						Fun: &ast.SelectorExpr{
+							X:   &ast.Ident{Name: receiverVarName},
+							Sel: &ast.Ident{Name: SaveChangesIfInitialized},
+						},
+					}},
 				},
 			},
 		},
-		Lhs: []ast.Expr{
-			&ast.Ident{Name: UpsertLibErrorVariableName},
+	}
+
+	return &ast.FuncDecl{
+		Name: &ast.Ident{Name: EndInvocationMethod},
+		Recv: &ast.FieldList{
+			List: []*ast.Field{
+				{
+					Names: []*ast.Ident{{Name: receiverVarName}},
+					Type:  &ast.StarExpr{X: &ast.Ident{Name: typeName}},
+				},
+			},
+		},
+		Body: &ast.BlockStmt{
+			List: []ast.Stmt{
+				&isPanicking,
+				&isResultErrorNil,
+				getInvocationDepthDecremntStmt(receiverVarName),
+			},
+		},
+		Type: &ast.FuncType{
+			Params: &ast.FieldList{
+				List: []*ast.Field{
+					{
+						Names: []*ast.Ident{{Name: errorParamName}},
+						Type:  &ast.StarExpr{X: &ast.Ident{Name: "error"}},
+					},
+				},
+			},
 		},
 	}
 }
 
-func getReadFromLibExpr(fn *ast.FuncDecl, typesWithCustomId map[string]string) ast.AssignStmt {
+func getReadFromLibExpr(fn *ast.FuncDecl, errorVariableName string, typesWithCustomId map[string]string) ast.AssignStmt {
 	typeName := types.ExprString(fn.Recv.List[0].Type)
 	isPointerReceiver := strings.Contains(typeName, "*")
 	typeName = strings.TrimPrefix(typeName, "*")
@@ -414,7 +592,7 @@ func getReadFromLibExpr(fn *ast.FuncDecl, typesWithCustomId map[string]string) a
 	}
 
 	assignStmt := ast.AssignStmt{
-		Tok: token.DEFINE,
+		Tok: token.ASSIGN,
 		Rhs: []ast.Expr{
 			&ast.CallExpr{
 				Fun: &ast.SelectorExpr{
@@ -436,15 +614,24 @@ func getReadFromLibExpr(fn *ast.FuncDecl, typesWithCustomId map[string]string) a
 	} else {
 		(assignStmt.Rhs[0].(*ast.CallExpr)).Args = append((assignStmt.Rhs[0].(*ast.CallExpr)).Args, &ast.UnaryExpr{Op: token.AND, X: ast.NewIdent(fn.Recv.List[0].Names[0].Name)})
 	}
+	// the state retrieved is saved as a snapshot, so that
+	// only the modified fields are saved in saveChangesIfInitialized
+	(assignStmt.Rhs[0].(*ast.CallExpr)).Args = append((assignStmt.Rhs[0].(*ast.CallExpr)).Args, &ast.UnaryExpr{
+		Op: token.AND,
+		X: &ast.SelectorExpr{
+			X:   &ast.Ident{Name: fn.Recv.List[0].Names[0].Name},
+			Sel: &ast.Ident{Name: StateSnapshotFieldName},
+		},
+	})
 
 	assignStmt.Lhs = []ast.Expr{
-		&ast.Ident{Name: LibErrorVariableName},
+		&ast.Ident{Name: errorVariableName},
 	}
 
 	return assignStmt
 }
 
-func getUpsertInLibExpr(typeName, receiverVariableName string, typesWithCustomId map[string]string) ast.AssignStmt {
+func getSaveChangesInLibExpr(typeName, receiverVariableName string, typesWithCustomId map[string]string) ast.AssignStmt {
 	idFieldName := ""
 	if idField, isPresent := typesWithCustomId[typeName]; isPresent {
 		idFieldName = idField
@@ -461,13 +648,17 @@ func getUpsertInLibExpr(typeName, receiverVariableName string, typesWithCustomId
 			&ast.CallExpr{
 				Fun: &ast.SelectorExpr{
 					X:   &ast.Ident{Name: "lib"},
-					Sel: &ast.Ident{Name: Upsert},
+					Sel: &ast.Ident{Name: SaveChanges},
 				},
 				Args: []ast.Expr{
 					&ast.Ident{Name: receiverVariableName},
 					&ast.SelectorExpr{
 						X:   &ast.Ident{Name: receiverVariableName},
 						Sel: &ast.Ident{Name: idFieldName},
+					},
+					&ast.SelectorExpr{
+						X:   &ast.Ident{Name: receiverVariableName},
+						Sel: &ast.Ident{Name: StateSnapshotFieldName},
 					}},
 			},
 		},
@@ -0,0 +1,68 @@
+package parser
+
+import (
+	"go/ast"
+	"go/token"
+)
+
+// getVersionImplementation returns the methods implementing
+// lib.Versioned interface for the type with the given version field
+func getVersionImplementation(typeName, fieldName string) []ast.Decl {
+	receiverName := "receiver"
+	versionParamName := "version"
+
+	getter := &ast.FuncDecl{
+		Name: &ast.Ident{Name: VersionGetterMethod},
+		Recv: &ast.FieldList{
+			List: []*ast.Field{
+				{
+					Names: []*ast.Ident{{Name: receiverName}},
+					Type:  &ast.Ident{Name: typeName},
+				},
+			},
+		},
+		Body: &ast.BlockStmt{
+			List: []ast.Stmt{&ast.ReturnStmt{
+				Results: []ast.Expr{&ast.SelectorExpr{
+					X:   &ast.Ident{Name: receiverName},
+					Sel: &ast.Ident{Name: fieldName},
+				}},
+			}},
+		},
+		Type: &ast.FuncType{
+			Params: &ast.FieldList{},
+			Results: &ast.FieldList{
+				List: []*ast.Field{{Type: &ast.Ident{Name: "int"}}},
+			},
+		},
+	}
+
+	setter := &ast.FuncDecl{
+		Name: &ast.Ident{Name: VersionSetterMethod},
+		Recv: &ast.FieldList{
+			List: []*ast.Field{
+				{
+					Names: []*ast.Ident{{Name: receiverName}},
+					Type:  &ast.StarExpr{X: &ast.Ident{Name: typeName}},
+				},
+			},
+		},
+		Body: &ast.BlockStmt{
+			List: []ast.Stmt{&ast.AssignStmt{
+				Tok: token.ASSIGN,
+				Lhs: []ast.Expr{&ast.SelectorExpr{
+					X:   &ast.Ident{Name: receiverName},
+					Sel: &ast.Ident{Name: fieldName},
+				}},
+				Rhs: []ast.Expr{&ast.Ident{Name: versionParamName}},
+			}},
+		},
+		Type: &ast.FuncType{
+			Params: &ast.FieldList{
+				List: []*ast.Field{{Names: []*ast.Ident{{Name: versionParamName}}, Type: &ast.Ident{Name: "int"}}},
+			},
+		},
+	}
+
+	return []ast.Decl{getter, setter}
+}
@@ -3,9 +3,7 @@ package parser
 import (
 	"fmt"
 	"go/ast"
-	"go/parser"
 	"go/token"
-	"go/types"
 	"strings"
 )
 
@@ -18,6 +16,7 @@ type ClientTypesParser struct {
 	functions []*ast.FuncDecl
 	tokenSet  *token.FileSet
 	packs     map[string]*ast.Package
+	resolver  *typeResolver
 }
 
 type StructTypeDefinition struct {
@@ -27,6 +26,7 @@ type StructTypeDefinition struct {
 	CustomIdFieldName       string
 	TypeNameLower           string
 	TypeNameOrginalCase     string
+	TypeNamePlural          string
 	MemberFunctions         []MethodDefinition
 	FieldDefinitions        []FieldDefinition
 	OneToManyRelationships  []OneToManyRelationshipField
@@ -44,6 +44,19 @@ type MethodDefinition struct {
 	IsReturnTypeNobject     bool
 	IsReturnTypeList        bool
 	IsInputParamNobject     bool
+	// InputParams are the parameters of the methods with more than one parameter,
+	// they are sent to the handler in the envelope struct
+	InputParams []ParamDefinition
+	// ReturnParams are the results of the methods with more than one non-error
+	// result, they are received from the handler in the envelope struct
+	ReturnParams []ParamDefinition
+}
+
+type ParamDefinition struct {
+	Name string
+	Type string
+	// FieldName is the name of the field of the envelope struct holding the parameter or the result
+	FieldName string
 }
 
 type FieldDefinition struct {
@@ -55,6 +68,9 @@ type FieldDefinition struct {
 	IsReference     bool
 	IsReferenceList bool
 	IsReadonly      bool
+	IsIndexed       bool
+	// IsValidated indicates the field has validation rules in the nubes tag
+	IsValidated bool
 }
 
 type OtherDecls struct {
@@ -62,15 +78,22 @@ type OtherDecls struct {
 	GenDecls []string
 }
 
-func NewClientTypesParser(path string) (*ClientTypesParser, error) {
+// NewClientTypesParser parses the packages in the paths, a path ending with /... denotes
+// the package and the packages nested in it. The types of all the packages are defined
+// in the single package of the client library.
+func NewClientTypesParser(paths ...string) (*ClientTypesParser, error) {
 	typeSpec := new(ClientTypesParser)
 	typeSpec.tokenSet = token.NewFileSet()
-	packs, err := parser.ParseDir(typeSpec.tokenSet, path, nil, 0)
+	packs, resolver, err := loadPackages(typeSpec.tokenSet, paths)
 	if err != nil {
 		return nil, err
 	}
+	if _, err := declaredTypes(packs); err != nil {
+		return nil, err
+	}
 
 	typeSpec.packs = packs
+	typeSpec.resolver = resolver
 	typeSpec.DefinedTypes = make(map[string]*StructTypeDefinition)
 	typeSpec.OtherDecls = OtherDecls{}
 	return typeSpec, nil
@@ -99,6 +122,16 @@ func (t *ClientTypesParser) detectAndSetNobjectsReturnTypes() {
 				typeDefinition.MemberFunctions[i].IsInputParamNobject = true
 				typeDefinition.MemberFunctions[i].InputParamType = function.InputParamType
 			}
+			for j, param := range typeDefinition.MemberFunctions[i].InputParams {
+				if isNobject(param.Type, t.DefinedTypes) {
+					typeDefinition.MemberFunctions[i].InputParams[j].Type = param.Type + "Stub"
+				}
+			}
+			for j, result := range typeDefinition.MemberFunctions[i].ReturnParams {
+				if isNobject(result.Type, t.DefinedTypes) {
+					typeDefinition.MemberFunctions[i].ReturnParams[j].Type = result.Type + "Stub"
+				}
+			}
 		}
 	}
 }
@@ -112,7 +145,7 @@ func (t *ClientTypesParser) detectCustomImplementations() {
 				continue
 			}
 
-			param, err := getFunctionParm(fn.Type.Params, t.DefinedTypes)
+			param, err := getFunctionParm(fn.Type.Params, t.resolver)
 			if err != nil {
 				fmt.Println("Maximum allowed number of parameters is 1. Custom export generation for " + fn.Name.Name + "skipped")
 				continue
@@ -130,7 +163,7 @@ func (t *ClientTypesParser) detectCustomImplementations() {
 				continue
 			}
 
-			param, err := getFunctionParm(fn.Type.Params, t.DefinedTypes)
+			param, err := getFunctionParm(fn.Type.Params, t.resolver)
 			if err != nil {
 				fmt.Println("Maximum allowed number of parameters is 1. Custom delete generation for " + fn.Name.Name + "skipped")
 				continue
@@ -146,7 +179,7 @@ func (t *ClientTypesParser) detectCustomImplementations() {
 			if !isNobject(typeName, t.DefinedTypes) {
 				continue
 			}
-			param, err := getFunctionParm(fn.Type.Params, t.DefinedTypes)
+			param, err := getFunctionParm(fn.Type.Params, t.resolver)
 			if err != nil {
 				fmt.Println("Maximum allowed number of parameters is 1. Custom constructor generation for " + fn.Name.Name + "skipped")
 				continue
@@ -177,13 +210,13 @@ func isReturnTypeList(typeName string) bool {
 	return strings.HasPrefix(typeName, "[]")
 }
 
-func getFunctionParm(params *ast.FieldList, definedStructs map[string]*StructTypeDefinition) (string, error) {
+func getFunctionParm(params *ast.FieldList, resolver *typeResolver) (string, error) {
 	if params.List == nil || len(params.List) == 0 {
 		return "", nil
 	} else if len(params.List) > 1 {
 		return "", fmt.Errorf("maximum allowed number of parameters is 1")
 	}
 
-	inputParamType := types.ExprString(params.List[0].Type)
+	inputParamType := resolver.typeString(params.List[0].Type)
 	return inputParamType, nil
 }
@@ -6,7 +6,6 @@ import (
 	"go/ast"
 	"go/printer"
 	"go/token"
-	"go/types"
 	"strings"
 )
 
@@ -26,10 +25,12 @@ func (t *ClientTypesParser) detectGenDecls() {
 
 								t.DefinedTypes[typeName].TypeNameOrginalCase = typeName
 								t.DefinedTypes[typeName].TypeNameLower = lowerCasedFirstChar(typeName)
+								t.DefinedTypes[typeName].TypeNamePlural = pluralized(typeName)
 
 								t.parseStructFields(strctType, typeName)
 							} else {
 								// DETECT AND SAVE CUSTOM TYPES (e.g. type MyInt int)
+								t.resolver.unqualifyParsedPackages(typeSpec)
 								def, err := getTypeSpecAsString(t.tokenSet, typeSpec)
 								if err != nil {
 									fmt.Println(err)
@@ -41,6 +42,7 @@ func (t *ClientTypesParser) detectGenDecls() {
 					}
 					// DETECT AND SAVE CONST DECLARATIONS
 					if genDecl.Tok == token.CONST {
+						t.resolver.unqualifyParsedPackages(genDecl)
 						constStr, err := getConstAsString(t.tokenSet, genDecl)
 						if err != nil {
 							fmt.Println(err)
@@ -60,7 +62,7 @@ func (t *ClientTypesParser) parseStructFields(astStrct *ast.StructType, typeName
 	}
 
 	for _, field := range astStrct.Fields.List {
-		fieldType := strings.TrimPrefix(types.ExprString(field.Type), "*")
+		fieldType := strings.TrimPrefix(t.resolver.typeString(field.Type), "*")
 
 		if field.Names[0].IsExported() {
 			field.Names[0].Name = lowerCasedFirstChar(field.Names[0].Name)
@@ -141,11 +143,14 @@ func parseFields(field *ast.Field, fieldType string, structDef *StructTypeDefini
 		newFieldDefinition.IsReference = true
 	}
 
-	if field.Names[0].Name == "id" || (field.Tag != nil && isFieldReadonly(field)) {
+	// the version is managed by the library, hence it can not be set by the clients
+	if field.Names[0].Name == "id" || (field.Tag != nil && (isFieldReadonly(field) || isVersionField(field))) {
 		newFieldDefinition.IsReadonly = true
 	}
 	if field.Tag != nil {
 		newFieldDefinition.Tags = field.Tag.Value
+		newFieldDefinition.IsIndexed = isIndexField(field)
+		newFieldDefinition.IsValidated = len(getValidationRules(field)) > 0
 	}
 
 	structDef.FieldDefinitions = append(structDef.FieldDefinitions, newFieldDefinition)
@@ -184,6 +189,18 @@ func lowerCasedFirstChar(str string) string {
 	return str
 }
 
+// pluralized returns the plural form of the english noun used as a type name
+func pluralized(str string) string {
+	lower := strings.ToLower(str)
+	switch {
+	case strings.HasSuffix(lower, "y") && len(lower) > 1 && !strings.ContainsRune("aeiou", rune(lower[len(lower)-2])):
+		return str[:len(str)-1] + "ies"
+	case strings.HasSuffix(lower, "s"), strings.HasSuffix(lower, "x"), strings.HasSuffix(lower, "ch"), strings.HasSuffix(lower, "sh"):
+		return str + "es"
+	}
+	return str + "s"
+}
+
 func upperCaseFirstChar(str string) string {
 	if len(str) < 2 {
 		return strings.ToUpper(str)
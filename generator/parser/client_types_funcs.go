@@ -8,6 +8,7 @@ import (
 	"go/printer"
 	"go/token"
 	"go/types"
+	"strconv"
 	"strings"
 )
 
@@ -30,6 +31,11 @@ func (t *ClientTypesParser) detectFuncs() {
 
 						typeName := strings.TrimPrefix(types.ExprString(fn.Recv.List[0].Type), "*")
 						if fn.Name.Name == NobjectImplementationMethod {
+							// without the types, the method is assumed to implement the interface
+							if t.resolver.isTypeChecked() && !t.resolver.implementsNobject(typeName) {
+								continue
+							}
+
 							funcString, err := getFunctionBodyAsString(t.tokenSet, fn.Body)
 							if err != nil {
 								fmt.Println("error occurred when parsing GetTypeName of " + typeName)
@@ -54,13 +60,19 @@ func (t *ClientTypesParser) detectFuncs() {
 							continue
 						}
 
+						// implementation of lib.Versioned is used only by the
+						// library on the server side
+						if fn.Name.Name == VersionGetterMethod || fn.Name.Name == VersionSetterMethod {
+							continue
+						}
+
 						if isGetterOrSetterMethod(fn, typeName, t.DefinedTypes) {
 							continue
 						}
 
 						// at this point, the method is recognized as a general,
 						// state-changing method
-						memberFunction, err := parseMethod(fn)
+						memberFunction, err := parseMethod(fn, t.resolver)
 						if err != nil {
 							fmt.Println("Function "+fn.Name.Name+"not generated in client lib", err)
 							continue
@@ -86,15 +98,11 @@ func getFunctionBodyAsString(fset *token.FileSet, body *ast.BlockStmt) (string,
 	return buf.String(), nil
 }
 
-func parseMethod(fn *ast.FuncDecl) (*MethodDefinition, error) {
+func parseMethod(fn *ast.FuncDecl, resolver *typeResolver) (*MethodDefinition, error) {
 
-	if fn.Type.Results == nil ||
-		types.ExprString(fn.Type.Results.List[len(fn.Type.Results.List)-1].Type) != "error" {
+	if fn.Type.Results == nil || !resolver.isError(fn.Type.Results.List[len(fn.Type.Results.List)-1].Type) {
 		return nil, fmt.Errorf("methods belonging to nobjects must return error type")
 	}
-	if len(fn.Type.Results.List) > 2 {
-		return nil, fmt.Errorf("methods belonging to nobjects can return at most 2 variables")
-	}
 
 	memberFunction := MethodDefinition{
 		FuncName: fn.Name.Name,
@@ -104,44 +112,90 @@ func parseMethod(fn *ast.FuncDecl) (*MethodDefinition, error) {
 		memberFunction.ReceiverName = fn.Recv.List[0].Names[0].Name
 	}
 
-	if len(fn.Type.Results.List) > 1 {
-		memberFunction.OptionalReturnType = types.ExprString(fn.Type.Results.List[0].Type)
+	results := fieldTypes(fn.Type.Results)
+	results = results[:len(results)-1]
+	if len(results) == 1 {
+		memberFunction.OptionalReturnType = resolver.typeString(results[0])
+	} else if len(results) > 1 {
+		names := envelopeFieldNames(fn.Type.Results, EnvelopeResultPrefix)
+		for i, result := range results {
+			memberFunction.ReturnParams = append(memberFunction.ReturnParams, ParamDefinition{FieldName: names[i], Type: resolver.typeString(result)})
+		}
 	}
 
-	if len(fn.Type.Params.List) > 1 {
-		return nil, fmt.Errorf("methods belonging to nobjects can have at most 1 parameter")
-	} else if len(fn.Type.Params.List) == 1 {
-		memberFunction.InputParamType = types.ExprString(fn.Type.Params.List[0].Type)
+	params := fieldTypes(fn.Type.Params)
+	if len(params) > 0 {
+		if _, isVariadic := params[len(params)-1].(*ast.Ellipsis); isVariadic {
+			return nil, fmt.Errorf("variadic parameters are not supported")
+		}
+	}
+	if len(params) == 1 {
+		memberFunction.InputParamType = resolver.typeString(params[0])
+	} else if len(params) > 1 {
+		memberFunction.InputParams = getMethodParams(fn.Type.Params, memberFunction.ReceiverName, resolver)
 	}
 
 	return &memberFunction, nil
 }
 
+// getMethodParams returns the parameters of the methods with more than one parameter.
+// The original names of the parameters are kept unless they are blank or clash with
+// the receiver or the variables of the generated client method.
+func getMethodParams(params *ast.FieldList, receiverName string, resolver *typeResolver) []ParamDefinition {
+	var definitions []ParamDefinition
+	for _, field := range params.List {
+		typeName := resolver.typeString(field.Type)
+		if len(field.Names) == 0 {
+			definitions = append(definitions, ParamDefinition{Type: typeName})
+		}
+		for _, name := range field.Names {
+			definitions = append(definitions, ParamDefinition{Name: name.Name, Type: typeName})
+		}
+	}
+
+	fieldNames := envelopeFieldNames(params, EnvelopeParamPrefix)
+	for i := range definitions {
+		definitions[i].FieldName = fieldNames[i]
+		name := definitions[i].Name
+		if name == "" || name == "_" || name == receiverName || clientMethodVariables[name] {
+			definitions[i].Name = "param" + strconv.Itoa(i)
+		}
+	}
+	return definitions
+}
+
+// clientMethodVariables are the names of the variables declared in the generated client methods
+var clientMethodVariables = map[string]bool{
+	"params": true, "jsonParam": true, "err": true, "_err": true, "out": true, "result": true,
+}
+
 // adjustSubtypesIfInputOrOuputParamsAreReferences changes type specification
 // of generics reference types in input and output parameters so that
 // initialized subtypes are used e.g. Reference<User> -> Reference<user>
 // or ReferenceList<User> -> ReferenceList<user>
 
 func adjustSubtypesIfInputOrOuputParamsAreReferences(methodDefinition *MethodDefinition) {
-	if strings.Contains(methodDefinition.InputParamType, ReferenceListType) {
-		methodDefinition.InputParamType = strings.TrimPrefix(methodDefinition.InputParamType, ReferenceListType)
-		methodDefinition.InputParamType = strings.Trim(methodDefinition.InputParamType, "[]")
-		methodDefinition.InputParamType = ReferenceListType + "[" + lowerCasedFirstChar(methodDefinition.InputParamType) + "]"
-	} else if strings.Contains(methodDefinition.InputParamType, ReferenceType) {
-		methodDefinition.InputParamType = strings.TrimPrefix(methodDefinition.InputParamType, ReferenceType)
-		methodDefinition.InputParamType = strings.Trim(methodDefinition.InputParamType, "[]")
-		methodDefinition.InputParamType = ReferenceType + "[" + lowerCasedFirstChar(methodDefinition.InputParamType) + "]"
+	methodDefinition.InputParamType = adjustReferenceSubtype(methodDefinition.InputParamType)
+	methodDefinition.OptionalReturnType = adjustReferenceSubtype(methodDefinition.OptionalReturnType)
+	for i := range methodDefinition.InputParams {
+		methodDefinition.InputParams[i].Type = adjustReferenceSubtype(methodDefinition.InputParams[i].Type)
 	}
+	for i := range methodDefinition.ReturnParams {
+		methodDefinition.ReturnParams[i].Type = adjustReferenceSubtype(methodDefinition.ReturnParams[i].Type)
+	}
+}
 
-	if strings.Contains(methodDefinition.OptionalReturnType, ReferenceListType) {
-		methodDefinition.OptionalReturnType = strings.TrimPrefix(methodDefinition.OptionalReturnType, ReferenceListType)
-		methodDefinition.OptionalReturnType = strings.Trim(methodDefinition.OptionalReturnType, "[]")
-		methodDefinition.OptionalReturnType = ReferenceListType + "[" + lowerCasedFirstChar(methodDefinition.OptionalReturnType) + "]"
-	} else if strings.Contains(methodDefinition.OptionalReturnType, ReferenceType) {
-		methodDefinition.OptionalReturnType = strings.TrimPrefix(methodDefinition.OptionalReturnType, ReferenceType)
-		methodDefinition.OptionalReturnType = strings.Trim(methodDefinition.OptionalReturnType, "[]")
-		methodDefinition.OptionalReturnType = ReferenceType + "[" + lowerCasedFirstChar(methodDefinition.OptionalReturnType) + "]"
+func adjustReferenceSubtype(typeName string) string {
+	if strings.Contains(typeName, ReferenceListType) {
+		typeName = strings.TrimPrefix(typeName, ReferenceListType)
+		typeName = strings.Trim(typeName, "[]")
+		typeName = ReferenceListType + "[" + lowerCasedFirstChar(typeName) + "]"
+	} else if strings.Contains(typeName, ReferenceType) {
+		typeName = strings.TrimPrefix(typeName, ReferenceType)
+		typeName = strings.Trim(typeName, "[]")
+		typeName = ReferenceType + "[" + lowerCasedFirstChar(typeName) + "]"
 	}
+	return typeName
 }
 
 func getIdFieldNameFromCustomIdImpl(fn *ast.FuncDecl) (string, error) {
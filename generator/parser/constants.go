@@ -3,24 +3,36 @@ package parser
 // METHODS
 const NobjectImplementationMethod = "GetTypeName"
 const CustomIdImplementationMethod = "GetId"
+const VersionGetterMethod = "GetVersion"
+const VersionSetterMethod = "SetVersion"
 const ConstructorPrefix = "New"
 const LibraryGetFieldOfType = "GetFieldOfType"
-const LibraryGetObjectStateMethod = "GetStub"
+const LibraryGetObjectStateMethod = "GetStubWithSnapshot"
 const InitFunctionName = "Init"
 const ReferenceNavigationListCtor = "NewReferenceNavigationList"
 const SetField = "SetField"
+const SetUniqueField = "SetUniqueField"
+const ValidateField = "ValidateField"
+const CheckFieldReferences = "CheckFieldReferences"
 const Upsert = "Upsert"
+const SaveChanges = "SaveChanges"
 const SaveChangesIfInitialized = "saveChangesIfInitialized"
+const EndInvocationMethod = "endInvocation"
+const RegisterType = "RegisterType"
 
 // FIELDS & PARAMETER TYPES
 const GetStateParamType = "GetStateParam"
 const HandlerInputParameterType = "lib.HandlerParameters"
+const NobjectInterface = "Nobject"
 const ReferenceType = "lib.Reference"
 const ReferenceListType = "lib.ReferenceList"
 const LibraryReferenceNavigationList = "lib.ReferenceNavigationList"
 const IsInitializedFieldName = "isInitialized"
 const InvocationDepthFieldName = "invocationDepth"
+const StateSnapshotFieldName = "stateSnapshot"
+const LibrarySnapshotType = "Snapshot"
 const SetFieldParam = "SetFieldParam"
+const SetFieldParamVersionedField = "Versioned"
 const ReferenceNavigationListParam = "ReferenceNavigationListParam"
 
 // TAGS
@@ -29,6 +41,10 @@ const ReadonlyTag = "readonly"
 const IndexTag = "index"
 const HasOneTag = "hasOne"
 const HasManyTag = "hasMany"
+const SortedByTag = "sortedBy"
+const OnDeleteTag = "onDelete"
+const NoReferenceCheckTag = "noReferenceCheck"
+const UniqueTag = "unique"
 const DynamoDBIgnoreTag = "dynamodbav:\"-\""
 const DynamoDBIgnoreValueTag = "-"
 const DynamoDBTagKey = "dynamodbav"
@@ -37,6 +53,19 @@ const DynamoDBIdTag = "dynamodbav:\"Id\""
 const DynamoDBIgnoreEmptyTagValue = "omitempty"
 const DynamoDBIgnoreEmptyTag = "dynamodbav:\",omitempty\""
 const CustomIdTag = "Id"
+const VersionTag = "version"
+const RequiredTag = "required"
+const MinTag = "min"
+const MaxTag = "max"
+const RegexTag = "regex"
+const OneOfTag = "oneof"
+const DynamoDBVersionTagValue = "Version"
+const DynamoDBVersionTag = "dynamodbav:\"Version\""
+
+// DELETE POLICIES
+const CascadeOnDelete = "cascade"
+const RestrictOnDelete = "restrict"
+const NullifyOnDelete = "nullify"
 
 // PARAMETER NAMES
 const Id = "Id"
@@ -44,14 +73,28 @@ const TypeName = "TypeName"
 const FieldName = "FieldName"
 const HandlerInputParameterName = "input"
 const HandlerInputParameterFieldName = "Parameter"
+
+// the prefixes of the names of the fields of the envelope structs
+// holding the unnamed parameters and results of the methods
+const EnvelopeParamPrefix = "Param"
+const EnvelopeResultPrefix = "Result"
 const LibErrorVariableName = "_libError"
-const UpsertLibErrorVariableName = "_libUpsertError"
+const ResultErrorVariableName = "_libResultError"
 const TemporaryReceiverName = "tempReceiverName"
 
 // OTHERS
-const LibImportPath = "\"github.com/Astenna/Nubes/lib\""
+const LibPackagePath = "github.com/Astenna/Nubes/lib"
+const LibImportPath = "\"" + LibPackagePath + "\""
 const OrginalPackageAlias = "org"
 
+// ShadowPackagesDirectory is the directory next to the types package, in which
+// the translated types are saved, e.g. nubes/types for the types package and
+// nubes/types/billing for the package nested in it.
+// The source files of the types are left unchanged.
+const ShadowPackagesDirectory = "nubes"
+const GeneratedFileHeader = "// Code generated by Nubes generator from %s. DO NOT EDIT.\n\n"
+const GeneratedFileHeaderPrefix = "Code generated by Nubes generator"
+
 const (
 	CustomExportPrefix = "Export"
 	CustomDeletePrefix = "Delete"
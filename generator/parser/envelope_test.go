@@ -0,0 +1,34 @@
+package parser
+
+import (
+	"go/ast"
+	"go/parser"
+	"reflect"
+	"testing"
+)
+
+func TestEnvelopeFieldNamesFallBackToPositionsForUnnamedOnes(t *testing.T) {
+	cases := map[string][]string{
+		"func(quantity int, price float64)": {"Quantity", "Price"},
+		"func(int, float64)":                {"Param0", "Param1"},
+		"func(x, y int, _ string)":          {"X", "Y", "Param2"},
+		"func(name string, Name string)":    {"Name", "Param1"},
+		"func(_ int, param0 string)":        {"Param0", "Param1"},
+	}
+
+	for signature, expected := range cases {
+		// Arrange
+		expr, err := parser.ParseExpr(signature)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// Act
+		names := envelopeFieldNames(expr.(*ast.FuncType).Params, EnvelopeParamPrefix)
+
+		// Assert
+		if !reflect.DeepEqual(names, expected) {
+			t.Errorf("%s: expected %v, got %v", signature, expected, names)
+		}
+	}
+}
@@ -0,0 +1,388 @@
+package parser
+
+import (
+	"fmt"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"golang.org/x/tools/go/ast/astutil"
+	"golang.org/x/tools/go/packages"
+)
+
+// typeResolver resolves the types of the expressions of the parsed packages with
+// go/types, so that the types are recognised regardless of how they are written,
+// e.g. with import aliases, dot imports or type aliases. If the packages could not
+// be type-checked, the types are recognised by the expressions as written.
+type typeResolver struct {
+	// pkgs are the type-checked packages by their import paths
+	pkgs map[string]*types.Package
+	// importPaths are the import paths of the packages by their directories
+	importPaths map[string]string
+	info        *types.Info
+	nobject     *types.Interface
+}
+
+// loadPackages parses and type-checks the packages in the paths. A path ending with
+// /... denotes the package in the directory and all the packages nested in it. The
+// files and the export data of the imported packages are listed with go/packages,
+// the packages are type-checked with go/types. The syntax trees are returned by the
+// directories of the packages, without the test files, the main packages and the
+// packages generated by Nubes. If the packages can not be listed, e.g. the paths
+// are not a part of any module, the files are only parsed.
+func loadPackages(tokenSet *token.FileSet, paths []string) (map[string]*ast.Package, *typeResolver, error) {
+	config := &packages.Config{
+		Mode: packages.NeedName | packages.NeedFiles | packages.NeedImports | packages.NeedDeps | packages.NeedExportFile,
+		Dir:  rootDir(paths[0]),
+	}
+	loaded, err := packages.Load(config, paths...)
+	var listed []*packages.Package
+	for _, pkg := range loaded {
+		// the errors of the packages that were listed, e.g.
+		// compilation errors, are reported by the type checker
+		if len(pkg.GoFiles) > 0 && pkg.Name != "main" {
+			listed = append(listed, pkg)
+		}
+	}
+	if err == nil && len(listed) == 0 {
+		err = fmt.Errorf("no packages with files listed in %v", paths)
+	}
+	if err != nil {
+		fmt.Println("WARNING: the packages in", paths, "could not be loaded, the types are recognised by their names. Error:", err)
+		packs, err := parsePackages(tokenSet, paths)
+		if err != nil {
+			return nil, nil, err
+		}
+		return packs, &typeResolver{}, nil
+	}
+
+	packs := map[string]*ast.Package{}
+	syntax := map[string][]*ast.File{}
+	importPaths := map[string]string{}
+	for _, pkg := range listed {
+		files := make(map[string]*ast.File, len(pkg.GoFiles))
+		for _, fileName := range pkg.GoFiles {
+			f, err := parser.ParseFile(tokenSet, fileName, nil, parser.ParseComments)
+			if err != nil {
+				return nil, nil, err
+			}
+			files[fileName] = f
+			syntax[pkg.PkgPath] = append(syntax[pkg.PkgPath], f)
+		}
+		if isGeneratedByNubes(files) {
+			delete(syntax, pkg.PkgPath)
+			continue
+		}
+		dir := filepath.Dir(pkg.GoFiles[0])
+		packs[dir] = &ast.Package{Name: pkg.Name, Files: files}
+		importPaths[dir] = pkg.PkgPath
+	}
+
+	exportFiles := map[string]string{}
+	packages.Visit(loaded, nil, func(imported *packages.Package) {
+		exportFiles[imported.PkgPath] = imported.ExportFile
+	})
+	// the importer is shared, so that the packages imported
+	// by several parsed packages are read only once
+	imp := importer.ForCompiler(tokenSet, "gc", func(importPath string) (io.ReadCloser, error) {
+		if exportFiles[importPath] == "" {
+			return nil, fmt.Errorf("no export data of the package %s", importPath)
+		}
+		return os.Open(exportFiles[importPath])
+	})
+	info := &types.Info{
+		Types: map[ast.Expr]types.TypeAndValue{},
+		Defs:  map[*ast.Ident]types.Object{},
+		Uses:  map[*ast.Ident]types.Object{},
+	}
+	checked := map[string]*types.Package{}
+	for dir, importPath := range importPaths {
+		typesConfig := types.Config{
+			Importer: imp,
+			Sizes:    types.SizesFor("gc", runtime.GOARCH),
+			Error: func(err error) {
+				fmt.Println("WARNING: type checking of the package in", dir, "failed:", err)
+			},
+		}
+		// the errors are reported above, the types of the invalid
+		// declarations are recognised by their names
+		checked[importPath], _ = typesConfig.Check(importPath, tokenSet, syntax[importPath], info)
+	}
+	return packs, newTypeResolver(checked, importPaths, info), nil
+}
+
+// parsePackages parses the packages in the paths without listing them,
+// the directories of the paths ending with /... are walked
+func parsePackages(tokenSet *token.FileSet, paths []string) (map[string]*ast.Package, error) {
+	var dirs []string
+	for _, path := range paths {
+		if !strings.HasSuffix(path, "/...") {
+			dirs = append(dirs, path)
+			continue
+		}
+		err := filepath.WalkDir(rootDir(path), func(dir string, entry fs.DirEntry, err error) error {
+			if err != nil || !entry.IsDir() {
+				return err
+			}
+			// the directories ignored by the go command
+			if dir != rootDir(path) && (strings.HasPrefix(entry.Name(), ".") || strings.HasPrefix(entry.Name(), "_") || entry.Name() == "testdata") {
+				return filepath.SkipDir
+			}
+			dirs = append(dirs, dir)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	packs := map[string]*ast.Package{}
+	for _, dir := range dirs {
+		parsed, err := parser.ParseDir(tokenSet, dir, func(info fs.FileInfo) bool {
+			return !strings.HasSuffix(info.Name(), "_test.go")
+		}, parser.ParseComments)
+		if err != nil {
+			return nil, err
+		}
+		for name, pack := range parsed {
+			if name != "main" && !isGeneratedByNubes(pack.Files) {
+				packs[dir] = pack
+			}
+		}
+	}
+	if len(packs) == 0 {
+		return nil, fmt.Errorf("no packages found in %v", paths)
+	}
+	return packs, nil
+}
+
+// rootDir returns the directory of the path, without the /... suffix
+func rootDir(path string) string {
+	return strings.TrimSuffix(path, "/...")
+}
+
+// isGeneratedByNubes returns true if the files are generated by the generator,
+// e.g. the shadow packages of the types listed with the /... paths
+func isGeneratedByNubes(files map[string]*ast.File) bool {
+	for _, f := range files {
+		if len(f.Comments) == 0 || !strings.HasPrefix(f.Comments[0].Text(), GeneratedFileHeaderPrefix) {
+			return false
+		}
+	}
+	return true
+}
+
+// declaredTypes returns the directories of the packages by the names of the types declared in them.
+// The names of the types identify the objects and name their tables and handlers, so the types
+// declared with the same name in several packages are reported as an error.
+func declaredTypes(packs map[string]*ast.Package) (map[string]string, error) {
+	typeDirs := map[string]string{}
+	for dir, pack := range packs {
+		for _, f := range pack.Files {
+			for _, decl := range f.Decls {
+				genDecl, isGenDecl := decl.(*ast.GenDecl)
+				if !isGenDecl || genDecl.Tok != token.TYPE {
+					continue
+				}
+				for _, spec := range genDecl.Specs {
+					typeName := spec.(*ast.TypeSpec).Name.Name
+					if otherDir, declared := typeDirs[typeName]; declared && otherDir != dir {
+						return nil, fmt.Errorf("the type %s is declared in %s and %s, the names of the types must be unique", typeName, otherDir, dir)
+					}
+					typeDirs[typeName] = dir
+				}
+			}
+		}
+	}
+	return typeDirs, nil
+}
+
+func newTypeResolver(pkgs map[string]*types.Package, importPaths map[string]string, info *types.Info) *typeResolver {
+	resolver := &typeResolver{pkgs: pkgs, importPaths: importPaths, info: info}
+
+	for _, pkg := range pkgs {
+		for _, imported := range pkg.Imports() {
+			if imported.Path() != LibPackagePath {
+				continue
+			}
+			if obj := imported.Scope().Lookup(NobjectInterface); obj != nil {
+				resolver.nobject, _ = obj.Type().Underlying().(*types.Interface)
+			}
+		}
+	}
+	if resolver.nobject == nil {
+		// the packages do not import the library, the interface
+		// is declared in the same way as lib.Nobject
+		typeName := types.NewVar(token.NoPos, nil, "", types.Typ[types.String])
+		getTypeName := types.NewFunc(token.NoPos, nil, NobjectImplementationMethod,
+			types.NewSignatureType(nil, nil, nil, nil, types.NewTuple(typeName), false))
+		resolver.nobject = types.NewInterfaceType([]*types.Func{getTypeName}, nil).Complete()
+	}
+	return resolver
+}
+
+// isTypeChecked returns true if the types of the package are resolved
+func (r *typeResolver) isTypeChecked() bool {
+	return r.info != nil
+}
+
+// typeOf returns the type of the expression with the aliases replaced by the
+// types they denote, nil if the type is unknown, e.g. the expression was added
+// to the ast by the generator
+func (r *typeResolver) typeOf(expr ast.Expr) types.Type {
+	if r.info == nil {
+		return nil
+	}
+	typ := r.info.TypeOf(expr)
+	if typ == nil || typ == types.Typ[types.Invalid] {
+		return nil
+	}
+	return withoutAliases(typ)
+}
+
+// importPath returns the import path of the package in the directory,
+// empty if the packages are not type-checked
+func (r *typeResolver) importPath(dir string) string {
+	return r.importPaths[dir]
+}
+
+// typeString returns the type of the expression as written in the package.
+// The types of the library are qualified with lib, regardless of the name the
+// library is imported with, and the types of the parsed packages are not qualified,
+// as the names of the types are unique among them.
+func (r *typeResolver) typeString(expr ast.Expr) string {
+	typ := r.typeOf(expr)
+	if typ == nil {
+		return types.ExprString(expr)
+	}
+
+	return types.TypeString(typ, func(pkg *types.Package) string {
+		switch {
+		case r.pkgs[pkg.Path()] != nil:
+			return ""
+		case pkg.Path() == LibPackagePath:
+			return "lib"
+		}
+		return pkg.Name()
+	})
+}
+
+// unqualifyParsedPackages removes the qualifiers of the parsed packages from the types
+// referred in the node, e.g. billing.Invoice becomes Invoice, as the types of all the
+// packages are declared in the same package of the client library
+func (r *typeResolver) unqualifyParsedPackages(node ast.Node) {
+	if r.info == nil {
+		return
+	}
+
+	astutil.Apply(node, func(c *astutil.Cursor) bool {
+		selector, isSelector := c.Node().(*ast.SelectorExpr)
+		if !isSelector {
+			return true
+		}
+		if pkg, isIdent := selector.X.(*ast.Ident); isIdent {
+			if pkgName, isPkgName := r.info.Uses[pkg].(*types.PkgName); isPkgName && r.pkgs[pkgName.Imported().Path()] != nil {
+				c.Replace(selector.Sel)
+			}
+		}
+		return true
+	}, nil)
+}
+
+// isError returns true if the expression denotes the error type
+func (r *typeResolver) isError(expr ast.Expr) bool {
+	if typ := r.typeOf(expr); typ != nil {
+		return types.Identical(typ, types.Universe.Lookup("error").Type())
+	}
+	return types.ExprString(expr) == "error"
+}
+
+// nobjectTypes returns the names of the types of the packages implementing lib.Nobject.
+// It returns nil if the packages are not type-checked.
+func (r *typeResolver) nobjectTypes() []string {
+	if r.info == nil {
+		return nil
+	}
+
+	var nobjects []string
+	for _, pkg := range r.pkgs {
+		for _, name := range pkg.Scope().Names() {
+			if r.implementsNobject(name) {
+				nobjects = append(nobjects, name)
+			}
+		}
+	}
+	return nobjects
+}
+
+// implementsNobject returns true if the type of the packages implements
+// lib.Nobject, with a value or a pointer receiver
+func (r *typeResolver) implementsNobject(typeName string) bool {
+	if r.info == nil {
+		return false
+	}
+
+	var obj *types.TypeName
+	for _, pkg := range r.pkgs {
+		if declared, isTypeName := pkg.Scope().Lookup(typeName).(*types.TypeName); isTypeName {
+			obj = declared
+		}
+	}
+	if obj == nil || obj.IsAlias() {
+		return false
+	}
+	if _, isInterface := obj.Type().Underlying().(*types.Interface); isInterface {
+		return false
+	}
+	return types.Implements(obj.Type(), r.nobject) || types.Implements(types.NewPointer(obj.Type()), r.nobject)
+}
+
+// withoutAliases returns the type with all the aliases in it replaced by the types
+// they denote, e.g. the type arguments of lib.Reference or the elements of slices
+func withoutAliases(typ types.Type) types.Type {
+	typ = unalias(typ)
+
+	switch t := typ.(type) {
+	case *types.Pointer:
+		return types.NewPointer(withoutAliases(t.Elem()))
+	case *types.Slice:
+		return types.NewSlice(withoutAliases(t.Elem()))
+	case *types.Array:
+		return types.NewArray(withoutAliases(t.Elem()), t.Len())
+	case *types.Map:
+		return types.NewMap(withoutAliases(t.Key()), withoutAliases(t.Elem()))
+	case *types.Named:
+		if t.TypeArgs().Len() == 0 {
+			return t
+		}
+		typeArgs := make([]types.Type, t.TypeArgs().Len())
+		for i := range typeArgs {
+			typeArgs[i] = withoutAliases(t.TypeArgs().At(i))
+		}
+		if instance, err := types.Instantiate(nil, t.Origin(), typeArgs, false); err == nil {
+			return instance
+		}
+	}
+	return typ
+}
+
+// unalias returns the type denoted by the alias. The aliases are types
+// of their own only in the newer versions of go/types, the Rhs method
+// is used so that the generator still builds with the older ones.
+func unalias(typ types.Type) types.Type {
+	for {
+		alias, isAlias := typ.(interface{ Rhs() types.Type })
+		if !isAlias {
+			return typ
+		}
+		typ = alias.Rhs()
+	}
+}
@@ -1,8 +1,10 @@
 package parser
 
 import (
+	"fmt"
 	"go/ast"
 	"go/token"
+	"regexp"
 	"strconv"
 	"strings"
 
@@ -13,6 +15,107 @@ func isFieldReadonly(field *ast.Field) bool {
 	return field.Tag != nil && strings.Contains(field.Tag.Value, ReadonlyTag) && strings.Contains(field.Tag.Value, NubesTagKey)
 }
 
+func isVersionField(field *ast.Field) bool {
+	tags, err := getParsedTags(field)
+	if err != nil || tags == nil {
+		return false
+	}
+	tag, _ := tags.Get(NubesTagKey)
+	return tag != nil && strings.EqualFold(tag.Name, VersionTag)
+}
+
+func isIndexField(field *ast.Field) bool {
+	tags, err := getParsedTags(field)
+	if err != nil || tags == nil {
+		return false
+	}
+	tag, _ := tags.Get(NubesTagKey)
+	return tag != nil && (strings.EqualFold(tag.Name, IndexTag) || tag.HasOption(IndexTag))
+}
+
+func hasNubesTagOption(field *ast.Field, option string) bool {
+	tags, err := getParsedTags(field)
+	if err != nil || tags == nil {
+		return false
+	}
+	tag, _ := tags.Get(NubesTagKey)
+	return tag != nil && (tag.Name == option || tag.HasOption(option))
+}
+
+func isDynamoDBIgnoredField(field *ast.Field) bool {
+	tags, err := getParsedTags(field)
+	if err != nil || tags == nil {
+		return false
+	}
+	tag, _ := tags.Get(DynamoDBTagKey)
+	return tag != nil && tag.Name == DynamoDBIgnoreValueTag
+}
+
+// getUniqueTagScope returns true if the field is tagged with UniqueTag
+// and the name of the scope field if given, e.g. unique-City
+func getUniqueTagScope(field *ast.Field) (string, bool) {
+	tags, err := getParsedTags(field)
+	if err != nil || tags == nil {
+		return "", false
+	}
+	tag, _ := tags.Get(NubesTagKey)
+	if tag == nil {
+		return "", false
+	}
+
+	for _, option := range append([]string{tag.Name}, tag.Options...) {
+		name, scopeFieldName, _ := strings.Cut(strings.TrimSpace(option), "-")
+		if name == UniqueTag {
+			return scopeFieldName, true
+		}
+	}
+	return "", false
+}
+
+// getValidationRules returns the options of the nubes tag which are validation
+// rules, e.g. required or min=0. They are checked at runtime by the library.
+func getValidationRules(field *ast.Field) []string {
+	tags, err := getParsedTags(field)
+	if err != nil || tags == nil {
+		return nil
+	}
+	tag, _ := tags.Get(NubesTagKey)
+	if tag == nil {
+		return nil
+	}
+
+	var rules []string
+	for _, option := range append([]string{tag.Name}, tag.Options...) {
+		name, _, _ := strings.Cut(strings.TrimSpace(option), "=")
+		switch name {
+		case RequiredTag, MinTag, MaxTag, RegexTag, OneOfTag:
+			rules = append(rules, strings.TrimSpace(option))
+		}
+	}
+	return rules
+}
+
+// checkValidationRule returns an error if the
+// parameter of the validation rule is invalid
+func checkValidationRule(rule string) error {
+	name, parameter, _ := strings.Cut(rule, "=")
+	switch name {
+	case MinTag, MaxTag:
+		if _, err := strconv.ParseFloat(parameter, 64); err != nil {
+			return fmt.Errorf("%s requires a number, e.g. %s=0", name, name)
+		}
+	case RegexTag:
+		if _, err := regexp.Compile(parameter); err != nil {
+			return err
+		}
+	case OneOfTag:
+		if len(strings.Fields(parameter)) == 0 {
+			return fmt.Errorf("%s requires the values separated with spaces", name)
+		}
+	}
+	return nil
+}
+
 func getParsedTags(field *ast.Field) (*structtag.Tags, error) {
 	if field.Tag != nil && field.Tag.Kind == token.STRING {
 		unquotedTag, err := strconv.Unquote(field.Tag.Value)
@@ -4,14 +4,17 @@ import (
 	"bytes"
 	"fmt"
 	"go/ast"
-	"go/parser"
 	"go/printer"
 	"go/token"
 	"go/types"
 	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
 
 	tp "github.com/Astenna/Nubes/generator/template"
+	"golang.org/x/exp/maps"
 )
 
 type TypeSpecParser struct {
@@ -19,29 +22,63 @@ type TypeSpecParser struct {
 	Handlers    []StateChangingHandler
 	CustomCtors []CustomCtorDefinition
 
-	tokenSet                  *token.FileSet
-	packs                     map[string]*ast.Package
-	detectedFunctions         map[string][]detectedFunction
-	isSaveChangesAlreadyAdded map[string]bool
-	isInitAlreadyAdded        map[string]bool
-	fileChanged               map[string]bool
+	// ShadowPackagePaths are the directories in which the translated types
+	// are saved, by the directories of the packages with the types
+	ShadowPackagePaths map[string]string
+
+	// baseDir is the directory of the module name, the packages are
+	// placed in the shadow packages relatively to it
+	baseDir                     string
+	tokenSet                    *token.FileSet
+	packs                       map[string]*ast.Package
+	typeDirs                    map[string]string
+	resolver                    *typeResolver
+	shadowImportPaths           map[string]string
+	detectedFunctions           map[string][]detectedFunction
+	isSaveChangesAlreadyAdded   map[string]bool
+	isEndInvocationAlreadyAdded map[string]bool
+	isInitAlreadyAdded          map[string]bool
+	isVersionAlreadyAdded       map[string]bool
+	fileChanged                 map[string]bool
 }
 
 type ParsedPackage struct {
-	ImportPath                string
+	// TypeImportPaths maps the types to the import paths
+	// of the shadow packages in which they are declared
+	TypeImportPaths map[string]string
+	// PackageAliases maps the import paths of the shadow packages
+	// to the names the generated handlers import them with
+	PackageAliases            map[string]string
 	IsNobjectInOrginalPackage map[string]bool
 	TypeFields                map[string]map[string]string
 	TypeAttributesIndexes     map[string][]string
+	IndexedFields             map[string]map[string]string
+	TypeSortedIndexes         map[string][]SortedIndex
 	BidrectionalOneToManyRel  map[string][]OneToManyRelationshipField
 	ManyToManyRelationships   map[string][]ManyToManyRelationshipField
 	TypesWithCustomId         map[string]string
+	TypesWithVersion          map[string]string
 	TypesWithCustomExport     map[string]CustomExportDefinition
 	TypesWithCustomDelete     map[string]CustomDeleteDefinition
+	// TypesWithValidation maps the types to their fields with validation rules
+	TypesWithValidation map[string][]string
+	// TypesWithReadonlyFields maps the types to their fields tagged with ReadonlyTag
+	TypesWithReadonlyFields map[string][]string
+	// TypesWithDeletePolicies maps the types to their relationships with OnDeleteTag
+	TypesWithDeletePolicies map[string][]string
+	// TypesWithCheckedReferences maps the types to their Reference and ReferenceList
+	// fields, whose referenced objects are checked to exist
+	TypesWithCheckedReferences map[string][]string
+	// TypesWithUniqueFields maps the types to their fields tagged with UniqueTag
+	// and the scope fields of them, the values of both are guarded by the library
+	TypesWithUniqueFields map[string][]string
 }
 
 type CustomCtorDefinition struct {
-	OrginalPackageAlias    string
-	OrginalPackage         string
+	OrginalPackageAlias string
+	// OrginalPackages maps the aliases to the import paths of
+	// the packages of the type and of the parameter
+	OrginalPackages        map[string]string
 	TypeName               string
 	OptionalParamType      string
 	IsOptionalParamNobject bool
@@ -55,31 +92,62 @@ type CustomDeleteDefinition struct {
 	InputParameterType string
 }
 
-func NewTypeSpecParser(path string) (*TypeSpecParser, error) {
+// NewTypeSpecParser parses the packages in the paths, a path ending with /... denotes the package
+// and the packages nested in it. The directory of the first path is expected to be placed
+// in the directory of the module name, e.g. the types directory of the module, and so are
+// the directories of all the packages.
+func NewTypeSpecParser(paths ...string) (*TypeSpecParser, error) {
 	typeSpecParser := new(TypeSpecParser)
 	typeSpecParser.tokenSet = token.NewFileSet()
-	packg, err := parser.ParseDir(typeSpecParser.tokenSet, path, nil, parser.Mode(parser.ParseComments))
+	packs, resolver, err := loadPackages(typeSpecParser.tokenSet, paths)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse packages in paths %v. Error: %w", paths, err)
+	}
+	typeDirs, err := declaredTypes(packs)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse package in path %s. Error: %w", path, err)
+		return nil, err
 	}
 
-	typeSpecParser.packs = packg
+	typeSpecParser.baseDir = filepath.Dir(rootDir(paths[0]))
+	for dir := range packs {
+		if rel, err := filepath.Rel(typeSpecParser.baseDir, dir); err != nil || strings.HasPrefix(rel, "..") {
+			return nil, fmt.Errorf("the package in %s is not placed in the directory %s", dir, typeSpecParser.baseDir)
+		}
+	}
+
+	typeSpecParser.packs = packs
+	typeSpecParser.typeDirs = typeDirs
+	typeSpecParser.resolver = resolver
+	typeSpecParser.ShadowPackagePaths = map[string]string{}
+	typeSpecParser.shadowImportPaths = map[string]string{}
 	typeSpecParser.Output = ParsedPackage{
-		IsNobjectInOrginalPackage: make(map[string]bool),
-		TypesWithCustomId:         map[string]string{},
-		TypesWithCustomExport:     map[string]CustomExportDefinition{},
-		TypesWithCustomDelete:     map[string]CustomDeleteDefinition{},
-		TypeAttributesIndexes:     map[string][]string{},
-		BidrectionalOneToManyRel:  map[string][]OneToManyRelationshipField{},
-		ManyToManyRelationships:   map[string][]ManyToManyRelationshipField{},
-		TypeFields:                map[string]map[string]string{},
+		TypeImportPaths:            map[string]string{},
+		PackageAliases:             map[string]string{},
+		IsNobjectInOrginalPackage:  make(map[string]bool),
+		TypesWithCustomId:          map[string]string{},
+		TypesWithVersion:           map[string]string{},
+		TypesWithValidation:        map[string][]string{},
+		TypesWithReadonlyFields:    map[string][]string{},
+		TypesWithDeletePolicies:    map[string][]string{},
+		TypesWithCheckedReferences: map[string][]string{},
+		TypesWithUniqueFields:      map[string][]string{},
+		TypesWithCustomExport:      map[string]CustomExportDefinition{},
+		TypesWithCustomDelete:      map[string]CustomDeleteDefinition{},
+		TypeAttributesIndexes:      map[string][]string{},
+		IndexedFields:              map[string]map[string]string{},
+		TypeSortedIndexes:          map[string][]SortedIndex{},
+		BidrectionalOneToManyRel:   map[string][]OneToManyRelationshipField{},
+		ManyToManyRelationships:    map[string][]ManyToManyRelationshipField{},
+		TypeFields:                 map[string]map[string]string{},
 	}
 	typeSpecParser.Handlers = []StateChangingHandler{}
 	typeSpecParser.CustomCtors = []CustomCtorDefinition{}
 	typeSpecParser.fileChanged = map[string]bool{}
 	typeSpecParser.detectedFunctions = make(map[string][]detectedFunction)
 	typeSpecParser.isInitAlreadyAdded = map[string]bool{}
+	typeSpecParser.isVersionAlreadyAdded = map[string]bool{}
 	typeSpecParser.isSaveChangesAlreadyAdded = map[string]bool{}
+	typeSpecParser.isEndInvocationAlreadyAdded = map[string]bool{}
 
 	return typeSpecParser, nil
 }
@@ -91,7 +159,15 @@ func (t *TypeSpecParser) Run(moduleName string) {
 	t.modifyAstMethods()
 	t.prepareDataForHandlers()
 	t.addNubesLibImportIfMissing()
-	t.saveChangesInAst()
+	t.replaceImportsWithShadowPackages()
+	t.saveShadowPackages()
+}
+
+// The Parse detects the types and their fields like Run,
+// without modifying the source files of the package
+func (t *TypeSpecParser) Parse(moduleName string) {
+	t.detectNobjectTypesAndFunctions(moduleName)
+	t.detectAndModifyAstStructs()
 }
 
 // The detectNobjectTypesAndFunctions detects object types
@@ -99,7 +175,12 @@ func (t *TypeSpecParser) Run(moduleName string) {
 // Nobject types are recognised as the types that implement
 // Nobject interface (i.e. GetTypeName method)
 func (t *TypeSpecParser) detectNobjectTypesAndFunctions(moduleName string) {
-	for packageName, pack := range t.packs {
+	for _, typeName := range t.resolver.nobjectTypes() {
+		t.Output.IsNobjectInOrginalPackage[typeName] = true
+	}
+	t.detectShadowPackages(moduleName)
+
+	for _, pack := range t.packs {
 		for path, f := range pack.Files {
 			for _, d := range f.Decls {
 				if fn, isFn := d.(*ast.FuncDecl); isFn {
@@ -110,12 +191,21 @@ func (t *TypeSpecParser) detectNobjectTypesAndFunctions(moduleName string) {
 						case SaveChangesIfInitialized:
 							t.isSaveChangesAlreadyAdded[ownerType] = true
 							continue
+						case EndInvocationMethod:
+							t.isEndInvocationAlreadyAdded[ownerType] = true
+							continue
 						case NobjectImplementationMethod:
-							t.Output.IsNobjectInOrginalPackage[ownerType] = true
+							// without the types, the method is assumed to implement the interface
+							if !t.resolver.isTypeChecked() {
+								t.Output.IsNobjectInOrginalPackage[ownerType] = true
+							}
 							continue
 						case InitFunctionName:
 							t.isInitAlreadyAdded[ownerType] = true
 							continue
+						case VersionGetterMethod, VersionSetterMethod:
+							t.isVersionAlreadyAdded[ownerType] = true
+							continue
 						case CustomIdImplementationMethod:
 							idFieldName, err := getIdFieldNameFromCustomIdImpl(fn)
 							if err != nil {
@@ -129,7 +219,7 @@ func (t *TypeSpecParser) detectNobjectTypesAndFunctions(moduleName string) {
 
 					// ignore unexported functions (i.e. starting with lowercase letter)
 					if fn.Name.IsExported() {
-						if areReturnParamsValid(fn) {
+						if areReturnParamsValid(fn, t.resolver) {
 							t.detectedFunctions[path] = append(t.detectedFunctions[path], detectedFunction{
 								Function: fn,
 								Imports:  f.Imports,
@@ -139,8 +229,47 @@ func (t *TypeSpecParser) detectNobjectTypesAndFunctions(moduleName string) {
 				}
 			}
 		}
+	}
+}
+
+// The detectShadowPackages determines the directories and the import paths of the shadow
+// packages, placed in ShadowPackagesDirectory with the same relative paths as the packages
+// of the types, and the aliases the generated handlers import them with. The aliases are
+// assigned in the order of the directories, so that the first package is imported as
+// OrginalPackageAlias and the nested ones, e.g. billing, as orgBilling.
+func (t *TypeSpecParser) detectShadowPackages(moduleName string) {
+	dirs := maps.Keys(t.packs)
+	sort.Strings(dirs)
+
+	aliasTaken := map[string]bool{}
+	shadowImportPathsByDir := map[string]string{}
+	for _, dir := range dirs {
+		rel, _ := filepath.Rel(t.baseDir, dir)
+		shadowImportPath := moduleName + "/" + ShadowPackagesDirectory + "/" + filepath.ToSlash(rel)
+		shadowImportPathsByDir[dir] = shadowImportPath
+		t.ShadowPackagePaths[dir] = filepath.Join(t.baseDir, ShadowPackagesDirectory, rel)
+
+		// without the types, the import path of the
+		// package is determined in the same way
+		importPath := t.resolver.importPath(dir)
+		if importPath == "" {
+			importPath = moduleName + "/" + filepath.ToSlash(rel)
+		}
+		t.shadowImportPaths[importPath] = shadowImportPath
 
-		t.Output.ImportPath = moduleName + "/" + packageName
+		alias := OrginalPackageAlias
+		if len(aliasTaken) > 0 {
+			alias = OrginalPackageAlias + upperCaseFirstChar(t.packs[dir].Name)
+		}
+		for i := 2; aliasTaken[alias]; i++ {
+			alias = OrginalPackageAlias + upperCaseFirstChar(t.packs[dir].Name) + strconv.Itoa(i)
+		}
+		aliasTaken[alias] = true
+		t.Output.PackageAliases[shadowImportPath] = alias
+	}
+
+	for typeName, dir := range t.typeDirs {
+		t.Output.TypeImportPaths[typeName] = shadowImportPathsByDir[dir]
 	}
 }
 
@@ -153,7 +282,9 @@ func (t TypeSpecParser) addNubesLibImportIfMissing() {
 			if value, exists := t.fileChanged[path]; exists && value {
 				libImported := false
 				for _, imp := range f.Imports {
-					if strings.Contains(imp.Path.Value, LibImportPath) {
+					// the generated code refers to the library as lib, so it is imported
+					// once more if it is imported with another name, e.g. a dot import
+					if strings.Contains(imp.Path.Value, LibImportPath) && (imp.Name == nil || imp.Name.Name == "lib") {
 						libImported = true
 						break
 					}
@@ -171,52 +302,186 @@ func (t TypeSpecParser) addNubesLibImportIfMissing() {
 	}
 }
 
-func (t TypeSpecParser) saveChangesInAst() {
+// The replaceImportsWithShadowPackages replaces the imports of the parsed packages with
+// the imports of their shadow packages, so that the translated types refer to each other
+func (t TypeSpecParser) replaceImportsWithShadowPackages() {
 	for _, pack := range t.packs {
+		for _, f := range pack.Files {
+			for _, imp := range f.Imports {
+				if shadowImportPath, isParsed := t.shadowImportPaths[strings.Trim(imp.Path.Value, "\"")]; isParsed {
+					imp.Path.Value = strconv.Quote(shadowImportPath)
+				}
+			}
+		}
+	}
+}
+
+// The saveShadowPackages saves all the files of the packages, including the translated ones,
+// in the shadow packages, so that the source files of the types are left unchanged.
+// The files of the shadow packages that no longer have their sources are removed.
+func (t TypeSpecParser) saveShadowPackages() {
+	for dir, pack := range t.packs {
+		if strings.HasSuffix(pack.Name, "_test") {
+			continue
+		}
+
+		shadowPackagePath := t.ShadowPackagePaths[dir]
+		if err := os.MkdirAll(shadowPackagePath, 0777); err != nil {
+			fmt.Println("Fatal error occurred creating the shadow package:", err)
+			os.Exit(1)
+		}
+		previousFiles, _ := filepath.Glob(filepath.Join(shadowPackagePath, "*.go"))
+		for _, path := range previousFiles {
+			os.Remove(path)
+		}
+
+		rel, _ := filepath.Rel(t.baseDir, dir)
 		for path, f := range pack.Files {
+			if strings.HasSuffix(path, "_test.go") {
+				continue
+			}
 
-			if value, exists := t.fileChanged[path]; exists && value {
+			var buf bytes.Buffer
+			fmt.Fprintf(&buf, GeneratedFileHeader, filepath.ToSlash(filepath.Join(rel, filepath.Base(path))))
+			err := printFileByDecls(&buf, t.tokenSet, f)
+			if err != nil {
+				fmt.Println(err)
+			}
+			shadowPath := filepath.Join(shadowPackagePath, filepath.Base(path))
+			shadowFile, err := os.Create(shadowPath)
+			if err != nil {
+				fmt.Println(err)
+				continue
+			}
+			buf.WriteTo(shadowFile)
+			shadowFile.Close()
+			tp.RunGoimportsOnFile(shadowPath)
+		}
+	}
+}
 
-				var buf bytes.Buffer
-				err := printer.Fprint(&buf, t.tokenSet, f)
-				if err != nil {
-					fmt.Println(err)
-				}
-				nobjectTypeFile, err := os.Create(path)
-				if err != nil {
-					fmt.Println(err)
-				}
-				buf.WriteTo(nobjectTypeFile)
-				nobjectTypeFile.Close()
-				tp.RunGoimportsOnFile(path)
+// The printFileByDecls prints the file declaration by declaration, each with the comments within it.
+// The nodes added to the ast have no positions, so the printer would place the comments of the whole
+// file among them if the file was printed at once, e.g. the doc of a method inside the previous one.
+func printFileByDecls(buf *bytes.Buffer, tokenSet *token.FileSet, f *ast.File) error {
+	remaining := f.Comments
+	// printLeadingComments prints the comments ending before pos, which are not
+	// printed with any decl, e.g. the package doc or the build constraints
+	printLeadingComments := func(pos token.Pos) {
+		for len(remaining) > 0 && (!pos.IsValid() || remaining[0].End() < pos) {
+			for _, comment := range remaining[0].List {
+				buf.WriteString(comment.Text + "\n")
+			}
+			if remaining[0] != f.Doc {
+				buf.WriteString("\n")
 			}
+			remaining = remaining[1:]
+		}
+	}
+
+	printLeadingComments(f.Package)
+	buf.WriteString("package " + f.Name.Name + "\n")
+
+	for _, decl := range f.Decls {
+		buf.WriteString("\n")
+		if !decl.Pos().IsValid() {
+			if err := printer.Fprint(buf, tokenSet, decl); err != nil {
+				return err
+			}
+			buf.WriteString("\n")
+			continue
+		}
+
+		start := decl.Pos()
+		if fn, isFn := decl.(*ast.FuncDecl); isFn && fn.Doc != nil {
+			start = fn.Doc.Pos()
+		} else if genDecl, isGenDecl := decl.(*ast.GenDecl); isGenDecl && genDecl.Doc != nil {
+			start = genDecl.Doc.Pos()
 		}
+		printLeadingComments(start)
+
+		end := 0
+		for end < len(remaining) && remaining[end].Pos() < decl.End() {
+			end++
+		}
+		if err := printer.Fprint(buf, tokenSet, &printer.CommentedNode{Node: decl, Comments: remaining[:end]}); err != nil {
+			return err
+		}
+		buf.WriteString("\n")
+		remaining = remaining[end:]
+	}
+
+	if len(remaining) > 0 {
+		// the comments after the last decl
+		buf.WriteString("\n")
+		printLeadingComments(token.NoPos)
 	}
+	return nil
 }
 
-// areReturnParamsValid returns true if the number of parameters is equal to two or one,
-// If exactly two return parameters are defined, then the second paramater
-// must be an error type.
-// If exactly one return parameter is defined, then the parameter must be
-// an error type.
+// areReturnParamsValid returns true if the last return parameter is an error type.
+// The methods can return any number of parameters before the error, which
+// the handlers return in the response envelopes, while the functions, e.g.
+// the custom constructors, can return at most one.
 // If the above conditions do not hold, it prints relevant error message
 // and returns false.
-func areReturnParamsValid(f *ast.FuncDecl) bool {
+func areReturnParamsValid(f *ast.FuncDecl, resolver *typeResolver) bool {
 
-	if f.Type.Results == nil || f.Type.Results.List == nil || !isErrorTypeReturned(f) {
+	if f.Type.Results == nil || f.Type.Results.List == nil || !isErrorTypeReturned(f, resolver) {
 		fmt.Println("error type must be defined as the last return type from type's method. Handler generation for " + f.Name.Name + " skipped")
 		return false
 	}
-	if len(f.Type.Results.List) > 2 {
-		fmt.Println("maximum allowed number of non-error return parameters is 1. Handler generation for " + f.Name.Name + " skipped")
+	if f.Recv == nil && len(fieldTypes(f.Type.Results)) > 2 {
+		fmt.Println("maximum allowed number of non-error return parameters of functions is 1. Handler generation for " + f.Name.Name + " skipped")
 		return false
 	}
 
 	return true
 }
 
-func isErrorTypeReturned(f *ast.FuncDecl) bool {
-	return len(f.Type.Results.List) > 0 && types.ExprString(f.Type.Results.List[len(f.Type.Results.List)-1].Type) == "error"
+func isErrorTypeReturned(f *ast.FuncDecl, resolver *typeResolver) bool {
+	return len(f.Type.Results.List) > 0 && resolver.isError(f.Type.Results.List[len(f.Type.Results.List)-1].Type)
+}
+
+// fieldTypes returns the types of the parameters or the results in the order of
+// declaration, the type of the fields declared together is repeated, e.g. x, y int
+func fieldTypes(fields *ast.FieldList) []ast.Expr {
+	if fields == nil {
+		return nil
+	}
+
+	var exprs []ast.Expr
+	for _, field := range fields.List {
+		for i := 0; i < len(field.Names) || i == 0; i++ {
+			exprs = append(exprs, field.Type)
+		}
+	}
+	return exprs
+}
+
+// envelopeFieldNames returns the names of the fields of the envelope struct of the
+// parameters or the results, which are their exported names. The unnamed, blank and
+// repeated ones are named with the prefix followed by the position, e.g. Param0.
+func envelopeFieldNames(fields *ast.FieldList, prefix string) []string {
+	var names []string
+	taken := map[string]bool{}
+	for _, field := range fields.List {
+		for i := 0; i < len(field.Names) || i == 0; i++ {
+			name := ""
+			if i < len(field.Names) {
+				name = upperCaseFirstChar(field.Names[i].Name)
+			}
+			if !token.IsExported(name) || taken[name] {
+				name = prefix + strconv.Itoa(len(names))
+			}
+			for taken[name] {
+				name += "_"
+			}
+			taken[name] = true
+			names = append(names, name)
+		}
+	}
+	return names
 }
 
 func getFunctionReceiverTypeAsString(fieldList *ast.FieldList) string {
@@ -3,10 +3,10 @@ package parser
 import (
 	"fmt"
 	"go/ast"
-	"go/types"
 	"strings"
 
 	"github.com/fatih/structtag"
+	"golang.org/x/exp/slices"
 )
 
 type structPath struct {
@@ -45,6 +45,16 @@ func (t *TypeSpecParser) detectAndModifyAstStructs() {
 									t.addSaveChangesIfInitializedMethod(f, typeName)
 									t.fileChanged[path] = true
 								}
+
+								if t.Output.IsNobjectInOrginalPackage[typeName] && !t.isEndInvocationAlreadyAdded[typeName] {
+									t.addEndInvocationMethod(f, typeName)
+									t.fileChanged[path] = true
+								}
+
+								if t.Output.IsNobjectInOrginalPackage[typeName] {
+									t.addRegisterTypeFunction(f, typeName)
+									t.fileChanged[path] = true
+								}
 							}
 						}
 					}
@@ -54,6 +64,33 @@ func (t *TypeSpecParser) detectAndModifyAstStructs() {
 	}
 
 	t.addIgnoreEmptyTagToBidirectionalOneToManyRel(detectedStructTypeWithFile)
+	t.setSortedIndexesAttributeTypes()
+}
+
+// The setSortedIndexesAttributeTypes determines the types of the sort keys
+// of the indexes requested with SortedByTag, once the fields of all the
+// types are known. The indexes of unsupported fields are dropped.
+func (t *TypeSpecParser) setSortedIndexesAttributeTypes() {
+	for typeName, indexes := range t.Output.TypeSortedIndexes {
+		supported := make([]SortedIndex, 0, len(indexes))
+		for _, index := range indexes {
+			fieldType, exists := t.Output.TypeFields[typeName][index.SortAttributeName]
+			if !exists {
+				fmt.Println("ERROR: The field", index.SortAttributeName, "given in", SortedByTag, "tag not found in type", typeName)
+				continue
+			}
+
+			attributeType, ok := getIndexAttributeType(fieldType)
+			if !ok {
+				fmt.Println("ERROR: The field given in", SortedByTag, "tag must be a string, a number or a", ReferenceType, ".",
+					index.SortAttributeName, "of type", typeName, "is not indexed")
+				continue
+			}
+			index.SortAttributeType = attributeType
+			supported = append(supported, index)
+		}
+		t.Output.TypeSortedIndexes[typeName] = supported
+	}
 }
 
 // The addIgnoreEmptyTagToBidirectionalOneToManyRel method adds `dynamodbav:",omitempty"` tag
@@ -74,13 +111,12 @@ func (t *TypeSpecParser) addIgnoreEmptyTagToBidirectionalOneToManyRel(detectedSt
 					if err != nil {
 						fmt.Println("error occurerd while checking struct tags of:", oneToMany.TypeName, " field: ", field.Names[0].Name, ". Error: ", err)
 					} else if tags != nil {
-						dynamodbTag, err := tags.Get(DynamoDBTagKey)
-						if err != nil {
-							fmt.Println("error occurerd while checking struct tags of:", oneToMany.TypeName, " field: ", field.Names[0].Name, ". Error: ", err)
-						} else if dynamodbTag == nil {
+						// Get returns an error if the tag does not exist
+						dynamodbTag, _ := tags.Get(DynamoDBTagKey)
+						if dynamodbTag == nil {
 							// no dynamoDB tags added before
 
-							tags.AddOptions(DynamoDBTagKey, DynamoDBIgnoreEmptyTagValue)
+							tags.Set(&structtag.Tag{Key: DynamoDBTagKey, Options: []string{DynamoDBIgnoreEmptyTagValue}})
 							field.Tag.Value = "`" + tags.String() + "`"
 							t.fileChanged[strctWithReferenceField.path] = true
 						} else if !strings.Contains(dynamodbTag.GoString(), DynamoDBIgnoreEmptyTagValue) {
@@ -114,6 +150,16 @@ func (t *TypeSpecParser) addSaveChangesIfInitializedMethod(f *ast.File, typeName
 	f.Decls = append(f.Decls, function)
 }
 
+func (t *TypeSpecParser) addEndInvocationMethod(f *ast.File, typeName string) {
+	function := getEndInvocationMethodForType(typeName)
+	f.Decls = append(f.Decls, function)
+}
+
+func (t *TypeSpecParser) addRegisterTypeFunction(f *ast.File, typeName string) {
+	function := getRegisterTypeFunctionForType(typeName)
+	f.Decls = append(f.Decls, function)
+}
+
 // The parseStructFields returns true if the ast representing
 // the struct was modified, otherwise false
 func (t *TypeSpecParser) parseStructFields(f *ast.File, strctType *ast.StructType, typeName string) bool {
@@ -127,14 +173,22 @@ func (t *TypeSpecParser) parseStructFields(f *ast.File, strctType *ast.StructTyp
 
 	isNobject := t.Output.IsNobjectInOrginalPackage[typeName]
 	for _, field := range strctType.Fields.List {
-		t.Output.TypeFields[typeName][field.Names[0].Name] = types.ExprString(field.Type)
+		t.Output.TypeFields[typeName][field.Names[0].Name] = t.resolver.typeString(field.Type)
 
 		if isNobject {
 			fieldModified = t.parseRelationshipsTags(field, typeName)
 			structModified = t.addCustomIdImplementationIfNeeded(f, field, typeName)
+			versionModified := t.addVersionImplementationIfNeeded(f, field, typeName)
+			t.detectIndexedField(field, typeName)
+			t.detectValidatedField(field, typeName)
+			t.detectCheckedReferenceField(field, typeName)
+			t.detectUniqueField(field, typeName)
+			if isFieldReadonly(field) {
+				t.Output.TypesWithReadonlyFields[typeName] = append(t.Output.TypesWithReadonlyFields[typeName], field.Names[0].Name)
+			}
 
 			if !structDefinitionModified {
-				structDefinitionModified = fieldModified || structModified
+				structDefinitionModified = fieldModified || structModified || versionModified
 			}
 		}
 	}
@@ -151,6 +205,13 @@ func (t *TypeSpecParser) parseStructFields(f *ast.File, strctType *ast.StructTyp
 		})
 		structDefinitionModified = true
 	}
+	if _, exists := t.Output.TypeFields[typeName][StateSnapshotFieldName]; !exists && isNobject {
+		strctType.Fields.List = append(strctType.Fields.List, &ast.Field{
+			Names: []*ast.Ident{{Name: StateSnapshotFieldName}},
+			Type:  &ast.SelectorExpr{X: &ast.Ident{Name: "lib"}, Sel: &ast.Ident{Name: LibrarySnapshotType}},
+		})
+		structDefinitionModified = true
+	}
 
 	return structDefinitionModified
 }
@@ -164,7 +225,7 @@ func (t *TypeSpecParser) addCustomIdImplementationIfNeeded(f *ast.File, field *a
 		if tag, _ := tags.Get(NubesTagKey); tag != nil {
 
 			if strings.EqualFold(tag.Name, CustomIdTag) {
-				if types.ExprString(field.Type) != "string" {
+				if t.resolver.typeString(field.Type) != "string" {
 					fmt.Println("ERROR: The field selected as CustomId field must be a string.", field.Names[0].Name,
 						"selected as CustomId field selected for type", typeName, "is not a string")
 					return false
@@ -191,6 +252,98 @@ func (t *TypeSpecParser) addCustomIdImplementationIfNeeded(f *ast.File, field *a
 	return false
 }
 
+// The detectIndexedField adds the field tagged with IndexTag to the
+// attributes of the type for which the secondary indexes are created
+func (t *TypeSpecParser) detectIndexedField(field *ast.Field, typeName string) {
+	if !isIndexField(field) {
+		return
+	}
+
+	fieldName := field.Names[0].Name
+	attributeType, supported := getIndexAttributeType(t.resolver.typeString(field.Type))
+	if !supported {
+		fmt.Println("ERROR: The field tagged with", IndexTag, "must be a string, a number or a", ReferenceType, ".", fieldName,
+			"of type", typeName, "is not indexed")
+		return
+	}
+
+	if _, exists := t.Output.IndexedFields[typeName]; !exists {
+		t.Output.IndexedFields[typeName] = map[string]string{}
+	}
+	t.Output.IndexedFields[typeName][fieldName] = attributeType
+	t.Output.TypeAttributesIndexes[typeName] = append(t.Output.TypeAttributesIndexes[typeName], fieldName)
+}
+
+// The detectValidatedField adds the field with validation rules in the nubes
+// tag to the validated fields of the type. The invalid rules are reported,
+// they are not satisfied by any value at runtime.
+func (t *TypeSpecParser) detectValidatedField(field *ast.Field, typeName string) {
+	rules := getValidationRules(field)
+	if len(rules) == 0 {
+		return
+	}
+
+	fieldName := field.Names[0].Name
+	for _, rule := range rules {
+		if err := checkValidationRule(rule); err != nil {
+			fmt.Println("ERROR: invalid validation rule", rule, "of field", fieldName, "of type", typeName, ".", err)
+		}
+	}
+	t.Output.TypesWithValidation[typeName] = append(t.Output.TypesWithValidation[typeName], fieldName)
+}
+
+// The detectCheckedReferenceField adds the Reference or ReferenceList field to the
+// fields whose referenced objects are checked to exist, unless it's tagged with NoReferenceCheckTag
+func (t *TypeSpecParser) detectCheckedReferenceField(field *ast.Field, typeName string) {
+	fieldType := t.resolver.typeString(field.Type)
+	if !strings.HasPrefix(fieldType, ReferenceType+"[") && !strings.HasPrefix(fieldType, ReferenceListType+"[") {
+		return
+	}
+	if hasNubesTagOption(field, NoReferenceCheckTag) || isDynamoDBIgnoredField(field) {
+		return
+	}
+	t.Output.TypesWithCheckedReferences[typeName] = append(t.Output.TypesWithCheckedReferences[typeName], field.Names[0].Name)
+}
+
+// The detectUniqueField adds the field tagged with UniqueTag, e.g. unique or
+// unique-City, and its scope field to the fields whose values are guarded
+func (t *TypeSpecParser) detectUniqueField(field *ast.Field, typeName string) {
+	scopeFieldName, isUnique := getUniqueTagScope(field)
+	if !isUnique {
+		return
+	}
+
+	fieldName := field.Names[0].Name
+	if _, supported := getIndexAttributeType(t.resolver.typeString(field.Type)); !supported || isDynamoDBIgnoredField(field) {
+		fmt.Println("ERROR: The field tagged with", UniqueTag, "must be a string, a number or a", ReferenceType, "stored in the DB.",
+			fieldName, "of type", typeName, "is not unique")
+		return
+	}
+
+	guardedFields := t.Output.TypesWithUniqueFields[typeName]
+	for _, guardedField := range []string{fieldName, scopeFieldName} {
+		if guardedField != "" && !slices.Contains(guardedFields, guardedField) {
+			guardedFields = append(guardedFields, guardedField)
+		}
+	}
+	t.Output.TypesWithUniqueFields[typeName] = guardedFields
+}
+
+// getIndexAttributeType returns the DynamoDB type of
+// the key attribute for the Go type of the indexed field
+func getIndexAttributeType(fieldType string) (string, bool) {
+	switch fieldType {
+	case "string":
+		return "S", true
+	case "int", "int8", "int16", "int32", "int64", "uint", "uint8", "uint16", "uint32", "uint64", "float32", "float64":
+		return "N", true
+	}
+	if strings.HasPrefix(fieldType, ReferenceType+"[") {
+		return "S", true
+	}
+	return "", false
+}
+
 func addDynamoDBIdTag(tags *structtag.Tags, typeName string, field *ast.Field) bool {
 	dynamodbTag, _ := tags.Get(DynamoDBTagKey)
 
@@ -204,3 +357,57 @@ func addDynamoDBIdTag(tags *structtag.Tags, typeName string, field *ast.Field) b
 	field.Tag.Value = field.Tag.Value[0:len(field.Tag.Value)-1] + " " + DynamoDBIdTag + "`"
 	return true
 }
+
+// The addVersionImplementationIfNeeded detects the field tagged with VersionTag.
+// It adds the dynamodb tag so that the field is stored in the Version attribute
+// and implementation of lib.Versioned interface if not added before.
+// The return value indicates whether the ast was modified.
+func (t *TypeSpecParser) addVersionImplementationIfNeeded(f *ast.File, field *ast.Field, typeName string) bool {
+	tags, err := getParsedTags(field)
+
+	if err != nil {
+		fmt.Println("error occurerd while checking struct tags of:", typeName, " field: ", field.Names[0].Name, ". Error: ", err)
+	} else if tags != nil {
+		if tag, _ := tags.Get(NubesTagKey); tag != nil && strings.EqualFold(tag.Name, VersionTag) {
+			if t.resolver.typeString(field.Type) != "int" {
+				fmt.Println("ERROR: The field selected as version field must be an int.", field.Names[0].Name,
+					"selected as version field for type", typeName, "is not an int")
+				return false
+			}
+
+			if fieldName, exists := t.Output.TypesWithVersion[typeName]; exists {
+				fmt.Println("ERROR: only one version field can be defined per type.", field.Names[0].Name,
+					"ignored, the version field of", typeName, "is", fieldName)
+				return false
+			}
+
+			t.Output.TypesWithVersion[typeName] = field.Names[0].Name
+			modified := addDynamoDBVersionTag(tags, typeName, field)
+
+			if !t.isVersionAlreadyAdded[typeName] {
+				f.Decls = append(f.Decls, getVersionImplementation(typeName, field.Names[0].Name)...)
+				t.isVersionAlreadyAdded[typeName] = true
+				modified = true
+			}
+			return modified
+		}
+	}
+
+	return false
+}
+
+func addDynamoDBVersionTag(tags *structtag.Tags, typeName string, field *ast.Field) bool {
+	dynamodbTag, _ := tags.Get(DynamoDBTagKey)
+
+	if dynamodbTag != nil && dynamodbTag.Name == DynamoDBVersionTagValue {
+		return false
+	}
+
+	if dynamodbTag != nil {
+		fmt.Println("invalid definition of dynamodb struct tag fixed in", typeName, "field:", field.Names[0].Name, " replaced with mandatory", DynamoDBVersionTag, "tag")
+		tags.Delete(DynamoDBTagKey)
+		field.Tag.Value = "`" + tags.String() + "`"
+	}
+	field.Tag.Value = field.Tag.Value[0:len(field.Tag.Value)-1] + " " + DynamoDBVersionTag + "`"
+	return true
+}
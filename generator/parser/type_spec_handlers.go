@@ -4,21 +4,39 @@ import (
 	"bytes"
 	"fmt"
 	"go/ast"
+	"go/parser"
 	"go/printer"
 	"go/token"
-	"go/types"
 	"strings"
 )
 
 type StateChangingHandler struct {
-	OrginalPackage      string
 	OrginalPackageAlias string
+	// OrginalPackages maps the aliases to the import paths of the packages
+	// of the receiver type, the parameter and the returned value
+	OrginalPackages     map[string]string
 	Imports             string
 	MethodName          string
 	ReceiverType        string
 	ReceiverIdFieldName string
 	OptionalReturnType  string
 	OptionalInputType   string
+	// InputFields are the parameters of the methods with several parameters,
+	// which the handler receives in the fields of the request envelope
+	InputFields []EnvelopeField
+	// ReturnFields are the results of the methods with several non-error
+	// results, which the handler returns in the fields of the response envelope
+	ReturnFields []EnvelopeField
+	// MaxRetries is the number of times the method is invoked again
+	// if it fails with lib.ConflictError, used with versioned types only
+	MaxRetries int
+}
+
+// EnvelopeField is the field of the envelope struct holding the parameter or the result
+// of the method, named after the parameter or the result if it's named, e.g. Quantity
+type EnvelopeField struct {
+	Name string
+	Type string
 }
 
 type detectedFunction struct {
@@ -35,7 +53,7 @@ func (t *TypeSpecParser) prepareDataForHandlers() {
 
 			if f.Recv == nil {
 
-				param, err := getHandlerInputParam(f.Type.Params, t.Output.TypeFields)
+				param, err := getHandlerInputParam(f.Type.Params, t.Output, t.resolver)
 				if err != nil {
 					fmt.Println("Maximum allowed number of parameters is 1. Handler generation for " + f.Name.Name + "skipped")
 					continue
@@ -43,8 +61,8 @@ func (t *TypeSpecParser) prepareDataForHandlers() {
 				if strings.HasPrefix(f.Name.Name, ConstructorPrefix) {
 					typeName := strings.TrimPrefix(f.Name.Name, ConstructorPrefix)
 					t.CustomCtors = append(t.CustomCtors, CustomCtorDefinition{
-						OrginalPackage:      t.Output.ImportPath,
-						OrginalPackageAlias: OrginalPackageAlias,
+						OrginalPackageAlias: t.Output.TypeAlias(typeName),
+						OrginalPackages:     t.Output.ImportsOf(t.Output.QualifiedType(typeName), param),
 						TypeName:            typeName,
 						OptionalParamType:   param,
 					})
@@ -93,54 +111,71 @@ func (t *TypeSpecParser) prepareDataForHandlers() {
 				}
 
 				newHandler := StateChangingHandler{
-					OrginalPackage:      t.Output.ImportPath,
-					OrginalPackageAlias: OrginalPackageAlias,
+					OrginalPackageAlias: t.Output.TypeAlias(receiverTypeName),
 					MethodName:          f.Name.Name,
 					ReceiverType:        receiverTypeName,
 					ReceiverIdFieldName: Id,
-					Imports:             getImportsAsString(t.tokenSet, detectedFunction.Imports),
+					Imports:             getImportsAsString(t.tokenSet, detectedFunction.Imports, t.shadowImportPaths),
 				}
 
 				if customIdFieldName, hasCustomId := t.Output.TypesWithCustomId[receiverTypeName]; hasCustomId {
 					newHandler.ReceiverIdFieldName = customIdFieldName
 				}
 
-				if len(f.Type.Results.List) > 1 {
-					newHandler.OptionalReturnType = types.ExprString(f.Type.Results.List[0].Type)
-
-					if arrayType, ok := f.Type.Results.List[0].Type.(*ast.ArrayType); ok {
-						arrType := types.ExprString(arrayType.Elt)
-						if isNobject, isPresent := t.Output.IsNobjectInOrginalPackage[arrType]; isPresent && isNobject {
-							newHandler.OptionalReturnType = "[]" + newHandler.OrginalPackageAlias + "." + arrType
-						}
-					} else if isNobject, isPresent := t.Output.IsNobjectInOrginalPackage[newHandler.OptionalReturnType]; isNobject && isPresent {
-						newHandler.OptionalReturnType = newHandler.OrginalPackageAlias + "." + newHandler.OptionalReturnType
-					} else if strings.Contains(newHandler.OptionalReturnType, ReferenceListType) {
-						newHandler.OptionalReturnType = strings.TrimPrefix(newHandler.OptionalReturnType, ReferenceListType)
-						newHandler.OptionalReturnType = strings.Trim(newHandler.OptionalReturnType, "[]")
-						newHandler.OptionalReturnType = ReferenceListType + "[" + OrginalPackageAlias + "." + newHandler.OptionalReturnType + "]"
-					} else if strings.Contains(newHandler.OptionalReturnType, ReferenceType) {
-						newHandler.OptionalReturnType = strings.TrimPrefix(newHandler.OptionalReturnType, ReferenceType)
-						newHandler.OptionalReturnType = strings.Trim(newHandler.OptionalReturnType, "[]")
-						newHandler.OptionalReturnType = ReferenceType + "[" + OrginalPackageAlias + "." + newHandler.OptionalReturnType + "]"
+				// the methods with several parameters or results receive
+				// and return them in the envelopes, the other ones as they are
+				params := fieldTypes(f.Type.Params)
+				if len(params) > 0 {
+					if _, isVariadic := params[len(params)-1].(*ast.Ellipsis); isVariadic {
+						fmt.Println("Variadic parameters are not supported. Handler generation for " + f.Name.Name + " skipped")
+						continue
+					}
+				}
+				if len(params) == 1 {
+					newHandler.OptionalInputType = t.Output.QualifiedHandlerType(t.resolver.typeString(params[0]))
+				} else if len(params) > 1 {
+					names := envelopeFieldNames(f.Type.Params, EnvelopeParamPrefix)
+					for i, param := range params {
+						newHandler.InputFields = append(newHandler.InputFields, EnvelopeField{
+							Name: names[i],
+							Type: t.Output.QualifiedHandlerType(t.resolver.typeString(param)),
+						})
 					}
 				}
 
-				param, err := getHandlerInputParam(f.Type.Params, t.Output.TypeFields)
-				if err != nil {
-					fmt.Println("Maximum allowed number of parameters is 1. Handler generation for " + f.Name.Name + "skipped")
-					continue
+				results := fieldTypes(f.Type.Results)
+				results = results[:len(results)-1]
+				if len(results) == 1 {
+					newHandler.OptionalReturnType = t.Output.QualifiedHandlerType(t.resolver.typeString(results[0]))
+				} else if len(results) > 1 {
+					names := envelopeFieldNames(f.Type.Results, EnvelopeResultPrefix)
+					for i, result := range results {
+						newHandler.ReturnFields = append(newHandler.ReturnFields, EnvelopeField{
+							Name: names[i],
+							Type: t.Output.QualifiedHandlerType(t.resolver.typeString(result)),
+						})
+					}
+				}
+
+				usedTypes := []string{t.Output.QualifiedType(receiverTypeName), newHandler.OptionalInputType, newHandler.OptionalReturnType}
+				for _, field := range append(newHandler.InputFields, newHandler.ReturnFields...) {
+					usedTypes = append(usedTypes, field.Type)
 				}
-				newHandler.OptionalInputType = param
+				newHandler.OrginalPackages = t.Output.ImportsOf(usedTypes...)
 				t.Handlers = append(t.Handlers, newHandler)
 			}
 		}
 	}
 }
 
-func getImportsAsString(fset *token.FileSet, imports []*ast.ImportSpec) string {
+// getImportsAsString returns the imports of the file, without the imports of the parsed
+// packages, as the handlers import their shadow packages with the aliases
+func getImportsAsString(fset *token.FileSet, imports []*ast.ImportSpec, parsedImportPaths map[string]string) string {
 	var buf bytes.Buffer
 	for _, imp := range imports {
+		if _, isParsed := parsedImportPaths[strings.Trim(imp.Path.Value, "\"")]; isParsed {
+			continue
+		}
 		err := printer.Fprint(&buf, fset, imp)
 		buf.WriteString("\n")
 		if err != nil {
@@ -151,25 +186,82 @@ func getImportsAsString(fset *token.FileSet, imports []*ast.ImportSpec) string {
 	return buf.String()
 }
 
-func getHandlerInputParam(params *ast.FieldList, typeFieldsInPkg map[string]map[string]string) (string, error) {
-	if params.List == nil || len(params.List) == 0 {
+func getHandlerInputParam(params *ast.FieldList, parsedPkg ParsedPackage, resolver *typeResolver) (string, error) {
+	paramTypes := fieldTypes(params)
+	if len(paramTypes) == 0 {
 		return "", nil
-	} else if len(params.List) > 1 {
+	} else if len(paramTypes) > 1 {
 		return "", fmt.Errorf("maximum allowed number of parameters is 1")
 	}
 
-	inputParamType := types.ExprString(params.List[0].Type)
-	if _, isPresent := typeFieldsInPkg[inputParamType]; isPresent {
-		inputParamType = OrginalPackageAlias + "." + inputParamType
-	} else if strings.Contains(inputParamType, ReferenceListType) {
-		inputParamType = strings.TrimPrefix(inputParamType, ReferenceListType)
-		inputParamType = strings.Trim(inputParamType, "[]")
-		inputParamType = ReferenceListType + "[" + OrginalPackageAlias + "." + inputParamType + "]"
-	} else if strings.Contains(inputParamType, ReferenceType) {
-		inputParamType = strings.TrimPrefix(inputParamType, ReferenceType)
-		inputParamType = strings.Trim(inputParamType, "[]")
-		inputParamType = ReferenceType + "[" + OrginalPackageAlias + "." + inputParamType + "]"
+	return parsedPkg.QualifiedHandlerType(resolver.typeString(paramTypes[0])), nil
+}
+
+// QualifiedHandlerType returns the type of a parameter or a result as used in the handlers,
+// with the types declared in the parsed packages, the slices of them and the types
+// referred by lib.Reference and lib.ReferenceList qualified with the aliases of their packages
+func (p ParsedPackage) QualifiedHandlerType(typeName string) string {
+	if strings.HasPrefix(typeName, "[]") {
+		arrType := strings.TrimPrefix(typeName, "[]")
+		if _, isDeclared := p.TypeImportPaths[arrType]; isDeclared {
+			typeName = "[]" + p.QualifiedType(arrType)
+		}
+	} else if _, isDeclared := p.TypeImportPaths[typeName]; isDeclared {
+		typeName = p.QualifiedType(typeName)
+	} else if strings.Contains(typeName, ReferenceListType) {
+		typeName = strings.TrimPrefix(typeName, ReferenceListType)
+		typeName = strings.Trim(typeName, "[]")
+		typeName = ReferenceListType + "[" + p.QualifiedType(typeName) + "]"
+	} else if strings.Contains(typeName, ReferenceType) {
+		typeName = strings.TrimPrefix(typeName, ReferenceType)
+		typeName = strings.Trim(typeName, "[]")
+		typeName = ReferenceType + "[" + p.QualifiedType(typeName) + "]"
+	}
+
+	return typeName
+}
+
+// TypeAlias returns the alias the handlers import the package of the type with,
+// OrginalPackageAlias if the type is not declared in any of the parsed packages
+func (p ParsedPackage) TypeAlias(typeName string) string {
+	if alias, isDeclared := p.PackageAliases[p.TypeImportPaths[typeName]]; isDeclared {
+		return alias
+	}
+	return OrginalPackageAlias
+}
+
+// QualifiedType returns the type qualified with the alias of its package, e.g. orgBilling.Invoice
+func (p ParsedPackage) QualifiedType(typeName string) string {
+	// without the types, the types of the other parsed
+	// packages are qualified with the names of the packages
+	if unqualified := typeName[strings.LastIndex(typeName, ".")+1:]; p.TypeImportPaths[unqualified] != "" {
+		typeName = unqualified
 	}
+	return p.TypeAlias(typeName) + "." + typeName
+}
 
-	return inputParamType, nil
+// ImportsOf returns the aliases and the import paths of the parsed packages
+// the types returned by QualifiedType, or containing them, are qualified with
+func (p ParsedPackage) ImportsOf(qualifiedTypes ...string) map[string]string {
+	importPaths := make(map[string]string, len(p.PackageAliases))
+	for importPath, alias := range p.PackageAliases {
+		importPaths[alias] = importPath
+	}
+
+	imports := map[string]string{}
+	for _, qualifiedType := range qualifiedTypes {
+		expr, err := parser.ParseExpr(qualifiedType)
+		if err != nil {
+			continue
+		}
+		ast.Inspect(expr, func(n ast.Node) bool {
+			if selector, isSelector := n.(*ast.SelectorExpr); isSelector {
+				if pkg, isIdent := selector.X.(*ast.Ident); isIdent && importPaths[pkg.Name] != "" {
+					imports[pkg.Name] = importPaths[pkg.Name]
+				}
+			}
+			return true
+		})
+	}
+	return imports
 }
@@ -1,12 +1,12 @@
 package parser
 
 import (
+	"fmt"
 	"go/ast"
-	"go/token"
 	"go/types"
 	"strings"
 
-	"golang.org/x/tools/go/ast/astutil"
+	"golang.org/x/exp/slices"
 )
 
 func (t TypeSpecParser) modifyAstMethods() {
@@ -26,42 +26,14 @@ func (t TypeSpecParser) modifyAstMethods() {
 				if !isGetter {
 					isSetter := t.addDBOperationsIfSetter(fn, path)
 					if !isSetter {
-						if !isFunctionStateless(fn.Recv) {
-							// add retrieve from DB if it doesn't exist
-							if !isInvocationDepthIncrementedInFirstStmt(fn.Body) {
-								functionProlog := getNobjectFunctionProlog(fn, t.Output)
-								fn.Body.List = prependList(fn.Body.List, functionProlog)
-								t.fileChanged[path] = true
-							}
-
-							astutil.Apply(fn, nil, func(c *astutil.Cursor) bool {
-								n := c.Node()
-
-								if x, ok := n.(*ast.ReturnStmt); ok {
-
-									// add invocation of a function that save changes in DB
-									// (if function uses a pointer receiver - i.e. is not readonly)
-									// decrement invocationDepth
-									if !isFuncReadonly(fn.Recv) && isErrorToBeReturnedNil(*x) {
-										c.InsertBefore(invokeSaveChangesMethodForType(fn, t.Output))
-										if len(x.Results) > 1 {
-											ident := x.Results[1].(*ast.Ident)
-											ident.Name = UpsertLibErrorVariableName
-										} else {
-											ident := x.Results[0].(*ast.Ident)
-											ident.Name = UpsertLibErrorVariableName
-										}
-										t.fileChanged[path] = true
-									}
-
-									// insert invocation depth decrement
-									if !isInvocationDepthDecreasedBefore(c) {
-										c.InsertBefore(getInvocationDepthDecremntStmt(fn.Recv.List[0].Names[0].Name))
-										t.fileChanged[path] = true
-									}
-								}
-								return true
-							})
+						// the methods translated before are left unchanged
+						if !isFunctionStateless(fn.Recv) && !isInvocationDepthIncrementedInFirstStmt(fn.Body) {
+							// the state is retrieved in the prolog and saved in the deferred
+							// epilogue, so that the body of the method is left as it is
+							resultErrorName := nameMethodResults(fn)
+							functionProlog := getNobjectFunctionProlog(fn, resultErrorName, t.Output)
+							fn.Body.List = prependList(fn.Body.List, functionProlog)
+							t.fileChanged[path] = true
 						}
 					}
 				}
@@ -82,38 +54,38 @@ func isFunctionStateless(fields *ast.FieldList) bool {
 	return fields.List == nil || fields.List[0].Names == nil || fields.List[0].Names[0].Name == ""
 }
 
-func isInvocationDepthDecreasedBefore(c *astutil.Cursor) bool {
-	stmts := c.Parent().(*ast.BlockStmt)
-	if index := c.Index(); index > 0 {
-		decStmt, casted := stmts.List[index-1].(*ast.IncDecStmt)
-		if decStmt == nil || !casted {
-			return false
-		}
-
-		return decStmt.Tok == token.DEC && strings.Contains(types.ExprString(decStmt.X), InvocationDepthFieldName)
-	}
-
-	return false
-}
-
 func (t TypeSpecParser) addDBOperationsIfSetter(fn *ast.FuncDecl, path string) bool {
 	typeName := getFunctionReceiverTypeAsString(fn.Recv)
 	if strings.HasPrefix(fn.Name.Name, SetPrefix) {
 		fieldName := strings.TrimPrefix(fn.Name.Name, SetPrefix)
 		if fieldType, fieldExists := t.Output.TypeFields[typeName][fieldName]; fieldExists {
+			isNavigationList := strings.Contains(fieldType, LibraryReferenceNavigationList)
+			if !isNavigationList && slices.Contains(t.Output.TypesWithReadonlyFields[typeName], fieldName) {
+				fmt.Println("ERROR: setter", fn.Name.Name, "of type", typeName, "modifies the field tagged with", ReadonlyTag,
+					". The readonly fields can be set only before the object is exported, the new value is not saved")
+				return true
+			}
 			if !isInitFieldCheckAlreadyAddedAsSecondLastStmt(fn.Body) {
 				idFieldName := getIdFieldNameOfType(typeName, t.Output.TypesWithCustomId)
-				if strings.Contains(fieldType, LibraryReferenceNavigationList) {
+				if isNavigationList {
 					returnErrorIfNotInitialized := getReferenceNavigationListDBStmts(fn)
 					fn.Body.List = prependElem[ast.Stmt](fn.Body.List, &returnErrorIfNotInitialized)
 					t.fileChanged[path] = true
 				} else {
+					_, isVersioned := t.Output.TypesWithVersion[typeName]
+					isValidated := slices.Contains(t.Output.TypesWithValidation[typeName], fieldName)
+					isReferenceChecked := slices.Contains(t.Output.TypesWithCheckedReferences[typeName], fieldName)
+					isUnique := slices.Contains(t.Output.TypesWithUniqueFields[typeName], fieldName)
 					saveInDbIfInitialized := getSetterDBStmts(fn, getDBStmtsParam{
 						idFieldName:          idFieldName,
 						typeName:             typeName,
 						fieldName:            fieldName,
 						fieldType:            fieldType,
 						receiverVariableName: fn.Recv.List[0].Names[0].Name,
+						isVersioned:          isVersioned,
+						isValidated:          isValidated,
+						isReferenceChecked:   isReferenceChecked,
+						isUnique:             isUnique,
 					})
 					fn.Body.List = appendBeforeLastElem[ast.Stmt](fn.Body.List, &saveInDbIfInitialized)
 				}
@@ -162,8 +134,24 @@ func (t TypeSpecParser) addDBOperationsIfGetter(fn *ast.FuncDecl, path string) b
 	return false
 }
 
-func isErrorToBeReturnedNil(x ast.ReturnStmt) bool {
-	return (len(x.Results) > 1 && types.ExprString(x.Results[1]) == "nil") || (len(x.Results) == 1 && types.ExprString(x.Results[0]) == "nil")
+// The nameMethodResults names the results of the method, so that the error
+// returned can be read and replaced in the deferred epilogue. The unnamed
+// results are named with the blank identifier, apart from the error.
+// The name of the error result is returned.
+func nameMethodResults(fn *ast.FuncDecl) string {
+	results := fn.Type.Results.List
+	if len(results[0].Names) == 0 {
+		for _, result := range results {
+			result.Names = []*ast.Ident{{Name: "_"}}
+		}
+	}
+
+	errorResult := results[len(results)-1]
+	errorName := errorResult.Names[len(errorResult.Names)-1]
+	if errorName.Name == "_" {
+		errorName.Name = ResultErrorVariableName
+	}
+	return errorName.Name
 }
 
 func isInvocationDepthIncrementedInFirstStmt(funcBlock *ast.BlockStmt) bool {
@@ -3,7 +3,6 @@ package parser
 import (
 	"fmt"
 	"go/ast"
-	"go/types"
 	"strings"
 
 	"github.com/fatih/structtag"
@@ -28,6 +27,15 @@ type OneToManyRelationshipField struct {
 	FromFieldNameUpper string
 }
 
+// SortedIndex is the index of the type with the field referring to the owner
+// of one-to-many relationship as the partition key and the field given in
+// the sortedBy tag of the relationship as the sort key
+type SortedIndex struct {
+	PartitionAttributeName string
+	SortAttributeName      string
+	SortAttributeType      string
+}
+
 func NewManyToManyRelationshipField(typeName1, typeName2, fieldName string) *ManyToManyRelationshipField {
 	// aproach: partion key id is always the "smaller" string
 	// where "smaller" means: the ASCII number of the first distinct character
@@ -73,7 +81,7 @@ func NewManyToManyRelationshipField(typeName1, typeName2, fieldName string) *Man
 // the ast was modified (= whether the dynamodb tag was added).
 func (t *TypeSpecParser) parseRelationshipsTags(field *ast.Field, typeName string) bool {
 	tags, err := getParsedTags(field)
-	fieldType := types.ExprString(field.Type)
+	fieldType := t.resolver.typeString(field.Type)
 
 	if err != nil {
 		fmt.Println("error occurerd while checking struct tags of:", typeName, " field: ", field.Names[0].Name, ". Error: ", err)
@@ -96,8 +104,15 @@ func (t *TypeSpecParser) parseRelationshipsTags(field *ast.Field, typeName strin
 					navigationToTypeName = strings.Trim(navigationToTypeName, "[]")
 
 					t.Output.TypeAttributesIndexes[navigationToTypeName] = append(t.Output.TypeAttributesIndexes[navigationToTypeName], navigationToFieldName)
+					for _, option := range tag.Options {
+						if strings.HasPrefix(option, SortedByTag+"-") {
+							t.Output.TypeSortedIndexes[navigationToTypeName] = append(t.Output.TypeSortedIndexes[navigationToTypeName],
+								SortedIndex{PartitionAttributeName: navigationToFieldName, SortAttributeName: strings.TrimPrefix(option, SortedByTag+"-")})
+						}
+					}
 					navToField := OneToManyRelationshipField{TypeName: navigationToTypeName, FieldName: navigationToFieldName, FromFieldName: field.Names[0].Name}
 					t.Output.BidrectionalOneToManyRel[typeName] = append(t.Output.BidrectionalOneToManyRel[typeName], navToField)
+					t.detectDeletePolicy(tag, typeName, field.Names[0].Name)
 
 					return addDynamoDBIgnoreTag(tags, field, typeName)
 				} else {
@@ -113,6 +128,7 @@ func (t *TypeSpecParser) parseRelationshipsTags(field *ast.Field, typeName strin
 					newManyToManyRelationship := NewManyToManyRelationshipField(typeName, navigationToTypeName, field.Names[0].Name)
 					newManyToManyRelationship.FromFieldName = field.Names[0].Name
 					t.Output.ManyToManyRelationships[typeName] = append(t.Output.ManyToManyRelationships[typeName], *newManyToManyRelationship)
+					t.detectDeletePolicy(tag, typeName, field.Names[0].Name)
 					return addDynamoDBIgnoreTag(tags, field, typeName)
 				} else {
 					fmt.Println(HasManyTag, " or ", HasOneTag, " can be used only with ", LibraryReferenceNavigationList, " fields!")
@@ -125,6 +141,27 @@ func (t *TypeSpecParser) parseRelationshipsTags(field *ast.Field, typeName strin
 	return false
 }
 
+// The detectDeletePolicy adds the relationship with OnDeleteTag, e.g. onDelete=cascade,
+// to the delete policies of the type. The policies are applied by lib.Delete,
+// the relationships with invalid policies are reported and left without them.
+func (t *TypeSpecParser) detectDeletePolicy(tag *structtag.Tag, typeName, fieldName string) {
+	for _, option := range tag.Options {
+		name, policy, _ := strings.Cut(strings.TrimSpace(option), "=")
+		if name != OnDeleteTag {
+			continue
+		}
+
+		switch policy {
+		case CascadeOnDelete, RestrictOnDelete, NullifyOnDelete:
+			t.Output.TypesWithDeletePolicies[typeName] = append(t.Output.TypesWithDeletePolicies[typeName], fieldName)
+		default:
+			fmt.Println("ERROR: invalid", OnDeleteTag, "policy", policy, "of field", fieldName, "of type", typeName, ". The supported policies are:",
+				CascadeOnDelete, RestrictOnDelete, NullifyOnDelete)
+		}
+		return
+	}
+}
+
 func addDynamoDBIgnoreTag(tags *structtag.Tags, field *ast.Field, typeName string) bool {
 	dynamoTag, _ := tags.Get(DynamoDBTagKey)
 	if dynamoTag == nil {
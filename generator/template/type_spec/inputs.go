@@ -3,14 +3,48 @@ package typespec
 import "github.com/Astenna/Nubes/generator/parser"
 
 type ExportTemplateInput struct {
-	OrginalPackage            string
-	OrginalPackageAlias       string
+	// OrginalPackages maps the aliases to the import paths of the packages used by the handler
+	OrginalPackages map[string]string
+	// TypeAliases maps the types to the aliases of their packages
+	TypeAliases               map[string]string
 	IsNobjectInOrginalPackage map[string]bool
 	TypesWithCustomExport     map[string]parser.CustomExportDefinition
 }
 
 type DeleteTemplateInput struct {
-	OrginalPackage        string
-	OrginalPackageAlias   string
+	// OrginalPackages maps the aliases to the import paths of the packages used by the handler
+	OrginalPackages map[string]string
+	// TypeAliases maps the types to the aliases of their packages
+	TypeAliases           map[string]string
 	TypesWithCustomDelete map[string]parser.CustomDeleteDefinition
+	// TypesDeletedWithLib are the types without custom delete, which are deleted
+	// with lib.Delete to apply the delete policies of their relationships
+	// or to release the values of their unique fields
+	TypesDeletedWithLib map[string]bool
+}
+
+type SetFieldTemplateInput struct {
+	// OrginalPackages maps the aliases to the import paths of the packages used by the handler
+	OrginalPackages map[string]string
+	// TypeAliases maps the types to the aliases of their packages
+	TypeAliases                map[string]string
+	IsNobjectInOrginalPackage  map[string]bool
+	TypesWithVersion           map[string]string
+	TypesWithValidation        map[string][]string
+	TypesWithReadonlyFields    map[string][]string
+	TypesWithCheckedReferences map[string][]string
+	TypesWithUniqueFields      map[string][]string
+}
+
+type MigrationTemplateInput struct {
+	// Steps are the Go expressions creating lib.MigrationStep
+	Steps []string
+	// Notes are the changes of the types to be migrated manually
+	Notes []string
+	// NamePrefix precedes the names of the tables
+	NamePrefix string
+	// Schema is the Go string literal with the JSON of the schema snapshot
+	// of the current types, saved in SchemaPath once the migration completes
+	Schema     string
+	SchemaPath string
 }
@@ -0,0 +1,223 @@
+package lib
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+const (
+	// batchGetItemLimit is the maximum number of keys of a single BatchGetItem request
+	batchGetItemLimit = 100
+	// batchWriteItemLimit is the maximum number of writes of a single BatchWriteItem request
+	batchWriteItemLimit = 25
+	// maxConcurrentBatches bounds the number of batch requests sent at the same time
+	maxConcurrentBatches = 8
+	// maxBatchAttempts is the number of attempts to process
+	// the unprocessed keys or items of a batch request
+	maxBatchAttempts = 8
+)
+
+// batchRetryBaseDelay is the delay before the first retry of the
+// unprocessed keys or items, it is doubled with every next attempt
+var batchRetryBaseDelay = 50 * time.Millisecond
+
+// getItemsInBatches retrieves the items with the given ids from the table of the type. The ids are
+// split into chunks of at most batchGetItemLimit keys retrieved concurrently and the
+// unprocessed keys are retried with exponential backoff. The items are returned in the
+// order of the requested ids, the ids that were not found are skipped.
+// The projection, if given, must include the Id attribute.
+func getItemsInBatches(ctx context.Context, tableName string, ids []string, projection *string) ([]map[string]*dynamodb.AttributeValue, error) {
+	tableName = getTableName(tableName)
+	uniqueIds := distinct(ids)
+	chunks := splitIntoChunks(uniqueIds, batchGetItemLimit)
+	found := make(map[string]map[string]*dynamodb.AttributeValue, len(uniqueIds))
+	var foundMu sync.Mutex
+
+	err := runConcurrently(len(chunks), func(i int) error {
+		items, err := getChunk(ctx, tableName, chunks[i], projection)
+		if err != nil {
+			return err
+		}
+
+		foundMu.Lock()
+		defer foundMu.Unlock()
+		for _, item := range items {
+			if id, ok := item["Id"]; ok && id.S != nil {
+				found[*id.S] = item
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]map[string]*dynamodb.AttributeValue, 0, len(found))
+	for _, id := range ids {
+		if item, ok := found[id]; ok {
+			items = append(items, item)
+		}
+	}
+	return items, nil
+}
+
+func getChunk(ctx context.Context, tableName string, ids []string, projection *string) ([]map[string]*dynamodb.AttributeValue, error) {
+	keys := make([]map[string]*dynamodb.AttributeValue, len(ids))
+	for i, id := range ids {
+		keys[i] = map[string]*dynamodb.AttributeValue{"Id": {
+			S: aws.String(id),
+		}}
+	}
+
+	var items []map[string]*dynamodb.AttributeValue
+	requestItems := map[string]*dynamodb.KeysAndAttributes{
+		tableName: {
+			Keys:                 keys,
+			ProjectionExpression: projection,
+		},
+	}
+
+	for attempt := 0; countUnprocessedKeys(requestItems) > 0; attempt++ {
+		if attempt == maxBatchAttempts {
+			return nil, fmt.Errorf("%d keys of %s were not processed after %d attempts", countUnprocessedKeys(requestItems), tableName, maxBatchAttempts)
+		}
+		if attempt > 0 {
+			if err := waitBeforeRetry(ctx, attempt); err != nil {
+				return nil, err
+			}
+		}
+
+		output, err := dbClient(ctx).BatchGetItemWithContext(ctx, &dynamodb.BatchGetItemInput{RequestItems: requestItems})
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, output.Responses[tableName]...)
+		requestItems = output.UnprocessedKeys
+	}
+
+	return items, nil
+}
+
+// writeInBatches sends the write requests to the table of the type in chunks of at most
+// batchWriteItemLimit requests written concurrently. The unprocessed items
+// are retried with exponential backoff.
+func writeInBatches(ctx context.Context, tableName string, requests []*dynamodb.WriteRequest) error {
+	tableName = getTableName(tableName)
+	chunks := splitIntoChunks(requests, batchWriteItemLimit)
+
+	return runConcurrently(len(chunks), func(i int) error {
+		requestItems := map[string][]*dynamodb.WriteRequest{
+			tableName: chunks[i],
+		}
+
+		for attempt := 0; countUnprocessedItems(requestItems) > 0; attempt++ {
+			if attempt == maxBatchAttempts {
+				return fmt.Errorf("%d writes to %s were not processed after %d attempts", countUnprocessedItems(requestItems), tableName, maxBatchAttempts)
+			}
+			if attempt > 0 {
+				if err := waitBeforeRetry(ctx, attempt); err != nil {
+					return err
+				}
+			}
+
+			output, err := dbClient(ctx).BatchWriteItemWithContext(ctx, &dynamodb.BatchWriteItemInput{RequestItems: requestItems})
+			if err != nil {
+				return err
+			}
+			requestItems = output.UnprocessedItems
+		}
+		return nil
+	})
+}
+
+// runConcurrently invokes fn for each of the count chunks, at most maxConcurrentBatches
+// at the same time, and returns the error of the first failed chunk
+func runConcurrently(count int, fn func(i int) error) error {
+	if count == 1 {
+		return fn(0)
+	}
+
+	errs := make([]error, count)
+	semaphore := make(chan struct{}, maxConcurrentBatches)
+	var wg sync.WaitGroup
+
+	for i := 0; i < count; i++ {
+		wg.Add(1)
+		semaphore <- struct{}{}
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+			errs[i] = fn(i)
+		}(i)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// waitBeforeRetry sleeps for the exponentially growing delay with jitter,
+// the error of the context is returned if it is done in the meantime
+func waitBeforeRetry(ctx context.Context, attempt int) error {
+	delay := batchRetryBaseDelay << (attempt - 1)
+	timer := time.NewTimer(delay/2 + time.Duration(rand.Int63n(int64(delay/2)+1)))
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func countUnprocessedKeys(requestItems map[string]*dynamodb.KeysAndAttributes) int {
+	count := 0
+	for _, request := range requestItems {
+		if request != nil {
+			count += len(request.Keys)
+		}
+	}
+	return count
+}
+
+func countUnprocessedItems(requestItems map[string][]*dynamodb.WriteRequest) int {
+	count := 0
+	for _, requests := range requestItems {
+		count += len(requests)
+	}
+	return count
+}
+
+func splitIntoChunks[T any](elems []T, size int) [][]T {
+	chunks := make([][]T, 0, (len(elems)+size-1)/size)
+	for size < len(elems) {
+		elems, chunks = elems[size:], append(chunks, elems[:size])
+	}
+	if len(elems) > 0 {
+		chunks = append(chunks, elems)
+	}
+	return chunks
+}
+
+func distinct(ids []string) []string {
+	seen := make(map[string]struct{}, len(ids))
+	result := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if _, ok := seen[id]; !ok {
+			seen[id] = struct{}{}
+			result = append(result, id)
+		}
+	}
+	return result
+}
@@ -0,0 +1,33 @@
+package lib
+
+import (
+	"context"
+	"sync"
+)
+
+var (
+	invocationContext   context.Context
+	invocationContextMu sync.Mutex
+)
+
+// SetInvocationContext sets the context of the invocation being handled, e.g. the
+// context of the lambda handler. The library operations invoked without a context,
+// e.g. in the methods of the types, use it for the DB calls, so that they are
+// stopped when the invocation is cancelled or its deadline is exceeded.
+// Passing nil resets it to the background context.
+func SetInvocationContext(ctx context.Context) {
+	invocationContextMu.Lock()
+	defer invocationContextMu.Unlock()
+	invocationContext = ctx
+}
+
+// InvocationContext returns the context of the invocation being handled,
+// or the background context if none was set with SetInvocationContext
+func InvocationContext() context.Context {
+	invocationContextMu.Lock()
+	defer invocationContextMu.Unlock()
+	if invocationContext == nil {
+		return context.Background()
+	}
+	return invocationContext
+}
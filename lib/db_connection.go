@@ -1,12 +1,206 @@
 package lib
 
 import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/request"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/dynamodb"
 )
 
-var _session = session.Must(session.NewSessionWithOptions(session.Options{
-	SharedConfigState: session.SharedConfigEnable,
-}))
+// Store defines the storage operations the library relies on.
+// The signatures follow the DynamoDB API, so the DynamoDB client
+// of the AWS SDK is a valid Store and is used by default.
+// Other implementations can be plugged in with SetStore.
+// The operations must stop when the context is cancelled.
+type Store interface {
+	GetItemWithContext(ctx aws.Context, input *dynamodb.GetItemInput, opts ...request.Option) (*dynamodb.GetItemOutput, error)
+	PutItemWithContext(ctx aws.Context, input *dynamodb.PutItemInput, opts ...request.Option) (*dynamodb.PutItemOutput, error)
+	UpdateItemWithContext(ctx aws.Context, input *dynamodb.UpdateItemInput, opts ...request.Option) (*dynamodb.UpdateItemOutput, error)
+	DeleteItemWithContext(ctx aws.Context, input *dynamodb.DeleteItemInput, opts ...request.Option) (*dynamodb.DeleteItemOutput, error)
+	QueryWithContext(ctx aws.Context, input *dynamodb.QueryInput, opts ...request.Option) (*dynamodb.QueryOutput, error)
+	BatchGetItemWithContext(ctx aws.Context, input *dynamodb.BatchGetItemInput, opts ...request.Option) (*dynamodb.BatchGetItemOutput, error)
+	BatchWriteItemWithContext(ctx aws.Context, input *dynamodb.BatchWriteItemInput, opts ...request.Option) (*dynamodb.BatchWriteItemOutput, error)
+	TransactWriteItemsWithContext(ctx aws.Context, input *dynamodb.TransactWriteItemsInput, opts ...request.Option) (*dynamodb.TransactWriteItemsOutput, error)
+}
+
+// The environment variables used as the defaults of the Config fields
+const (
+	EndpointEnvVariable    = "NUBES_DYNAMODB_ENDPOINT"
+	RegionEnvVariable      = "NUBES_DYNAMODB_REGION"
+	TablePrefixEnvVariable = "NUBES_TABLE_PREFIX"
+)
+
+// Config configures the default DynamoDB store and the names of the tables.
+// The empty fields are set from the corresponding environment variables.
+type Config struct {
+	// Endpoint of DynamoDB, e.g. http://localhost:8000 for DynamoDB Local.
+	// If empty, the endpoint of the region is used.
+	Endpoint string
+	// Region of DynamoDB. If empty, the region of the shared AWS config is used.
+	Region string
+	// TablePrefix is prepended to the names of all the tables used by the library
+	TablePrefix string
+	// Credentials used to sign the requests. If nil, the default credentials
+	// chain is used, i.e. the environment variables, the shared credentials
+	// file and the IAM role of the lambda function.
+	Credentials *credentials.Credentials
+}
+
+func (c Config) withEnvDefaults() Config {
+	if c.Endpoint == "" {
+		c.Endpoint = os.Getenv(EndpointEnvVariable)
+	}
+	if c.Region == "" {
+		c.Region = os.Getenv(RegionEnvVariable)
+	}
+	if c.TablePrefix == "" {
+		c.TablePrefix = os.Getenv(TablePrefixEnvVariable)
+	}
+	return c
+}
+
+var (
+	store   Store
+	storeMu sync.Mutex
+	// config is nil until Configure is invoked or the library is used
+	config *Config
+	// isDefaultStore indicates the store was created from the config
+	isDefaultStore bool
+)
+
+// Configure sets the configuration of the library. It is meant to be invoked
+// before the library is used, e.g. in the main function. The default DynamoDB
+// store is created with the new configuration when it is needed, while the
+// store set with SetStore is kept.
+func Configure(c Config) {
+	c = c.withEnvDefaults()
+
+	storeMu.Lock()
+	defer storeMu.Unlock()
+	config = &c
+	if isDefaultStore {
+		store = nil
+		isDefaultStore = false
+	}
+}
+
+// SetStore replaces the store used by all the library operations.
+// It is meant to be invoked before the library is used, e.g. in tests setup.
+// Passing nil restores the default DynamoDB store.
+func SetStore(s Store) {
+	storeMu.Lock()
+	defer storeMu.Unlock()
+	store = s
+	isDefaultStore = false
+}
+
+// NewDynamoDBStore creates the DynamoDB client configured
+// with the config and the shared AWS config
+func NewDynamoDBStore(c Config) (Store, error) {
+	awsConfig := aws.Config{Credentials: c.Credentials}
+	if c.Endpoint != "" {
+		awsConfig.Endpoint = aws.String(c.Endpoint)
+	}
+	if c.Region != "" {
+		awsConfig.Region = aws.String(c.Region)
+	}
+
+	_session, err := session.NewSessionWithOptions(session.Options{
+		Config:            awsConfig,
+		SharedConfigState: session.SharedConfigEnable,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the AWS session: %w", err)
+	}
+	return dynamodb.New(_session), nil
+}
+
+// dbClient returns the store currently in use. The default DynamoDB store
+// is created lazily, so that no AWS session is created unless needed.
+// If ctx carries a transaction, its writes are collected by the store returned.
+func dbClient(ctx context.Context) Store {
+	if tx := transactionFromContext(ctx); tx != nil {
+		return transactionStore{Store: tx.store, tx: tx}
+	}
+
+	storeMu.Lock()
+	defer storeMu.Unlock()
+	return currentStore()
+}
+
+// currentStore returns the store in use, creating the default one if needed.
+// If it can not be created, the returned store fails all the operations,
+// and the creation is attempted again on the next use. storeMu must be held.
+func currentStore() Store {
+	if store != nil {
+		return store
+	}
+
+	created, err := NewDynamoDBStore(currentConfig())
+	if err != nil {
+		return failingStore{err: err}
+	}
+	store, isDefaultStore = created, true
+	return store
+}
+
+// currentConfig returns the config set with Configure or the
+// one read from the environment variables. storeMu must be held.
+func currentConfig() Config {
+	if config == nil {
+		defaults := Config{}.withEnvDefaults()
+		config = &defaults
+	}
+	return *config
+}
+
+// getTableName returns the name of the table of the type,
+// i.e. the type name preceded by the configured table prefix
+func getTableName(typeName string) string {
+	storeMu.Lock()
+	defer storeMu.Unlock()
+	return currentConfig().TablePrefix + typeName
+}
+
+// failingStore is used when the default store can not be created
+type failingStore struct {
+	err error
+}
+
+func (f failingStore) GetItemWithContext(aws.Context, *dynamodb.GetItemInput, ...request.Option) (*dynamodb.GetItemOutput, error) {
+	return nil, f.err
+}
+
+func (f failingStore) PutItemWithContext(aws.Context, *dynamodb.PutItemInput, ...request.Option) (*dynamodb.PutItemOutput, error) {
+	return nil, f.err
+}
+
+func (f failingStore) UpdateItemWithContext(aws.Context, *dynamodb.UpdateItemInput, ...request.Option) (*dynamodb.UpdateItemOutput, error) {
+	return nil, f.err
+}
+
+func (f failingStore) DeleteItemWithContext(aws.Context, *dynamodb.DeleteItemInput, ...request.Option) (*dynamodb.DeleteItemOutput, error) {
+	return nil, f.err
+}
+
+func (f failingStore) QueryWithContext(aws.Context, *dynamodb.QueryInput, ...request.Option) (*dynamodb.QueryOutput, error) {
+	return nil, f.err
+}
+
+func (f failingStore) BatchGetItemWithContext(aws.Context, *dynamodb.BatchGetItemInput, ...request.Option) (*dynamodb.BatchGetItemOutput, error) {
+	return nil, f.err
+}
+
+func (f failingStore) BatchWriteItemWithContext(aws.Context, *dynamodb.BatchWriteItemInput, ...request.Option) (*dynamodb.BatchWriteItemOutput, error) {
+	return nil, f.err
+}
 
-var dbClient = dynamodb.New(_session)
+func (f failingStore) TransactWriteItemsWithContext(aws.Context, *dynamodb.TransactWriteItemsInput, ...request.Option) (*dynamodb.TransactWriteItemsOutput, error) {
+	return nil, f.err
+}
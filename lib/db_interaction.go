@@ -1,8 +1,11 @@
 package lib
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"reflect"
+	"strconv"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/dynamodb"
@@ -12,12 +15,26 @@ import (
 )
 
 func Insert(objToInsert Nobject) (string, error) {
+	return InsertWithContext(InvocationContext(), objToInsert)
+}
+
+func InsertWithContext(ctx context.Context, objToInsert Nobject) (string, error) {
+	if err := Validate(objToInsert); err != nil {
+		return "", err
+	}
+	if err := CheckReferencesWithContext(ctx, objToInsert); err != nil {
+		return "", err
+	}
+
 	var attributeVals, err = dynamodbattribute.MarshalMap(objToInsert)
 	if err != nil {
 		return "", err
 	}
 
 	var newId string
+	// the object with the custom id replaces the existing one,
+	// whose unique values are read to release them
+	var previous map[string]*dynamodb.AttributeValue
 	if custom, ok := objToInsert.(CustomId); ok {
 		if newId = custom.GetId(); newId == "" {
 			return "", errors.New("id field empty. It must be set when using non-default id field")
@@ -28,14 +45,18 @@ func Insert(objToInsert Nobject) (string, error) {
 		// without this, dynamodb throws error because more than
 		// one of the supported datatypes is set to not nil
 		attr.NULL = nil
+		previous = map[string]*dynamodb.AttributeValue{}
 	}
 
 	input := &dynamodb.PutItemInput{
 		Item:      attributeVals,
-		TableName: aws.String(objToInsert.GetTypeName()),
+		TableName: aws.String(getTableName(objToInsert.GetTypeName())),
 	}
 
-	_, err = dbClient.PutItem(input)
+	err = writeGuardingUniqueValues(ctx, getObjectUniqueFields(objToInsert), objToInsert.GetTypeName(), newId, previous, attributeVals, nil, func(ctx context.Context) error {
+		_, err := dbClient(ctx).PutItemWithContext(ctx, input)
+		return err
+	})
 	if err != nil {
 		return "", err
 	}
@@ -44,6 +65,17 @@ func Insert(objToInsert Nobject) (string, error) {
 }
 
 func Upsert(objToInsert Nobject, id string) error {
+	return UpsertWithContext(InvocationContext(), objToInsert, id)
+}
+
+func UpsertWithContext(ctx context.Context, objToInsert Nobject, id string) error {
+	if err := Validate(objToInsert); err != nil {
+		return err
+	}
+	if err := CheckReferencesWithContext(ctx, objToInsert); err != nil {
+		return err
+	}
+
 	var attributeVals, err = dynamodbattribute.MarshalMap(objToInsert)
 	if err != nil {
 		return err
@@ -54,27 +86,141 @@ func Upsert(objToInsert Nobject, id string) error {
 	// one of the supported datatypes is set to not nil
 	attr.NULL = nil
 
+	if readonlyAttributes := getReadonlyAttributes(objToInsert); len(readonlyAttributes) > 0 {
+		return upsertPreservingReadonly(ctx, objToInsert, id, attributeVals, readonlyAttributes)
+	}
+
 	input := &dynamodb.PutItemInput{
 		Item:      attributeVals,
-		TableName: aws.String(objToInsert.GetTypeName()),
+		TableName: aws.String(getTableName(objToInsert.GetTypeName())),
 	}
 
-	_, err = dbClient.PutItem(input)
+	var conditionErr error
+	versioned, isVersioned := objToInsert.(Versioned)
+	if isVersioned {
+		expectedVersion := versioned.GetVersion()
+		attributeVals[VersionAttributeName] = &dynamodb.AttributeValue{N: aws.String(strconv.Itoa(expectedVersion + 1))}
+
+		expr, err := expression.NewBuilder().WithCondition(getVersionCondition(expectedVersion)).Build()
+		if err != nil {
+			return fmt.Errorf("error occurred when building dynamodb condition expression %w", err)
+		}
+		input.ConditionExpression = expr.Condition()
+		input.ExpressionAttributeNames = expr.Names()
+		input.ExpressionAttributeValues = expr.Values()
+		conditionErr = ConflictError{Id: id, TypeName: objToInsert.GetTypeName(), ExpectedVersion: expectedVersion}
+	}
+
+	err = writeGuardingUniqueValues(ctx, getObjectUniqueFields(objToInsert), objToInsert.GetTypeName(), id, nil, attributeVals, conditionErr, func(ctx context.Context) error {
+		_, err := dbClient(ctx).PutItemWithContext(ctx, input)
+		return err
+	})
 	if err != nil {
+		if _, ok := err.(*dynamodb.ConditionalCheckFailedException); ok && isVersioned {
+			return ConflictError{Id: id, TypeName: objToInsert.GetTypeName(), ExpectedVersion: versioned.GetVersion()}
+		}
 		return err
 	}
 
+	if isVersioned {
+		versioned.SetVersion(versioned.GetVersion() + 1)
+	}
+	return nil
+}
+
+// upsertPreservingReadonly saves the object with an update instead of replacing the item,
+// so that the values of the readonly attributes are set only if the object does not exist.
+// The attributes of the fields missing in attributeVals are removed, as they are by Upsert.
+func upsertPreservingReadonly(ctx context.Context, objToInsert Nobject, id string, attributeVals map[string]*dynamodb.AttributeValue, readonlyAttributes map[string]bool) error {
+	update := expression.UpdateBuilder{}
+	for _, name := range sortedAttributeNames(attributeVals) {
+		if name == "Id" || name == VersionAttributeName {
+			continue
+		}
+		if readonlyAttributes[name] {
+			update = update.Set(expression.Name(name), expression.IfNotExists(expression.Name(name), expression.Value(attributeVals[name])))
+		} else {
+			update = update.Set(expression.Name(name), expression.Value(attributeVals[name]))
+		}
+	}
+	for _, name := range getStoredAttributes(reflect.Indirect(reflect.ValueOf(objToInsert)).Type()).names {
+		if _, exists := attributeVals[name]; !exists && !readonlyAttributes[name] && name != "Id" && name != VersionAttributeName {
+			update = update.Remove(expression.Name(name))
+		}
+	}
+
+	builder := expression.NewBuilder()
+	var conditionErr error
+	versioned, isVersioned := objToInsert.(Versioned)
+	if isVersioned {
+		expectedVersion := versioned.GetVersion()
+		update = update.Set(expression.Name(VersionAttributeName), expression.Value(expectedVersion+1))
+		builder = builder.WithCondition(getVersionCondition(expectedVersion))
+		conditionErr = ConflictError{Id: id, TypeName: objToInsert.GetTypeName(), ExpectedVersion: expectedVersion}
+	}
+	expr, err := builder.WithUpdate(update).Build()
+	if err != nil {
+		return fmt.Errorf("error occurred when building dynamodb update expression %w", err)
+	}
+
+	uniqueFields := getObjectUniqueFields(objToInsert)
+	var previous map[string]*dynamodb.AttributeValue
+	current := attributeVals
+	if len(uniqueFields) > 0 {
+		if previous, err = getStoredUniqueAttributes(ctx, objToInsert.GetTypeName(), id, uniqueFields); err != nil {
+			return err
+		}
+		// the stored values of the readonly fields are preserved,
+		// so they are the ones whose uniqueness is guarded
+		current = make(map[string]*dynamodb.AttributeValue, len(attributeVals))
+		for name, attribute := range attributeVals {
+			if stored, exists := previous[name]; exists && readonlyAttributes[name] {
+				attribute = stored
+			}
+			current[name] = attribute
+		}
+	}
+
+	err = writeGuardingUniqueValues(ctx, uniqueFields, objToInsert.GetTypeName(), id, previous, current, conditionErr, func(ctx context.Context) error {
+		_, err := dbClient(ctx).UpdateItemWithContext(ctx, &dynamodb.UpdateItemInput{
+			TableName: aws.String(getTableName(objToInsert.GetTypeName())),
+			Key: map[string]*dynamodb.AttributeValue{
+				"Id": {
+					S: aws.String(id),
+				},
+			},
+			UpdateExpression:          expr.Update(),
+			ConditionExpression:       expr.Condition(),
+			ExpressionAttributeNames:  expr.Names(),
+			ExpressionAttributeValues: expr.Values(),
+		})
+		return err
+	})
+	if err != nil {
+		if _, ok := err.(*dynamodb.ConditionalCheckFailedException); ok && isVersioned {
+			return ConflictError{Id: id, TypeName: objToInsert.GetTypeName(), ExpectedVersion: versioned.GetVersion()}
+		}
+		return err
+	}
+
+	if isVersioned {
+		versioned.SetVersion(versioned.GetVersion() + 1)
+	}
 	return nil
 }
 
 func GetStub[T Nobject](id string, object *T) error {
+	return GetStubWithContext[T](InvocationContext(), id, object)
+}
+
+func GetStubWithContext[T Nobject](ctx context.Context, id string, object *T) error {
 
 	if object == nil {
 		return fmt.Errorf("object whose state is to be retrieved is nil")
 	}
 
 	input := &dynamodb.GetItemInput{
-		TableName: aws.String((*object).GetTypeName()),
+		TableName: aws.String(getTableName((*object).GetTypeName())),
 		Key: map[string]*dynamodb.AttributeValue{
 			"Id": {
 				S: aws.String(id),
@@ -82,7 +228,7 @@ func GetStub[T Nobject](id string, object *T) error {
 		},
 	}
 
-	item, err := dbClient.GetItem(input)
+	item, err := dbClient(ctx).GetItemWithContext(ctx, input)
 	if err != nil {
 		return err
 	}
@@ -96,6 +242,10 @@ func GetStub[T Nobject](id string, object *T) error {
 }
 
 func GetStubWithTypeNameAsArg(id, typeName string) (map[string]interface{}, error) {
+	return GetStubWithTypeNameAsArgWithContext(InvocationContext(), id, typeName)
+}
+
+func GetStubWithTypeNameAsArgWithContext(ctx context.Context, id, typeName string) (map[string]interface{}, error) {
 	if id == "" {
 		return nil, fmt.Errorf("missing id of object to get")
 	}
@@ -104,7 +254,7 @@ func GetStubWithTypeNameAsArg(id, typeName string) (map[string]interface{}, erro
 	}
 
 	input := &dynamodb.GetItemInput{
-		TableName: aws.String(typeName),
+		TableName: aws.String(getTableName(typeName)),
 		Key: map[string]*dynamodb.AttributeValue{
 			"Id": {
 				S: aws.String(id),
@@ -112,7 +262,7 @@ func GetStubWithTypeNameAsArg(id, typeName string) (map[string]interface{}, erro
 		},
 	}
 
-	item, err := dbClient.GetItem(input)
+	item, err := dbClient(ctx).GetItemWithContext(ctx, input)
 	if err != nil {
 		return nil, err
 	}
@@ -127,6 +277,33 @@ func GetStubWithTypeNameAsArg(id, typeName string) (map[string]interface{}, erro
 }
 
 func GetByIndex(param QueryByIndexParam) ([]string, error) {
+	return GetByIndexWithContext(InvocationContext(), param)
+}
+
+func GetByIndexWithContext(ctx context.Context, param QueryByIndexParam) ([]string, error) {
+	queryInput, err := getQueryByIndexInput(param)
+	if err != nil {
+		return nil, err
+	}
+	return queryAllIds(ctx, queryInput, param.OutputAttributeName)
+}
+
+// GetByIndexPage returns at most limit ids starting from the cursor
+// returned with the previous page, the empty cursor denotes the first page
+func GetByIndexPage(param QueryByIndexParam, limit int, cursor string) (IdsPage, error) {
+	return GetByIndexPageWithContext(InvocationContext(), param, limit, cursor)
+}
+
+// GetByIndexPageWithContext is the same as GetByIndexPage with the addition of the ability to pass a context
+func GetByIndexPageWithContext(ctx context.Context, param QueryByIndexParam, limit int, cursor string) (IdsPage, error) {
+	queryInput, err := getQueryByIndexInput(param)
+	if err != nil {
+		return IdsPage{}, err
+	}
+	return queryIdsPage(ctx, queryInput, param.OutputAttributeName, limit, cursor)
+}
+
+func getQueryByIndexInput(param QueryByIndexParam) (*dynamodb.QueryInput, error) {
 	if err := param.Validate(); err != nil {
 		return nil, err
 	}
@@ -141,28 +318,44 @@ func GetByIndex(param QueryByIndexParam) ([]string, error) {
 		return nil, errExpression
 	}
 
-	queryInput := &dynamodb.QueryInput{
-		TableName:                 aws.String(param.TableName),
+	return &dynamodb.QueryInput{
+		TableName:                 aws.String(getTableName(param.TableName)),
 		IndexName:                 aws.String(param.IndexName),
 		ExpressionAttributeNames:  expr.Names(),
 		KeyConditionExpression:    expr.KeyCondition(),
 		ProjectionExpression:      expr.Projection(),
 		ExpressionAttributeValues: expr.Values(),
-	}
+	}, nil
+}
 
-	items, err := dbClient.Query(queryInput)
+func GetSortKeysByPartitionKey(q QueryByPartitionKeyParam) ([]string, error) {
+	return GetSortKeysByPartitionKeyWithContext(InvocationContext(), q)
+}
+
+func GetSortKeysByPartitionKeyWithContext(ctx context.Context, q QueryByPartitionKeyParam) ([]string, error) {
+	input, err := getQueryByPartitionKeyInput(q)
 	if err != nil {
 		return nil, err
 	}
+	return queryAllIds(ctx, input, q.OutputAttributeName)
+}
+
+// GetSortKeysByPartitionKeyPage returns at most limit sort keys starting from
+// the cursor returned with the previous page, the empty cursor denotes the first page
+func GetSortKeysByPartitionKeyPage(q QueryByPartitionKeyParam, limit int, cursor string) (IdsPage, error) {
+	return GetSortKeysByPartitionKeyPageWithContext(InvocationContext(), q, limit, cursor)
+}
 
-	outputIds := make([]string, len(items.Items))
-	for index, attr := range items.Items {
-		outputIds[index] = *attr[param.OutputAttributeName].S
+// GetSortKeysByPartitionKeyPageWithContext is the same as GetSortKeysByPartitionKeyPage with the addition of the ability to pass a context
+func GetSortKeysByPartitionKeyPageWithContext(ctx context.Context, q QueryByPartitionKeyParam, limit int, cursor string) (IdsPage, error) {
+	input, err := getQueryByPartitionKeyInput(q)
+	if err != nil {
+		return IdsPage{}, err
 	}
-	return outputIds, nil
+	return queryIdsPage(ctx, input, q.OutputAttributeName, limit, cursor)
 }
 
-func GetSortKeysByPartitionKey(q QueryByPartitionKeyParam) ([]string, error) {
+func getQueryByPartitionKeyInput(q QueryByPartitionKeyParam) (*dynamodb.QueryInput, error) {
 	if err := q.Validate(); err != nil {
 		return nil, err
 	}
@@ -176,57 +369,35 @@ func GetSortKeysByPartitionKey(q QueryByPartitionKeyParam) ([]string, error) {
 		fmt.Println("error: creating dynamoDB expression ", errExpression)
 		return nil, errExpression
 	}
-	input := &dynamodb.QueryInput{
-		TableName:                 aws.String(q.TableName),
+
+	return &dynamodb.QueryInput{
+		TableName:                 aws.String(getTableName(q.TableName)),
 		ExpressionAttributeNames:  expr.Names(),
 		KeyConditionExpression:    expr.KeyCondition(),
 		ProjectionExpression:      expr.Projection(),
 		ExpressionAttributeValues: expr.Values(),
-	}
-
-	items, err := dbClient.Query(input)
-	if err != nil {
-		return nil, err
-	}
-
-	outputIds := make([]string, len(items.Items))
-	for index, attr := range items.Items {
-		outputIds[index] = *attr[q.OutputAttributeName].S
-	}
-
-	return outputIds, err
+	}, nil
 }
 
 func GetStubsInBatch[T Nobject](ids []string) (*[]T, error) {
+	return GetStubsInBatchWithContext[T](InvocationContext(), ids)
+}
+
+func GetStubsInBatchWithContext[T Nobject](ctx context.Context, ids []string) (*[]T, error) {
 	if ids == nil {
 		return nil, fmt.Errorf("missing id of object to get")
 	}
 
-	keysToRetrieve := make([]map[string]*dynamodb.AttributeValue, len(ids))
-	for i, id := range ids {
-		keysToRetrieve[i] = map[string]*dynamodb.AttributeValue{"Id": {
-			S: aws.String(id),
-		}}
-	}
-
 	tableName := (*new(T)).GetTypeName()
-	input := &dynamodb.BatchGetItemInput{
-		RequestItems: map[string]*dynamodb.KeysAndAttributes{
-			tableName: {
-				Keys: keysToRetrieve,
-			},
-		},
-	}
-
-	items, err := dbClient.BatchGetItem(input)
+	items, err := getItemsInBatches(ctx, tableName, ids, nil)
 	if err != nil {
 		return nil, err
 	}
 
 	var parsedItem = new([]T)
-	if items.Responses[tableName] != nil {
+	if len(items) > 0 {
 
-		err = dynamodbattribute.UnmarshalListOfMaps(items.Responses[tableName], parsedItem)
+		err = dynamodbattribute.UnmarshalListOfMaps(items, parsedItem)
 		return parsedItem, err
 	}
 
@@ -234,34 +405,23 @@ func GetStubsInBatch[T Nobject](ids []string) (*[]T, error) {
 }
 
 func GetStubsInBatchWithTypeNameAsArg(param GetBatchParam) ([]interface{}, error) {
+	return GetStubsInBatchWithTypeNameAsArgWithContext(InvocationContext(), param)
+}
+
+func GetStubsInBatchWithTypeNameAsArgWithContext(ctx context.Context, param GetBatchParam) ([]interface{}, error) {
 	if err := param.Validate(); err != nil {
 		return nil, err
 	}
 
-	keysToRetrieve := make([]map[string]*dynamodb.AttributeValue, len(param.Ids))
-	for i, id := range param.Ids {
-		keysToRetrieve[i] = map[string]*dynamodb.AttributeValue{"Id": {
-			S: aws.String(id),
-		}}
-	}
-
-	input := &dynamodb.BatchGetItemInput{
-		RequestItems: map[string]*dynamodb.KeysAndAttributes{
-			param.TypeName: {
-				Keys: keysToRetrieve,
-			},
-		},
-	}
-
-	items, err := dbClient.BatchGetItem(input)
+	items, err := getItemsInBatches(ctx, param.TypeName, param.Ids, nil)
 	if err != nil {
 		return nil, err
 	}
 
 	var parsedItem = new([]interface{})
-	if items.Responses[param.TypeName] != nil {
+	if len(items) > 0 {
 
-		err = dynamodbattribute.UnmarshalListOfMaps(items.Responses[param.TypeName], parsedItem)
+		err = dynamodbattribute.UnmarshalListOfMaps(items, parsedItem)
 		return *parsedItem, err
 	}
 
@@ -269,6 +429,10 @@ func GetStubsInBatchWithTypeNameAsArg(param GetBatchParam) ([]interface{}, error
 }
 
 func GetField(param GetStateParam) (interface{}, error) {
+	return GetFieldWithContext(InvocationContext(), param)
+}
+
+func GetFieldWithContext(ctx context.Context, param GetStateParam) (interface{}, error) {
 	if err := param.Validate(); err != nil {
 		return nil, err
 	}
@@ -277,7 +441,7 @@ func GetField(param GetStateParam) (interface{}, error) {
 	}
 
 	input := &dynamodb.GetItemInput{
-		TableName: aws.String(param.TypeName),
+		TableName: aws.String(getTableName(param.TypeName)),
 		Key: map[string]*dynamodb.AttributeValue{
 			"Id": {
 				S: aws.String(param.Id),
@@ -286,7 +450,7 @@ func GetField(param GetStateParam) (interface{}, error) {
 		ProjectionExpression: &param.FieldName,
 	}
 
-	item, err := dbClient.GetItem(input)
+	item, err := dbClient(ctx).GetItemWithContext(ctx, input)
 	if err != nil {
 		return *new(interface{}), err
 	}
@@ -301,12 +465,16 @@ func GetField(param GetStateParam) (interface{}, error) {
 }
 
 func GetFieldOfType[N any](param GetStateParam, field *N) error {
+	return GetFieldOfTypeWithContext[N](InvocationContext(), param, field)
+}
+
+func GetFieldOfTypeWithContext[N any](ctx context.Context, param GetStateParam, field *N) error {
 	if err := param.Validate(); err != nil {
 		return err
 	}
 
 	input := &dynamodb.GetItemInput{
-		TableName: aws.String(param.TypeName),
+		TableName: aws.String(getTableName(param.TypeName)),
 		Key: map[string]*dynamodb.AttributeValue{
 			"Id": {
 				S: aws.String(param.Id),
@@ -315,7 +483,7 @@ func GetFieldOfType[N any](param GetStateParam, field *N) error {
 		ProjectionExpression: &param.FieldName,
 	}
 
-	item, err := dbClient.GetItem(input)
+	item, err := dbClient(ctx).GetItemWithContext(ctx, input)
 	if err != nil {
 		return err
 	}
@@ -331,36 +499,75 @@ func GetFieldOfType[N any](param GetStateParam, field *N) error {
 }
 
 func SetField(param SetFieldParam) error {
+	return SetFieldWithContext(InvocationContext(), param)
+}
+
+// SetFieldWithContext is the same as SetField with the addition of the ability to pass a context.
+// If the type of the object was registered with RegisterType, ReadonlyFieldError is returned
+// for the readonly fields, ValidationError if the value does not satisfy the validation rules
+// of the field, and the guard items of the unique values are updated together with the field.
+func SetFieldWithContext(ctx context.Context, param SetFieldParam) error {
 	if err := param.Validate(); err != nil {
 		return err
 	}
 
+	objType, isRegistered := getRegisteredType(param.TypeName)
+	if !isRegistered {
+		return setField(ctx, param)
+	}
+	obj := reflect.New(objType).Elem().Interface()
+	if err := CheckFieldIsWritable(obj, param.FieldName); err != nil {
+		return err
+	}
+	if err := ValidateField(obj, param.FieldName, param.Value); err != nil {
+		return err
+	}
+	return setUniqueField(ctx, objType, param)
+}
+
+// setField sets the field without checking the rules of the field
+func setField(ctx context.Context, param SetFieldParam) error {
 	update := expression.UpdateBuilder{}
 	update = update.Set(expression.Name(param.FieldName), expression.Value(param.Value))
-	expr, err := expression.NewBuilder().WithUpdate(update).Build()
+	builder := expression.NewBuilder()
+	if param.Versioned || param.ExpectedVersion != nil {
+		update = update.Add(expression.Name(VersionAttributeName), expression.Value(1))
+	}
+	if param.ExpectedVersion != nil {
+		builder = builder.WithCondition(getVersionCondition(*param.ExpectedVersion))
+	}
+	expr, err := builder.WithUpdate(update).Build()
 	if err != nil {
 		return fmt.Errorf("error occurred when building dynamodb update expression %w", err)
 	}
 
-	_, err = dbClient.UpdateItem(&dynamodb.UpdateItemInput{
-		TableName: aws.String(param.TypeName),
+	_, err = dbClient(ctx).UpdateItemWithContext(ctx, &dynamodb.UpdateItemInput{
+		TableName: aws.String(getTableName(param.TypeName)),
 		Key: map[string]*dynamodb.AttributeValue{
 			"Id": {
 				S: aws.String(param.Id),
 			},
 		},
 		UpdateExpression:          expr.Update(),
+		ConditionExpression:       expr.Condition(),
 		ExpressionAttributeNames:  expr.Names(),
 		ExpressionAttributeValues: expr.Values(),
 	})
 
+	if _, ok := err.(*dynamodb.ConditionalCheckFailedException); ok {
+		return ConflictError{Id: param.Id, TypeName: param.TypeName, ExpectedVersion: *param.ExpectedVersion}
+	}
 	return err
 }
 
 func IsInstanceAlreadyCreated(param IsInstanceAlreadyCreatedParam) (bool, error) {
+	return IsInstanceAlreadyCreatedWithContext(InvocationContext(), param)
+}
+
+func IsInstanceAlreadyCreatedWithContext(ctx context.Context, param IsInstanceAlreadyCreatedParam) (bool, error) {
 
-	item, err := dbClient.GetItem(&dynamodb.GetItemInput{
-		TableName: aws.String(param.TypeName),
+	item, err := dbClient(ctx).GetItemWithContext(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(getTableName(param.TypeName)),
 		Key: map[string]*dynamodb.AttributeValue{
 			"Id": {
 				S: aws.String(param.Id),
@@ -381,47 +588,30 @@ func IsInstanceAlreadyCreated(param IsInstanceAlreadyCreatedParam) (bool, error)
 }
 
 func AreInstancesAlreadyCreated(param LoadBatchParam) error {
+	return AreInstancesAlreadyCreatedWithContext(InvocationContext(), param)
+}
+
+func AreInstancesAlreadyCreatedWithContext(ctx context.Context, param LoadBatchParam) error {
 	if err := param.Verify(); err != nil {
 		return err
 	}
 
-	keysToRetrieve := make([]map[string]*dynamodb.AttributeValue, len(param.Ids))
-	for i, id := range param.Ids {
-		keysToRetrieve[i] = map[string]*dynamodb.AttributeValue{"Id": {
-			S: aws.String(id),
-		}}
-	}
-
-	tableName := param.TypeName
-	input := &dynamodb.BatchGetItemInput{
-		RequestItems: map[string]*dynamodb.KeysAndAttributes{
-			tableName: {
-				Keys:                 keysToRetrieve,
-				ProjectionExpression: aws.String("Id"),
-			},
-		},
-	}
-
-	items, err := dbClient.BatchGetItem(input)
+	items, err := getItemsInBatches(ctx, param.TypeName, param.Ids, aws.String("Id"))
 	if err != nil {
 		return err
 	}
 
-	if items.Responses[tableName] != nil && len(items.Responses[tableName]) > 0 {
-		var parsedItem = make([]string, 0, len(items.Responses[tableName]))
-		for _, attr := range items.Responses[tableName] {
-			parsedItem = append(parsedItem, *attr["Id"].S)
-		}
-
-		// if not all Ids were found
-		if len(param.Ids)-len(parsedItem) > 0 {
-			return NotFoundError{TypeName: param.TypeName, Ids: difference(param.Ids, parsedItem)}
-		}
+	var parsedItem = make([]string, 0, len(items))
+	for _, attr := range items {
+		parsedItem = append(parsedItem, *attr["Id"].S)
+	}
 
-		return err
+	// if not all Ids were found
+	if notFound := difference(param.Ids, parsedItem); len(notFound) > 0 {
+		return NotFoundError{TypeName: param.TypeName, Ids: notFound}
 	}
 
-	return NotFoundError{TypeName: param.TypeName, Ids: param.Ids}
+	return nil
 }
 
 func difference(a, b []string) []string {
@@ -0,0 +1,242 @@
+package lib
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/expression"
+)
+
+// The delete policies that can be given in the nubes tags of the relationships,
+// e.g. `nubes:"hasOne-Shop,onDelete=cascade"`. They determine what happens to
+// the related objects when the owner of the ReferenceNavigationList is deleted.
+// Without the policy, only the object itself is deleted.
+const (
+	// CascadeOnDelete deletes the related objects, and the rows of
+	// the join table in case of many-to-many relationships
+	CascadeOnDelete = "cascade"
+	// RestrictOnDelete prevents deleting the object while any objects are related to it
+	RestrictOnDelete = "restrict"
+	// NullifyOnDelete clears the references of the related objects in case of
+	// one-to-many relationships, and deletes the rows of the join table in case
+	// of many-to-many relationships
+	NullifyOnDelete = "nullify"
+)
+
+const onDeleteTagOption = "onDelete"
+
+// navigationList is implemented by ReferenceNavigationList
+type navigationList interface {
+	referencedType() reflect.Type
+}
+
+type deletePolicy struct {
+	fieldName          string
+	policy             string
+	otherType          reflect.Type
+	otherTypeName      string
+	referringFieldName string
+	isManyToMany       bool
+}
+
+// the delete policies of the struct types, parsed once per type
+var deletePoliciesByType sync.Map
+
+func getDeletePolicies(structType reflect.Type) []deletePolicy {
+	if structType.Kind() != reflect.Struct {
+		return nil
+	}
+	if cached, ok := deletePoliciesByType.Load(structType); ok {
+		return cached.([]deletePolicy)
+	}
+
+	var result []deletePolicy
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		list, isNavigationList := reflect.Zero(field.Type).Interface().(navigationList)
+		if !isNavigationList {
+			continue
+		}
+
+		policy, found := parseDeletePolicy(field.Name, field.Tag.Get("nubes"))
+		if !found {
+			continue
+		}
+		policy.otherType = list.referencedType()
+		if nobject, ok := reflect.New(policy.otherType).Interface().(Nobject); ok {
+			policy.otherTypeName = nobject.GetTypeName()
+			result = append(result, policy)
+		}
+	}
+	deletePoliciesByType.Store(structType, result)
+	return result
+}
+
+// parseDeletePolicy returns the delete policy given in the nubes
+// tag of the relationship, e.g. hasMany-Owners,onDelete=nullify
+func parseDeletePolicy(fieldName, tag string) (deletePolicy, bool) {
+	options := strings.Split(tag, ",")
+	policy := deletePolicy{fieldName: fieldName}
+
+	relationship, referringFieldName, _ := strings.Cut(strings.TrimSpace(options[0]), "-")
+	switch relationship {
+	case "hasOne":
+		policy.referringFieldName = referringFieldName
+	case "hasMany":
+		policy.referringFieldName = fieldName
+		policy.isManyToMany = true
+	default:
+		return policy, false
+	}
+
+	for _, option := range options[1:] {
+		name, value, _ := strings.Cut(strings.TrimSpace(option), "=")
+		if name != onDeleteTagOption {
+			continue
+		}
+		switch value {
+		case CascadeOnDelete, RestrictOnDelete, NullifyOnDelete:
+			policy.policy = value
+			return policy, true
+		}
+	}
+	return policy, false
+}
+
+// deleteWithPolicies deletes the object after applying the delete policies of its
+// relationships and releases the unique values of the deleted objects.
+// The writes are issued in a transaction, unless there
+// are more than TransactionWritesLimit of them, then they are issued one by one
+// with the object itself deleted last, so that the delete can be retried.
+func deleteWithPolicies(ctx context.Context, objType reflect.Type, typeName, id string) error {
+	exists, err := IsInstanceAlreadyCreatedWithContext(ctx, IsInstanceAlreadyCreatedParam{Id: id, TypeName: typeName})
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return deleteNotFoundError(typeName, id)
+	}
+
+	if tx := transactionFromContext(ctx); tx != nil {
+		deletion := objectDeletion{tx: tx, deletedObjects: map[string]bool{}, deletedJoinRows: map[string]bool{}}
+		return deletion.delete(ctx, objType, typeName, id)
+	}
+
+	tx := newTransaction(ctx)
+	deletion := objectDeletion{tx: tx, deletedObjects: map[string]bool{}, deletedJoinRows: map[string]bool{}}
+	if err := deletion.delete(tx.Context(), objType, typeName, id); err != nil {
+		return err
+	}
+	if len(tx.items) > TransactionWritesLimit {
+		return tx.commitSequentially(ctx)
+	}
+	return tx.commit(ctx)
+}
+
+// objectDeletion tracks the objects and the rows of join tables already deleted,
+// so that the cycles of the relationships do not delete the same item twice
+type objectDeletion struct {
+	tx              *Tx
+	deletedObjects  map[string]bool
+	deletedJoinRows map[string]bool
+}
+
+func (d objectDeletion) delete(ctx context.Context, objType reflect.Type, typeName, id string) error {
+	if d.deletedObjects[typeName+"/"+id] {
+		return nil
+	}
+	d.deletedObjects[typeName+"/"+id] = true
+
+	for _, policy := range getDeletePolicies(objType) {
+		setup := newReferenceNavigationListSetup(ReferenceNavigationListParam{
+			OwnerId:            id,
+			OwnerTypeName:      typeName,
+			OtherTypeName:      policy.otherTypeName,
+			ReferringFieldName: policy.referringFieldName,
+			IsManyToMany:       policy.isManyToMany,
+		})
+		relatedIds, err := ReferenceNavigationListHandlers{setup: setup}.GetIdsWithContext(ctx)
+		if err != nil {
+			return fmt.Errorf("error occurred while retrieving the objects of field %s of %s with id: %s. Error %w", policy.fieldName, typeName, id, err)
+		}
+		if len(relatedIds) == 0 {
+			continue
+		}
+
+		if policy.policy == RestrictOnDelete {
+			return DeleteRestrictedError{Id: id, TypeName: typeName, FieldName: policy.fieldName}
+		}
+		if policy.isManyToMany {
+			if err = d.deleteJoinRows(ctx, setup, relatedIds); err != nil {
+				return err
+			}
+		}
+
+		for _, relatedId := range relatedIds {
+			if policy.policy == CascadeOnDelete {
+				err = d.delete(ctx, policy.otherType, policy.otherTypeName, relatedId)
+			} else if !policy.isManyToMany {
+				err = nullifyReference(ctx, policy.otherTypeName, relatedId, policy.referringFieldName, id)
+			}
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := releaseUniqueValues(ctx, d.tx, objType, typeName, id); err != nil {
+		return err
+	}
+	return DeleteWithTypeNameAsArgWithContext(ctx, id, typeName)
+}
+
+func (d objectDeletion) deleteJoinRows(ctx context.Context, setup referenceNavigationListSetup, relatedIds []string) error {
+	toDelete := make([]string, 0, len(relatedIds))
+	for _, relatedId := range relatedIds {
+		partitionKey, sortKey := setup.ownerId, relatedId
+		if setup.UsesIndex {
+			partitionKey, sortKey = relatedId, setup.ownerId
+		}
+		if row := setup.TableName + "/" + partitionKey + "/" + sortKey; !d.deletedJoinRows[row] {
+			d.deletedJoinRows[row] = true
+			toDelete = append(toDelete, relatedId)
+		}
+	}
+	if len(toDelete) == 0 {
+		return nil
+	}
+	return DeleteFromManyToManyTableWithContext(ctx, setup.GetDeleteFromManyToManyParam(toDelete))
+}
+
+// nullifyReference removes the attribute of the field referring to the deleted object,
+// provided it was not changed to refer to another object in the meantime
+func nullifyReference(ctx context.Context, typeName, id, referringFieldName, deletedId string) error {
+	update := expression.Remove(expression.Name(referringFieldName))
+	condition := expression.Name(referringFieldName).Equal(expression.Value(deletedId))
+	expr, err := expression.NewBuilder().WithUpdate(update).WithCondition(condition).Build()
+	if err != nil {
+		return fmt.Errorf("error occurred when building dynamodb update expression %w", err)
+	}
+
+	_, err = dbClient(ctx).UpdateItemWithContext(ctx, &dynamodb.UpdateItemInput{
+		TableName: aws.String(getTableName(typeName)),
+		Key: map[string]*dynamodb.AttributeValue{
+			"Id": {
+				S: aws.String(id),
+			},
+		},
+		UpdateExpression:          expr.Update(),
+		ConditionExpression:       expr.Condition(),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+	})
+	return err
+}
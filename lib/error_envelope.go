@@ -0,0 +1,227 @@
+package lib
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"sync"
+)
+
+// ErrorEnvelope is the representation of an error returned by a handler.
+// It carries the name under which the error is registered, so that the
+// client library can rebuild the error of the same type.
+type ErrorEnvelope struct {
+	// Type is the name of the registered error, empty for the other errors
+	Type    string
+	Message string
+	// Details contain the JSON encoded fields of the registered error types
+	Details json.RawMessage `json:",omitempty"`
+}
+
+// registeredError is either a sentinel error or an error type
+type registeredError struct {
+	name      string
+	sentinel  error
+	errorType reflect.Type
+}
+
+var (
+	registeredErrors   []registeredError
+	registeredErrorsMu sync.RWMutex
+)
+
+func init() {
+	RegisterErrorType[NotFoundError]("NotFoundError")
+	RegisterErrorType[AlreadyExistsError]("AlreadyExistsError")
+	RegisterErrorType[ConflictError]("ConflictError")
+	RegisterErrorType[TransactionCancelledError]("TransactionCancelledError")
+	RegisterErrorType[TransactionInProgressError]("TransactionInProgressError")
+	RegisterErrorType[ValidationError]("ValidationError")
+	RegisterErrorType[ReadonlyFieldError]("ReadonlyFieldError")
+	RegisterErrorType[DeleteRestrictedError]("DeleteRestrictedError")
+	RegisterErrorType[ReferenceNotFoundError]("ReferenceNotFoundError")
+	RegisterErrorType[DuplicateValueError]("DuplicateValueError")
+}
+
+// RegisterError registers a sentinel error, e.g. var ErrSoldOut = errors.New("sold out"),
+// so that the error returned to the client library matches it with errors.Is.
+// The errors must be registered under the same names both in the handlers and in the
+// client, e.g. in the init function of the package with the types.
+func RegisterError(name string, sentinel error) {
+	register(registeredError{name: name, sentinel: sentinel})
+}
+
+// RegisterErrorType registers the error type E, so that the error returned to the
+// client library is of type E, with the exported fields encoded in JSON.
+// The types must be registered under the same names both in the handlers and in the client.
+func RegisterErrorType[E error](name string) {
+	register(registeredError{name: name, errorType: reflect.TypeOf((*E)(nil)).Elem()})
+}
+
+func register(e registeredError) {
+	registeredErrorsMu.Lock()
+	defer registeredErrorsMu.Unlock()
+	for i, registered := range registeredErrors {
+		if registered.name == e.name {
+			registeredErrors[i] = e
+			return
+		}
+	}
+	registeredErrors = append(registeredErrors, e)
+}
+
+// NewErrorEnvelope creates the envelope of the error. The first registered
+// error the err matches, with errors.Is or errors.As, determines its Type.
+func NewErrorEnvelope(err error) ErrorEnvelope {
+	envelope := ErrorEnvelope{Message: err.Error()}
+
+	registeredErrorsMu.RLock()
+	defer registeredErrorsMu.RUnlock()
+	for _, registered := range registeredErrors {
+		if registered.sentinel != nil {
+			if errors.Is(err, registered.sentinel) {
+				envelope.Type = registered.name
+				return envelope
+			}
+			continue
+		}
+
+		target := reflect.New(registered.errorType)
+		if errors.As(err, target.Interface()) {
+			details, marshalErr := json.Marshal(target.Elem().Interface())
+			if marshalErr == nil {
+				envelope.Type = registered.name
+				envelope.Details = details
+				return envelope
+			}
+		}
+	}
+	return envelope
+}
+
+// Err rebuilds the error from the envelope. If the message of the registered error
+// differs from the original one, e.g. because it was wrapped, the returned error
+// has the original message and wraps the registered error.
+func (e ErrorEnvelope) Err() error {
+	registeredErrorsMu.RLock()
+	var found *registeredError
+	for i := range registeredErrors {
+		if registeredErrors[i].name == e.Type {
+			found = &registeredErrors[i]
+			break
+		}
+	}
+	registeredErrorsMu.RUnlock()
+
+	if e.Type == "" || found == nil {
+		return errors.New(e.Message)
+	}
+
+	var err error
+	if found.sentinel != nil {
+		err = found.sentinel
+	} else {
+		target := reflect.New(found.errorType)
+		if len(e.Details) > 0 {
+			if unmarshalErr := json.Unmarshal(e.Details, target.Interface()); unmarshalErr != nil {
+				return errors.New(e.Message)
+			}
+		}
+		err, _ = target.Elem().Interface().(error)
+		if err == nil {
+			return errors.New(e.Message)
+		}
+	}
+
+	if err.Error() == e.Message {
+		return err
+	}
+	return remoteError{message: e.Message, err: err}
+}
+
+// remoteError preserves the message of a wrapped registered error
+type remoteError struct {
+	message string
+	err     error
+}
+
+func (r remoteError) Error() string {
+	return r.message
+}
+
+func (r remoteError) Unwrap() error {
+	return r.err
+}
+
+// envelopedError is returned by the handlers, its message is the JSON encoded
+// ErrorEnvelope, which becomes the errorMessage of the lambda function error
+type envelopedError struct {
+	envelope ErrorEnvelope
+}
+
+func (e envelopedError) Error() string {
+	encoded, err := json.Marshal(e.envelope)
+	if err != nil {
+		return e.envelope.Message
+	}
+	return string(encoded)
+}
+
+// EncodeError returns the error whose message is the JSON encoded envelope of the err.
+// It returns nil if err is nil.
+func EncodeError(err error) error {
+	if err == nil {
+		return nil
+	}
+	if _, isEnveloped := err.(envelopedError); isEnveloped {
+		return err
+	}
+	return envelopedError{envelope: NewErrorEnvelope(err)}
+}
+
+var errorInterface = reflect.TypeOf((*error)(nil)).Elem()
+
+// WithErrorEnvelope wraps the handler of a lambda function, so that
+// the error it returns is encoded with EncodeError. The returned function
+// has the signature of the handler, which must return an error as its last result.
+func WithErrorEnvelope(handler interface{}) interface{} {
+	handlerValue := reflect.ValueOf(handler)
+	handlerType := handlerValue.Type()
+	if handlerType.Kind() != reflect.Func || handlerType.NumOut() == 0 || handlerType.Out(handlerType.NumOut()-1) != errorInterface {
+		// the invalid handlers are reported by lambda.Start
+		return handler
+	}
+
+	return reflect.MakeFunc(handlerType, func(args []reflect.Value) []reflect.Value {
+		results := handlerValue.Call(args)
+		last := len(results) - 1
+		if err, _ := results[last].Interface().(error); err != nil {
+			encoded := reflect.New(errorInterface).Elem()
+			encoded.Set(reflect.ValueOf(EncodeError(err)))
+			results[last] = encoded
+		}
+		return results
+	}).Interface()
+}
+
+// functionErrorPayload is the payload of the lambda function error
+type functionErrorPayload struct {
+	ErrorMessage string `json:"errorMessage"`
+	ErrorType    string `json:"errorType"`
+}
+
+// DecodeFunctionError rebuilds the error returned by the handler
+// from the payload of the failed lambda function invocation
+func DecodeFunctionError(payload []byte) error {
+	var functionErr functionErrorPayload
+	if err := json.Unmarshal(payload, &functionErr); err != nil || functionErr.ErrorMessage == "" {
+		return errors.New(string(payload))
+	}
+
+	var envelope ErrorEnvelope
+	if err := json.Unmarshal([]byte(functionErr.ErrorMessage), &envelope); err != nil || envelope.Message == "" {
+		// the error was not returned by the handler, e.g. the function timed out
+		return errors.New(functionErr.ErrorMessage)
+	}
+	return envelope.Err()
+}
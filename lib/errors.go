@@ -2,7 +2,9 @@ package lib
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
+	"strings"
 )
 
 type NotFoundError struct {
@@ -26,3 +28,65 @@ func (m NotFoundError) Error() string {
 
 	return fmt.Sprint(buffer.String())
 }
+
+// AlreadyExistsError is returned when an object is exported
+// with the id of an object that already exists
+type AlreadyExistsError struct {
+	Id       string
+	TypeName string
+}
+
+func (a AlreadyExistsError) Error() string {
+	return fmt.Sprintf("instance of %s with id: %s already exists. Use lib.Load(id) to work on existing instances", a.TypeName, a.Id)
+}
+
+// ConflictError is returned when the state of a versioned Nobject
+// is to be saved, but it was modified in the meantime by another invocation
+type ConflictError struct {
+	Id              string
+	TypeName        string
+	ExpectedVersion int
+}
+
+func (c ConflictError) Error() string {
+	return fmt.Sprintf("Object instance of %s with id: %s was modified concurrently, expected version: %d not found", c.TypeName, c.Id, c.ExpectedVersion)
+}
+
+// IsConflictError returns true if the error, or any error it wraps, is a ConflictError
+func IsConflictError(err error) bool {
+	var conflictErr ConflictError
+	return errors.As(err, &conflictErr)
+}
+
+// TransactionCancelledError is returned by RunInTransaction if the transaction was
+// cancelled, e.g. because a condition of one of the writes was not satisfied.
+// The Reasons contain the reason codes of the writes in the order they were issued,
+// "None" for the writes that did not cause the cancellation.
+type TransactionCancelledError struct {
+	Reasons []string
+}
+
+func (t TransactionCancelledError) Error() string {
+	return "Transaction cancelled, reasons: [" + strings.Join(t.Reasons, ", ") + "]"
+}
+
+// TransactionInProgressError is returned by RunInTransaction and RunInTransactionWithContext
+// if a transaction is already in progress in the context, as the transactions can not be nested
+type TransactionInProgressError struct{}
+
+func (TransactionInProgressError) Error() string {
+	return "Transaction already in progress, transactions can not be nested"
+}
+
+// DeleteRestrictedError is returned when an object is to be deleted, but the field
+// with the restrict delete policy, e.g. `nubes:"hasOne-Shop,onDelete=restrict"`,
+// refers to existing objects
+type DeleteRestrictedError struct {
+	Id        string
+	TypeName  string
+	FieldName string
+}
+
+func (d DeleteRestrictedError) Error() string {
+	return fmt.Sprintf("instance of %s with id: %s can not be deleted, the objects of its field %s refer to it", d.TypeName, d.Id, d.FieldName)
+}
@@ -0,0 +1,61 @@
+package lib
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/expression"
+)
+
+// FindBy returns the objects whose field, tagged with nubes:"index",
+// is equal to the value. The objects are retrieved by the index of
+// the field created by the generator, in no particular order.
+func FindBy[T Nobject](fieldName string, value interface{}) ([]*T, error) {
+	return FindByWithContext[T](InvocationContext(), fieldName, value)
+}
+
+// FindByWithContext is the same as FindBy with the addition of the ability to pass a context
+func FindByWithContext[T Nobject](ctx context.Context, fieldName string, value interface{}) ([]*T, error) {
+	ids, err := FindIdsByWithContext(ctx, FindByParam{TypeName: (*new(T)).GetTypeName(), FieldName: fieldName, Value: value})
+	if err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 {
+		return []*T{}, nil
+	}
+
+	return LoadBatchWithContext[T](ctx, ids)
+}
+
+// FindIdsBy returns the ids of the objects whose field, tagged
+// with nubes:"index", is equal to the value
+func FindIdsBy(param FindByParam) ([]string, error) {
+	return FindIdsByWithContext(InvocationContext(), param)
+}
+
+// FindIdsByWithContext is the same as FindIdsBy with the addition of the ability to pass a context
+func FindIdsByWithContext(ctx context.Context, param FindByParam) ([]string, error) {
+	if err := param.Validate(); err != nil {
+		return nil, err
+	}
+
+	keyCondition := expression.Key(param.FieldName).Equal(expression.Value(param.Value))
+	expr, err := expression.NewBuilder().
+		WithKeyCondition(keyCondition).
+		WithProjection(getProjection([]string{"Id"})).
+		Build()
+	if err != nil {
+		return nil, fmt.Errorf("error occurred when building dynamodb query expression %w", err)
+	}
+
+	return queryAllIds(ctx, &dynamodb.QueryInput{
+		TableName:                 aws.String(getTableName(param.TypeName)),
+		IndexName:                 aws.String(param.TypeName + param.FieldName),
+		ExpressionAttributeNames:  expr.Names(),
+		KeyConditionExpression:    expr.KeyCondition(),
+		ProjectionExpression:      expr.Projection(),
+		ExpressionAttributeValues: expr.Values(),
+	}, "Id")
+}
@@ -0,0 +1,343 @@
+package dynamoexpr
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+// Condition is a parsed condition or key condition expression
+type Condition struct {
+	eval       condition
+	equalities map[string]*dynamodb.AttributeValue
+}
+
+// ParseCondition parses the condition expression. An empty expression
+// results in a condition satisfied by every item.
+func ParseCondition(expr string, names map[string]*string, values Item) (*Condition, error) {
+	if strings.TrimSpace(expr) == "" {
+		return &Condition{eval: func(Item) (bool, error) { return true, nil }}, nil
+	}
+
+	p, err := newParser(expr, names, values)
+	if err != nil {
+		return nil, err
+	}
+	eval, err := p.parseCondition()
+	if err != nil {
+		return nil, err
+	}
+	if err = p.expectEOF(); err != nil {
+		return nil, err
+	}
+
+	condition := &Condition{eval: eval}
+	if !p.topLevelOr {
+		condition.equalities = p.equalities
+	}
+	return condition, nil
+}
+
+// Matches reports whether the item satisfies the condition.
+// A nil item stands for an item that does not exist.
+func (c *Condition) Matches(item Item) (bool, error) {
+	if item == nil {
+		item = Item{}
+	}
+	return c.eval(item)
+}
+
+// EqualityValue returns the value the attribute must be equal to for the
+// condition to be satisfied, e.g. the partition key value of a key condition.
+// Only the conditions joined with AND at the top level are taken into account.
+func (c *Condition) EqualityValue(attribute string) (*dynamodb.AttributeValue, bool) {
+	value, found := c.equalities[attribute]
+	return value, found
+}
+
+type updateAction func(original, updated Item) error
+
+// Update is a parsed update expression
+type Update struct {
+	actions []updateAction
+}
+
+// ParseUpdate parses the update expression consisting of SET, REMOVE, ADD and DELETE clauses
+func ParseUpdate(expr string, names map[string]*string, values Item) (*Update, error) {
+	p, err := newParser(expr, names, values)
+	if err != nil {
+		return nil, err
+	}
+
+	update := &Update{}
+	seenClauses := map[string]bool{}
+	for p.peek().kind != tokenEOF {
+		clause := strings.ToUpper(p.peek().text)
+		if p.peek().kind != tokenIdent || seenClauses[clause] {
+			return nil, p.errorf("expected SET, REMOVE, ADD or DELETE clause")
+		}
+		seenClauses[clause] = true
+		p.next()
+
+		for {
+			var action updateAction
+			switch clause {
+			case "SET":
+				action, err = p.parseSetAction()
+			case "REMOVE":
+				action, err = p.parseRemoveAction()
+			case "ADD":
+				action, err = p.parseAddAction()
+			case "DELETE":
+				action, err = p.parseDeleteAction()
+			default:
+				p.pos--
+				return nil, p.errorf("expected SET, REMOVE, ADD or DELETE clause")
+			}
+			if err != nil {
+				return nil, err
+			}
+			update.actions = append(update.actions, action)
+
+			if !p.accept(tokenSymbol, ",") {
+				break
+			}
+		}
+	}
+
+	if len(update.actions) == 0 {
+		return nil, fmt.Errorf("invalid update expression %q: the expression can not be empty", expr)
+	}
+	return update, nil
+}
+
+// Apply returns a copy of the item with the update applied, the item itself
+// is not modified. All the operands are evaluated against the original item.
+func (u *Update) Apply(item Item) (Item, error) {
+	original := CopyItem(item)
+	if original == nil {
+		original = Item{}
+	}
+	updated := CopyItem(original)
+	for _, action := range u.actions {
+		if err := action(original, updated); err != nil {
+			return nil, err
+		}
+	}
+	return updated, nil
+}
+
+func (p *parser) parseSetAction() (updateAction, error) {
+	target, err := p.parsePath()
+	if err != nil {
+		return nil, err
+	}
+	if err = p.expect(tokenSymbol, "="); err != nil {
+		return nil, err
+	}
+	value, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+
+	if p.peek().is(tokenSymbol, "+") || p.peek().is(tokenSymbol, "-") {
+		subtract := p.next().text == "-"
+		left := value
+		right, err := p.parseOperand()
+		if err != nil {
+			return nil, err
+		}
+		value = func(item Item) (*dynamodb.AttributeValue, error) {
+			a, err := left(item)
+			if err != nil {
+				return nil, err
+			}
+			b, err := right(item)
+			if err != nil {
+				return nil, err
+			}
+			if a == nil || b == nil {
+				return nil, fmt.Errorf("the provided expression refers to an attribute that does not exist in the item")
+			}
+			return addNumbers(a, b, subtract)
+		}
+	}
+
+	return func(original, updated Item) error {
+		newValue, err := value(original)
+		if err != nil {
+			return err
+		}
+		if newValue == nil {
+			return fmt.Errorf("the provided expression refers to an attribute that does not exist in the item")
+		}
+		return target.set(updated, CopyValue(newValue))
+	}, nil
+}
+
+func (p *parser) parseRemoveAction() (updateAction, error) {
+	target, err := p.parsePath()
+	if err != nil {
+		return nil, err
+	}
+	return func(original, updated Item) error {
+		target.remove(updated)
+		return nil
+	}, nil
+}
+
+func (p *parser) parseAddAction() (updateAction, error) {
+	target, value, err := p.parsePathAndValue()
+	if err != nil {
+		return nil, err
+	}
+
+	return func(original, updated Item) error {
+		current := target.get(original)
+		if current == nil {
+			return target.set(updated, CopyValue(value))
+		}
+
+		switch TypeOf(value) {
+		case "N":
+			sum, err := addNumbers(current, value, false)
+			if err != nil {
+				return err
+			}
+			return target.set(updated, sum)
+		case "SS", "NS", "BS":
+			if TypeOf(current) != TypeOf(value) {
+				return fmt.Errorf("an operand in the update expression has an incorrect data type")
+			}
+			union := CopyValue(current)
+			for _, elem := range setElements(value) {
+				if !contains(union, elem) {
+					appendToSet(union, elem)
+				}
+			}
+			return target.set(updated, union)
+		}
+		return fmt.Errorf("an operand in the update expression has an incorrect data type")
+	}, nil
+}
+
+func (p *parser) parseDeleteAction() (updateAction, error) {
+	target, value, err := p.parsePathAndValue()
+	if err != nil {
+		return nil, err
+	}
+
+	return func(original, updated Item) error {
+		current := target.get(original)
+		if current == nil {
+			return nil
+		}
+		if TypeOf(current) != TypeOf(value) {
+			return fmt.Errorf("an operand in the update expression has an incorrect data type")
+		}
+
+		difference := &dynamodb.AttributeValue{}
+		for _, elem := range setElements(current) {
+			if !contains(value, elem) {
+				appendToSet(difference, elem)
+			}
+		}
+		if TypeOf(difference) == "" {
+			// DynamoDB does not store empty sets
+			target.remove(updated)
+			return nil
+		}
+		return target.set(updated, difference)
+	}, nil
+}
+
+func (p *parser) parsePathAndValue() (path, *dynamodb.AttributeValue, error) {
+	target, err := p.parsePath()
+	if err != nil {
+		return nil, nil, err
+	}
+	if p.peek().kind != tokenValue {
+		return nil, nil, p.errorf("expected expression attribute value")
+	}
+	value, err := p.parseValue()
+	if err != nil {
+		return nil, nil, err
+	}
+	return target, value, nil
+}
+
+func setElements(set *dynamodb.AttributeValue) []*dynamodb.AttributeValue {
+	var elements []*dynamodb.AttributeValue
+	for _, s := range set.SS {
+		elements = append(elements, &dynamodb.AttributeValue{S: s})
+	}
+	for _, n := range set.NS {
+		elements = append(elements, &dynamodb.AttributeValue{N: n})
+	}
+	for _, b := range set.BS {
+		elements = append(elements, &dynamodb.AttributeValue{B: b})
+	}
+	return elements
+}
+
+func appendToSet(set, elem *dynamodb.AttributeValue) {
+	switch {
+	case elem.S != nil:
+		set.SS = append(set.SS, stringPtr(*elem.S))
+	case elem.N != nil:
+		set.NS = append(set.NS, stringPtr(*elem.N))
+	case elem.B != nil:
+		set.BS = append(set.BS, append([]byte{}, elem.B...))
+	}
+}
+
+// Projection is a parsed projection expression
+type Projection struct {
+	paths []path
+}
+
+// ParseProjection parses the projection expression. An empty
+// expression results in a projection of all the attributes.
+func ParseProjection(expr string, names map[string]*string) (*Projection, error) {
+	if strings.TrimSpace(expr) == "" {
+		return &Projection{}, nil
+	}
+
+	p, err := newParser(expr, names, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	projection := &Projection{}
+	for {
+		attrPath, err := p.parsePath()
+		if err != nil {
+			return nil, err
+		}
+		projection.paths = append(projection.paths, attrPath)
+		if !p.accept(tokenSymbol, ",") {
+			break
+		}
+	}
+	if err = p.expectEOF(); err != nil {
+		return nil, err
+	}
+	return projection, nil
+}
+
+// Apply returns a copy of the item containing only the projected attributes
+func (p *Projection) Apply(item Item) Item {
+	if item == nil {
+		return nil
+	}
+	if len(p.paths) == 0 {
+		return CopyItem(item)
+	}
+
+	result := Item{}
+	for _, attrPath := range p.paths {
+		attrPath.copyInto(result, item)
+	}
+	return result
+}
@@ -0,0 +1,322 @@
+package dynamoexpr
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+func s(value string) *dynamodb.AttributeValue {
+	return &dynamodb.AttributeValue{S: aws.String(value)}
+}
+
+func n(value string) *dynamodb.AttributeValue {
+	return &dynamodb.AttributeValue{N: aws.String(value)}
+}
+
+func testItem() Item {
+	return Item{
+		"Id":    s("product-1"),
+		"Name":  s("Green tea"),
+		"Price": n("12.5"),
+		"Count": n("3"),
+		"Tags":  {SS: aws.StringSlice([]string{"tea", "green"})},
+		"Sizes": {L: []*dynamodb.AttributeValue{s("S"), s("M")}},
+		"Shop":  {M: Item{"City": s("Warsaw")}},
+		"Gone":  {NULL: aws.Bool(true)},
+	}
+}
+
+var testNames = map[string]*string{"#name": aws.String("Name"), "#city": aws.String("City"), "#shop": aws.String("Shop")}
+
+var testValues = Item{
+	":id":    s("product-1"),
+	":other": s("product-2"),
+	":tea":   s("Green tea"),
+	":green": s("Green"),
+	":low":   n("10"),
+	":high":  n("12.50"),
+	":one":   n("1"),
+	":two":   n("2"),
+	":tag":   s("tea"),
+	":city":  s("Warsaw"),
+	":type":  s("NULL"),
+	":tags":  {SS: aws.StringSlice([]string{"green", "black"})},
+	":sizes": {L: []*dynamodb.AttributeValue{s("L")}},
+}
+
+func TestConditionMatches(t *testing.T) {
+	cases := []struct {
+		expr     string
+		expected bool
+	}{
+		{"", true},
+		{"Id = :id", true},
+		{"Id <> :id", false},
+		{"#name = :tea AND Price BETWEEN :low AND :high", true},
+		{"Price BETWEEN :low AND :one", false},
+		{"Id = :other OR #name = :tea", true},
+		{"Id = :other OR Id = :other AND #name = :tea", false},
+		{"(Id = :other OR Id = :id) AND NOT Count < :two", true},
+		{"NOT (Id = :id AND Count > :two)", false},
+		{"NOT NOT Id = :id", true},
+		{"Id IN (:other, :id)", true},
+		{"Id IN (:other)", false},
+		{"Price > :low AND Price <= :high AND Count >= :two", true},
+		{"Price < Count", false},
+		{"begins_with(#name, :green)", true},
+		{"begins_with(Id, :green)", false},
+		{"contains(Tags, :tag) AND contains(#name, :tag)", true},
+		{"contains(Missing, :tag)", false},
+		{"size(Tags) = :two AND size(Sizes) > :one AND size(#shop) = :one", true},
+		{"size(Price) = :one", false},
+		{"attribute_exists(Id) AND attribute_not_exists(Missing)", true},
+		{"attribute_exists(Missing)", false},
+		{"attribute_type(Gone, :type)", true},
+		{"#shop.#city = :city AND Sizes[1] = :two", false},
+		{"#shop.#city = :city AND attribute_exists(Sizes[1])", true},
+		{"attribute_exists(Sizes[2])", false},
+		{"Missing = :id", false},
+		{"Missing <> :id", true},
+		{"Missing < :id", false},
+	}
+
+	for _, c := range cases {
+		// Arrange
+		condition, err := ParseCondition(c.expr, testNames, testValues)
+		if err != nil {
+			t.Errorf("%q: unexpected error %v", c.expr, err)
+			continue
+		}
+
+		// Act
+		matches, err := condition.Matches(testItem())
+
+		// Assert
+		if err != nil || matches != c.expected {
+			t.Errorf("%q: expected %v, got %v, error %v", c.expr, c.expected, matches, err)
+		}
+	}
+}
+
+func TestConditionOfMissingItemIsEvaluatedAgainstNoAttributes(t *testing.T) {
+	// Arrange
+	exists, _ := ParseCondition("attribute_exists(Id)", nil, nil)
+	notExists, _ := ParseCondition("attribute_not_exists(Id)", nil, nil)
+
+	// Act
+	existsMatches, existsErr := exists.Matches(nil)
+	notExistsMatches, notExistsErr := notExists.Matches(nil)
+
+	// Assert
+	if existsMatches || existsErr != nil || !notExistsMatches || notExistsErr != nil {
+		t.Errorf("expected only attribute_not_exists to match the missing item")
+	}
+}
+
+func TestParseConditionRejectsMalformedExpressions(t *testing.T) {
+	cases := []string{
+		"Id =",
+		"Id = :missing",
+		"#missing = :id",
+		"Id == :id",
+		"Id = :id AND",
+		"(Id = :id",
+		"Id = :id)",
+		"Id BETWEEN :low",
+		"Id IN :id",
+		"Id IN (:id",
+		"unknown_function(Id)",
+		"begins_with(Id :id)",
+		"Sizes[x] = :id",
+		"Id = :id Id",
+		"Id = 'product-1'",
+	}
+
+	for _, expr := range cases {
+		// Act
+		_, err := ParseCondition(expr, testNames, testValues)
+
+		// Assert
+		if err == nil {
+			t.Errorf("%q: expected an error", expr)
+		}
+	}
+}
+
+func TestEqualityValueIsRecordedForTopLevelConjunctions(t *testing.T) {
+	cases := []struct {
+		expr     string
+		found    bool
+		expected *dynamodb.AttributeValue
+	}{
+		{"Id = :id", true, s("product-1")},
+		{"Id = :id AND Price BETWEEN :low AND :high", true, s("product-1")},
+		{"(Id = :id) AND (Price >= :low)", true, s("product-1")},
+		{"Price >= :low AND #name = :tea", false, nil},
+		{"Id = :id OR Id = :other", false, nil},
+		{"NOT Id = :id", false, nil},
+		{"(Id = :id OR Id = :other) AND Price >= :low", false, nil},
+	}
+
+	for _, c := range cases {
+		// Arrange
+		condition, err := ParseCondition(c.expr, testNames, testValues)
+		if err != nil {
+			t.Fatalf("%q: unexpected error %v", c.expr, err)
+		}
+
+		// Act
+		value, found := condition.EqualityValue("Id")
+
+		// Assert
+		if found != c.found || !reflect.DeepEqual(value, c.expected) {
+			t.Errorf("%q: expected %v %v, got %v %v", c.expr, c.found, c.expected, found, value)
+		}
+	}
+}
+
+func TestUpdateApply(t *testing.T) {
+	cases := []struct {
+		expr     string
+		expected Item
+	}{
+		{"SET #name = :green", Item{"Name": s("Green")}},
+		{"SET Count = Count + :two, Price = Price - :low", Item{"Count": n("5"), "Price": n("2.5")}},
+		{"SET Price = :one, Count = Price", Item{"Price": n("1"), "Count": n("12.5")}},
+		{"SET Added = if_not_exists(Added, :one), Count = if_not_exists(Count, :one)", Item{"Added": n("1")}},
+		{"SET Sizes = list_append(Sizes, :sizes)", Item{"Sizes": {L: []*dynamodb.AttributeValue{s("S"), s("M"), s("L")}}}},
+		{"SET #shop.#city = :green, Sizes[0] = :tag", Item{
+			"Shop":  {M: Item{"City": s("Green")}},
+			"Sizes": {L: []*dynamodb.AttributeValue{s("tea"), s("M")}},
+		}},
+		{"REMOVE Gone, #shop.#city", Item{"Gone": nil, "Shop": {M: Item{}}}},
+		{"REMOVE Sizes[0]", Item{"Sizes": {L: []*dynamodb.AttributeValue{s("M")}}}},
+		{"ADD Count :two, Visits :one", Item{"Count": n("5"), "Visits": n("1")}},
+		{"ADD Tags :tags", Item{"Tags": {SS: aws.StringSlice([]string{"tea", "green", "black"})}}},
+		{"DELETE Tags :tags", Item{"Tags": {SS: aws.StringSlice([]string{"tea"})}}},
+		{"SET Count = :one REMOVE Gone ADD Visits :two", Item{"Count": n("1"), "Gone": nil, "Visits": n("2")}},
+	}
+
+	for _, c := range cases {
+		// Arrange
+		update, err := ParseUpdate(c.expr, testNames, testValues)
+		if err != nil {
+			t.Errorf("%q: unexpected error %v", c.expr, err)
+			continue
+		}
+		item := testItem()
+		expected := testItem()
+		for name, value := range c.expected {
+			if value == nil {
+				delete(expected, name)
+			} else {
+				expected[name] = value
+			}
+		}
+
+		// Act
+		updated, err := update.Apply(item)
+
+		// Assert
+		if err != nil {
+			t.Errorf("%q: unexpected error %v", c.expr, err)
+			continue
+		}
+		if !reflect.DeepEqual(updated, expected) {
+			t.Errorf("%q: expected %v, got %v", c.expr, expected, updated)
+		}
+		if !reflect.DeepEqual(item, testItem()) {
+			t.Errorf("%q: the original item was modified", c.expr)
+		}
+	}
+}
+
+func TestUpdateApplyFailsOnIncorrectOperands(t *testing.T) {
+	cases := []string{
+		"SET Count = Missing + :one",
+		"SET Count = Missing",
+		"SET Sizes = list_append(Sizes, :one)",
+		"ADD #name :one",
+		"ADD Tags :one",
+		"DELETE Tags :one",
+	}
+
+	for _, expr := range cases {
+		// Arrange
+		update, err := ParseUpdate(expr, testNames, testValues)
+		if err != nil {
+			t.Errorf("%q: unexpected error %v", expr, err)
+			continue
+		}
+
+		// Act
+		_, err = update.Apply(testItem())
+
+		// Assert
+		if err == nil {
+			t.Errorf("%q: expected an error", expr)
+		}
+	}
+}
+
+func TestParseUpdateRejectsMalformedExpressions(t *testing.T) {
+	cases := []string{
+		"",
+		"Count = :one",
+		"SET",
+		"SET Count :one",
+		"SET Count = :one SET Price = :one",
+		"UPSERT Count = :one",
+		"ADD Count",
+		"ADD Count Price",
+		"REMOVE :one",
+		"SET Count = unknown_function(Count)",
+	}
+
+	for _, expr := range cases {
+		// Act
+		_, err := ParseUpdate(expr, testNames, testValues)
+
+		// Assert
+		if err == nil {
+			t.Errorf("%q: expected an error", expr)
+		}
+	}
+}
+
+func TestProjectionApply(t *testing.T) {
+	cases := []struct {
+		expr     string
+		expected Item
+	}{
+		{"", testItem()},
+		{"Id, #name", Item{"Id": s("product-1"), "Name": s("Green tea")}},
+		{"#shop.#city, Missing", Item{"Shop": {M: Item{"City": s("Warsaw")}}}},
+	}
+
+	for _, c := range cases {
+		// Arrange
+		projection, err := ParseProjection(c.expr, testNames)
+		if err != nil {
+			t.Errorf("%q: unexpected error %v", c.expr, err)
+			continue
+		}
+
+		// Act
+		projected := projection.Apply(testItem())
+
+		// Assert
+		if !reflect.DeepEqual(projected, c.expected) {
+			t.Errorf("%q: expected %v, got %v", c.expr, c.expected, projected)
+		}
+	}
+
+	for _, expr := range []string{"Id,", "#missing", "Id Name"} {
+		if _, err := ParseProjection(expr, testNames); err == nil {
+			t.Errorf("%q: expected an error", expr)
+		}
+	}
+}
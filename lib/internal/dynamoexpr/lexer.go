@@ -0,0 +1,91 @@
+// Package dynamoexpr parses and evaluates the subset of DynamoDB expressions
+// (condition, key condition, update and projection expressions) used by the
+// stores alternative to DynamoDB.
+package dynamoexpr
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenIdent
+	tokenName
+	tokenValue
+	tokenNumber
+	tokenSymbol
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+func (t token) is(kind tokenKind, text string) bool {
+	return t.kind == kind && strings.EqualFold(t.text, text)
+}
+
+func (t token) isKeyword(keyword string) bool {
+	return t.is(tokenIdent, keyword)
+}
+
+func tokenize(expr string) ([]token, error) {
+	var tokens []token
+	runes := []rune(expr)
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '#' || r == ':':
+			start := i
+			i++
+			for i < len(runes) && isIdentRune(runes[i]) {
+				i++
+			}
+			if i == start+1 {
+				return nil, fmt.Errorf("invalid expression %q: missing placeholder name at position %d", expr, start)
+			}
+			kind := tokenName
+			if r == ':' {
+				kind = tokenValue
+			}
+			tokens = append(tokens, token{kind: kind, text: string(runes[start:i]), pos: start})
+		case unicode.IsDigit(r):
+			start := i
+			for i < len(runes) && unicode.IsDigit(runes[i]) {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenNumber, text: string(runes[start:i]), pos: start})
+		case isIdentRune(r):
+			start := i
+			for i < len(runes) && isIdentRune(runes[i]) {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenIdent, text: string(runes[start:i]), pos: start})
+		case r == '<' && i+1 < len(runes) && (runes[i+1] == '=' || runes[i+1] == '>'):
+			tokens = append(tokens, token{kind: tokenSymbol, text: string(runes[i : i+2]), pos: i})
+			i += 2
+		case r == '>' && i+1 < len(runes) && runes[i+1] == '=':
+			tokens = append(tokens, token{kind: tokenSymbol, text: ">=", pos: i})
+			i += 2
+		case strings.ContainsRune("()[],.=<>+-", r):
+			tokens = append(tokens, token{kind: tokenSymbol, text: string(r), pos: i})
+			i++
+		default:
+			return nil, fmt.Errorf("invalid expression %q: unexpected character %q at position %d", expr, r, i)
+		}
+	}
+
+	return append(tokens, token{kind: tokenEOF, pos: len(runes)}), nil
+}
+
+func isIdentRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
@@ -0,0 +1,573 @@
+package dynamoexpr
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+// operand evaluates to a value of the item, nil means that the value does not exist
+type operand func(item Item) (*dynamodb.AttributeValue, error)
+
+// condition evaluates to true if the item satisfies the condition
+type condition func(item Item) (bool, error)
+
+type parser struct {
+	expr   string
+	tokens []token
+	pos    int
+	names  map[string]*string
+	values Item
+
+	// depth is the nesting level of the currently parsed condition,
+	// equalities are the attribute = :value conditions at the top level
+	depth      int
+	topLevelOr bool
+	equalities map[string]*dynamodb.AttributeValue
+}
+
+func newParser(expr string, names map[string]*string, values Item) (*parser, error) {
+	tokens, err := tokenize(expr)
+	if err != nil {
+		return nil, err
+	}
+	return &parser{expr: expr, tokens: tokens, names: names, values: values, equalities: map[string]*dynamodb.AttributeValue{}}, nil
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokenEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *parser) accept(kind tokenKind, text string) bool {
+	if p.peek().is(kind, text) {
+		p.next()
+		return true
+	}
+	return false
+}
+
+func (p *parser) expect(kind tokenKind, text string) error {
+	if !p.accept(kind, text) {
+		return p.errorf("expected %q", text)
+	}
+	return nil
+}
+
+func (p *parser) expectEOF() error {
+	if p.peek().kind != tokenEOF {
+		return p.errorf("unexpected token")
+	}
+	return nil
+}
+
+func (p *parser) errorf(format string, args ...interface{}) error {
+	t := p.peek()
+	near := t.text
+	if t.kind == tokenEOF {
+		near = "<EOF>"
+	}
+	return fmt.Errorf("invalid expression %q: %s near %q at position %d", p.expr, fmt.Sprintf(format, args...), near, t.pos)
+}
+
+func (p *parser) parsePath() (path, error) {
+	var result path
+	for {
+		t := p.next()
+		switch t.kind {
+		case tokenIdent:
+			result = append(result, pathElement{name: t.text})
+		case tokenName:
+			name, ok := p.names[t.text]
+			if !ok || name == nil {
+				return nil, fmt.Errorf("invalid expression %q: an expression attribute name used in the document path is not defined: %s", p.expr, t.text)
+			}
+			result = append(result, pathElement{name: *name})
+		default:
+			p.pos--
+			return nil, p.errorf("expected attribute name")
+		}
+
+		for p.accept(tokenSymbol, "[") {
+			indexToken := p.next()
+			if indexToken.kind != tokenNumber {
+				p.pos--
+				return nil, p.errorf("expected list index")
+			}
+			index, err := strconv.Atoi(indexToken.text)
+			if err != nil {
+				return nil, p.errorf("invalid list index")
+			}
+			result = append(result, pathElement{index: index, isIndex: true})
+			if err := p.expect(tokenSymbol, "]"); err != nil {
+				return nil, err
+			}
+		}
+
+		if !p.accept(tokenSymbol, ".") {
+			return result, nil
+		}
+	}
+}
+
+func (p *parser) parseValue() (*dynamodb.AttributeValue, error) {
+	t := p.next()
+	value, ok := p.values[t.text]
+	if !ok || value == nil {
+		return nil, fmt.Errorf("invalid expression %q: an expression attribute value used in expression is not defined: %s", p.expr, t.text)
+	}
+	return value, nil
+}
+
+// parseOperand parses a path, a value placeholder or a function returning a value
+func (p *parser) parseOperand() (operand, error) {
+	t := p.peek()
+	switch {
+	case t.kind == tokenValue:
+		value, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		return func(Item) (*dynamodb.AttributeValue, error) { return value, nil }, nil
+
+	case t.kind == tokenIdent && p.tokens[p.pos+1].is(tokenSymbol, "("):
+		return p.parseOperandFunction()
+
+	case t.kind == tokenIdent || t.kind == tokenName:
+		attrPath, err := p.parsePath()
+		if err != nil {
+			return nil, err
+		}
+		return func(item Item) (*dynamodb.AttributeValue, error) { return attrPath.get(item), nil }, nil
+	}
+	return nil, p.errorf("expected operand")
+}
+
+func (p *parser) parseOperandFunction() (operand, error) {
+	name := strings.ToLower(p.next().text)
+	p.next() // (
+
+	var result operand
+	switch name {
+	case "size":
+		attrPath, err := p.parsePath()
+		if err != nil {
+			return nil, err
+		}
+		result = func(item Item) (*dynamodb.AttributeValue, error) {
+			value := attrPath.get(item)
+			size, ok := sizeOf(value)
+			if !ok {
+				return nil, nil
+			}
+			return &dynamodb.AttributeValue{N: stringPtr(strconv.Itoa(size))}, nil
+		}
+
+	case "if_not_exists":
+		attrPath, err := p.parsePath()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(tokenSymbol, ","); err != nil {
+			return nil, err
+		}
+		fallback, err := p.parseOperand()
+		if err != nil {
+			return nil, err
+		}
+		result = func(item Item) (*dynamodb.AttributeValue, error) {
+			if value := attrPath.get(item); value != nil {
+				return value, nil
+			}
+			return fallback(item)
+		}
+
+	case "list_append":
+		first, err := p.parseOperand()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(tokenSymbol, ","); err != nil {
+			return nil, err
+		}
+		second, err := p.parseOperand()
+		if err != nil {
+			return nil, err
+		}
+		result = func(item Item) (*dynamodb.AttributeValue, error) {
+			a, err := first(item)
+			if err != nil {
+				return nil, err
+			}
+			b, err := second(item)
+			if err != nil {
+				return nil, err
+			}
+			if TypeOf(a) != "L" || TypeOf(b) != "L" {
+				return nil, fmt.Errorf("an operand in the update expression has an incorrect data type")
+			}
+			appended := append(append([]*dynamodb.AttributeValue{}, a.L...), b.L...)
+			return &dynamodb.AttributeValue{L: appended}, nil
+		}
+
+	default:
+		p.pos--
+		return nil, p.errorf("invalid function name %s", name)
+	}
+
+	if err := p.expect(tokenSymbol, ")"); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// parseCondition parses: condition OR condition
+func (p *parser) parseCondition() (condition, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept(tokenIdent, "OR") {
+		if p.depth == 0 {
+			p.topLevelOr = true
+		}
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = or(left, right)
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd() (condition, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept(tokenIdent, "AND") {
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		left = and(left, right)
+	}
+	return left, nil
+}
+
+func (p *parser) parseNot() (condition, error) {
+	if p.accept(tokenIdent, "NOT") {
+		p.depth++
+		inner, err := p.parseNot()
+		p.depth--
+		if err != nil {
+			return nil, err
+		}
+		return func(item Item) (bool, error) {
+			result, err := inner(item)
+			return !result, err
+		}, nil
+	}
+	return p.parsePrimaryCondition()
+}
+
+func (p *parser) parsePrimaryCondition() (condition, error) {
+	if p.accept(tokenSymbol, "(") {
+		// the equalities of a parenthesized condition hold at the top level,
+		// e.g. in (#0 = :0) AND (#1 >= :1), unless it contains OR
+		equalities, topLevelOr := p.copyEqualities(), p.topLevelOr
+		p.topLevelOr = false
+		inner, err := p.parseCondition()
+		if p.topLevelOr {
+			p.equalities = equalities
+		}
+		p.topLevelOr = topLevelOr
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(tokenSymbol, ")"); err != nil {
+			return nil, err
+		}
+		return inner, nil
+	}
+
+	t := p.peek()
+	if t.kind == tokenIdent && p.tokens[p.pos+1].is(tokenSymbol, "(") {
+		switch strings.ToLower(t.text) {
+		case "attribute_exists", "attribute_not_exists", "attribute_type", "begins_with", "contains":
+			return p.parseConditionFunction()
+		}
+	}
+
+	if p.depth == 0 {
+		p.recordEquality()
+	}
+	left, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+
+	if p.accept(tokenIdent, "BETWEEN") {
+		lower, err := p.parseOperand()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(tokenIdent, "AND"); err != nil {
+			return nil, err
+		}
+		upper, err := p.parseOperand()
+		if err != nil {
+			return nil, err
+		}
+		return and(comparison(">=", left, lower), comparison("<=", left, upper)), nil
+	}
+
+	if p.accept(tokenIdent, "IN") {
+		if err := p.expect(tokenSymbol, "("); err != nil {
+			return nil, err
+		}
+		var alternatives []condition
+		for {
+			candidate, err := p.parseOperand()
+			if err != nil {
+				return nil, err
+			}
+			alternatives = append(alternatives, comparison("=", left, candidate))
+			if !p.accept(tokenSymbol, ",") {
+				break
+			}
+		}
+		if err := p.expect(tokenSymbol, ")"); err != nil {
+			return nil, err
+		}
+		return func(item Item) (bool, error) {
+			for _, alternative := range alternatives {
+				if ok, err := alternative(item); ok || err != nil {
+					return ok, err
+				}
+			}
+			return false, nil
+		}, nil
+	}
+
+	comparator := p.peek()
+	if comparator.kind != tokenSymbol || !isComparator(comparator.text) {
+		return nil, p.errorf("expected comparator")
+	}
+	p.next()
+	right, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	return comparison(comparator.text, left, right), nil
+}
+
+// recordEquality records the condition starting at the current
+// token if it is a comparison of a top level attribute with a value
+func (p *parser) recordEquality() {
+	if p.pos+3 >= len(p.tokens) || !p.tokens[p.pos+1].is(tokenSymbol, "=") || p.tokens[p.pos+2].kind != tokenValue {
+		return
+	}
+	if following := p.tokens[p.pos+3]; following.kind != tokenEOF && !following.isKeyword("AND") &&
+		!following.isKeyword("OR") && !following.is(tokenSymbol, ")") {
+		return
+	}
+
+	var name string
+	switch t := p.tokens[p.pos]; t.kind {
+	case tokenIdent:
+		name = t.text
+	case tokenName:
+		if p.names[t.text] == nil {
+			return
+		}
+		name = *p.names[t.text]
+	default:
+		return
+	}
+	if value := p.values[p.tokens[p.pos+2].text]; value != nil {
+		p.equalities[name] = value
+	}
+}
+
+func (p *parser) copyEqualities() map[string]*dynamodb.AttributeValue {
+	equalities := make(map[string]*dynamodb.AttributeValue, len(p.equalities))
+	for name, value := range p.equalities {
+		equalities[name] = value
+	}
+	return equalities
+}
+
+func isComparator(symbol string) bool {
+	switch symbol {
+	case "=", "<>", "<", "<=", ">", ">=":
+		return true
+	}
+	return false
+}
+
+func (p *parser) parseConditionFunction() (condition, error) {
+	name := strings.ToLower(p.next().text)
+	p.next() // (
+
+	attrPath, err := p.parsePath()
+	if err != nil {
+		return nil, err
+	}
+
+	var result condition
+	switch name {
+	case "attribute_exists", "attribute_not_exists":
+		expected := name == "attribute_exists"
+		result = func(item Item) (bool, error) {
+			return (attrPath.get(item) != nil) == expected, nil
+		}
+
+	case "attribute_type", "begins_with", "contains":
+		if err := p.expect(tokenSymbol, ","); err != nil {
+			return nil, err
+		}
+		argument, err := p.parseOperand()
+		if err != nil {
+			return nil, err
+		}
+		result = func(item Item) (bool, error) {
+			value := attrPath.get(item)
+			arg, err := argument(item)
+			if err != nil || value == nil || arg == nil {
+				return false, err
+			}
+			switch name {
+			case "attribute_type":
+				return arg.S != nil && TypeOf(value) == *arg.S, nil
+			case "begins_with":
+				return beginsWith(value, arg), nil
+			default:
+				return contains(value, arg), nil
+			}
+		}
+	}
+
+	if err := p.expect(tokenSymbol, ")"); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func and(left, right condition) condition {
+	return func(item Item) (bool, error) {
+		ok, err := left(item)
+		if !ok || err != nil {
+			return false, err
+		}
+		return right(item)
+	}
+}
+
+func or(left, right condition) condition {
+	return func(item Item) (bool, error) {
+		ok, err := left(item)
+		if ok || err != nil {
+			return ok, err
+		}
+		return right(item)
+	}
+}
+
+func comparison(comparator string, left, right operand) condition {
+	return func(item Item) (bool, error) {
+		a, err := left(item)
+		if err != nil {
+			return false, err
+		}
+		b, err := right(item)
+		if err != nil {
+			return false, err
+		}
+
+		switch comparator {
+		case "=":
+			return Equal(a, b), nil
+		case "<>":
+			return !Equal(a, b), nil
+		}
+
+		cmp, ok := Compare(a, b)
+		if !ok {
+			return false, nil
+		}
+		switch comparator {
+		case "<":
+			return cmp < 0, nil
+		case "<=":
+			return cmp <= 0, nil
+		case ">":
+			return cmp > 0, nil
+		default:
+			return cmp >= 0, nil
+		}
+	}
+}
+
+func beginsWith(value, prefix *dynamodb.AttributeValue) bool {
+	switch {
+	case value.S != nil && prefix.S != nil:
+		return strings.HasPrefix(*value.S, *prefix.S)
+	case value.B != nil && prefix.B != nil:
+		return strings.HasPrefix(string(value.B), string(prefix.B))
+	}
+	return false
+}
+
+func contains(value, elem *dynamodb.AttributeValue) bool {
+	switch TypeOf(value) {
+	case "S":
+		return elem.S != nil && strings.Contains(*value.S, *elem.S)
+	case "B":
+		return elem.B != nil && strings.Contains(string(value.B), string(elem.B))
+	case "SS":
+		return elem.S != nil && containsElem(value.SS, elem.S, func(x, y *string) bool { return *x == *y })
+	case "NS":
+		return elem.N != nil && containsElem(value.NS, elem.N, func(x, y *string) bool {
+			return Equal(&dynamodb.AttributeValue{N: x}, &dynamodb.AttributeValue{N: y})
+		})
+	case "BS":
+		return elem.B != nil && containsElem(value.BS, elem.B, func(x, y []byte) bool { return string(x) == string(y) })
+	case "L":
+		return containsElem(value.L, elem, Equal)
+	}
+	return false
+}
+
+func sizeOf(value *dynamodb.AttributeValue) (int, bool) {
+	switch TypeOf(value) {
+	case "S":
+		return len(*value.S), true
+	case "B":
+		return len(value.B), true
+	case "SS":
+		return len(value.SS), true
+	case "NS":
+		return len(value.NS), true
+	case "BS":
+		return len(value.BS), true
+	case "L":
+		return len(value.L), true
+	case "M":
+		return len(value.M), true
+	}
+	return 0, false
+}
+
+func stringPtr(s string) *string {
+	return &s
+}
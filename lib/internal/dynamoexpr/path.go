@@ -0,0 +1,136 @@
+package dynamoexpr
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+// pathElement is either a map key or a list index
+type pathElement struct {
+	name    string
+	index   int
+	isIndex bool
+}
+
+type path []pathElement
+
+func (p path) String() string {
+	var sb strings.Builder
+	for i, elem := range p {
+		if elem.isIndex {
+			fmt.Fprintf(&sb, "[%d]", elem.index)
+			continue
+		}
+		if i > 0 {
+			sb.WriteByte('.')
+		}
+		sb.WriteString(elem.name)
+	}
+	return sb.String()
+}
+
+// get returns the value under the path or nil if it does not exist
+func (p path) get(item Item) *dynamodb.AttributeValue {
+	current := &dynamodb.AttributeValue{M: item}
+	for _, elem := range p {
+		if elem.isIndex {
+			if current.L == nil || elem.index >= len(current.L) {
+				return nil
+			}
+			current = current.L[elem.index]
+		} else {
+			if current.M == nil {
+				return nil
+			}
+			current = current.M[elem.name]
+		}
+		if current == nil {
+			return nil
+		}
+	}
+	return current
+}
+
+// set stores the value under the path. All the path elements
+// but the last one must exist, as it is the case in DynamoDB.
+func (p path) set(item Item, value *dynamodb.AttributeValue) error {
+	parent := p[:len(p)-1].get(item)
+	if parent == nil {
+		return fmt.Errorf("the document path provided in the update expression is invalid for update: %s", p)
+	}
+
+	last := p[len(p)-1]
+	if last.isIndex {
+		if parent.L == nil {
+			return fmt.Errorf("the document path provided in the update expression is invalid for update: %s", p)
+		}
+		if last.index >= len(parent.L) {
+			parent.L = append(parent.L, value)
+		} else {
+			parent.L[last.index] = value
+		}
+		return nil
+	}
+
+	if parent.M == nil {
+		return fmt.Errorf("the document path provided in the update expression is invalid for update: %s", p)
+	}
+	parent.M[last.name] = value
+	return nil
+}
+
+// remove deletes the value under the path, removing a non-existent value is a no-op
+func (p path) remove(item Item) {
+	parent := p[:len(p)-1].get(item)
+	if parent == nil {
+		return
+	}
+
+	last := p[len(p)-1]
+	if last.isIndex {
+		if parent.L != nil && last.index < len(parent.L) {
+			parent.L = append(parent.L[:last.index], parent.L[last.index+1:]...)
+		}
+		return
+	}
+	if parent.M != nil {
+		delete(parent.M, last.name)
+	}
+}
+
+// copyInto copies the value under the path from the source item
+// into the destination item creating the intermediate documents
+func (p path) copyInto(dst, src Item) {
+	value := p.get(src)
+	if value == nil {
+		return
+	}
+
+	current := &dynamodb.AttributeValue{M: dst}
+	for i, elem := range p {
+		isLast := i == len(p)-1
+		var next *dynamodb.AttributeValue
+		if isLast {
+			next = CopyValue(value)
+		} else if p[i+1].isIndex {
+			next = &dynamodb.AttributeValue{L: []*dynamodb.AttributeValue{}}
+		} else {
+			next = &dynamodb.AttributeValue{M: Item{}}
+		}
+
+		if elem.isIndex {
+			// projected list elements are compacted, like in DynamoDB
+			current.L = append(current.L, next)
+			current = next
+			continue
+		}
+		if existing, ok := current.M[elem.name]; ok && !isLast {
+			current = existing
+			continue
+		}
+		current.M[elem.name] = next
+		current = next
+	}
+}
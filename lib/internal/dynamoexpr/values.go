@@ -0,0 +1,244 @@
+package dynamoexpr
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+// Item is a single item as represented by the DynamoDB API
+type Item = map[string]*dynamodb.AttributeValue
+
+// TypeOf returns the DynamoDB data type descriptor of the value, e.g. S, N, M
+func TypeOf(v *dynamodb.AttributeValue) string {
+	switch {
+	case v == nil:
+		return ""
+	case v.S != nil:
+		return "S"
+	case v.N != nil:
+		return "N"
+	case v.B != nil:
+		return "B"
+	case v.BOOL != nil:
+		return "BOOL"
+	case v.NULL != nil:
+		return "NULL"
+	case v.SS != nil:
+		return "SS"
+	case v.NS != nil:
+		return "NS"
+	case v.BS != nil:
+		return "BS"
+	case v.L != nil:
+		return "L"
+	case v.M != nil:
+		return "M"
+	}
+	return ""
+}
+
+// Compare compares two scalar values of the same type (S, N or B).
+// The second return value is false if the values are not comparable.
+func Compare(a, b *dynamodb.AttributeValue) (int, bool) {
+	typeA, typeB := TypeOf(a), TypeOf(b)
+	if typeA != typeB {
+		return 0, false
+	}
+
+	switch typeA {
+	case "S":
+		return strings.Compare(*a.S, *b.S), true
+	case "N":
+		numA, okA := parseNumber(*a.N)
+		numB, okB := parseNumber(*b.N)
+		if !okA || !okB {
+			return 0, false
+		}
+		return numA.Cmp(numB), true
+	case "B":
+		return bytes.Compare(a.B, b.B), true
+	}
+	return 0, false
+}
+
+// Equal reports whether the two values are equal according to DynamoDB rules,
+// i.e. the sets are compared regardless of their elements order
+func Equal(a, b *dynamodb.AttributeValue) bool {
+	typeA, typeB := TypeOf(a), TypeOf(b)
+	if typeA != typeB || typeA == "" {
+		return false
+	}
+
+	switch typeA {
+	case "S", "N", "B":
+		cmp, ok := Compare(a, b)
+		return ok && cmp == 0
+	case "BOOL":
+		return *a.BOOL == *b.BOOL
+	case "NULL":
+		return true
+	case "SS":
+		return equalSets(aws.StringValueSlice(a.SS), aws.StringValueSlice(b.SS), func(x, y string) bool { return x == y })
+	case "NS":
+		return equalSets(aws.StringValueSlice(a.NS), aws.StringValueSlice(b.NS), func(x, y string) bool {
+			cmp, ok := Compare(&dynamodb.AttributeValue{N: &x}, &dynamodb.AttributeValue{N: &y})
+			return ok && cmp == 0
+		})
+	case "BS":
+		return equalSets(a.BS, b.BS, bytes.Equal)
+	case "L":
+		if len(a.L) != len(b.L) {
+			return false
+		}
+		for i := range a.L {
+			if !Equal(a.L[i], b.L[i]) {
+				return false
+			}
+		}
+		return true
+	case "M":
+		if len(a.M) != len(b.M) {
+			return false
+		}
+		for key, value := range a.M {
+			if !Equal(value, b.M[key]) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+func equalSets[T any](a, b []T, eq func(x, y T) bool) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for _, x := range a {
+		if !containsElem(b, x, eq) {
+			return false
+		}
+	}
+	return true
+}
+
+func containsElem[T any](list []T, elem T, eq func(x, y T) bool) bool {
+	for _, x := range list {
+		if eq(x, elem) {
+			return true
+		}
+	}
+	return false
+}
+
+// CopyItem returns a deep copy of the item
+func CopyItem(item Item) Item {
+	if item == nil {
+		return nil
+	}
+	result := make(Item, len(item))
+	for key, value := range item {
+		result[key] = CopyValue(value)
+	}
+	return result
+}
+
+// CopyValue returns a deep copy of the value
+func CopyValue(v *dynamodb.AttributeValue) *dynamodb.AttributeValue {
+	if v == nil {
+		return nil
+	}
+	result := &dynamodb.AttributeValue{}
+	if v.S != nil {
+		result.S = aws.String(*v.S)
+	}
+	if v.N != nil {
+		result.N = aws.String(*v.N)
+	}
+	if v.B != nil {
+		result.B = append([]byte{}, v.B...)
+	}
+	if v.BOOL != nil {
+		result.BOOL = aws.Bool(*v.BOOL)
+	}
+	if v.NULL != nil {
+		result.NULL = aws.Bool(*v.NULL)
+	}
+	if v.SS != nil {
+		result.SS = aws.StringSlice(aws.StringValueSlice(v.SS))
+	}
+	if v.NS != nil {
+		result.NS = aws.StringSlice(aws.StringValueSlice(v.NS))
+	}
+	if v.BS != nil {
+		result.BS = make([][]byte, len(v.BS))
+		for i, b := range v.BS {
+			result.BS[i] = append([]byte{}, b...)
+		}
+	}
+	if v.L != nil {
+		result.L = make([]*dynamodb.AttributeValue, len(v.L))
+		for i, elem := range v.L {
+			result.L[i] = CopyValue(elem)
+		}
+	}
+	if v.M != nil {
+		result.M = CopyItem(v.M)
+	}
+	return result
+}
+
+func parseNumber(n string) (*big.Rat, bool) {
+	return new(big.Rat).SetString(strings.TrimSpace(n))
+}
+
+func formatNumber(r *big.Rat) string {
+	if r.IsInt() {
+		return r.Num().String()
+	}
+	formatted := r.FloatString(38)
+	formatted = strings.TrimRight(formatted, "0")
+	return strings.TrimSuffix(formatted, ".")
+}
+
+func addNumbers(a, b *dynamodb.AttributeValue, subtract bool) (*dynamodb.AttributeValue, error) {
+	if TypeOf(a) != "N" || TypeOf(b) != "N" {
+		return nil, fmt.Errorf("an operand in the update expression has an incorrect data type")
+	}
+	numA, okA := parseNumber(*a.N)
+	numB, okB := parseNumber(*b.N)
+	if !okA || !okB {
+		return nil, fmt.Errorf("invalid number in the update expression")
+	}
+
+	var result *big.Rat
+	if subtract {
+		result = new(big.Rat).Sub(numA, numB)
+	} else {
+		result = new(big.Rat).Add(numA, numB)
+	}
+	return &dynamodb.AttributeValue{N: aws.String(formatNumber(result))}, nil
+}
+
+// KeyValue returns the string representation of a key attribute value (S, N or B),
+// the numbers are normalized so that equal numbers have equal representations.
+// An empty string is returned for other types and empty values.
+func KeyValue(v *dynamodb.AttributeValue) string {
+	switch TypeOf(v) {
+	case "S":
+		return *v.S
+	case "N":
+		if number, ok := parseNumber(*v.N); ok {
+			return formatNumber(number)
+		}
+		return *v.N
+	case "B":
+		return string(v.B)
+	}
+	return ""
+}
@@ -0,0 +1,126 @@
+package storeutil
+
+import (
+	"fmt"
+
+	"github.com/Astenna/Nubes/lib/internal/dynamoexpr"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+// Query is a validated query against a table or one of its indexes
+type Query struct {
+	input        *dynamodb.QueryInput
+	table        *Table
+	schema       KeySchema
+	keyCondition *dynamoexpr.Condition
+	filter       *dynamoexpr.Condition
+	projection   *dynamoexpr.Projection
+	hashKeyValue string
+}
+
+// NewQuery parses the expressions of the query and checks
+// that the key condition specifies the partition key value
+func NewQuery(table *Table, input *dynamodb.QueryInput) (*Query, error) {
+	if input.KeyConditionExpression == nil {
+		return nil, ValidationError("Either the KeyConditions or KeyConditionExpression parameter must be specified in the request")
+	}
+
+	var err error
+	q := &Query{input: input, table: table}
+	if q.schema, err = table.IndexSchema(input.IndexName); err != nil {
+		return nil, err
+	}
+	if q.keyCondition, err = dynamoexpr.ParseCondition(*input.KeyConditionExpression, input.ExpressionAttributeNames, input.ExpressionAttributeValues); err != nil {
+		return nil, ValidationError(err.Error())
+	}
+	if q.filter, err = dynamoexpr.ParseCondition(aws.StringValue(input.FilterExpression), input.ExpressionAttributeNames, input.ExpressionAttributeValues); err != nil {
+		return nil, ValidationError(err.Error())
+	}
+	if q.projection, err = dynamoexpr.ParseProjection(aws.StringValue(input.ProjectionExpression), input.ExpressionAttributeNames); err != nil {
+		return nil, ValidationError(err.Error())
+	}
+
+	hashKey, found := q.keyCondition.EqualityValue(q.schema.HashKey)
+	if !found {
+		return nil, ValidationError(fmt.Sprintf("Query condition missed key schema element: %s", q.schema.HashKey))
+	}
+	q.hashKeyValue = dynamoexpr.KeyValue(hashKey)
+	return q, nil
+}
+
+// HashKey returns the name of the partition key attribute of the queried table or index
+func (q *Query) HashKey() string {
+	return q.schema.HashKey
+}
+
+// HashKeyValue returns the queried partition key value encoded as by dynamoexpr.KeyValue
+func (q *Query) HashKeyValue() string {
+	return q.hashKeyValue
+}
+
+// Run evaluates the query against the items, which must contain at least
+// all the items of the queried partition. As in DynamoDB, the results are
+// ordered by the range key, the Limit is applied before the filter expression
+// and the items not having the key attributes of the index are not indexed.
+func (q *Query) Run(items []dynamoexpr.Item) (*dynamodb.QueryOutput, error) {
+	var matching []dynamoexpr.Item
+	for _, item := range items {
+		if !q.table.IsIndexed(q.schema, item) {
+			continue
+		}
+		ok, err := q.keyCondition.Matches(item)
+		if err != nil {
+			return nil, ValidationError(err.Error())
+		}
+		if ok {
+			matching = append(matching, item)
+		}
+	}
+
+	forward := q.input.ScanIndexForward == nil || *q.input.ScanIndexForward
+	q.table.SortItems(q.schema, matching)
+	if !forward {
+		for i, j := 0, len(matching)-1; i < j; i, j = i+1, j-1 {
+			matching[i], matching[j] = matching[j], matching[i]
+		}
+	}
+
+	if q.input.ExclusiveStartKey != nil {
+		matching = q.itemsAfter(matching, q.input.ExclusiveStartKey, forward)
+	}
+
+	output := &dynamodb.QueryOutput{Items: []map[string]*dynamodb.AttributeValue{}}
+	if q.input.Limit != nil && int(*q.input.Limit) < len(matching) {
+		matching = matching[:*q.input.Limit]
+		output.LastEvaluatedKey = q.table.LastEvaluatedKey(q.schema, matching[len(matching)-1])
+	}
+
+	for _, item := range matching {
+		ok, err := q.filter.Matches(item)
+		if err != nil {
+			return nil, ValidationError(err.Error())
+		}
+		if ok {
+			output.Items = append(output.Items, q.projection.Apply(item))
+		}
+	}
+	output.Count = aws.Int64(int64(len(output.Items)))
+	output.ScannedCount = aws.Int64(int64(len(matching)))
+	if aws.StringValue(q.input.Select) == dynamodb.SelectCount {
+		output.Items = nil
+	}
+	return output, nil
+}
+
+// itemsAfter returns the items following the start key,
+// which may refer to an item that is no longer stored
+func (q *Query) itemsAfter(items []dynamoexpr.Item, startKey dynamoexpr.Item, forward bool) []dynamoexpr.Item {
+	for i, item := range items {
+		cmp := q.table.CompareItems(q.schema, item, startKey)
+		if (forward && cmp > 0) || (!forward && cmp < 0) {
+			return items[i:]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,271 @@
+package storeutil
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Astenna/Nubes/lib/internal/dynamoexpr"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+func queriedProducts() []dynamoexpr.Item {
+	return []dynamoexpr.Item{
+		product("p1", "A", "30"),
+		product("p2", "A", "10"),
+		product("p3", "A", "20"),
+		product("p4", "B", "5"),
+		product("p5", "A", ""),
+		{"Id": s("p6"), "Shop": s("A"), "Price": s("15")},
+		product("p7", "A", "20"),
+	}
+}
+
+func ids(items []map[string]*dynamodb.AttributeValue) []string {
+	result := []string{}
+	for _, item := range items {
+		result = append(result, aws.StringValue(item["Id"].S))
+	}
+	return result
+}
+
+func TestQueryRun(t *testing.T) {
+	values := map[string]*dynamodb.AttributeValue{":a": s("A"), ":p1": s("p1"), ":low": n("15"), ":high": n("25"), ":p7": s("p7")}
+	cases := []struct {
+		name                 string
+		input                *dynamodb.QueryInput
+		expectedIds          []string
+		expectedScannedCount int64
+		expectedLastKey      dynamoexpr.Item
+	}{
+		{
+			name:                 "primary key",
+			input:                &dynamodb.QueryInput{KeyConditionExpression: aws.String("Id = :p1")},
+			expectedIds:          []string{"p1"},
+			expectedScannedCount: 1,
+		},
+		{
+			name:                 "index sorted by range key",
+			input:                &dynamodb.QueryInput{IndexName: aws.String("ShopPrice"), KeyConditionExpression: aws.String("Shop = :a")},
+			expectedIds:          []string{"p2", "p3", "p7", "p1"},
+			expectedScannedCount: 4,
+		},
+		{
+			name:                 "index in reverse order",
+			input:                &dynamodb.QueryInput{IndexName: aws.String("ShopPrice"), KeyConditionExpression: aws.String("Shop = :a"), ScanIndexForward: aws.Bool(false)},
+			expectedIds:          []string{"p1", "p7", "p3", "p2"},
+			expectedScannedCount: 4,
+		},
+		{
+			name:                 "range key condition",
+			input:                &dynamodb.QueryInput{IndexName: aws.String("ShopPrice"), KeyConditionExpression: aws.String("Shop = :a AND Price BETWEEN :low AND :high")},
+			expectedIds:          []string{"p3", "p7"},
+			expectedScannedCount: 2,
+		},
+		{
+			name:                 "limit",
+			input:                &dynamodb.QueryInput{IndexName: aws.String("ShopPrice"), KeyConditionExpression: aws.String("Shop = :a"), Limit: aws.Int64(2)},
+			expectedIds:          []string{"p2", "p3"},
+			expectedScannedCount: 2,
+			expectedLastKey:      product("p3", "A", "20"),
+		},
+		{
+			name: "limit applied before filter",
+			input: &dynamodb.QueryInput{IndexName: aws.String("ShopPrice"), KeyConditionExpression: aws.String("Shop = :a"),
+				FilterExpression: aws.String("Price > :low"), Limit: aws.Int64(3)},
+			expectedIds:          []string{"p3", "p7"},
+			expectedScannedCount: 3,
+			expectedLastKey:      product("p7", "A", "20"),
+		},
+		{
+			name: "exclusive start key",
+			input: &dynamodb.QueryInput{IndexName: aws.String("ShopPrice"), KeyConditionExpression: aws.String("Shop = :a"),
+				ExclusiveStartKey: product("p3", "A", "20")},
+			expectedIds:          []string{"p7", "p1"},
+			expectedScannedCount: 2,
+		},
+		{
+			name: "exclusive start key of deleted item in reverse order",
+			input: &dynamodb.QueryInput{IndexName: aws.String("ShopPrice"), KeyConditionExpression: aws.String("Shop = :a"),
+				ExclusiveStartKey: product("p0", "A", "25"), ScanIndexForward: aws.Bool(false)},
+			expectedIds:          []string{"p7", "p3", "p2"},
+			expectedScannedCount: 3,
+		},
+		{
+			name:                 "no matching items",
+			input:                &dynamodb.QueryInput{IndexName: aws.String("ShopPrice"), KeyConditionExpression: aws.String("Shop = :p1")},
+			expectedIds:          []string{},
+			expectedScannedCount: 0,
+		},
+	}
+
+	for _, c := range cases {
+		// Arrange
+		c.input.ExpressionAttributeValues = values
+		query, err := NewQuery(productsTable(t), c.input)
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", c.name, err)
+			continue
+		}
+
+		// Act
+		output, err := query.Run(queriedProducts())
+
+		// Assert
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", c.name, err)
+			continue
+		}
+		if got := ids(output.Items); !reflect.DeepEqual(got, c.expectedIds) {
+			t.Errorf("%s: expected items %v, got %v", c.name, c.expectedIds, got)
+		}
+		if *output.Count != int64(len(c.expectedIds)) || *output.ScannedCount != c.expectedScannedCount {
+			t.Errorf("%s: expected counts %d and %d, got %d and %d", c.name, len(c.expectedIds), c.expectedScannedCount, *output.Count, *output.ScannedCount)
+		}
+		if !reflect.DeepEqual(dynamoexpr.Item(output.LastEvaluatedKey), c.expectedLastKey) && !(output.LastEvaluatedKey == nil && c.expectedLastKey == nil) {
+			t.Errorf("%s: expected last evaluated key %v, got %v", c.name, c.expectedLastKey, output.LastEvaluatedKey)
+		}
+	}
+}
+
+func TestQueryPagesThroughAllItems(t *testing.T) {
+	// Arrange
+	var pages [][]string
+	input := &dynamodb.QueryInput{
+		IndexName:                 aws.String("ShopPrice"),
+		KeyConditionExpression:    aws.String("Shop = :a"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{":a": s("A")},
+		Limit:                     aws.Int64(3),
+	}
+
+	// Act
+	for {
+		query, err := NewQuery(productsTable(t), input)
+		if err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+		output, err := query.Run(queriedProducts())
+		if err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+		pages = append(pages, ids(output.Items))
+		if output.LastEvaluatedKey == nil {
+			break
+		}
+		if len(pages) > len(queriedProducts()) {
+			t.Fatalf("expected the paging to end, got pages %v", pages)
+		}
+		input.ExclusiveStartKey = output.LastEvaluatedKey
+	}
+
+	// Assert
+	expected := [][]string{{"p2", "p3", "p7"}, {"p1"}}
+	if !reflect.DeepEqual(pages, expected) {
+		t.Errorf("expected pages %v, got %v", expected, pages)
+	}
+}
+
+func TestQueryWithSelectCountReturnsNoItems(t *testing.T) {
+	// Arrange
+	query, err := NewQuery(productsTable(t), &dynamodb.QueryInput{
+		IndexName:                 aws.String("ShopPrice"),
+		KeyConditionExpression:    aws.String("Shop = :a"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{":a": s("A")},
+		Select:                    aws.String(dynamodb.SelectCount),
+	})
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	// Act
+	output, err := query.Run(queriedProducts())
+
+	// Assert
+	if err != nil || output.Items != nil || *output.Count != 4 {
+		t.Errorf("expected only the count of 4 items, got %v, error %v", output, err)
+	}
+}
+
+func TestNewQueryRejectsIncorrectInputs(t *testing.T) {
+	values := map[string]*dynamodb.AttributeValue{":a": s("A"), ":p1": s("p1")}
+	cases := map[string]*dynamodb.QueryInput{
+		"missing key condition":       {},
+		"missing hash key equality":   {KeyConditionExpression: aws.String("Shop = :a")},
+		"hash key not in conjunction": {KeyConditionExpression: aws.String("Id = :p1 OR Id = :a")},
+		"primary key on index":        {IndexName: aws.String("ShopPrice"), KeyConditionExpression: aws.String("Id = :p1")},
+		"unknown index":               {IndexName: aws.String("Missing"), KeyConditionExpression: aws.String("Shop = :a")},
+		"malformed filter":            {KeyConditionExpression: aws.String("Id = :p1"), FilterExpression: aws.String("Shop =")},
+		"malformed projection":        {KeyConditionExpression: aws.String("Id = :p1"), ProjectionExpression: aws.String("Id,")},
+	}
+
+	for name, input := range cases {
+		// Arrange
+		input.ExpressionAttributeValues = values
+
+		// Act
+		_, err := NewQuery(productsTable(t), input)
+
+		// Assert
+		if !isValidationError(err) {
+			t.Errorf("%s: expected a ValidationException, got %v", name, err)
+		}
+	}
+}
+
+func TestScanPagesThroughAllItemsInOrderOfPrimaryKeys(t *testing.T) {
+	// Arrange
+	var pages [][]string
+	var scannedCount int64
+	input := &dynamodb.ScanInput{
+		FilterExpression:          aws.String("Shop = :a"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{":a": s("A")},
+		Limit:                     aws.Int64(3),
+	}
+
+	// Act
+	for {
+		scan, err := NewScan(productsTable(t), input)
+		if err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+		output, err := scan.Run(queriedProducts())
+		if err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+		pages = append(pages, ids(output.Items))
+		scannedCount += *output.ScannedCount
+		if output.LastEvaluatedKey == nil {
+			break
+		}
+		if len(pages) > len(queriedProducts()) {
+			t.Fatalf("expected the paging to end, got pages %v", pages)
+		}
+		input.ExclusiveStartKey = output.LastEvaluatedKey
+	}
+
+	// Assert
+	expected := [][]string{{"p1", "p2", "p3"}, {"p5", "p6"}, {"p7"}}
+	if !reflect.DeepEqual(pages, expected) {
+		t.Errorf("expected pages %v, got %v", expected, pages)
+	}
+	if scannedCount != 7 {
+		t.Errorf("expected all 7 items to be scanned once, got %d", scannedCount)
+	}
+}
+
+func TestNewScanRejectsScansOfIndexesAndParallelScans(t *testing.T) {
+	cases := map[string]*dynamodb.ScanInput{
+		"index":         {IndexName: aws.String("ShopPrice")},
+		"parallel scan": {Segment: aws.Int64(0), TotalSegments: aws.Int64(2)},
+	}
+
+	for name, input := range cases {
+		// Act
+		_, err := NewScan(productsTable(t), input)
+
+		// Assert
+		if !isValidationError(err) {
+			t.Errorf("%s: expected a ValidationException, got %v", name, err)
+		}
+	}
+}
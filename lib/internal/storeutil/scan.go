@@ -0,0 +1,75 @@
+package storeutil
+
+import (
+	"github.com/Astenna/Nubes/lib/internal/dynamoexpr"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+// Scan is a validated scan of a table
+type Scan struct {
+	input      *dynamodb.ScanInput
+	table      *Table
+	filter     *dynamoexpr.Condition
+	projection *dynamoexpr.Projection
+}
+
+// NewScan parses the expressions of the scan, the scans of the indexes and the parallel scans are not supported
+func NewScan(table *Table, input *dynamodb.ScanInput) (*Scan, error) {
+	if input.IndexName != nil || input.Segment != nil || input.TotalSegments != nil {
+		return nil, ValidationError("Scans of the indexes and parallel scans are not supported")
+	}
+
+	var err error
+	s := &Scan{input: input, table: table}
+	if s.filter, err = dynamoexpr.ParseCondition(aws.StringValue(input.FilterExpression), input.ExpressionAttributeNames, input.ExpressionAttributeValues); err != nil {
+		return nil, ValidationError(err.Error())
+	}
+	if s.projection, err = dynamoexpr.ParseProjection(aws.StringValue(input.ProjectionExpression), input.ExpressionAttributeNames); err != nil {
+		return nil, ValidationError(err.Error())
+	}
+	return s, nil
+}
+
+// Run evaluates the scan against all the items of the table. The items are
+// returned in the order of their primary keys, so that the scan can be continued
+// from the LastEvaluatedKey. As in DynamoDB, the Limit is applied before the filter.
+func (s *Scan) Run(items []dynamoexpr.Item) (*dynamodb.ScanOutput, error) {
+	scanned := make([]dynamoexpr.Item, len(items))
+	copy(scanned, items)
+	s.table.SortItems(s.table.Key, scanned)
+
+	if s.input.ExclusiveStartKey != nil {
+		// the start key may refer to an item that is no longer stored
+		start := len(scanned)
+		for i, item := range scanned {
+			if s.table.CompareItems(s.table.Key, item, s.input.ExclusiveStartKey) > 0 {
+				start = i
+				break
+			}
+		}
+		scanned = scanned[start:]
+	}
+
+	output := &dynamodb.ScanOutput{Items: []map[string]*dynamodb.AttributeValue{}}
+	if s.input.Limit != nil && int(*s.input.Limit) < len(scanned) {
+		scanned = scanned[:*s.input.Limit]
+		output.LastEvaluatedKey = s.table.LastEvaluatedKey(s.table.Key, scanned[len(scanned)-1])
+	}
+
+	for _, item := range scanned {
+		ok, err := s.filter.Matches(item)
+		if err != nil {
+			return nil, ValidationError(err.Error())
+		}
+		if ok {
+			output.Items = append(output.Items, s.projection.Apply(item))
+		}
+	}
+	output.Count = aws.Int64(int64(len(output.Items)))
+	output.ScannedCount = aws.Int64(int64(len(scanned)))
+	if aws.StringValue(s.input.Select) == dynamodb.SelectCount {
+		output.Items = nil
+	}
+	return output, nil
+}
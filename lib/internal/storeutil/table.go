@@ -0,0 +1,225 @@
+// Package storeutil contains the table schema handling, validation and query
+// evaluation shared by the Store implementations alternative to DynamoDB.
+package storeutil
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/Astenna/Nubes/lib/internal/dynamoexpr"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+// KeySchema is the key of a table or of a secondary index
+type KeySchema struct {
+	HashKey  string
+	RangeKey string
+}
+
+// Attributes returns the names of the key attributes
+func (s KeySchema) Attributes() []string {
+	if s.RangeKey == "" {
+		return []string{s.HashKey}
+	}
+	return []string{s.HashKey, s.RangeKey}
+}
+
+// Extract returns the key attributes of the item
+func (s KeySchema) Extract(item dynamoexpr.Item) dynamoexpr.Item {
+	key := dynamoexpr.Item{}
+	for _, attribute := range s.Attributes() {
+		if value, found := item[attribute]; found {
+			key[attribute] = value
+		}
+	}
+	return key
+}
+
+// Table describes the primary key and the secondary indexes of a table
+type Table struct {
+	Name           string
+	Key            KeySchema
+	Indexes        map[string]KeySchema
+	AttributeTypes map[string]string
+}
+
+// NewTable creates the table description from the DynamoDB input used to create it
+func NewTable(input *dynamodb.CreateTableInput) (*Table, error) {
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
+
+	table := &Table{
+		Name:           *input.TableName,
+		Indexes:        map[string]KeySchema{},
+		AttributeTypes: map[string]string{},
+	}
+	for _, definition := range input.AttributeDefinitions {
+		table.AttributeTypes[*definition.AttributeName] = *definition.AttributeType
+	}
+
+	var err error
+	if table.Key, err = table.parseKeySchema(input.KeySchema); err != nil {
+		return nil, err
+	}
+	for _, index := range input.GlobalSecondaryIndexes {
+		if table.Indexes[*index.IndexName], err = table.parseKeySchema(index.KeySchema); err != nil {
+			return nil, err
+		}
+	}
+	for _, index := range input.LocalSecondaryIndexes {
+		if table.Indexes[*index.IndexName], err = table.parseKeySchema(index.KeySchema); err != nil {
+			return nil, err
+		}
+	}
+	return table, nil
+}
+
+func (t *Table) parseKeySchema(elements []*dynamodb.KeySchemaElement) (KeySchema, error) {
+	var schema KeySchema
+	for _, element := range elements {
+		name := *element.AttributeName
+		if _, defined := t.AttributeTypes[name]; !defined {
+			return schema, ValidationError(fmt.Sprintf("One or more parameter values were invalid: Some index key attributes are not defined in AttributeDefinitions: %s", name))
+		}
+		switch *element.KeyType {
+		case dynamodb.KeyTypeHash:
+			schema.HashKey = name
+		case dynamodb.KeyTypeRange:
+			schema.RangeKey = name
+		}
+	}
+	if schema.HashKey == "" {
+		return schema, ValidationError("One or more parameter values were invalid: Missing the HASH key in the KeySchema")
+	}
+	return schema, nil
+}
+
+// KeyAttributes returns the names of all the attributes being
+// part of the primary key or of the key of any secondary index
+func (t *Table) KeyAttributes() []string {
+	attributes := t.Key.Attributes()
+	seen := map[string]bool{}
+	for _, attribute := range attributes {
+		seen[attribute] = true
+	}
+
+	indexNames := make([]string, 0, len(t.Indexes))
+	for name := range t.Indexes {
+		indexNames = append(indexNames, name)
+	}
+	sort.Strings(indexNames)
+	for _, name := range indexNames {
+		for _, attribute := range t.Indexes[name].Attributes() {
+			if !seen[attribute] {
+				seen[attribute] = true
+				attributes = append(attributes, attribute)
+			}
+		}
+	}
+	return attributes
+}
+
+// IndexSchema returns the key schema of the index,
+// or the primary key if the index name is nil
+func (t *Table) IndexSchema(indexName *string) (KeySchema, error) {
+	if indexName == nil {
+		return t.Key, nil
+	}
+	schema, found := t.Indexes[*indexName]
+	if !found {
+		return schema, ValidationError(fmt.Sprintf("The table does not have the specified index: %s", *indexName))
+	}
+	return schema, nil
+}
+
+// EncodeKey validates the primary key against the table
+// schema and returns its encoding identifying the item
+func (t *Table) EncodeKey(key dynamoexpr.Item) (string, error) {
+	attributes := t.Key.Attributes()
+	if len(key) != len(attributes) {
+		return "", ValidationError("The provided key element does not match the schema")
+	}
+
+	var sb strings.Builder
+	for _, attribute := range attributes {
+		value, found := key[attribute]
+		if !found || dynamoexpr.TypeOf(value) != t.AttributeTypes[attribute] {
+			return "", ValidationError("The provided key element does not match the schema")
+		}
+		encoded := dynamoexpr.KeyValue(value)
+		if encoded == "" {
+			return "", ValidationError(fmt.Sprintf("One or more parameter values are not valid. The AttributeValue for a key attribute cannot contain an empty value. Key: %s", attribute))
+		}
+		fmt.Fprintf(&sb, "%d:%s", len(encoded), encoded)
+	}
+	return sb.String(), nil
+}
+
+// EncodeItemKey returns the encoding of the primary key of the item
+func (t *Table) EncodeItemKey(item dynamoexpr.Item) (string, error) {
+	return t.EncodeKey(t.Key.Extract(item))
+}
+
+// IndexKeyValue returns the value of the key attribute of the item,
+// the second return value is false if the item is not indexed by it,
+// i.e. the attribute is missing or is not of the declared type
+func (t *Table) IndexKeyValue(attribute string, item dynamoexpr.Item) (string, bool) {
+	value := item[attribute]
+	if dynamoexpr.TypeOf(value) != t.AttributeTypes[attribute] {
+		return "", false
+	}
+	encoded := dynamoexpr.KeyValue(value)
+	return encoded, encoded != ""
+}
+
+// IsIndexed reports whether the item has all the key attributes of the index
+func (t *Table) IsIndexed(schema KeySchema, item dynamoexpr.Item) bool {
+	for _, attribute := range schema.Attributes() {
+		if _, ok := t.IndexKeyValue(attribute, item); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// CompareItems orders the items by the range key of the schema,
+// the items with equal range keys are ordered by the primary key
+func (t *Table) CompareItems(schema KeySchema, a, b dynamoexpr.Item) int {
+	if schema.RangeKey != "" {
+		if cmp, _ := dynamoexpr.Compare(a[schema.RangeKey], b[schema.RangeKey]); cmp != 0 {
+			return cmp
+		}
+	}
+	keyA, _ := t.EncodeItemKey(a)
+	keyB, _ := t.EncodeItemKey(b)
+	return strings.Compare(keyA, keyB)
+}
+
+// SortItems sorts the items in the order defined by CompareItems
+func (t *Table) SortItems(schema KeySchema, items []dynamoexpr.Item) {
+	sort.Slice(items, func(i, j int) bool {
+		return t.CompareItems(schema, items[i], items[j]) < 0
+	})
+}
+
+// LastEvaluatedKey returns the key used to continue the query after the item
+func (t *Table) LastEvaluatedKey(schema KeySchema, item dynamoexpr.Item) dynamoexpr.Item {
+	key := t.Key.Extract(item)
+	for attribute, value := range schema.Extract(item) {
+		key[attribute] = value
+	}
+	return dynamoexpr.CopyItem(key)
+}
+
+// NewTableDescription returns the description of the table returned by CreateTable
+func NewTableDescription(input *dynamodb.CreateTableInput) *dynamodb.TableDescription {
+	return &dynamodb.TableDescription{
+		TableName:            input.TableName,
+		TableStatus:          aws.String(dynamodb.TableStatusActive),
+		AttributeDefinitions: input.AttributeDefinitions,
+		KeySchema:            input.KeySchema,
+	}
+}
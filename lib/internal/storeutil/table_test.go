@@ -0,0 +1,147 @@
+package storeutil
+
+import (
+	"testing"
+
+	"github.com/Astenna/Nubes/lib/internal/dynamoexpr"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+func s(value string) *dynamodb.AttributeValue {
+	return &dynamodb.AttributeValue{S: aws.String(value)}
+}
+
+func n(value string) *dynamodb.AttributeValue {
+	return &dynamodb.AttributeValue{N: aws.String(value)}
+}
+
+func product(id, shop, price string) dynamoexpr.Item {
+	item := dynamoexpr.Item{"Id": s(id), "Shop": s(shop)}
+	if price != "" {
+		item["Price"] = n(price)
+	}
+	return item
+}
+
+func productsTableInput() *dynamodb.CreateTableInput {
+	return &dynamodb.CreateTableInput{
+		TableName: aws.String("Product"),
+		AttributeDefinitions: []*dynamodb.AttributeDefinition{
+			{AttributeName: aws.String("Id"), AttributeType: aws.String(dynamodb.ScalarAttributeTypeS)},
+			{AttributeName: aws.String("Shop"), AttributeType: aws.String(dynamodb.ScalarAttributeTypeS)},
+			{AttributeName: aws.String("Price"), AttributeType: aws.String(dynamodb.ScalarAttributeTypeN)},
+		},
+		KeySchema: []*dynamodb.KeySchemaElement{
+			{AttributeName: aws.String("Id"), KeyType: aws.String(dynamodb.KeyTypeHash)},
+		},
+		GlobalSecondaryIndexes: []*dynamodb.GlobalSecondaryIndex{{
+			IndexName: aws.String("ShopPrice"),
+			KeySchema: []*dynamodb.KeySchemaElement{
+				{AttributeName: aws.String("Shop"), KeyType: aws.String(dynamodb.KeyTypeHash)},
+				{AttributeName: aws.String("Price"), KeyType: aws.String(dynamodb.KeyTypeRange)},
+			},
+			Projection: &dynamodb.Projection{ProjectionType: aws.String(dynamodb.ProjectionTypeAll)},
+		}},
+		BillingMode: aws.String(dynamodb.BillingModePayPerRequest),
+	}
+}
+
+func productsTable(t *testing.T) *Table {
+	table, err := NewTable(productsTableInput())
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	return table
+}
+
+func isValidationError(err error) bool {
+	awsErr, ok := err.(awserr.Error)
+	return ok && awsErr.Code() == "ValidationException"
+}
+
+func TestNewTable(t *testing.T) {
+	// Act
+	table, err := NewTable(productsTableInput())
+
+	// Assert
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if table.Name != "Product" || table.Key != (KeySchema{HashKey: "Id"}) {
+		t.Errorf("unexpected table %v", table)
+	}
+	if table.Indexes["ShopPrice"] != (KeySchema{HashKey: "Shop", RangeKey: "Price"}) {
+		t.Errorf("unexpected indexes %v", table.Indexes)
+	}
+	if attributes := table.KeyAttributes(); len(attributes) != 3 || attributes[0] != "Id" || attributes[1] != "Shop" || attributes[2] != "Price" {
+		t.Errorf("unexpected key attributes %v", attributes)
+	}
+}
+
+func TestNewTableRejectsIncorrectKeySchemas(t *testing.T) {
+	cases := map[string]func(input *dynamodb.CreateTableInput){
+		"undefined key attribute": func(input *dynamodb.CreateTableInput) {
+			input.AttributeDefinitions = input.AttributeDefinitions[1:]
+		},
+		"undefined index key attribute": func(input *dynamodb.CreateTableInput) {
+			input.AttributeDefinitions = input.AttributeDefinitions[:2]
+		},
+		"missing hash key": func(input *dynamodb.CreateTableInput) {
+			input.KeySchema[0].KeyType = aws.String(dynamodb.KeyTypeRange)
+		},
+		"missing table name": func(input *dynamodb.CreateTableInput) {
+			input.TableName = nil
+		},
+	}
+
+	for name, modify := range cases {
+		// Arrange
+		input := productsTableInput()
+		modify(input)
+
+		// Act
+		_, err := NewTable(input)
+
+		// Assert
+		if err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestEncodeKey(t *testing.T) {
+	table := productsTable(t)
+	cases := []struct {
+		name  string
+		key   dynamoexpr.Item
+		valid bool
+	}{
+		{"primary key", dynamoexpr.Item{"Id": s("p1")}, true},
+		{"missing key", dynamoexpr.Item{}, false},
+		{"other attribute", dynamoexpr.Item{"Shop": s("A")}, false},
+		{"additional attribute", dynamoexpr.Item{"Id": s("p1"), "Shop": s("A")}, false},
+		{"incorrect type", dynamoexpr.Item{"Id": n("1")}, false},
+		{"empty value", dynamoexpr.Item{"Id": s("")}, false},
+	}
+
+	for _, c := range cases {
+		// Act
+		_, err := table.EncodeKey(c.key)
+
+		// Assert
+		if c.valid && err != nil {
+			t.Errorf("%s: unexpected error %v", c.name, err)
+		}
+		if !c.valid && !isValidationError(err) {
+			t.Errorf("%s: expected a ValidationException, got %v", c.name, err)
+		}
+	}
+
+	encodedA, _ := table.EncodeKey(dynamoexpr.Item{"Id": s("a:1")})
+	encodedB, _ := table.EncodeItemKey(dynamoexpr.Item{"Id": s("a:1"), "Shop": s("A")})
+	if encodedA != encodedB {
+		t.Errorf("expected the key of the item to be encoded as the key, got %q and %q", encodedA, encodedB)
+	}
+}
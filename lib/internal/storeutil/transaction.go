@@ -0,0 +1,195 @@
+package storeutil
+
+import (
+	"strings"
+
+	"github.com/Astenna/Nubes/lib/internal/dynamoexpr"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+const TransactWriteItemsLimit = 100
+
+const (
+	cancellationReasonNone            = "None"
+	cancellationReasonConditionFailed = "ConditionalCheckFailed"
+)
+
+// TransactWrite is a single write of a transaction. The Item is nil
+// for the deletes, the condition checks do not result in a write.
+type TransactWrite struct {
+	Table *Table
+	Key   string
+	Item  dynamoexpr.Item
+}
+
+// PrepareTransactWrites evaluates the operations of the transaction against the
+// current state of the items returned by getItem and returns the writes to apply.
+// If any of the conditions is not satisfied, TransactionCanceledException with
+// the cancellation reasons of all the operations is returned and nothing is to be written.
+func PrepareTransactWrites(input *dynamodb.TransactWriteItemsInput, getTable func(name string) (*Table, error),
+	getItem func(table *Table, key string) (dynamoexpr.Item, error)) ([]TransactWrite, error) {
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
+	if len(input.TransactItems) > TransactWriteItemsLimit {
+		return nil, ValidationError("Member must have length less than or equal to 100")
+	}
+
+	writes := make([]TransactWrite, 0, len(input.TransactItems))
+	reasons := make([]*dynamodb.CancellationReason, len(input.TransactItems))
+	writtenKeys := map[string]bool{}
+	cancelled := false
+
+	for i, transactItem := range input.TransactItems {
+		operation, err := newTransactOperation(transactItem)
+		if err != nil {
+			return nil, err
+		}
+		table, err := getTable(operation.tableName)
+		if err != nil {
+			return nil, err
+		}
+
+		var key string
+		if operation.put != nil {
+			if err = ValidateItem(operation.put.Item); err != nil {
+				return nil, err
+			}
+			key, err = table.EncodeItemKey(operation.put.Item)
+		} else {
+			key, err = table.EncodeKey(operation.key)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if writtenKeys[table.Name+"\x00"+key] {
+			return nil, ValidationError("Transaction request cannot include multiple operations on one item")
+		}
+		writtenKeys[table.Name+"\x00"+key] = true
+
+		existing, err := getItem(table, key)
+		if err != nil {
+			return nil, err
+		}
+		write, err := operation.apply(table, key, existing)
+		if _, failed := err.(*dynamodb.ConditionalCheckFailedException); failed {
+			cancelled = true
+			reasons[i] = &dynamodb.CancellationReason{
+				Code:    aws.String(cancellationReasonConditionFailed),
+				Message: aws.String("The conditional request failed"),
+			}
+			if aws.StringValue(operation.returnValues) == dynamodb.ReturnValuesOnConditionCheckFailureAllOld {
+				reasons[i].Item = dynamoexpr.CopyItem(existing)
+			}
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		reasons[i] = &dynamodb.CancellationReason{Code: aws.String(cancellationReasonNone)}
+		if write != nil {
+			writes = append(writes, *write)
+		}
+	}
+
+	if cancelled {
+		return nil, transactionCanceledError(reasons)
+	}
+	return writes, nil
+}
+
+func transactionCanceledError(reasons []*dynamodb.CancellationReason) error {
+	codes := make([]string, len(reasons))
+	for i, reason := range reasons {
+		codes[i] = aws.StringValue(reason.Code)
+	}
+	return &dynamodb.TransactionCanceledException{
+		Message_:            aws.String("Transaction cancelled, please refer cancellation reasons for specific reasons [" + strings.Join(codes, ", ") + "]"),
+		CancellationReasons: reasons,
+	}
+}
+
+// transactOperation unifies the four kinds of operations of TransactWriteItem
+type transactOperation struct {
+	tableName    string
+	key          dynamoexpr.Item
+	returnValues *string
+
+	conditionExpression *string
+	names               map[string]*string
+	values              dynamoexpr.Item
+
+	put    *dynamodb.Put
+	update *dynamodb.Update
+	delete *dynamodb.Delete
+}
+
+func newTransactOperation(item *dynamodb.TransactWriteItem) (*transactOperation, error) {
+	operationsCount := 0
+	operation := &transactOperation{}
+
+	if check := item.ConditionCheck; check != nil {
+		operationsCount++
+		*operation = transactOperation{tableName: *check.TableName, key: check.Key, returnValues: check.ReturnValuesOnConditionCheckFailure,
+			conditionExpression: check.ConditionExpression, names: check.ExpressionAttributeNames, values: check.ExpressionAttributeValues}
+	}
+	if put := item.Put; put != nil {
+		operationsCount++
+		*operation = transactOperation{tableName: *put.TableName, returnValues: put.ReturnValuesOnConditionCheckFailure,
+			conditionExpression: put.ConditionExpression, names: put.ExpressionAttributeNames, values: put.ExpressionAttributeValues, put: put}
+	}
+	if update := item.Update; update != nil {
+		operationsCount++
+		*operation = transactOperation{tableName: *update.TableName, key: update.Key, returnValues: update.ReturnValuesOnConditionCheckFailure,
+			conditionExpression: update.ConditionExpression, names: update.ExpressionAttributeNames, values: update.ExpressionAttributeValues, update: update}
+	}
+	if delete := item.Delete; delete != nil {
+		operationsCount++
+		*operation = transactOperation{tableName: *delete.TableName, key: delete.Key, returnValues: delete.ReturnValuesOnConditionCheckFailure,
+			conditionExpression: delete.ConditionExpression, names: delete.ExpressionAttributeNames, values: delete.ExpressionAttributeValues, delete: delete}
+	}
+
+	if operationsCount != 1 {
+		return nil, ValidationError("TransactItems can only contain one of Check, Put, Update or Delete")
+	}
+	if item.ConditionCheck != nil && item.ConditionCheck.ConditionExpression == nil {
+		return nil, ValidationError("ConditionCheck must contain ConditionExpression")
+	}
+	return operation, nil
+}
+
+// apply checks the condition of the operation and returns the resulting write, nil for condition checks
+func (o *transactOperation) apply(table *Table, key string, existing dynamoexpr.Item) (*TransactWrite, error) {
+	if o.update != nil {
+		updated, err := UpdatedItem(table, &dynamodb.UpdateItemInput{
+			TableName:                 o.update.TableName,
+			Key:                       o.update.Key,
+			UpdateExpression:          o.update.UpdateExpression,
+			ConditionExpression:       o.update.ConditionExpression,
+			ExpressionAttributeNames:  o.update.ExpressionAttributeNames,
+			ExpressionAttributeValues: o.update.ExpressionAttributeValues,
+		}, existing)
+		if err != nil {
+			return nil, err
+		}
+		return &TransactWrite{Table: table, Key: key, Item: updated}, nil
+	}
+
+	condition, err := ParseCondition(o.conditionExpression, o.names, o.values)
+	if err != nil {
+		return nil, err
+	}
+	if err = CheckCondition(condition, existing); err != nil {
+		return nil, err
+	}
+
+	switch {
+	case o.put != nil:
+		return &TransactWrite{Table: table, Key: key, Item: dynamoexpr.CopyItem(o.put.Item)}, nil
+	case o.delete != nil:
+		return &TransactWrite{Table: table, Key: key}, nil
+	}
+	return nil, nil
+}
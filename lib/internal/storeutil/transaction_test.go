@@ -0,0 +1,220 @@
+package storeutil
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/Astenna/Nubes/lib/internal/dynamoexpr"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+func prepareTransactWrites(t *testing.T, stored []dynamoexpr.Item, items ...*dynamodb.TransactWriteItem) ([]TransactWrite, error) {
+	table := productsTable(t)
+	storedByKey := map[string]dynamoexpr.Item{}
+	for _, item := range stored {
+		key, err := table.EncodeItemKey(item)
+		if err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+		storedByKey[key] = item
+	}
+
+	return PrepareTransactWrites(&dynamodb.TransactWriteItemsInput{TransactItems: items},
+		func(name string) (*Table, error) {
+			if name != table.Name {
+				return nil, ResourceNotFoundError(name)
+			}
+			return table, nil
+		},
+		func(table *Table, key string) (dynamoexpr.Item, error) {
+			return storedByKey[key], nil
+		})
+}
+
+func TestPrepareTransactWrites(t *testing.T) {
+	// Arrange
+	stored := []dynamoexpr.Item{product("p1", "A", "10"), product("p2", "A", "20"), product("p3", "B", "30")}
+
+	// Act
+	writes, err := prepareTransactWrites(t, stored,
+		&dynamodb.TransactWriteItem{Put: &dynamodb.Put{
+			TableName:           aws.String("Product"),
+			Item:                product("p4", "B", "40"),
+			ConditionExpression: aws.String("attribute_not_exists(Id)"),
+		}},
+		&dynamodb.TransactWriteItem{Update: &dynamodb.Update{
+			TableName:                 aws.String("Product"),
+			Key:                       dynamoexpr.Item{"Id": s("p1")},
+			UpdateExpression:          aws.String("SET Price = Price + :one REMOVE Shop"),
+			ConditionExpression:       aws.String("Price = :ten"),
+			ExpressionAttributeValues: dynamoexpr.Item{":one": n("1"), ":ten": n("10")},
+		}},
+		&dynamodb.TransactWriteItem{Delete: &dynamodb.Delete{
+			TableName: aws.String("Product"),
+			Key:       dynamoexpr.Item{"Id": s("p2")},
+		}},
+		&dynamodb.TransactWriteItem{ConditionCheck: &dynamodb.ConditionCheck{
+			TableName:           aws.String("Product"),
+			Key:                 dynamoexpr.Item{"Id": s("p3")},
+			ConditionExpression: aws.String("attribute_exists(Id)"),
+		}},
+	)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	expected := []dynamoexpr.Item{product("p4", "B", "40"), {"Id": s("p1"), "Price": n("11")}, nil}
+	if len(writes) != len(expected) {
+		t.Fatalf("expected %d writes, got %v", len(expected), writes)
+	}
+	for i, write := range writes {
+		if !reflect.DeepEqual(write.Item, expected[i]) {
+			t.Errorf("write %d: expected %v, got %v", i, expected[i], write.Item)
+		}
+	}
+	if deletedKey, _ := writes[2].Table.EncodeKey(dynamoexpr.Item{"Id": s("p2")}); writes[2].Key != deletedKey {
+		t.Errorf("expected the delete of p2, got %q", writes[2].Key)
+	}
+}
+
+func TestPrepareTransactWritesCancelsTransactionWhenAnyConditionFails(t *testing.T) {
+	// Arrange
+	stored := []dynamoexpr.Item{product("p1", "A", "10"), product("p2", "A", "20")}
+
+	// Act
+	writes, err := prepareTransactWrites(t, stored,
+		&dynamodb.TransactWriteItem{Put: &dynamodb.Put{
+			TableName:           aws.String("Product"),
+			Item:                product("p3", "A", "30"),
+			ConditionExpression: aws.String("attribute_not_exists(Id)"),
+		}},
+		&dynamodb.TransactWriteItem{Update: &dynamodb.Update{
+			TableName:                           aws.String("Product"),
+			Key:                                 dynamoexpr.Item{"Id": s("p1")},
+			UpdateExpression:                    aws.String("SET Price = :ten"),
+			ConditionExpression:                 aws.String("Price > :ten"),
+			ExpressionAttributeValues:           dynamoexpr.Item{":ten": n("10")},
+			ReturnValuesOnConditionCheckFailure: aws.String(dynamodb.ReturnValuesOnConditionCheckFailureAllOld),
+		}},
+		&dynamodb.TransactWriteItem{ConditionCheck: &dynamodb.ConditionCheck{
+			TableName:           aws.String("Product"),
+			Key:                 dynamoexpr.Item{"Id": s("p5")},
+			ConditionExpression: aws.String("attribute_exists(Id)"),
+		}},
+		&dynamodb.TransactWriteItem{Delete: &dynamodb.Delete{
+			TableName: aws.String("Product"),
+			Key:       dynamoexpr.Item{"Id": s("p2")},
+		}},
+	)
+
+	// Assert
+	if writes != nil {
+		t.Errorf("expected no writes, got %v", writes)
+	}
+	canceled, ok := err.(*dynamodb.TransactionCanceledException)
+	if !ok {
+		t.Fatalf("expected TransactionCanceledException, got %v", err)
+	}
+	expectedCodes := []string{"None", "ConditionalCheckFailed", "ConditionalCheckFailed", "None"}
+	expectedItems := []dynamoexpr.Item{nil, product("p1", "A", "10"), nil, nil}
+	if len(canceled.CancellationReasons) != len(expectedCodes) {
+		t.Fatalf("expected %d cancellation reasons, got %v", len(expectedCodes), canceled.CancellationReasons)
+	}
+	for i, reason := range canceled.CancellationReasons {
+		if aws.StringValue(reason.Code) != expectedCodes[i] {
+			t.Errorf("reason %d: expected code %s, got %s", i, expectedCodes[i], aws.StringValue(reason.Code))
+		}
+		if !reflect.DeepEqual(dynamoexpr.Item(reason.Item), expectedItems[i]) && !(reason.Item == nil && expectedItems[i] == nil) {
+			t.Errorf("reason %d: expected item %v, got %v", i, expectedItems[i], reason.Item)
+		}
+	}
+}
+
+func TestPrepareTransactWritesRejectsIncorrectTransactions(t *testing.T) {
+	checkP1 := &dynamodb.ConditionCheck{
+		TableName:           aws.String("Product"),
+		Key:                 dynamoexpr.Item{"Id": s("p1")},
+		ConditionExpression: aws.String("attribute_exists(Id)"),
+	}
+	deleteP1 := &dynamodb.Delete{TableName: aws.String("Product"), Key: dynamoexpr.Item{"Id": s("p1")}}
+	tooMany := make([]*dynamodb.TransactWriteItem, TransactWriteItemsLimit+1)
+	for i := range tooMany {
+		tooMany[i] = &dynamodb.TransactWriteItem{Put: &dynamodb.Put{TableName: aws.String("Product"), Item: product(fmt.Sprintf("p%d", i), "A", "1")}}
+	}
+	cases := map[string][]*dynamodb.TransactWriteItem{
+		"multiple operations on one item": {{ConditionCheck: checkP1}, {Delete: deleteP1}},
+		"multiple operations in one item": {{ConditionCheck: checkP1, Delete: deleteP1}},
+		"incorrect key":                   {{Delete: &dynamodb.Delete{TableName: aws.String("Product"), Key: dynamoexpr.Item{"Shop": s("A")}}}},
+		"update of key attribute": {{Update: &dynamodb.Update{
+			TableName:                 aws.String("Product"),
+			Key:                       dynamoexpr.Item{"Id": s("p1")},
+			UpdateExpression:          aws.String("SET Id = :p2"),
+			ExpressionAttributeValues: dynamoexpr.Item{":p2": s("p2")},
+		}}},
+		"too many items": tooMany,
+	}
+
+	for name, items := range cases {
+		// Act
+		_, err := prepareTransactWrites(t, []dynamoexpr.Item{product("p1", "A", "10")}, items...)
+
+		// Assert
+		if !isValidationError(err) {
+			t.Errorf("%s: expected a ValidationException, got %v", name, err)
+		}
+	}
+}
+
+func TestUpdatedItem(t *testing.T) {
+	values := dynamoexpr.Item{":one": n("1"), ":ten": n("10"), ":b": s("B"), ":tags": {SS: aws.StringSlice([]string{"new"})}}
+	cases := []struct {
+		name           string
+		existing       dynamoexpr.Item
+		update         string
+		condition      string
+		expected       dynamoexpr.Item
+		conditionFails bool
+	}{
+		{"set", product("p1", "A", "10"), "SET Shop = :b, Price = Price + :one", "", product("p1", "B", "11"), false},
+		{"remove", product("p1", "A", "10"), "REMOVE Price", "", product("p1", "A", ""), false},
+		{"add", product("p1", "A", "10"), "ADD Price :ten, Tags :tags", "", dynamoexpr.Item{
+			"Id": s("p1"), "Shop": s("A"), "Price": n("20"), "Tags": {SS: aws.StringSlice([]string{"new"})},
+		}, false},
+		{"upsert of missing item", nil, "SET Shop = :b ADD Price :one", "attribute_not_exists(Id)", product("p1", "B", "1"), false},
+		{"satisfied condition", product("p1", "A", "10"), "SET Shop = :b", "Price = :ten", product("p1", "B", "10"), false},
+		{"unsatisfied condition", product("p1", "A", "10"), "SET Shop = :b", "Price > :ten", nil, true},
+		{"condition on missing item", nil, "SET Shop = :b", "attribute_exists(Id)", nil, true},
+	}
+
+	for _, c := range cases {
+		// Arrange
+		input := &dynamodb.UpdateItemInput{
+			TableName:                 aws.String("Product"),
+			Key:                       dynamoexpr.Item{"Id": s("p1")},
+			UpdateExpression:          aws.String(c.update),
+			ExpressionAttributeValues: values,
+		}
+		if c.condition != "" {
+			input.ConditionExpression = aws.String(c.condition)
+		}
+		existing := dynamoexpr.CopyItem(c.existing)
+
+		// Act
+		updated, err := UpdatedItem(productsTable(t), input, c.existing)
+
+		// Assert
+		if _, failed := err.(*dynamodb.ConditionalCheckFailedException); failed != c.conditionFails {
+			t.Errorf("%s: unexpected error %v", c.name, err)
+			continue
+		}
+		if !c.conditionFails && !reflect.DeepEqual(updated, c.expected) {
+			t.Errorf("%s: expected %v, got %v", c.name, c.expected, updated)
+		}
+		if !reflect.DeepEqual(c.existing, existing) {
+			t.Errorf("%s: the existing item was modified", c.name)
+		}
+	}
+}
@@ -0,0 +1,203 @@
+package storeutil
+
+import (
+	"fmt"
+
+	"github.com/Astenna/Nubes/lib/internal/dynamoexpr"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+const (
+	BatchGetItemLimit   = 100
+	BatchWriteItemLimit = 25
+)
+
+// ValidationError returns the error DynamoDB returns for invalid requests
+func ValidationError(message string) error {
+	return awserr.New("ValidationException", message, nil)
+}
+
+// ResourceNotFoundError returns the error DynamoDB returns for requests to missing tables
+func ResourceNotFoundError(tableName string) error {
+	return &dynamodb.ResourceNotFoundException{Message_: aws.String("Requested resource not found: Table: " + tableName + " not found")}
+}
+
+// ResourceInUseError returns the error DynamoDB returns when creating an existing table
+func ResourceInUseError(tableName string) error {
+	return &dynamodb.ResourceInUseException{Message_: aws.String("Table already exists: " + tableName)}
+}
+
+// ParseCondition parses the condition expression, an empty one is always satisfied
+func ParseCondition(expr *string, names map[string]*string, values dynamoexpr.Item) (*dynamoexpr.Condition, error) {
+	condition, err := dynamoexpr.ParseCondition(aws.StringValue(expr), names, values)
+	if err != nil {
+		return nil, ValidationError(err.Error())
+	}
+	return condition, nil
+}
+
+// ParseProjection parses the projection expression, an empty one projects all the attributes
+func ParseProjection(expr *string, names map[string]*string) (*dynamoexpr.Projection, error) {
+	projection, err := dynamoexpr.ParseProjection(aws.StringValue(expr), names)
+	if err != nil {
+		return nil, ValidationError(err.Error())
+	}
+	return projection, nil
+}
+
+// CheckCondition returns ConditionalCheckFailedException if the item,
+// nil if it does not exist, does not satisfy the condition
+func CheckCondition(condition *dynamoexpr.Condition, item dynamoexpr.Item) error {
+	ok, err := condition.Matches(item)
+	if err != nil {
+		return ValidationError(err.Error())
+	}
+	if !ok {
+		return &dynamodb.ConditionalCheckFailedException{Message_: aws.String("The conditional request failed")}
+	}
+	return nil
+}
+
+// UpdatedItem returns the result of the update of the existing item, nil if it does
+// not exist, checking the condition of the update and that the key is not modified
+func UpdatedItem(table *Table, input *dynamodb.UpdateItemInput, existing dynamoexpr.Item) (dynamoexpr.Item, error) {
+	condition, err := ParseCondition(input.ConditionExpression, input.ExpressionAttributeNames, input.ExpressionAttributeValues)
+	if err != nil {
+		return nil, err
+	}
+	if err = CheckCondition(condition, existing); err != nil {
+		return nil, err
+	}
+
+	updated := dynamoexpr.CopyItem(existing)
+	if updated == nil {
+		updated = dynamoexpr.CopyItem(input.Key)
+	}
+	if input.UpdateExpression != nil {
+		update, err := dynamoexpr.ParseUpdate(*input.UpdateExpression, input.ExpressionAttributeNames, input.ExpressionAttributeValues)
+		if err != nil {
+			return nil, ValidationError(err.Error())
+		}
+		if updated, err = update.Apply(updated); err != nil {
+			return nil, ValidationError(err.Error())
+		}
+	}
+
+	for _, keyAttribute := range table.Key.Attributes() {
+		if !dynamoexpr.Equal(updated[keyAttribute], input.Key[keyAttribute]) {
+			return nil, ValidationError(fmt.Sprintf("Cannot update attribute %s. This attribute is part of the key", keyAttribute))
+		}
+	}
+	if err = ValidateItem(updated); err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+// ReturnedAttributes returns the attributes requested with the ReturnValues parameter
+func ReturnedAttributes(returnValues *string, oldItem, newItem dynamoexpr.Item) map[string]*dynamodb.AttributeValue {
+	switch aws.StringValue(returnValues) {
+	case dynamodb.ReturnValueAllOld:
+		return dynamoexpr.CopyItem(oldItem)
+	case dynamodb.ReturnValueAllNew:
+		return dynamoexpr.CopyItem(newItem)
+	}
+	return nil
+}
+
+// ValidateReturnValues checks if the ReturnValues parameter is one of the supported values
+func ValidateReturnValues(returnValues *string, supported ...string) error {
+	if returnValues == nil {
+		return nil
+	}
+	for _, s := range supported {
+		if *returnValues == s {
+			return nil
+		}
+	}
+	return ValidationError(fmt.Sprintf("ReturnValues %s is not supported by this operation", *returnValues))
+}
+
+// ValidateBatchGetItem checks the number of the requested keys
+func ValidateBatchGetItem(input *dynamodb.BatchGetItemInput) error {
+	if err := input.Validate(); err != nil {
+		return err
+	}
+
+	keysCount := 0
+	for _, request := range input.RequestItems {
+		keysCount += len(request.Keys)
+	}
+	if keysCount > BatchGetItemLimit {
+		return ValidationError(fmt.Sprintf("Too many items requested for the BatchGetItem call, the limit is %d", BatchGetItemLimit))
+	}
+	return nil
+}
+
+// ValidateBatchWriteItem checks the number of the write requests and
+// that each of them contains exactly one of PutRequest or DeleteRequest
+func ValidateBatchWriteItem(input *dynamodb.BatchWriteItemInput) error {
+	if err := input.Validate(); err != nil {
+		return err
+	}
+
+	requestsCount := 0
+	for _, requests := range input.RequestItems {
+		requestsCount += len(requests)
+		for _, request := range requests {
+			if (request.PutRequest == nil) == (request.DeleteRequest == nil) {
+				return ValidationError("Supplied WriteRequest must contain exactly one of PutRequest or DeleteRequest")
+			}
+			if request.PutRequest != nil {
+				if err := ValidateItem(request.PutRequest.Item); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	if requestsCount > BatchWriteItemLimit {
+		return ValidationError(fmt.Sprintf("Too many items requested for the BatchWriteItem call, the limit is %d", BatchWriteItemLimit))
+	}
+	return nil
+}
+
+// ValidateItem checks if every value of the item has exactly one data type set
+func ValidateItem(item dynamoexpr.Item) error {
+	for _, value := range item {
+		if err := validateAttributeValue(value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func validateAttributeValue(value *dynamodb.AttributeValue) error {
+	if value == nil {
+		return ValidationError("Supplied AttributeValue is empty, must contain exactly one of the supported datatypes")
+	}
+
+	typesSet := 0
+	for _, isSet := range []bool{value.S != nil, value.N != nil, value.B != nil, value.BOOL != nil, value.NULL != nil,
+		value.SS != nil, value.NS != nil, value.BS != nil, value.L != nil, value.M != nil} {
+		if isSet {
+			typesSet++
+		}
+	}
+	switch {
+	case typesSet == 0:
+		return ValidationError("Supplied AttributeValue is empty, must contain exactly one of the supported datatypes")
+	case typesSet > 1:
+		return ValidationError("Supplied AttributeValue has more than one datatypes set, must contain exactly one of the supported datatypes")
+	case (value.SS != nil && len(value.SS) == 0) || (value.NS != nil && len(value.NS) == 0) || (value.BS != nil && len(value.BS) == 0):
+		return ValidationError("One or more parameter values were invalid: An string set may not be empty")
+	}
+
+	for _, elem := range value.L {
+		if err := validateAttributeValue(elem); err != nil {
+			return err
+		}
+	}
+	return ValidateItem(value.M)
+}
@@ -1,8 +1,10 @@
 package lib
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"reflect"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/dynamodb"
@@ -11,12 +13,16 @@ import (
 )
 
 func Load[T Nobject](id string) (*T, error) {
+	return LoadWithContext[T](InvocationContext(), id)
+}
+
+func LoadWithContext[T Nobject](ctx context.Context, id string) (*T, error) {
 	instance := new(T)
 	instanceTypeName := (*instance).GetTypeName()
 	dbIdAttributeName := "Id"
 
-	item, err := dbClient.GetItem(&dynamodb.GetItemInput{
-		TableName: aws.String(instanceTypeName),
+	item, err := dbClient(ctx).GetItemWithContext(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(getTableName(instanceTypeName)),
 		Key: map[string]*dynamodb.AttributeValue{
 			"Id": {
 				S: aws.String(id),
@@ -39,35 +45,23 @@ func Load[T Nobject](id string) (*T, error) {
 }
 
 func LoadBatch[T Nobject](ids []string) ([]*T, error) {
+	return LoadBatchWithContext[T](InvocationContext(), ids)
+}
+
+func LoadBatchWithContext[T Nobject](ctx context.Context, ids []string) ([]*T, error) {
 	if ids == nil {
 		return nil, fmt.Errorf("missing ids of objects to get")
 	}
 
-	keysToRetrieve := make([]map[string]*dynamodb.AttributeValue, len(ids))
-	for i, id := range ids {
-		keysToRetrieve[i] = map[string]*dynamodb.AttributeValue{"Id": {
-			S: aws.String(id),
-		}}
-	}
-
 	tableName := (*new(T)).GetTypeName()
-	input := &dynamodb.BatchGetItemInput{
-		RequestItems: map[string]*dynamodb.KeysAndAttributes{
-			tableName: {
-				Keys:                 keysToRetrieve,
-				ProjectionExpression: aws.String("Id"),
-			},
-		},
-	}
-
-	items, err := dbClient.BatchGetItem(input)
+	items, err := getItemsInBatches(ctx, tableName, ids, aws.String("Id"))
 	if err != nil {
 		return nil, err
 	}
 
 	var parsedItem = new([]*T)
-	if items.Responses[tableName] != nil {
-		err = dynamodbattribute.UnmarshalListOfMaps(items.Responses[tableName], parsedItem)
+	if len(items) > 0 {
+		err = dynamodbattribute.UnmarshalListOfMaps(items, parsedItem)
 
 		for _, item := range *parsedItem {
 			invokeInitOnNobjectType(item)
@@ -79,6 +73,17 @@ func LoadBatch[T Nobject](ids []string) ([]*T, error) {
 }
 
 func Export[T Nobject](objToInsert Nobject) (*T, error) {
+	return ExportWithContext[T](InvocationContext(), objToInsert)
+}
+
+func ExportWithContext[T Nobject](ctx context.Context, objToInsert Nobject) (*T, error) {
+	if err := Validate(objToInsert); err != nil {
+		return new(T), err
+	}
+	if err := CheckReferencesWithContext(ctx, objToInsert); err != nil {
+		return new(T), err
+	}
+
 	var attributeVals, err = dynamodbattribute.MarshalMap(objToInsert)
 	if err != nil {
 		return new(T), err
@@ -86,12 +91,14 @@ func Export[T Nobject](objToInsert Nobject) (*T, error) {
 
 	var newId string
 	var conditionExpression *string
+	var conditionErr error
 	if custom, ok := objToInsert.(CustomId); ok {
 		if newId = custom.GetId(); newId == "" {
 			return new(T), errors.New("id field empty. It must be set when using non-default id field")
 		}
 		newId = custom.GetId()
 		conditionExpression = aws.String("attribute_not_exists(Id)")
+		conditionErr = AlreadyExistsError{Id: newId, TypeName: objToInsert.GetTypeName()}
 	} else {
 		newId = uuid.New().String()
 		attr := attributeVals["Id"].SetS(newId)
@@ -102,14 +109,18 @@ func Export[T Nobject](objToInsert Nobject) (*T, error) {
 
 	input := &dynamodb.PutItemInput{
 		Item:                attributeVals,
-		TableName:           aws.String(objToInsert.GetTypeName()),
+		TableName:           aws.String(getTableName(objToInsert.GetTypeName())),
 		ConditionExpression: conditionExpression,
 	}
 
-	_, err = dbClient.PutItem(input)
+	// the exported object is new, none of its unique values is held yet
+	err = writeGuardingUniqueValues(ctx, getObjectUniqueFields(objToInsert), objToInsert.GetTypeName(), newId, map[string]*dynamodb.AttributeValue{}, attributeVals, conditionErr, func(ctx context.Context) error {
+		_, err := dbClient(ctx).PutItemWithContext(ctx, input)
+		return err
+	})
 	if err != nil {
 		if _, ok := err.(*dynamodb.ConditionalCheckFailedException); ok {
-			return nil, fmt.Errorf("instance of %s with id: %s already exists. Use lib.Load(id) to work on existing instances", objToInsert.GetTypeName(), newId)
+			return nil, conditionErr
 		}
 		return nil, err
 	}
@@ -135,15 +146,31 @@ func invokeInitOnNobjectType[T Nobject](item *T) {
 }
 
 func Delete[T Nobject](id string) error {
+	return DeleteWithContext[T](InvocationContext(), id)
+}
+
+func DeleteWithContext[T Nobject](ctx context.Context, id string) error {
 	if id == "" {
 		return fmt.Errorf("missing id of object to delete")
 	}
 	typeName := (*new(T)).GetTypeName()
 
-	return DeleteWithTypeNameAsArg(id, typeName)
+	objType := reflect.TypeOf((*T)(nil)).Elem()
+	if len(getDeletePolicies(objType)) > 0 || len(getUniqueFields(objType)) > 0 {
+		return deleteWithPolicies(ctx, objType, typeName, id)
+	}
+	return DeleteWithTypeNameAsArgWithContext(ctx, id, typeName)
 }
 
+// DeleteWithTypeNameAsArg deletes only the object itself, the delete policies
+// of its relationships are applied and its unique values are released
+// by Delete, which is given the type of the object
+
 func DeleteWithTypeNameAsArg(id, typeName string) error {
+	return DeleteWithTypeNameAsArgWithContext(InvocationContext(), id, typeName)
+}
+
+func DeleteWithTypeNameAsArgWithContext(ctx context.Context, id, typeName string) error {
 	if id == "" {
 		return fmt.Errorf("missing id of object to delete")
 	}
@@ -152,7 +179,7 @@ func DeleteWithTypeNameAsArg(id, typeName string) error {
 	}
 
 	input := &dynamodb.DeleteItemInput{
-		TableName: aws.String(typeName),
+		TableName: aws.String(getTableName(typeName)),
 		Key: map[string]*dynamodb.AttributeValue{
 			"Id": {
 				S: aws.String(id),
@@ -161,9 +188,13 @@ func DeleteWithTypeNameAsArg(id, typeName string) error {
 		ConditionExpression: aws.String("attribute_exists(Id)"),
 	}
 
-	_, err := dbClient.DeleteItem(input)
+	_, err := dbClient(ctx).DeleteItemWithContext(ctx, input)
 	if _, ok := err.(*dynamodb.ConditionalCheckFailedException); ok {
-		return fmt.Errorf("delete failed. Instance of %s with id: %s not found", typeName, id)
+		return deleteNotFoundError(typeName, id)
 	}
 	return err
 }
+
+func deleteNotFoundError(typeName, id string) error {
+	return fmt.Errorf("delete failed. Instance of %s with id: %s not found", typeName, id)
+}
@@ -0,0 +1,384 @@
+package lib
+
+import (
+	"sync"
+
+	"github.com/Astenna/Nubes/lib/internal/dynamoexpr"
+	"github.com/Astenna/Nubes/lib/internal/storeutil"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+// MemoryStore is a Store keeping the items in memory. It follows
+// the DynamoDB semantics of the operations used by the library:
+// condition, update and projection expressions, global secondary
+// indexes and composite primary keys, so that it can replace
+// DynamoDB in tests. The tables must be created with CreateTable.
+type MemoryStore struct {
+	mu     sync.RWMutex
+	tables map[string]*memoryTable
+}
+
+type memoryTable struct {
+	*storeutil.Table
+	items map[string]dynamoexpr.Item
+}
+
+var _ Store = (*MemoryStore)(nil)
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{tables: map[string]*memoryTable{}}
+}
+
+// CreateTable creates a table with the key schema, attribute definitions and
+// secondary indexes of the input. Other settings, e.g. billing mode, are ignored.
+func (m *MemoryStore) CreateTable(input *dynamodb.CreateTableInput) (*dynamodb.CreateTableOutput, error) {
+	table, err := storeutil.NewTable(input)
+	if err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, exists := m.tables[table.Name]; exists {
+		return nil, storeutil.ResourceInUseError(table.Name)
+	}
+	m.tables[table.Name] = &memoryTable{Table: table, items: map[string]dynamoexpr.Item{}}
+	return &dynamodb.CreateTableOutput{TableDescription: storeutil.NewTableDescription(input)}, nil
+}
+
+func (m *MemoryStore) GetItemWithContext(ctx aws.Context, input *dynamodb.GetItemInput, _ ...request.Option) (*dynamodb.GetItemOutput, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
+	projection, err := storeutil.ParseProjection(input.ProjectionExpression, input.ExpressionAttributeNames)
+	if err != nil {
+		return nil, err
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	table, err := m.table(*input.TableName)
+	if err != nil {
+		return nil, err
+	}
+	key, err := table.EncodeKey(input.Key)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dynamodb.GetItemOutput{Item: projection.Apply(table.items[key])}, nil
+}
+
+func (m *MemoryStore) PutItemWithContext(ctx aws.Context, input *dynamodb.PutItemInput, _ ...request.Option) (*dynamodb.PutItemOutput, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
+	if err := storeutil.ValidateReturnValues(input.ReturnValues, dynamodb.ReturnValueNone, dynamodb.ReturnValueAllOld); err != nil {
+		return nil, err
+	}
+	if err := storeutil.ValidateItem(input.Item); err != nil {
+		return nil, err
+	}
+	condition, err := storeutil.ParseCondition(input.ConditionExpression, input.ExpressionAttributeNames, input.ExpressionAttributeValues)
+	if err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	table, err := m.table(*input.TableName)
+	if err != nil {
+		return nil, err
+	}
+	key, err := table.EncodeItemKey(input.Item)
+	if err != nil {
+		return nil, err
+	}
+
+	existing := table.items[key]
+	if err = storeutil.CheckCondition(condition, existing); err != nil {
+		return nil, err
+	}
+
+	table.items[key] = dynamoexpr.CopyItem(input.Item)
+	return &dynamodb.PutItemOutput{Attributes: storeutil.ReturnedAttributes(input.ReturnValues, existing, nil)}, nil
+}
+
+// UpdateItemWithContext updates the item or creates it if it does not exist
+func (m *MemoryStore) UpdateItemWithContext(ctx aws.Context, input *dynamodb.UpdateItemInput, _ ...request.Option) (*dynamodb.UpdateItemOutput, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
+	if err := storeutil.ValidateReturnValues(input.ReturnValues, dynamodb.ReturnValueNone, dynamodb.ReturnValueAllOld, dynamodb.ReturnValueAllNew); err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	table, err := m.table(*input.TableName)
+	if err != nil {
+		return nil, err
+	}
+	key, err := table.EncodeKey(input.Key)
+	if err != nil {
+		return nil, err
+	}
+
+	existing := table.items[key]
+	updated, err := storeutil.UpdatedItem(table.Table, input, existing)
+	if err != nil {
+		return nil, err
+	}
+
+	table.items[key] = updated
+	return &dynamodb.UpdateItemOutput{Attributes: storeutil.ReturnedAttributes(input.ReturnValues, existing, updated)}, nil
+}
+
+func (m *MemoryStore) DeleteItemWithContext(ctx aws.Context, input *dynamodb.DeleteItemInput, _ ...request.Option) (*dynamodb.DeleteItemOutput, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
+	if err := storeutil.ValidateReturnValues(input.ReturnValues, dynamodb.ReturnValueNone, dynamodb.ReturnValueAllOld); err != nil {
+		return nil, err
+	}
+	condition, err := storeutil.ParseCondition(input.ConditionExpression, input.ExpressionAttributeNames, input.ExpressionAttributeValues)
+	if err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	table, err := m.table(*input.TableName)
+	if err != nil {
+		return nil, err
+	}
+	key, err := table.EncodeKey(input.Key)
+	if err != nil {
+		return nil, err
+	}
+
+	existing := table.items[key]
+	if err = storeutil.CheckCondition(condition, existing); err != nil {
+		return nil, err
+	}
+
+	delete(table.items, key)
+	return &dynamodb.DeleteItemOutput{Attributes: storeutil.ReturnedAttributes(input.ReturnValues, existing, nil)}, nil
+}
+
+func (m *MemoryStore) QueryWithContext(ctx aws.Context, input *dynamodb.QueryInput, _ ...request.Option) (*dynamodb.QueryOutput, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	table, err := m.table(*input.TableName)
+	if err != nil {
+		return nil, err
+	}
+	query, err := storeutil.NewQuery(table.Table, input)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]dynamoexpr.Item, 0, len(table.items))
+	for _, item := range table.items {
+		items = append(items, item)
+	}
+	return query.Run(items)
+}
+
+// ScanWithContext reads the items of the table in the order of their primary keys
+func (m *MemoryStore) ScanWithContext(ctx aws.Context, input *dynamodb.ScanInput, _ ...request.Option) (*dynamodb.ScanOutput, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	table, err := m.table(*input.TableName)
+	if err != nil {
+		return nil, err
+	}
+	scan, err := storeutil.NewScan(table.Table, input)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]dynamoexpr.Item, 0, len(table.items))
+	for _, item := range table.items {
+		items = append(items, item)
+	}
+	return scan.Run(items)
+}
+
+func (m *MemoryStore) BatchGetItemWithContext(ctx aws.Context, input *dynamodb.BatchGetItemInput, _ ...request.Option) (*dynamodb.BatchGetItemOutput, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if err := storeutil.ValidateBatchGetItem(input); err != nil {
+		return nil, err
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	output := &dynamodb.BatchGetItemOutput{
+		Responses:       map[string][]map[string]*dynamodb.AttributeValue{},
+		UnprocessedKeys: map[string]*dynamodb.KeysAndAttributes{},
+	}
+	for tableName, request := range input.RequestItems {
+		table, err := m.table(tableName)
+		if err != nil {
+			return nil, err
+		}
+		projection, err := storeutil.ParseProjection(request.ProjectionExpression, request.ExpressionAttributeNames)
+		if err != nil {
+			return nil, err
+		}
+
+		requestedKeys := make(map[string]bool, len(request.Keys))
+		output.Responses[tableName] = []map[string]*dynamodb.AttributeValue{}
+		for _, requestKey := range request.Keys {
+			key, err := table.EncodeKey(requestKey)
+			if err != nil {
+				return nil, err
+			}
+			if requestedKeys[key] {
+				return nil, storeutil.ValidationError("Provided list of item keys contains duplicates")
+			}
+			requestedKeys[key] = true
+
+			if item, found := table.items[key]; found {
+				output.Responses[tableName] = append(output.Responses[tableName], projection.Apply(item))
+			}
+		}
+	}
+	return output, nil
+}
+
+// BatchWriteItemWithContext applies all the requests atomically,
+// i.e. no item is returned as unprocessed
+func (m *MemoryStore) BatchWriteItemWithContext(ctx aws.Context, input *dynamodb.BatchWriteItemInput, _ ...request.Option) (*dynamodb.BatchWriteItemOutput, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if err := storeutil.ValidateBatchWriteItem(input); err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	type write struct {
+		table *memoryTable
+		key   string
+		item  dynamoexpr.Item
+	}
+	var writes []write
+	writtenKeys := map[string]bool{}
+
+	for tableName, requests := range input.RequestItems {
+		table, err := m.table(tableName)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, request := range requests {
+			w := write{table: table}
+			if request.PutRequest != nil {
+				w.item = request.PutRequest.Item
+				w.key, err = table.EncodeItemKey(w.item)
+			} else {
+				w.key, err = table.EncodeKey(request.DeleteRequest.Key)
+			}
+			if err != nil {
+				return nil, err
+			}
+			if writtenKeys[tableName+"\x00"+w.key] {
+				return nil, storeutil.ValidationError("Provided list of item keys contains duplicates")
+			}
+			writtenKeys[tableName+"\x00"+w.key] = true
+			writes = append(writes, w)
+		}
+	}
+
+	for _, w := range writes {
+		if w.item == nil {
+			delete(w.table.items, w.key)
+		} else {
+			w.table.items[w.key] = dynamoexpr.CopyItem(w.item)
+		}
+	}
+	return &dynamodb.BatchWriteItemOutput{UnprocessedItems: map[string][]*dynamodb.WriteRequest{}}, nil
+}
+
+// TransactWriteItemsWithContext checks the conditions of all the operations
+// and applies the writes only if all of them are satisfied
+func (m *MemoryStore) TransactWriteItemsWithContext(ctx aws.Context, input *dynamodb.TransactWriteItemsInput, _ ...request.Option) (*dynamodb.TransactWriteItemsOutput, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	writes, err := storeutil.PrepareTransactWrites(input,
+		func(name string) (*storeutil.Table, error) {
+			table, err := m.table(name)
+			if err != nil {
+				return nil, err
+			}
+			return table.Table, nil
+		},
+		func(table *storeutil.Table, key string) (dynamoexpr.Item, error) {
+			return m.tables[table.Name].items[key], nil
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, w := range writes {
+		if w.Item == nil {
+			delete(m.tables[w.Table.Name].items, w.Key)
+		} else {
+			m.tables[w.Table.Name].items[w.Key] = w.Item
+		}
+	}
+	return &dynamodb.TransactWriteItemsOutput{}, nil
+}
+
+func (m *MemoryStore) table(name string) (*memoryTable, error) {
+	table, found := m.tables[name]
+	if !found {
+		return nil, storeutil.ResourceNotFoundError(name)
+	}
+	return table, nil
+}
@@ -0,0 +1,505 @@
+package lib
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/aws/aws-sdk-go/service/dynamodb/expression"
+)
+
+// The types of the attributes the values can be converted to with ConvertAttribute
+const (
+	StringAttributeType = "S"
+	NumberAttributeType = "N"
+	BoolAttributeType   = "BOOL"
+)
+
+// the number of times the item is read again and transformed
+// when it was modified between the scan and the write
+const migrationWriteRetries = 3
+
+const defaultMigrationPageSize = 100
+
+// MigrationStep is a change of the items of the table of the type, e.g. a rename of
+// the attribute, or a change of the table itself. The steps are created with
+// RenameAttribute, FillDefault, ConvertAttribute, AddIndex or TransformItems.
+type MigrationStep struct {
+	TypeName    string
+	Description string
+
+	// transform modifies the item and returns true if it was changed,
+	// it must leave the items already transformed unchanged
+	transform func(item map[string]*dynamodb.AttributeValue) (bool, error)
+	// indexAttribute and indexAttributeType describe the added secondary index
+	indexAttribute     string
+	indexAttributeType string
+}
+
+// MigrationOptions configures RunMigration
+type MigrationOptions struct {
+	// DryRun counts the items to be changed without writing them
+	DryRun bool
+	// ProgressPath is the file in which the progress of the migration is recorded after each
+	// page of items, so that the interrupted migration is resumed where it stopped. The types
+	// are skipped only if they were completed with the same steps, so the file can be left
+	// in place for the migrations of the later changes of the types.
+	// If empty, the migration starts from the beginning every time.
+	ProgressPath string
+	// PageSize is the number of items scanned at once, 100 by default
+	PageSize int64
+}
+
+// MigrationReport holds the number of items scanned and changed
+// for each type, the items are not written in the dry run
+type MigrationReport struct {
+	Scanned map[string]int
+	Changed map[string]int
+}
+
+// migrationProgress is recorded in the file given in MigrationOptions.ProgressPath
+type migrationProgress struct {
+	// LastEvaluatedKeys are the keys the scans of the tables are continued from
+	LastEvaluatedKeys map[string]map[string]*dynamodb.AttributeValue
+	// Completed maps the types whose steps are all applied to the key of the steps,
+	// the type is migrated again with other steps, e.g. of a later migration
+	Completed map[string]string
+}
+
+// tableScanner is implemented by DynamoDB and the stores of the library
+type tableScanner interface {
+	ScanWithContext(ctx aws.Context, input *dynamodb.ScanInput, opts ...request.Option) (*dynamodb.ScanOutput, error)
+}
+
+// tableUpdater is implemented by DynamoDB
+type tableUpdater interface {
+	UpdateTableWithContext(ctx aws.Context, input *dynamodb.UpdateTableInput, opts ...request.Option) (*dynamodb.UpdateTableOutput, error)
+}
+
+// RenameAttribute moves the value of the attribute to the attribute
+// with the new name, e.g. after the field of the type was renamed
+func RenameAttribute(typeName, from, to string) MigrationStep {
+	return TransformItems(typeName, fmt.Sprintf("rename %s to %s", from, to), func(item map[string]*dynamodb.AttributeValue) (bool, error) {
+		value, exists := item[from]
+		if !exists {
+			return false, nil
+		}
+		delete(item, from)
+		item[to] = value
+		return true, nil
+	})
+}
+
+// FillDefault sets the attribute to the value in the items
+// in which it's missing, e.g. after the field was added to the type
+func FillDefault(typeName, attribute string, value interface{}) MigrationStep {
+	return TransformItems(typeName, fmt.Sprintf("fill %s with %v", attribute, value), func(item map[string]*dynamodb.AttributeValue) (bool, error) {
+		if current, exists := item[attribute]; exists && !aws.BoolValue(current.NULL) {
+			return false, nil
+		}
+		marshalled, err := dynamodbattribute.Marshal(value)
+		if err != nil {
+			return false, err
+		}
+		item[attribute] = marshalled
+		return true, nil
+	})
+}
+
+// ConvertAttribute converts the value of the attribute to the StringAttributeType,
+// NumberAttributeType or BoolAttributeType, e.g. after the type of the field was changed
+func ConvertAttribute(typeName, attribute, attributeType string) MigrationStep {
+	return TransformItems(typeName, fmt.Sprintf("convert %s to %s", attribute, attributeType), func(item map[string]*dynamodb.AttributeValue) (bool, error) {
+		value, exists := item[attribute]
+		if !exists || aws.BoolValue(value.NULL) {
+			return false, nil
+		}
+		converted, err := convertAttributeValue(value, attributeType)
+		if err != nil {
+			return false, fmt.Errorf("failed to convert %s to %s. Error %w", attribute, attributeType, err)
+		}
+		if converted == value {
+			return false, nil
+		}
+		item[attribute] = converted
+		return true, nil
+	})
+}
+
+// AddIndex adds the secondary index of the attribute to the table of the type, e.g. after
+// the field was tagged with index. It's named like the indexes created by the generator and
+// DynamoDB fills it with the existing items. The stores of the library do not support it.
+func AddIndex(typeName, attribute, attributeType string) MigrationStep {
+	return MigrationStep{
+		TypeName:           typeName,
+		Description:        fmt.Sprintf("add index of %s", attribute),
+		indexAttribute:     attribute,
+		indexAttributeType: attributeType,
+	}
+}
+
+// TransformItems applies the transform to the items of the type. The transform modifies
+// the item and returns true if it was changed. It must leave the items already transformed
+// unchanged, as the page of items being migrated when the migration was interrupted is migrated again.
+func TransformItems(typeName, description string, transform func(item map[string]*dynamodb.AttributeValue) (bool, error)) MigrationStep {
+	return MigrationStep{TypeName: typeName, Description: description, transform: transform}
+}
+
+// RunMigration applies the steps to the tables of the types in the order of the types'
+// first steps. The items of each table are scanned once and all the steps of the type
+// are applied to each of them, then the indexes are added.
+// The store in use must support scans, e.g. DynamoDB, MemoryStore or the SQLite store.
+func RunMigration(steps []MigrationStep, options MigrationOptions) (MigrationReport, error) {
+	return RunMigrationWithContext(InvocationContext(), steps, options)
+}
+
+// RunMigrationWithContext is the same as RunMigration with the addition of the ability to pass a context
+func RunMigrationWithContext(ctx context.Context, steps []MigrationStep, options MigrationOptions) (MigrationReport, error) {
+	report := MigrationReport{Scanned: map[string]int{}, Changed: map[string]int{}}
+	if options.PageSize <= 0 {
+		options.PageSize = defaultMigrationPageSize
+	}
+
+	progress, err := loadMigrationProgress(options.ProgressPath)
+	if err != nil {
+		return report, err
+	}
+
+	var typeNames []string
+	stepsByType := map[string][]MigrationStep{}
+	for _, step := range steps {
+		if _, exists := stepsByType[step.TypeName]; !exists {
+			typeNames = append(typeNames, step.TypeName)
+		}
+		stepsByType[step.TypeName] = append(stepsByType[step.TypeName], step)
+	}
+
+	for _, typeName := range typeNames {
+		stepsKey := getMigrationStepsKey(stepsByType[typeName])
+		if progress.Completed[typeName] == stepsKey {
+			continue
+		}
+		if err = migrateTable(ctx, typeName, stepsByType[typeName], options, &progress, &report); err != nil {
+			return report, fmt.Errorf("migration of %s failed. Error %w", typeName, err)
+		}
+		if options.DryRun {
+			continue
+		}
+		progress.Completed[typeName] = stepsKey
+		delete(progress.LastEvaluatedKeys, typeName)
+		if err = saveMigrationProgress(options.ProgressPath, progress); err != nil {
+			return report, err
+		}
+	}
+	return report, nil
+}
+
+// getMigrationStepsKey returns the key identifying the steps of the type,
+// made of their descriptions and the attributes of the added indexes
+func getMigrationStepsKey(steps []MigrationStep) string {
+	hash := sha256.New()
+	for _, step := range steps {
+		fmt.Fprintf(hash, "%q %q %q %q\n", step.TypeName, step.Description, step.indexAttribute, step.indexAttributeType)
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+func migrateTable(ctx context.Context, typeName string, steps []MigrationStep, options MigrationOptions, progress *migrationProgress, report *MigrationReport) error {
+	var transforms []MigrationStep
+	var indexes []MigrationStep
+	for _, step := range steps {
+		if step.transform != nil {
+			transforms = append(transforms, step)
+		} else {
+			indexes = append(indexes, step)
+		}
+	}
+
+	if len(transforms) > 0 {
+		scanner, ok := dbClient(ctx).(tableScanner)
+		if !ok {
+			return errors.New("the store in use does not support scans")
+		}
+
+		startKey := progress.LastEvaluatedKeys[typeName]
+		for {
+			output, err := scanner.ScanWithContext(ctx, &dynamodb.ScanInput{
+				TableName:         aws.String(getTableName(typeName)),
+				ConsistentRead:    aws.Bool(true),
+				ExclusiveStartKey: startKey,
+				Limit:             aws.Int64(options.PageSize),
+			})
+			if err != nil {
+				return err
+			}
+
+			for _, item := range output.Items {
+				report.Scanned[typeName]++
+				changed, err := migrateItem(ctx, typeName, item, transforms, options.DryRun)
+				if err != nil {
+					return fmt.Errorf("item with id: %s. Error %w", aws.StringValue(item["Id"].S), err)
+				}
+				if changed {
+					report.Changed[typeName]++
+				}
+			}
+
+			startKey = output.LastEvaluatedKey
+			if len(startKey) == 0 {
+				break
+			}
+			if !options.DryRun {
+				progress.LastEvaluatedKeys[typeName] = startKey
+				if err = saveMigrationProgress(options.ProgressPath, *progress); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	if options.DryRun {
+		return nil
+	}
+	for _, index := range indexes {
+		if err := addIndex(ctx, typeName, index); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// migrateItem applies the transforms to the item and saves it, unless it's the dry run.
+// If the item was modified in the meantime, it's read again and transformed once more.
+func migrateItem(ctx context.Context, typeName string, item map[string]*dynamodb.AttributeValue, transforms []MigrationStep, dryRun bool) (bool, error) {
+	for attempt := 0; ; attempt++ {
+		original := make(map[string]*dynamodb.AttributeValue, len(item))
+		for name, value := range item {
+			original[name] = value
+		}
+
+		changed := false
+		for _, step := range transforms {
+			stepChanged, err := step.transform(item)
+			if err != nil {
+				return false, fmt.Errorf("%s failed. Error %w", step.Description, err)
+			}
+			changed = changed || stepChanged
+		}
+		if !changed || dryRun {
+			return changed, nil
+		}
+
+		err := putMigratedItem(ctx, typeName, original, item)
+		if _, ok := err.(*dynamodb.ConditionalCheckFailedException); !ok || attempt == migrationWriteRetries {
+			return err == nil, err
+		}
+
+		output, err := dbClient(ctx).GetItemWithContext(ctx, &dynamodb.GetItemInput{
+			TableName:      aws.String(getTableName(typeName)),
+			Key:            map[string]*dynamodb.AttributeValue{"Id": original["Id"]},
+			ConsistentRead: aws.Bool(true),
+		})
+		if err != nil || output.Item == nil {
+			// the item deleted in the meantime needs no migration
+			return false, err
+		}
+		item = output.Item
+	}
+}
+
+// putMigratedItem replaces the item, provided it was not modified since it was scanned.
+// The version of the versioned types is compared and incremented. The items of the other types are required
+// to hold the scanned values of all their attributes and none of the attributes added by the
+// migration, the attributes the item did not have and the migration did not add are not checked.
+func putMigratedItem(ctx context.Context, typeName string, original, migrated map[string]*dynamodb.AttributeValue) error {
+	condition := expression.AttributeExists(expression.Name("Id"))
+	if version, isVersioned := original[VersionAttributeName]; isVersioned && version.N != nil {
+		condition = condition.And(expression.Name(VersionAttributeName).Equal(expression.Value(version)))
+
+		// the writes of the objects read before the migration fail with ConflictError,
+		// so that they do not overwrite the migrated attributes with the previous ones
+		number, err := strconv.ParseInt(aws.StringValue(version.N), 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid version %s. Error %w", aws.StringValue(version.N), err)
+		}
+		migrated[VersionAttributeName] = &dynamodb.AttributeValue{N: aws.String(strconv.FormatInt(number+1, 10))}
+	} else {
+		condition = condition.And(getUnmodifiedItemCondition(original, migrated))
+	}
+	expr, err := expression.NewBuilder().WithCondition(condition).Build()
+	if err != nil {
+		return fmt.Errorf("error occurred when building dynamodb condition expression %w", err)
+	}
+
+	_, err = dbClient(ctx).PutItemWithContext(ctx, &dynamodb.PutItemInput{
+		TableName:                 aws.String(getTableName(typeName)),
+		Item:                      migrated,
+		ConditionExpression:       expr.Condition(),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+	})
+	return err
+}
+
+// getUnmodifiedItemCondition returns the condition satisfied if the item holds the
+// original values of its attributes and none of the attributes added by the migration
+func getUnmodifiedItemCondition(original, migrated map[string]*dynamodb.AttributeValue) expression.ConditionBuilder {
+	names := make([]string, 0, len(original)+len(migrated))
+	for name := range original {
+		names = append(names, name)
+	}
+	for name := range migrated {
+		if _, exists := original[name]; !exists {
+			names = append(names, name)
+		}
+	}
+	// the conditions are built in the same order every time
+	sort.Strings(names)
+
+	conditions := make([]expression.ConditionBuilder, 0, len(names))
+	for _, name := range names {
+		value, exists := original[name]
+		switch {
+		case !exists:
+			conditions = append(conditions, expression.AttributeNotExists(expression.Name(name)))
+		case aws.BoolValue(value.NULL):
+			conditions = append(conditions, expression.AttributeType(expression.Name(name), expression.Null))
+		default:
+			conditions = append(conditions, expression.Name(name).Equal(expression.Value(value)))
+		}
+	}
+	if len(conditions) == 1 {
+		return conditions[0]
+	}
+	return expression.And(conditions[0], conditions[1], conditions[2:]...)
+}
+
+func addIndex(ctx context.Context, typeName string, step MigrationStep) error {
+	updater, ok := dbClient(ctx).(tableUpdater)
+	if !ok {
+		return fmt.Errorf("the store in use does not support adding the indexes, the table of %s must be created again with the index of %s", typeName, step.indexAttribute)
+	}
+
+	_, err := updater.UpdateTableWithContext(ctx, &dynamodb.UpdateTableInput{
+		TableName: aws.String(getTableName(typeName)),
+		AttributeDefinitions: []*dynamodb.AttributeDefinition{
+			{
+				AttributeName: aws.String(step.indexAttribute),
+				AttributeType: aws.String(step.indexAttributeType),
+			},
+		},
+		GlobalSecondaryIndexUpdates: []*dynamodb.GlobalSecondaryIndexUpdate{
+			{
+				Create: &dynamodb.CreateGlobalSecondaryIndexAction{
+					IndexName: aws.String(typeName + step.indexAttribute),
+					KeySchema: []*dynamodb.KeySchemaElement{
+						{
+							AttributeName: aws.String(step.indexAttribute),
+							KeyType:       aws.String("HASH"),
+						},
+					},
+					Projection: &dynamodb.Projection{
+						ProjectionType: aws.String("KEYS_ONLY"),
+					},
+				},
+			},
+		},
+	})
+	return err
+}
+
+func convertAttributeValue(value *dynamodb.AttributeValue, attributeType string) (*dynamodb.AttributeValue, error) {
+	switch {
+	case value.S != nil:
+		switch attributeType {
+		case StringAttributeType:
+			return value, nil
+		case NumberAttributeType:
+			if _, err := strconv.ParseFloat(strings.TrimSpace(*value.S), 64); err != nil {
+				return nil, err
+			}
+			return &dynamodb.AttributeValue{N: aws.String(strings.TrimSpace(*value.S))}, nil
+		case BoolAttributeType:
+			converted, err := strconv.ParseBool(strings.TrimSpace(*value.S))
+			if err != nil {
+				return nil, err
+			}
+			return &dynamodb.AttributeValue{BOOL: aws.Bool(converted)}, nil
+		}
+	case value.N != nil:
+		switch attributeType {
+		case StringAttributeType:
+			return &dynamodb.AttributeValue{S: value.N}, nil
+		case NumberAttributeType:
+			return value, nil
+		case BoolAttributeType:
+			number, err := strconv.ParseFloat(*value.N, 64)
+			if err != nil {
+				return nil, err
+			}
+			return &dynamodb.AttributeValue{BOOL: aws.Bool(number != 0)}, nil
+		}
+	case value.BOOL != nil:
+		switch attributeType {
+		case StringAttributeType:
+			return &dynamodb.AttributeValue{S: aws.String(strconv.FormatBool(*value.BOOL))}, nil
+		case NumberAttributeType:
+			if *value.BOOL {
+				return &dynamodb.AttributeValue{N: aws.String("1")}, nil
+			}
+			return &dynamodb.AttributeValue{N: aws.String("0")}, nil
+		case BoolAttributeType:
+			return value, nil
+		}
+	default:
+		return nil, errors.New("only strings, numbers and booleans can be converted")
+	}
+	return nil, fmt.Errorf("unknown attribute type %s", attributeType)
+}
+
+func loadMigrationProgress(path string) (migrationProgress, error) {
+	progress := migrationProgress{LastEvaluatedKeys: map[string]map[string]*dynamodb.AttributeValue{}, Completed: map[string]string{}}
+	if path == "" {
+		return progress, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return progress, nil
+	}
+	if err != nil {
+		return progress, err
+	}
+	if err = json.Unmarshal(data, &progress); err != nil {
+		return progress, fmt.Errorf("failed to read the progress of the migration from %s. Error %w", path, err)
+	}
+	if progress.LastEvaluatedKeys == nil {
+		progress.LastEvaluatedKeys = map[string]map[string]*dynamodb.AttributeValue{}
+	}
+	if progress.Completed == nil {
+		progress.Completed = map[string]string{}
+	}
+	return progress, nil
+}
+
+func saveMigrationProgress(path string, progress migrationProgress) error {
+	if path == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(progress, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
@@ -1,5 +1,10 @@
 package lib
 
+import (
+	"reflect"
+	"sync"
+)
+
 type Nobject interface {
 	GetTypeName() string
 }
@@ -7,3 +12,22 @@ type Nobject interface {
 type nobjectInit interface {
 	Init()
 }
+
+// the types of the Nobjects registered with RegisterType by their type names
+var registeredTypes sync.Map
+
+// RegisterType registers the Nobject type T under its type name, so that the operations
+// identifying the objects by the type name only, e.g. SetField, enforce the rules of
+// its fields. The types are registered in the init functions of the shadow packages.
+func RegisterType[T Nobject]() {
+	registeredTypes.Store((*new(T)).GetTypeName(), reflect.TypeOf((*T)(nil)).Elem())
+}
+
+// getRegisteredType returns the type registered under the type name with RegisterType
+func getRegisteredType(typeName string) (reflect.Type, bool) {
+	objType, ok := registeredTypes.Load(typeName)
+	if !ok {
+		return nil, false
+	}
+	return objType.(reflect.Type), true
+}
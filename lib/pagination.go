@@ -0,0 +1,100 @@
+package lib
+
+import (
+	"context"
+
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+// IdsPage is a single page of ids. The Cursor is used to retrieve
+// the next page, it is empty if there are no more pages.
+type IdsPage struct {
+	Ids    []string
+	Cursor string
+}
+
+// queryAllIds runs the query following all the pages of the
+// results and returns the values of the output attribute
+func queryAllIds(ctx context.Context, input *dynamodb.QueryInput, outputAttributeName string) ([]string, error) {
+	outputIds := []string{}
+	for {
+		items, err := dbClient(ctx).QueryWithContext(ctx, input)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, attr := range items.Items {
+			outputIds = append(outputIds, *attr[outputAttributeName].S)
+		}
+
+		if len(items.LastEvaluatedKey) == 0 {
+			return outputIds, nil
+		}
+		input.ExclusiveStartKey = items.LastEvaluatedKey
+	}
+}
+
+// queryIdsPage runs the query from the position denoted by the cursor until limit
+// values of the output attribute are retrieved or there are no more results.
+// The returned page may have the next cursor set even if it is the last one,
+// in such case the next page is empty.
+func queryIdsPage(ctx context.Context, input *dynamodb.QueryInput, outputAttributeName string, limit int, cursor string) (IdsPage, error) {
+	if limit <= 0 {
+		return IdsPage{}, fmt.Errorf("limit must be greater than 0")
+	}
+	startKey, err := decodeCursor(cursor)
+	if err != nil {
+		return IdsPage{}, err
+	}
+	input.ExclusiveStartKey = startKey
+
+	page := IdsPage{Ids: make([]string, 0, limit)}
+	for len(page.Ids) < limit {
+		input.Limit = aws.Int64(int64(limit - len(page.Ids)))
+		items, err := dbClient(ctx).QueryWithContext(ctx, input)
+		if err != nil {
+			return IdsPage{}, err
+		}
+
+		for _, attr := range items.Items {
+			page.Ids = append(page.Ids, *attr[outputAttributeName].S)
+		}
+
+		if len(items.LastEvaluatedKey) == 0 {
+			return page, nil
+		}
+		input.ExclusiveStartKey = items.LastEvaluatedKey
+	}
+
+	page.Cursor, err = encodeCursor(input.ExclusiveStartKey)
+	return page, err
+}
+
+func encodeCursor(key map[string]*dynamodb.AttributeValue) (string, error) {
+	encoded, err := json.Marshal(key)
+	if err != nil {
+		return "", fmt.Errorf("error occurred when encoding the cursor %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(encoded), nil
+}
+
+func decodeCursor(cursor string) (map[string]*dynamodb.AttributeValue, error) {
+	if cursor == "" {
+		return nil, nil
+	}
+
+	decoded, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cursor")
+	}
+	var key map[string]*dynamodb.AttributeValue
+	if err = json.Unmarshal(decoded, &key); err != nil || len(key) == 0 {
+		return nil, fmt.Errorf("invalid cursor")
+	}
+	return key, nil
+}
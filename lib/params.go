@@ -42,6 +42,25 @@ func (a DeleteFromManyToManyParam) Verify() error {
 	return nil
 }
 
+type ReferenceGetPageParam struct {
+	RefNavListParam ReferenceNavigationListParam
+	// Maximum number of ids in the page
+	Limit int
+	// Cursor returned with the previous page,
+	// empty to retrieve the first page
+	Cursor string
+}
+
+func (a ReferenceGetPageParam) Verify() error {
+	if err := a.RefNavListParam.Verify(); err != nil {
+		return err
+	}
+	if a.Limit <= 0 {
+		return fmt.Errorf("Limit must be greater than 0")
+	}
+	return nil
+}
+
 type LoadBatchParam struct {
 	TypeName string
 	Ids      []string
@@ -81,6 +100,13 @@ type SetFieldParam struct {
 	FieldName string
 	TypeName  string
 	Value     interface{}
+	// Versioned indicates that the type of the object
+	// implements Versioned, the version is then incremented
+	Versioned bool
+	// ExpectedVersion is optional, if set the field is updated
+	// only if the stored version is equal to it, otherwise
+	// ConflictError is returned
+	ExpectedVersion *int
 }
 
 func (s SetFieldParam) Validate() error {
@@ -166,3 +192,23 @@ func (q GetBatchParam) Validate() error {
 	}
 	return nil
 }
+
+type FindByParam struct {
+	TypeName string
+	// Name of the field tagged with nubes:"index"
+	FieldName string
+	Value     interface{}
+}
+
+func (q FindByParam) Validate() error {
+	if q.TypeName == "" {
+		return fmt.Errorf("missing TypeName")
+	}
+	if q.FieldName == "" {
+		return fmt.Errorf("missing FieldName")
+	}
+	if q.Value == nil {
+		return fmt.Errorf("missing Value")
+	}
+	return nil
+}
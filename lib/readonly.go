@@ -0,0 +1,113 @@
+package lib
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// ReadonlyFieldError is returned when a field tagged
+// with `nubes:"readonly"` is to be modified
+type ReadonlyFieldError struct {
+	TypeName  string
+	FieldName string
+}
+
+func (r ReadonlyFieldError) Error() string {
+	return fmt.Sprintf("field %s of %s is readonly, it can be set only when the object is exported", r.FieldName, r.TypeName)
+}
+
+// storedAttributes describes the DB attributes of the fields of a struct type
+type storedAttributes struct {
+	// all the attribute names, the fields ignored with `dynamodbav:"-"` are skipped
+	names []string
+	// readonlyFields maps the names of the readonly fields to the names of their attributes
+	readonlyFields map[string]string
+}
+
+// the attributes of the struct types, determined once per type
+var storedAttributesByType sync.Map
+
+// CheckFieldIsWritable returns ReadonlyFieldError if the field
+// of the given struct type is tagged with `nubes:"readonly"`
+func CheckFieldIsWritable(obj interface{}, fieldName string) error {
+	objType := reflect.Indirect(reflect.ValueOf(obj)).Type()
+	if objType.Kind() != reflect.Struct {
+		return nil
+	}
+
+	if _, isReadonly := getStoredAttributes(objType).readonlyFields[fieldName]; isReadonly {
+		return ReadonlyFieldError{TypeName: getObjectTypeName(obj, objType), FieldName: fieldName}
+	}
+	return nil
+}
+
+// getReadonlyAttributes returns the names of the attributes of the readonly fields of the object
+func getReadonlyAttributes(obj interface{}) map[string]bool {
+	objType := reflect.Indirect(reflect.ValueOf(obj)).Type()
+	if objType.Kind() != reflect.Struct {
+		return nil
+	}
+
+	readonlyFields := getStoredAttributes(objType).readonlyFields
+	if len(readonlyFields) == 0 {
+		return nil
+	}
+	result := make(map[string]bool, len(readonlyFields))
+	for _, attributeName := range readonlyFields {
+		result[attributeName] = true
+	}
+	return result
+}
+
+func getStoredAttributes(structType reflect.Type) storedAttributes {
+	if cached, ok := storedAttributesByType.Load(structType); ok {
+		return cached.(storedAttributes)
+	}
+
+	result := storedAttributes{readonlyFields: map[string]string{}}
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		attributeName, isStored := getAttributeName(field)
+		if !isStored {
+			continue
+		}
+
+		result.names = append(result.names, attributeName)
+		if isReadonlyTag(field.Tag.Get("nubes")) {
+			result.readonlyFields[field.Name] = attributeName
+		}
+	}
+	storedAttributesByType.Store(structType, result)
+	return result
+}
+
+// getAttributeName returns the name of the DB attribute of the field,
+// the second return value is false if the field is not stored
+func getAttributeName(field reflect.StructField) (string, bool) {
+	if !field.IsExported() {
+		return "", false
+	}
+
+	attributeName := field.Name
+	if dynamodbTag, exists := field.Tag.Lookup("dynamodbav"); exists {
+		name, _, _ := strings.Cut(dynamodbTag, ",")
+		if name == "-" {
+			return "", false
+		}
+		if name != "" {
+			attributeName = name
+		}
+	}
+	return attributeName, true
+}
+
+func isReadonlyTag(tag string) bool {
+	for _, option := range strings.Split(tag, ",") {
+		if strings.EqualFold(strings.TrimSpace(option), "readonly") {
+			return true
+		}
+	}
+	return false
+}
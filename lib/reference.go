@@ -1,5 +1,10 @@
 package lib
 
+import (
+	"context"
+	"reflect"
+)
+
 type Reference[T Nobject] string
 
 func NewReference[T Nobject](id string) *Reference[T] {
@@ -11,12 +16,29 @@ func (r Reference[T]) Id() string {
 	return string(r)
 }
 
+// referencedObjects returns the type and the id of the referenced object,
+// it's used to check if the referenced object exists
+func (r Reference[T]) referencedObjects() (reflect.Type, []string) {
+	if r == "" {
+		return reflect.TypeOf((*T)(nil)).Elem(), nil
+	}
+	return reflect.TypeOf((*T)(nil)).Elem(), []string{string(r)}
+}
+
 func (r Reference[T]) Get() (*T, error) {
-	return Load[T](string(r))
+	return r.GetWithContext(InvocationContext())
+}
+
+func (r Reference[T]) GetWithContext(ctx context.Context) (*T, error) {
+	return LoadWithContext[T](ctx, string(r))
 }
 
 func (r Reference[T]) GetStub() (*T, error) {
+	return r.GetStubWithContext(InvocationContext())
+}
+
+func (r Reference[T]) GetStubWithContext(ctx context.Context) (*T, error) {
 	object := new(T)
-	err := GetStub(string(r), object)
+	err := GetStubWithContext(ctx, string(r), object)
 	return object, err
 }
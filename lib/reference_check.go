@@ -0,0 +1,234 @@
+package lib
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/Astenna/Nubes/lib/internal/dynamoexpr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+// the option of nubes tag of the Reference and ReferenceList fields
+// whose referenced objects are not checked to exist
+const noReferenceCheckTagOption = "noReferenceCheck"
+
+// ReferenceNotFoundError is returned when the Reference or
+// ReferenceList field refers to objects that do not exist
+type ReferenceNotFoundError struct {
+	TypeName           string
+	FieldName          string
+	ReferencedTypeName string
+	// Ids of the missing objects
+	Ids []string
+}
+
+func (r ReferenceNotFoundError) Error() string {
+	return fmt.Sprintf("field %s of %s refers to instances of %s that do not exist, ids: %s",
+		r.FieldName, r.TypeName, r.ReferencedTypeName, strings.Join(r.Ids, ", "))
+}
+
+// reference is implemented by Reference and ReferenceList
+type reference interface {
+	referencedObjects() (reflect.Type, []string)
+}
+
+type referenceField struct {
+	fieldIndex         int
+	fieldName          string
+	attributeName      string
+	referencedTypeName string
+}
+
+// the checked reference fields of the struct types, determined once per type
+var referenceFieldsByType sync.Map
+
+// CheckReferences returns ReferenceNotFoundError if any Reference or ReferenceList
+// field of the struct, or a pointer to it, refers to objects that do not exist.
+// The fields tagged with `nubes:"noReferenceCheck"` are not checked.
+func CheckReferences(obj interface{}) error {
+	return CheckReferencesWithContext(InvocationContext(), obj)
+}
+
+// CheckReferencesWithContext is the same as CheckReferences with the addition of the ability to pass a context
+func CheckReferencesWithContext(ctx context.Context, obj interface{}) error {
+	value := reflect.Indirect(reflect.ValueOf(obj))
+	if value.Kind() != reflect.Struct {
+		return nil
+	}
+
+	fields := getReferenceFields(value.Type())
+	return checkReferencedIds(ctx, getObjectTypeName(obj, value.Type()), fields, func(field referenceField) []string {
+		return getFieldReferencedIds(value, field)
+	})
+}
+
+// CheckFieldReferences returns ReferenceNotFoundError if the value to be set in the
+// Reference or ReferenceList field of the given struct type refers to objects that
+// do not exist. The value can be given as the ids, e.g. decoded from JSON.
+func CheckFieldReferences(obj interface{}, fieldName string, value interface{}) error {
+	return CheckFieldReferencesWithContext(InvocationContext(), obj, fieldName, value)
+}
+
+// CheckFieldReferencesWithContext is the same as CheckFieldReferences with the addition of the ability to pass a context
+func CheckFieldReferencesWithContext(ctx context.Context, obj interface{}, fieldName string, value interface{}) error {
+	objType := reflect.Indirect(reflect.ValueOf(obj)).Type()
+	if objType.Kind() != reflect.Struct {
+		return nil
+	}
+
+	for _, field := range getReferenceFields(objType) {
+		if field.fieldName == fieldName {
+			return checkReferencedIds(ctx, getObjectTypeName(obj, objType), []referenceField{field}, func(referenceField) []string {
+				return getReferencedIds(reflect.ValueOf(value))
+			})
+		}
+	}
+	return nil
+}
+
+// checkChangedReferences checks the references of the fields
+// whose attributes differ from the snapshot
+func checkChangedReferences(ctx context.Context, obj Nobject, snapshot, current map[string]*dynamodb.AttributeValue) error {
+	value := reflect.Indirect(reflect.ValueOf(obj))
+	if value.Kind() != reflect.Struct {
+		return nil
+	}
+
+	var changed []referenceField
+	for _, field := range getReferenceFields(value.Type()) {
+		previous, wasSet := snapshot[field.attributeName]
+		currentValue, isSet := current[field.attributeName]
+		if wasSet != isSet || (isSet && !dynamoexpr.Equal(previous, currentValue)) {
+			changed = append(changed, field)
+		}
+	}
+	return checkReferencedIds(ctx, obj.GetTypeName(), changed, func(field referenceField) []string {
+		return getFieldReferencedIds(value, field)
+	})
+}
+
+func getReferenceFields(structType reflect.Type) []referenceField {
+	if cached, ok := referenceFieldsByType.Load(structType); ok {
+		return cached.([]referenceField)
+	}
+
+	var result []referenceField
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		attributeName, isStored := getAttributeName(field)
+		if !isStored || hasTagOption(field.Tag.Get("nubes"), noReferenceCheckTagOption) {
+			continue
+		}
+		ref, isReference := reflect.Zero(field.Type).Interface().(reference)
+		if !isReference {
+			continue
+		}
+
+		referencedType, _ := ref.referencedObjects()
+		if nobject, ok := reflect.New(referencedType).Interface().(Nobject); ok {
+			result = append(result, referenceField{
+				fieldIndex:         i,
+				fieldName:          field.Name,
+				attributeName:      attributeName,
+				referencedTypeName: nobject.GetTypeName(),
+			})
+		}
+	}
+	referenceFieldsByType.Store(structType, result)
+	return result
+}
+
+// checkReferencedIds checks the ids referenced by the fields with one batch
+// of reads per referenced type. The objects put in the transaction in progress
+// are considered to exist. ReferenceNotFoundError is returned for the first
+// field referring to missing objects.
+func checkReferencedIds(ctx context.Context, typeName string, fields []referenceField, idsOf func(referenceField) []string) error {
+	idsByField := make([][]string, len(fields))
+	idsByType := map[string][]string{}
+	tx := transactionFromContext(ctx)
+	for i, field := range fields {
+		idsByField[i] = distinct(idsOf(field))
+		for _, id := range idsByField[i] {
+			if tx == nil || !tx.isPut(getTableName(field.referencedTypeName), id) {
+				idsByType[field.referencedTypeName] = append(idsByType[field.referencedTypeName], id)
+			}
+		}
+	}
+
+	missing := map[string]bool{}
+	for referencedTypeName, ids := range idsByType {
+		err := AreInstancesAlreadyCreatedWithContext(ctx, LoadBatchParam{TypeName: referencedTypeName, Ids: distinct(ids)})
+		var notFoundErr NotFoundError
+		if errors.As(err, &notFoundErr) {
+			for _, id := range notFoundErr.Ids {
+				missing[referencedTypeName+"/"+id] = true
+			}
+		} else if err != nil {
+			return fmt.Errorf("error occurred while checking if the instances of %s exist. Error %w", referencedTypeName, err)
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+
+	for i, field := range fields {
+		var missingIds []string
+		for _, id := range idsByField[i] {
+			if missing[field.referencedTypeName+"/"+id] {
+				missingIds = append(missingIds, id)
+			}
+		}
+		if len(missingIds) > 0 {
+			return ReferenceNotFoundError{TypeName: typeName, FieldName: field.fieldName, ReferencedTypeName: field.referencedTypeName, Ids: missingIds}
+		}
+	}
+	return nil
+}
+
+func getFieldReferencedIds(value reflect.Value, field referenceField) []string {
+	_, ids := value.Field(field.fieldIndex).Interface().(reference).referencedObjects()
+	result := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if id != "" {
+			result = append(result, id)
+		}
+	}
+	return result
+}
+
+// getReferencedIds returns the non empty ids of the string or the slice
+// of strings, e.g. the value of the field decoded from JSON
+func getReferencedIds(value reflect.Value) []string {
+	for value.IsValid() && (value.Kind() == reflect.Pointer || value.Kind() == reflect.Interface) {
+		if value.IsNil() {
+			return nil
+		}
+		value = value.Elem()
+	}
+
+	var ids []string
+	switch value.Kind() {
+	case reflect.String:
+		if value.Len() > 0 {
+			ids = append(ids, value.String())
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < value.Len(); i++ {
+			ids = append(ids, getReferencedIds(value.Index(i))...)
+		}
+	}
+	return ids
+}
+
+func hasTagOption(tag, option string) bool {
+	for _, tagOption := range strings.Split(tag, ",") {
+		if strings.TrimSpace(tagOption) == option {
+			return true
+		}
+	}
+	return false
+}
@@ -1,6 +1,10 @@
 package lib
 
-import "fmt"
+import (
+	"context"
+	"fmt"
+	"reflect"
+)
 
 type ReferenceList[T Nobject] []string
 
@@ -13,16 +17,30 @@ func (r ReferenceList[T]) GetIds() []string {
 	return []string(r)
 }
 
+// referencedObjects returns the type and the ids of the referenced objects,
+// it's used to check if the referenced objects exist
+func (r ReferenceList[T]) referencedObjects() (reflect.Type, []string) {
+	return reflect.TypeOf((*T)(nil)).Elem(), r
+}
+
 func (r ReferenceList[T]) Get() ([]*T, error) {
-	res, err := LoadBatch[T](r)
+	return r.GetWithContext(InvocationContext())
+}
+
+func (r ReferenceList[T]) GetWithContext(ctx context.Context) ([]*T, error) {
+	res, err := LoadBatchWithContext[T](ctx, r)
 	return res, err
 }
 
 func (r ReferenceList[T]) GetAt(index int) (*T, error) {
+	return r.GetAtWithContext(InvocationContext(), index)
+}
+
+func (r ReferenceList[T]) GetAtWithContext(ctx context.Context, index int) (*T, error) {
 	if len(r)-1 < index || index < 0 {
 		return nil, fmt.Errorf("provided index: %d is out of bounds of the list", index)
 	}
-	instance, err := Load[T](r[index])
+	instance, err := LoadWithContext[T](ctx, r[index])
 	if err != nil {
 		return nil, fmt.Errorf("could not retrieve object with id: %d. Error: %w", index, err)
 	}
@@ -31,7 +49,11 @@ func (r ReferenceList[T]) GetAt(index int) (*T, error) {
 }
 
 func (r ReferenceList[T]) GetStubs() ([]T, error) {
-	batch, err := GetStubsInBatch[T](r.GetIds())
+	return r.GetStubsWithContext(InvocationContext())
+}
+
+func (r ReferenceList[T]) GetStubsWithContext(ctx context.Context) ([]T, error) {
+	batch, err := GetStubsInBatchWithContext[T](ctx, r.GetIds())
 
 	if err != nil {
 		return nil, fmt.Errorf("error occurred while retriving the objects from DB: %w", err)
@@ -40,12 +62,16 @@ func (r ReferenceList[T]) GetStubs() ([]T, error) {
 }
 
 func (r ReferenceList[T]) GetStubAt(index int) (*T, error) {
+	return r.GetStubAtWithContext(InvocationContext(), index)
+}
+
+func (r ReferenceList[T]) GetStubAtWithContext(ctx context.Context, index int) (*T, error) {
 	if len(r)-1 < index || index < 0 {
 		return nil, fmt.Errorf("provided index: %d is out of bounds of the list", index)
 	}
 
 	instance := new(T)
-	err := GetStub(r[index], instance)
+	err := GetStubWithContext(ctx, r[index], instance)
 	if err != nil {
 		return nil, fmt.Errorf("could not retrieve object with id: %d. Error: %w", index, err)
 	}
@@ -1,7 +1,9 @@
 package lib
 
 import (
+	"context"
 	"fmt"
+	"reflect"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/dynamodb"
@@ -43,10 +45,20 @@ func NewReferenceNavigationList[T Nobject](param ReferenceNavigationListParam) *
 	return r
 }
 
+// referencedType returns the type of the objects in the relationship,
+// it's used to apply the delete policies given in the tags of the fields
+func (r ReferenceNavigationList[T]) referencedType() reflect.Type {
+	return reflect.TypeOf((*T)(nil)).Elem()
+}
+
 func (r ReferenceNavigationList[T]) GetIds() ([]string, error) {
+	return r.GetIdsWithContext(InvocationContext())
+}
+
+func (r ReferenceNavigationList[T]) GetIdsWithContext(ctx context.Context) ([]string, error) {
 
 	if r.setup.UsesIndex {
-		out, err := GetByIndex(r.setup.GetQueryByIndexParam())
+		out, err := GetByIndexWithContext(ctx, r.setup.GetQueryByIndexParam())
 		return out, err
 	}
 
@@ -55,7 +67,7 @@ func (r ReferenceNavigationList[T]) GetIds() ([]string, error) {
 		if err != nil {
 			return nil, err
 		}
-		out, err := GetSortKeysByPartitionKey(input)
+		out, err := GetSortKeysByPartitionKeyWithContext(ctx, input)
 		return out, err
 	}
 
@@ -63,7 +75,11 @@ func (r ReferenceNavigationList[T]) GetIds() ([]string, error) {
 }
 
 func (r ReferenceNavigationList[T]) Get() ([]*T, error) {
-	ids, err := r.GetIds()
+	return r.GetWithContext(InvocationContext())
+}
+
+func (r ReferenceNavigationList[T]) GetWithContext(ctx context.Context) ([]*T, error) {
+	ids, err := r.GetIdsWithContext(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -71,15 +87,19 @@ func (r ReferenceNavigationList[T]) Get() ([]*T, error) {
 		return []*T{}, nil
 	}
 
-	res, err := LoadBatch[T](ids)
+	res, err := LoadBatchWithContext[T](ctx, ids)
 	return res, err
 }
 
 func (r ReferenceNavigationList[T]) GetStubs() ([]T, error) {
+	return r.GetStubsWithContext(InvocationContext())
+}
+
+func (r ReferenceNavigationList[T]) GetStubsWithContext(ctx context.Context) ([]T, error) {
 	var ids []string
 	var err error
 	if r.setup.UsesIndex {
-		ids, err = GetByIndex(r.setup.GetQueryByIndexParam())
+		ids, err = GetByIndexWithContext(ctx, r.setup.GetQueryByIndexParam())
 		if err != nil {
 			return nil, err
 		}
@@ -88,7 +108,7 @@ func (r ReferenceNavigationList[T]) GetStubs() ([]T, error) {
 		if err != nil {
 			return nil, err
 		}
-		ids, err = GetSortKeysByPartitionKey(input)
+		ids, err = GetSortKeysByPartitionKeyWithContext(ctx, input)
 		if err != nil {
 			return nil, err
 		}
@@ -96,7 +116,7 @@ func (r ReferenceNavigationList[T]) GetStubs() ([]T, error) {
 		return nil, fmt.Errorf("invalid initialization of ReferenceNavigationList")
 	}
 
-	batch, err := GetStubsInBatch[T](ids)
+	batch, err := GetStubsInBatchWithContext[T](ctx, ids)
 
 	if err != nil {
 		return nil, fmt.Errorf("error occurred while retriving the objects from DB: %w", err)
@@ -104,7 +124,32 @@ func (r ReferenceNavigationList[T]) GetStubs() ([]T, error) {
 	return *batch, err
 }
 
+// GetPage returns at most limit objects of the relationship starting from the
+// cursor returned with the previous page, the empty cursor denotes the first page.
+// The returned cursor is empty if there are no more objects.
+func (r ReferenceNavigationList[T]) GetPage(limit int, cursor string) ([]*T, string, error) {
+	return r.GetPageWithContext(InvocationContext(), limit, cursor)
+}
+
+// GetPageWithContext is the same as GetPage with the addition of the ability to pass a context
+func (r ReferenceNavigationList[T]) GetPageWithContext(ctx context.Context, limit int, cursor string) ([]*T, string, error) {
+	page, err := r.setup.getIdsPage(ctx, limit, cursor)
+	if err != nil {
+		return nil, "", err
+	}
+	if len(page.Ids) == 0 {
+		return []*T{}, page.Cursor, nil
+	}
+
+	res, err := LoadBatchWithContext[T](ctx, page.Ids)
+	return res, page.Cursor, err
+}
+
 func (r ReferenceNavigationList[T]) AddToManyToMany(newId string) error {
+	return r.AddToManyToManyWithContext(InvocationContext(), newId)
+}
+
+func (r ReferenceNavigationList[T]) AddToManyToManyWithContext(ctx context.Context, newId string) error {
 
 	if newId == "" {
 		return fmt.Errorf("missing id")
@@ -113,7 +158,7 @@ func (r ReferenceNavigationList[T]) AddToManyToMany(newId string) error {
 	if r.setup.IsManyToMany {
 
 		typeName := (*new(T)).GetTypeName()
-		exists, err := IsInstanceAlreadyCreated(IsInstanceAlreadyCreatedParam{Id: newId, TypeName: typeName})
+		exists, err := IsInstanceAlreadyCreatedWithContext(ctx, IsInstanceAlreadyCreatedParam{Id: newId, TypeName: typeName})
 		if err != nil {
 			return fmt.Errorf("error occurred while checking if typename %s with id %s exists. Error %w", typeName, newId, err)
 		}
@@ -121,7 +166,7 @@ func (r ReferenceNavigationList[T]) AddToManyToMany(newId string) error {
 			return fmt.Errorf("only existing instances can be added to many to many relationships. Typename %s with id %s not found", typeName, newId)
 		}
 
-		return InsertToManyToManyTable(r.setup.GetInsertToManyToManyTableParam(newId))
+		return InsertToManyToManyTableWithContext(ctx, r.setup.GetInsertToManyToManyTableParam(newId))
 	}
 
 	return fmt.Errorf(`can not add elements to ReferenceNavigationList used as OneToMany relationship. 
@@ -129,12 +174,16 @@ func (r ReferenceNavigationList[T]) AddToManyToMany(newId string) error {
 }
 
 func (r ReferenceNavigationList[T]) DeleteBatchFromManyToMany(ids []string) error {
+	return r.DeleteBatchFromManyToManyWithContext(InvocationContext(), ids)
+}
+
+func (r ReferenceNavigationList[T]) DeleteBatchFromManyToManyWithContext(ctx context.Context, ids []string) error {
 	if len(ids) == 0 {
 		return fmt.Errorf("missing ids of objects to delete")
 	}
 
 	param := r.setup.GetDeleteFromManyToManyParam(ids)
-	return DeleteFromManyToManyTable(param)
+	return DeleteFromManyToManyTableWithContext(ctx, param)
 }
 
 type InsertToManyToManyTableLibParam struct {
@@ -145,9 +194,13 @@ type InsertToManyToManyTableLibParam struct {
 }
 
 func InsertToManyToManyTable(param InsertToManyToManyTableLibParam) error {
+	return InsertToManyToManyTableWithContext(InvocationContext(), param)
+}
+
+func InsertToManyToManyTableWithContext(ctx context.Context, param InsertToManyToManyTableLibParam) error {
 
 	input := &dynamodb.PutItemInput{
-		TableName: aws.String(param.PartitionKeyName + param.SortKeyName),
+		TableName: aws.String(getTableName(param.PartitionKeyName + param.SortKeyName)),
 		Item: map[string]*dynamodb.AttributeValue{
 			param.PartitionKeyName: {
 				S: aws.String(param.PartitionKeyValue),
@@ -158,20 +211,20 @@ func InsertToManyToManyTable(param InsertToManyToManyTableLibParam) error {
 		},
 	}
 
-	_, err := dbClient.PutItem(input)
+	_, err := dbClient(ctx).PutItemWithContext(ctx, input)
 	return err
 }
 
 func DeleteFromManyToManyTable(param DeleteFromManyToManyLibParam) error {
+	return DeleteFromManyToManyTableWithContext(InvocationContext(), param)
+}
 
-	input := dynamodb.BatchWriteItemInput{
-		RequestItems: map[string][]*dynamodb.WriteRequest{
-			param.TableName: {},
-		},
-	}
+func DeleteFromManyToManyTableWithContext(ctx context.Context, param DeleteFromManyToManyLibParam) error {
+
+	requests := make([]*dynamodb.WriteRequest, 0, len(param.IdsToDelete))
 	if param.AreIdsToDeletePartitionKeys {
 		for _, id := range param.IdsToDelete {
-			input.RequestItems[param.TableName] = append(input.RequestItems[param.TableName],
+			requests = append(requests,
 				&dynamodb.WriteRequest{
 					DeleteRequest: &dynamodb.DeleteRequest{
 						Key: map[string]*dynamodb.AttributeValue{
@@ -188,7 +241,7 @@ func DeleteFromManyToManyTable(param DeleteFromManyToManyLibParam) error {
 
 	} else {
 		for _, id := range param.IdsToDelete {
-			input.RequestItems[param.TableName] = append(input.RequestItems[param.TableName],
+			requests = append(requests,
 				&dynamodb.WriteRequest{
 					DeleteRequest: &dynamodb.DeleteRequest{
 						Key: map[string]*dynamodb.AttributeValue{
@@ -204,6 +257,5 @@ func DeleteFromManyToManyTable(param DeleteFromManyToManyLibParam) error {
 		}
 	}
 
-	_, err := dbClient.BatchWriteItem(&input)
-	return err
+	return writeInBatches(ctx, param.TableName, requests)
 }
@@ -1,6 +1,7 @@
 package lib
 
 import (
+	"context"
 	"fmt"
 )
 
@@ -15,9 +16,13 @@ func NewReferenceNavigationListHandlers(param ReferenceNavigationListParam) *Ref
 }
 
 func (r ReferenceNavigationListHandlers) GetIds() ([]string, error) {
+	return r.GetIdsWithContext(InvocationContext())
+}
+
+func (r ReferenceNavigationListHandlers) GetIdsWithContext(ctx context.Context) ([]string, error) {
 
 	if r.setup.UsesIndex {
-		out, err := GetByIndex(r.setup.GetQueryByIndexParam())
+		out, err := GetByIndexWithContext(ctx, r.setup.GetQueryByIndexParam())
 		return out, err
 	}
 
@@ -26,7 +31,7 @@ func (r ReferenceNavigationListHandlers) GetIds() ([]string, error) {
 		if err != nil {
 			return nil, err
 		}
-		out, err := GetSortKeysByPartitionKey(input)
+		out, err := GetSortKeysByPartitionKeyWithContext(ctx, input)
 		return out, err
 	}
 
@@ -34,14 +39,18 @@ func (r ReferenceNavigationListHandlers) GetIds() ([]string, error) {
 }
 
 func (r ReferenceNavigationListHandlers) Get() ([]string, error) {
-	ids, err := r.GetIds()
+	return r.GetWithContext(InvocationContext())
+}
+
+func (r ReferenceNavigationListHandlers) GetWithContext(ctx context.Context) ([]string, error) {
+	ids, err := r.GetIdsWithContext(ctx)
 	if err != nil {
 		return nil, err
 	}
 	if len(ids) == 0 {
 		return nil, nil
 	}
-	err = AreInstancesAlreadyCreated(LoadBatchParam{
+	err = AreInstancesAlreadyCreatedWithContext(ctx, LoadBatchParam{
 		TypeName: r.setup.otherTypeName,
 		Ids:      ids,
 	})
@@ -49,7 +58,11 @@ func (r ReferenceNavigationListHandlers) Get() ([]string, error) {
 }
 
 func (r ReferenceNavigationListHandlers) GetStubs() ([]interface{}, error) {
-	ids, err := r.GetIds()
+	return r.GetStubsWithContext(InvocationContext())
+}
+
+func (r ReferenceNavigationListHandlers) GetStubsWithContext(ctx context.Context) ([]interface{}, error) {
+	ids, err := r.GetIdsWithContext(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -57,13 +70,25 @@ func (r ReferenceNavigationListHandlers) GetStubs() ([]interface{}, error) {
 		return nil, nil
 	}
 
-	return GetStubsInBatchWithTypeNameAsArg(GetBatchParam{
+	return GetStubsInBatchWithTypeNameAsArgWithContext(ctx, GetBatchParam{
 		Ids:      ids,
 		TypeName: r.setup.otherTypeName,
 	})
 }
 
+func (r ReferenceNavigationListHandlers) GetPage(limit int, cursor string) (IdsPage, error) {
+	return r.GetPageWithContext(InvocationContext(), limit, cursor)
+}
+
+func (r ReferenceNavigationListHandlers) GetPageWithContext(ctx context.Context, limit int, cursor string) (IdsPage, error) {
+	return r.setup.getIdsPage(ctx, limit, cursor)
+}
+
 func (r ReferenceNavigationListHandlers) AddToManyToMany(newId string) error {
+	return r.AddToManyToManyWithContext(InvocationContext(), newId)
+}
+
+func (r ReferenceNavigationListHandlers) AddToManyToManyWithContext(ctx context.Context, newId string) error {
 
 	if newId == "" {
 		return fmt.Errorf("missing id")
@@ -72,7 +97,7 @@ func (r ReferenceNavigationListHandlers) AddToManyToMany(newId string) error {
 	if r.setup.IsManyToMany {
 
 		typeName := r.setup.ownerTypeName
-		exists, err := IsInstanceAlreadyCreated(IsInstanceAlreadyCreatedParam{Id: newId, TypeName: r.setup.otherTypeName})
+		exists, err := IsInstanceAlreadyCreatedWithContext(ctx, IsInstanceAlreadyCreatedParam{Id: newId, TypeName: r.setup.otherTypeName})
 		if err != nil {
 			return fmt.Errorf("error occurred while checking if typename %s with id %s exists. Error %w", typeName, newId, err)
 		}
@@ -80,7 +105,7 @@ func (r ReferenceNavigationListHandlers) AddToManyToMany(newId string) error {
 			return fmt.Errorf("only existing instances can be added to many to many relationships. Typename %s with id %s not found", typeName, newId)
 		}
 
-		return InsertToManyToManyTable(r.setup.GetInsertToManyToManyTableParam(newId))
+		return InsertToManyToManyTableWithContext(ctx, r.setup.GetInsertToManyToManyTableParam(newId))
 	}
 
 	return fmt.Errorf(`can not add elements to ReferenceNavigationListHandlers used as OneToMany relationship. 
@@ -88,10 +113,14 @@ func (r ReferenceNavigationListHandlers) AddToManyToMany(newId string) error {
 }
 
 func (r ReferenceNavigationListHandlers) DeleteBatchFromManyToMany(ids []string) error {
+	return r.DeleteBatchFromManyToManyWithContext(InvocationContext(), ids)
+}
+
+func (r ReferenceNavigationListHandlers) DeleteBatchFromManyToManyWithContext(ctx context.Context, ids []string) error {
 	if len(ids) == 0 {
 		return fmt.Errorf("missing ids of objects to delete")
 	}
 
 	param := r.setup.GetDeleteFromManyToManyParam(ids)
-	return DeleteFromManyToManyTable(param)
+	return DeleteFromManyToManyTableWithContext(ctx, param)
 }
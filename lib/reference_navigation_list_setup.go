@@ -1,6 +1,9 @@
 package lib
 
-import "fmt"
+import (
+	"context"
+	"fmt"
+)
 
 type referenceNavigationListSetup struct {
 	ownerId            string
@@ -100,6 +103,19 @@ func (r referenceNavigationListSetup) GetQueryByPartitionKeyParam() (QueryByPart
 	return result, nil
 }
 
+// getIdsPage returns a single page of ids of the objects in the relationship
+func (r referenceNavigationListSetup) getIdsPage(ctx context.Context, limit int, cursor string) (IdsPage, error) {
+	if r.UsesIndex {
+		return GetByIndexPageWithContext(ctx, r.GetQueryByIndexParam(), limit, cursor)
+	}
+
+	input, err := r.GetQueryByPartitionKeyParam()
+	if err != nil {
+		return IdsPage{}, err
+	}
+	return GetSortKeysByPartitionKeyPageWithContext(ctx, input, limit, cursor)
+}
+
 func (r referenceNavigationListSetup) GetInsertToManyToManyTableParam(newId string) InsertToManyToManyTableLibParam {
 	result := InsertToManyToManyTableLibParam{}
 
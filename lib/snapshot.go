@@ -0,0 +1,152 @@
+package lib
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/Astenna/Nubes/lib/internal/dynamoexpr"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/aws/aws-sdk-go/service/dynamodb/expression"
+)
+
+// Snapshot is the state of an object at the time it was retrieved
+// from the DB, in the form of DynamoDB attributes. It makes it possible
+// to save only the attributes modified since then with SaveChanges.
+type Snapshot map[string]*dynamodb.AttributeValue
+
+// GetStubWithSnapshot retrieves the state of the object like GetStub
+// and sets the snapshot to the retrieved state
+func GetStubWithSnapshot[T Nobject](id string, object *T, snapshot *Snapshot) error {
+	return GetStubWithSnapshotWithContext[T](InvocationContext(), id, object, snapshot)
+}
+
+// GetStubWithSnapshotWithContext is the same as GetStubWithSnapshot with the addition of the ability to pass a context
+func GetStubWithSnapshotWithContext[T Nobject](ctx context.Context, id string, object *T, snapshot *Snapshot) error {
+	if err := GetStubWithContext(ctx, id, object); err != nil {
+		return err
+	}
+
+	attributeVals, err := dynamodbattribute.MarshalMap(object)
+	if err != nil {
+		return err
+	}
+	*snapshot = attributeVals
+	return nil
+}
+
+// SaveChanges saves the attributes of the object modified since the snapshot was taken,
+// the attributes missing in the current state of the object are removed.
+// If the snapshot is nil, the whole object is saved with Upsert.
+// As opposed to Upsert, the object is not created if it does not exist.
+func SaveChanges(objToSave Nobject, id string, snapshot Snapshot) error {
+	return SaveChangesWithContext(InvocationContext(), objToSave, id, snapshot)
+}
+
+// SaveChangesWithContext is the same as SaveChanges with the addition of the ability to pass a context
+func SaveChangesWithContext(ctx context.Context, objToSave Nobject, id string, snapshot Snapshot) error {
+	if snapshot == nil {
+		return UpsertWithContext(ctx, objToSave, id)
+	}
+
+	attributeVals, err := dynamodbattribute.MarshalMap(objToSave)
+	if err != nil {
+		return err
+	}
+
+	update, isModified := getChangedAttributesUpdate(snapshot, attributeVals, getReadonlyAttributes(objToSave))
+	if !isModified {
+		return nil
+	}
+	if err = checkChangedReferences(ctx, objToSave, snapshot, attributeVals); err != nil {
+		return err
+	}
+
+	condition := expression.AttributeExists(expression.Name("Id"))
+	var conditionErr error = NotFoundError{TypeName: objToSave.GetTypeName(), Ids: []string{id}}
+	versioned, isVersioned := objToSave.(Versioned)
+	if isVersioned {
+		expectedVersion := versioned.GetVersion()
+		condition = condition.And(getVersionCondition(expectedVersion))
+		update = update.Set(expression.Name(VersionAttributeName), expression.Value(expectedVersion+1))
+		conditionErr = ConflictError{Id: id, TypeName: objToSave.GetTypeName(), ExpectedVersion: expectedVersion}
+	}
+
+	expr, err := expression.NewBuilder().WithUpdate(update).WithCondition(condition).Build()
+	if err != nil {
+		return fmt.Errorf("error occurred when building dynamodb update expression %w", err)
+	}
+
+	// the unique values held according to the snapshot are released if changed,
+	// the values of the readonly fields are not saved so they are not changed
+	current := make(map[string]*dynamodb.AttributeValue, len(attributeVals))
+	for name, attribute := range attributeVals {
+		current[name] = attribute
+	}
+	for name := range getReadonlyAttributes(objToSave) {
+		current[name] = snapshot[name]
+	}
+	err = writeGuardingUniqueValues(ctx, getObjectUniqueFields(objToSave), objToSave.GetTypeName(), id, snapshot, current, conditionErr, func(ctx context.Context) error {
+		_, err := dbClient(ctx).UpdateItemWithContext(ctx, &dynamodb.UpdateItemInput{
+			TableName: aws.String(getTableName(objToSave.GetTypeName())),
+			Key: map[string]*dynamodb.AttributeValue{
+				"Id": {
+					S: aws.String(id),
+				},
+			},
+			UpdateExpression:          expr.Update(),
+			ConditionExpression:       expr.Condition(),
+			ExpressionAttributeNames:  expr.Names(),
+			ExpressionAttributeValues: expr.Values(),
+		})
+		return err
+	})
+	if _, ok := err.(*dynamodb.ConditionalCheckFailedException); ok {
+		return conditionErr
+	}
+	if err != nil {
+		return err
+	}
+
+	if isVersioned {
+		versioned.SetVersion(versioned.GetVersion() + 1)
+	}
+	return nil
+}
+
+// getChangedAttributesUpdate returns the update setting the attributes whose values differ
+// from the snapshot and removing the ones not present anymore. The key, the version and
+// the readonly attributes are omitted. The second return value is false if nothing was modified.
+func getChangedAttributesUpdate(snapshot, current map[string]*dynamodb.AttributeValue, readonly map[string]bool) (expression.UpdateBuilder, bool) {
+	update := expression.UpdateBuilder{}
+	isModified := false
+
+	for _, name := range sortedAttributeNames(current) {
+		if name == "Id" || name == VersionAttributeName || readonly[name] {
+			continue
+		}
+		if previous, exists := snapshot[name]; !exists || !dynamoexpr.Equal(previous, current[name]) {
+			update = update.Set(expression.Name(name), expression.Value(current[name]))
+			isModified = true
+		}
+	}
+	for _, name := range sortedAttributeNames(snapshot) {
+		if _, exists := current[name]; !exists && name != "Id" && name != VersionAttributeName && !readonly[name] {
+			update = update.Remove(expression.Name(name))
+			isModified = true
+		}
+	}
+
+	return update, isModified
+}
+
+func sortedAttributeNames(attributes map[string]*dynamodb.AttributeValue) []string {
+	names := make([]string, 0, len(attributes))
+	for name := range attributes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,128 @@
+package lib
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/expression"
+)
+
+// SortedQuery describes the query of the objects of a one-to-many relationship
+// ordered by the field given in the sortedBy tag of the relationship, e.g.
+// `nubes:"hasOne-City,sortedBy-Rate"`. Only the objects satisfying the
+// bounds are read, at most one of the range bounds and BeginsWith can be used.
+type SortedQuery struct {
+	// Name of the field given in the sortedBy tag
+	SortedBy string
+	// Indicates whether the objects are returned from the greatest value of the field
+	Descending bool
+	// Maximum number of the returned objects, 0 means all of them
+	Limit int
+	// Inclusive lower bound of the field value, nil if not bounded
+	From interface{}
+	// Inclusive upper bound of the field value, nil if not bounded
+	To interface{}
+	// Prefix of the field value, the field must be a string
+	BeginsWith string
+}
+
+func (q SortedQuery) Validate() error {
+	if q.SortedBy == "" {
+		return fmt.Errorf("missing SortedBy")
+	}
+	if q.Limit < 0 {
+		return fmt.Errorf("Limit must not be negative")
+	}
+	if q.BeginsWith != "" && (q.From != nil || q.To != nil) {
+		return fmt.Errorf("BeginsWith can not be used together with From or To")
+	}
+	return nil
+}
+
+// keyCondition returns the condition on the partition key
+// of the index combined with the bounds of the sort key
+func (q SortedQuery) keyCondition(partitionKeyName, partitionKeyValue string) expression.KeyConditionBuilder {
+	keyCondition := expression.Key(partitionKeyName).Equal(expression.Value(partitionKeyValue))
+	sortKey := expression.Key(q.SortedBy)
+
+	switch {
+	case q.BeginsWith != "":
+		return keyCondition.And(sortKey.BeginsWith(q.BeginsWith))
+	case q.From != nil && q.To != nil:
+		return keyCondition.And(sortKey.Between(expression.Value(q.From), expression.Value(q.To)))
+	case q.From != nil:
+		return keyCondition.And(sortKey.GreaterThanEqual(expression.Value(q.From)))
+	case q.To != nil:
+		return keyCondition.And(sortKey.LessThanEqual(expression.Value(q.To)))
+	}
+	return keyCondition
+}
+
+// GetSortedIds returns the ids of the objects in the order of the field given
+// in the query. The objects are retrieved by the index with the field as the
+// sort key, created by the generator for the sortedBy tag of the relationship.
+func (r ReferenceNavigationList[T]) GetSortedIds(query SortedQuery) ([]string, error) {
+	return r.GetSortedIdsWithContext(InvocationContext(), query)
+}
+
+// GetSortedIdsWithContext is the same as GetSortedIds with the addition of the ability to pass a context
+func (r ReferenceNavigationList[T]) GetSortedIdsWithContext(ctx context.Context, query SortedQuery) ([]string, error) {
+	return r.setup.getSortedIds(ctx, query)
+}
+
+// GetSortedStubs returns the states of the objects in the order of the field given in the query
+func (r ReferenceNavigationList[T]) GetSortedStubs(query SortedQuery) ([]T, error) {
+	return r.GetSortedStubsWithContext(InvocationContext(), query)
+}
+
+// GetSortedStubsWithContext is the same as GetSortedStubs with the addition of the ability to pass a context
+func (r ReferenceNavigationList[T]) GetSortedStubsWithContext(ctx context.Context, query SortedQuery) ([]T, error) {
+	ids, err := r.setup.getSortedIds(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 {
+		return []T{}, nil
+	}
+
+	batch, err := GetStubsInBatchWithContext[T](ctx, ids)
+	if err != nil {
+		return nil, fmt.Errorf("error occurred while retriving the objects from DB: %w", err)
+	}
+	return *batch, nil
+}
+
+func (r referenceNavigationListSetup) getSortedIds(ctx context.Context, query SortedQuery) ([]string, error) {
+	if err := query.Validate(); err != nil {
+		return nil, err
+	}
+	if r.IsManyToMany {
+		return nil, fmt.Errorf("sorted queries can only be used in OneToMany relationships")
+	}
+
+	expr, err := expression.NewBuilder().
+		WithKeyCondition(query.keyCondition(r.referringFieldName, r.ownerId)).
+		WithProjection(getProjection([]string{"Id"})).
+		Build()
+	if err != nil {
+		return nil, fmt.Errorf("error occurred when building dynamodb query expression %w", err)
+	}
+
+	input := &dynamodb.QueryInput{
+		TableName:                 aws.String(getTableName(r.TableName)),
+		IndexName:                 aws.String(r.IndexName + query.SortedBy),
+		ExpressionAttributeNames:  expr.Names(),
+		KeyConditionExpression:    expr.KeyCondition(),
+		ProjectionExpression:      expr.Projection(),
+		ExpressionAttributeValues: expr.Values(),
+		ScanIndexForward:          aws.Bool(!query.Descending),
+	}
+
+	if query.Limit == 0 {
+		return queryAllIds(ctx, input, "Id")
+	}
+	page, err := queryIdsPage(ctx, input, "Id", query.Limit, "")
+	return page.Ids, err
+}
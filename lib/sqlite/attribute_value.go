@@ -0,0 +1,133 @@
+package sqlite
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/Astenna/Nubes/lib/internal/dynamoexpr"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+// The items are stored in the DynamoDB JSON format, e.g. {"Id":{"S":"1"},"Price":{"N":"10"}},
+// so that the data types of the attributes are preserved.
+
+func marshalItem(item dynamoexpr.Item) (string, error) {
+	encoded, err := json.Marshal(toJSONItem(item))
+	return string(encoded), err
+}
+
+func unmarshalItem(data string) (dynamoexpr.Item, error) {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(data), &raw); err != nil {
+		return nil, err
+	}
+	return fromJSONItem(raw)
+}
+
+func toJSONItem(item dynamoexpr.Item) map[string]interface{} {
+	result := make(map[string]interface{}, len(item))
+	for name, value := range item {
+		result[name] = toJSONValue(value)
+	}
+	return result
+}
+
+func toJSONValue(v *dynamodb.AttributeValue) map[string]interface{} {
+	switch dynamoexpr.TypeOf(v) {
+	case "S":
+		return map[string]interface{}{"S": *v.S}
+	case "N":
+		return map[string]interface{}{"N": *v.N}
+	case "B":
+		return map[string]interface{}{"B": v.B}
+	case "BOOL":
+		return map[string]interface{}{"BOOL": *v.BOOL}
+	case "NULL":
+		return map[string]interface{}{"NULL": *v.NULL}
+	case "SS":
+		return map[string]interface{}{"SS": aws.StringValueSlice(v.SS)}
+	case "NS":
+		return map[string]interface{}{"NS": aws.StringValueSlice(v.NS)}
+	case "BS":
+		return map[string]interface{}{"BS": v.BS}
+	case "L":
+		list := make([]interface{}, len(v.L))
+		for i, elem := range v.L {
+			list[i] = toJSONValue(elem)
+		}
+		return map[string]interface{}{"L": list}
+	case "M":
+		return map[string]interface{}{"M": toJSONItem(v.M)}
+	}
+	return map[string]interface{}{}
+}
+
+func fromJSONItem(raw map[string]json.RawMessage) (dynamoexpr.Item, error) {
+	item := make(dynamoexpr.Item, len(raw))
+	for name, data := range raw {
+		value, err := fromJSONValue(data)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value of attribute %s: %w", name, err)
+		}
+		item[name] = value
+	}
+	return item, nil
+}
+
+func fromJSONValue(data json.RawMessage) (*dynamodb.AttributeValue, error) {
+	var typed map[string]json.RawMessage
+	if err := json.Unmarshal(data, &typed); err != nil {
+		return nil, err
+	}
+	if len(typed) != 1 {
+		return nil, fmt.Errorf("exactly one data type expected, found %d", len(typed))
+	}
+
+	value := &dynamodb.AttributeValue{}
+	for dataType, content := range typed {
+		var err error
+		switch dataType {
+		case "S":
+			err = json.Unmarshal(content, &value.S)
+		case "N":
+			err = json.Unmarshal(content, &value.N)
+		case "B":
+			value.B = []byte{}
+			err = json.Unmarshal(content, &value.B)
+		case "BOOL":
+			err = json.Unmarshal(content, &value.BOOL)
+		case "NULL":
+			err = json.Unmarshal(content, &value.NULL)
+		case "SS":
+			err = json.Unmarshal(content, &value.SS)
+		case "NS":
+			err = json.Unmarshal(content, &value.NS)
+		case "BS":
+			err = json.Unmarshal(content, &value.BS)
+		case "L":
+			var list []json.RawMessage
+			if err = json.Unmarshal(content, &list); err != nil {
+				break
+			}
+			value.L = make([]*dynamodb.AttributeValue, len(list))
+			for i, elem := range list {
+				if value.L[i], err = fromJSONValue(elem); err != nil {
+					break
+				}
+			}
+		case "M":
+			var raw map[string]json.RawMessage
+			if err = json.Unmarshal(content, &raw); err != nil {
+				break
+			}
+			value.M, err = fromJSONItem(raw)
+		default:
+			err = fmt.Errorf("unknown data type %s", dataType)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return value, nil
+}
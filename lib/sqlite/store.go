@@ -0,0 +1,583 @@
+// Package sqlite provides a Store keeping the Nobjects in an embedded
+// SQLite database, e.g. to run Nubes types on a single node outside AWS.
+//
+//	store, err := sqlite.Open("nubes.db")
+//	...
+//	lib.SetStore(store)
+//
+// The tables are created with CreateTable, which accepts the same
+// input as DynamoDB, so they can be initialized by the generator.
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/Astenna/Nubes/lib"
+	"github.com/Astenna/Nubes/lib/internal/dynamoexpr"
+	"github.com/Astenna/Nubes/lib/internal/storeutil"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+const (
+	// tablesTable keeps the definitions of the created tables
+	tablesTable = "nubes_tables"
+	// keyColumn and itemColumn are present in every table, the first one
+	// is the encoded primary key, the second one the item in JSON format.
+	// Additionally, every key attribute of the table and of its indexes
+	// has its own column, set only if the item is indexed by the attribute.
+	keyColumn  = "nubes_key"
+	itemColumn = "nubes_item"
+)
+
+// Store is a lib.Store keeping the items in a SQLite database.
+// Each table, i.e. Nobject type or join table of a many-to-many
+// relationship, is mapped to a SQLite table with the attributes of
+// the items stored as a JSON document. The secondary indexes are
+// mapped to SQLite indexes on the key attributes columns.
+type Store struct {
+	db *sql.DB
+
+	mu     sync.Mutex
+	tables map[string]*storeutil.Table
+}
+
+var _ lib.Store = (*Store)(nil)
+
+// Open opens the SQLite database, creating it if it does not exist.
+// The data source name is passed to the go-sqlite3 driver, so it can be
+// a file path or e.g. ":memory:" for a database kept only in memory.
+func Open(dataSourceName string) (*Store, error) {
+	db, err := sql.Open("sqlite3", dataSourceName)
+	if err != nil {
+		return nil, err
+	}
+	// a single connection serializes the transactions, so that the condition
+	// of a write is checked against the current state of the item. It is
+	// also required for the in-memory databases, which are per connection.
+	db.SetMaxOpenConns(1)
+
+	_, err = db.Exec(fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (name TEXT PRIMARY KEY, definition TEXT NOT NULL)", tablesTable))
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to initialize the SQLite database: %w", err)
+	}
+
+	return &Store{db: db, tables: map[string]*storeutil.Table{}}, nil
+}
+
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// CreateTable creates a table with the key schema, attribute definitions and
+// secondary indexes of the input. Other settings, e.g. billing mode, are ignored.
+func (s *Store) CreateTable(input *dynamodb.CreateTableInput) (*dynamodb.CreateTableOutput, error) {
+	table, err := storeutil.NewTable(input)
+	if err != nil {
+		return nil, err
+	}
+	definition, err := json.Marshal(input)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.inTransaction(context.Background(), func(tx *sql.Tx) error {
+		var exists int
+		err := tx.QueryRow(fmt.Sprintf("SELECT COUNT(*) FROM %s WHERE name = ?", tablesTable), table.Name).Scan(&exists)
+		if err != nil {
+			return err
+		}
+		if exists > 0 {
+			return storeutil.ResourceInUseError(table.Name)
+		}
+
+		for _, statement := range createTableStatements(table) {
+			if _, err = tx.Exec(statement); err != nil {
+				return err
+			}
+		}
+		_, err = tx.Exec(fmt.Sprintf("INSERT INTO %s (name, definition) VALUES (?, ?)", tablesTable), table.Name, string(definition))
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	s.tables[table.Name] = table
+	s.mu.Unlock()
+	return &dynamodb.CreateTableOutput{TableDescription: storeutil.NewTableDescription(input)}, nil
+}
+
+func createTableStatements(table *storeutil.Table) []string {
+	tableName := quote(table.Name)
+	columns := []string{keyColumn + " TEXT PRIMARY KEY", itemColumn + " TEXT NOT NULL"}
+	for _, attribute := range table.KeyAttributes() {
+		columns = append(columns, quote(attribute)+" TEXT")
+	}
+
+	statements := []string{
+		fmt.Sprintf("CREATE TABLE %s (%s)", tableName, strings.Join(columns, ", ")),
+		fmt.Sprintf("CREATE INDEX %s ON %s (%s)", quote(table.Name+"_"+keyColumn), tableName, quote(table.Key.HashKey)),
+	}
+	for indexName, schema := range table.Indexes {
+		statements = append(statements, fmt.Sprintf("CREATE INDEX %s ON %s (%s)", quote(table.Name+"_"+indexName), tableName, quote(schema.HashKey)))
+	}
+	return statements
+}
+
+func (s *Store) GetItemWithContext(ctx aws.Context, input *dynamodb.GetItemInput, _ ...request.Option) (*dynamodb.GetItemOutput, error) {
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
+	projection, err := storeutil.ParseProjection(input.ProjectionExpression, input.ExpressionAttributeNames)
+	if err != nil {
+		return nil, err
+	}
+
+	output := &dynamodb.GetItemOutput{}
+	err = s.inTransaction(ctx, func(tx *sql.Tx) error {
+		table, err := s.table(tx, *input.TableName)
+		if err != nil {
+			return err
+		}
+		key, err := table.EncodeKey(input.Key)
+		if err != nil {
+			return err
+		}
+
+		item, err := getItem(tx, table, key)
+		output.Item = projection.Apply(item)
+		return err
+	})
+	return output, err
+}
+
+func (s *Store) PutItemWithContext(ctx aws.Context, input *dynamodb.PutItemInput, _ ...request.Option) (*dynamodb.PutItemOutput, error) {
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
+	if err := storeutil.ValidateReturnValues(input.ReturnValues, dynamodb.ReturnValueNone, dynamodb.ReturnValueAllOld); err != nil {
+		return nil, err
+	}
+	if err := storeutil.ValidateItem(input.Item); err != nil {
+		return nil, err
+	}
+	condition, err := storeutil.ParseCondition(input.ConditionExpression, input.ExpressionAttributeNames, input.ExpressionAttributeValues)
+	if err != nil {
+		return nil, err
+	}
+
+	output := &dynamodb.PutItemOutput{}
+	err = s.inTransaction(ctx, func(tx *sql.Tx) error {
+		table, err := s.table(tx, *input.TableName)
+		if err != nil {
+			return err
+		}
+		key, err := table.EncodeItemKey(input.Item)
+		if err != nil {
+			return err
+		}
+
+		existing, err := getItem(tx, table, key)
+		if err != nil {
+			return err
+		}
+		if err = storeutil.CheckCondition(condition, existing); err != nil {
+			return err
+		}
+
+		output.Attributes = storeutil.ReturnedAttributes(input.ReturnValues, existing, nil)
+		return putItem(tx, table, key, input.Item)
+	})
+	return output, err
+}
+
+// UpdateItemWithContext updates the item or creates it if it does not exist
+func (s *Store) UpdateItemWithContext(ctx aws.Context, input *dynamodb.UpdateItemInput, _ ...request.Option) (*dynamodb.UpdateItemOutput, error) {
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
+	if err := storeutil.ValidateReturnValues(input.ReturnValues, dynamodb.ReturnValueNone, dynamodb.ReturnValueAllOld, dynamodb.ReturnValueAllNew); err != nil {
+		return nil, err
+	}
+
+	output := &dynamodb.UpdateItemOutput{}
+	err := s.inTransaction(ctx, func(tx *sql.Tx) error {
+		table, err := s.table(tx, *input.TableName)
+		if err != nil {
+			return err
+		}
+		key, err := table.EncodeKey(input.Key)
+		if err != nil {
+			return err
+		}
+
+		existing, err := getItem(tx, table, key)
+		if err != nil {
+			return err
+		}
+		updated, err := storeutil.UpdatedItem(table, input, existing)
+		if err != nil {
+			return err
+		}
+
+		output.Attributes = storeutil.ReturnedAttributes(input.ReturnValues, existing, updated)
+		return putItem(tx, table, key, updated)
+	})
+	return output, err
+}
+
+func (s *Store) DeleteItemWithContext(ctx aws.Context, input *dynamodb.DeleteItemInput, _ ...request.Option) (*dynamodb.DeleteItemOutput, error) {
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
+	if err := storeutil.ValidateReturnValues(input.ReturnValues, dynamodb.ReturnValueNone, dynamodb.ReturnValueAllOld); err != nil {
+		return nil, err
+	}
+	condition, err := storeutil.ParseCondition(input.ConditionExpression, input.ExpressionAttributeNames, input.ExpressionAttributeValues)
+	if err != nil {
+		return nil, err
+	}
+
+	output := &dynamodb.DeleteItemOutput{}
+	err = s.inTransaction(ctx, func(tx *sql.Tx) error {
+		table, err := s.table(tx, *input.TableName)
+		if err != nil {
+			return err
+		}
+		key, err := table.EncodeKey(input.Key)
+		if err != nil {
+			return err
+		}
+
+		existing, err := getItem(tx, table, key)
+		if err != nil {
+			return err
+		}
+		if err = storeutil.CheckCondition(condition, existing); err != nil {
+			return err
+		}
+
+		output.Attributes = storeutil.ReturnedAttributes(input.ReturnValues, existing, nil)
+		return deleteItem(tx, table, key)
+	})
+	return output, err
+}
+
+// QueryWithContext reads the items of the queried partition using the index on
+// the partition key column, the rest of the key condition, the filter
+// and the ordering are evaluated on the retrieved items
+func (s *Store) QueryWithContext(ctx aws.Context, input *dynamodb.QueryInput, _ ...request.Option) (*dynamodb.QueryOutput, error) {
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
+
+	var output *dynamodb.QueryOutput
+	err := s.inTransaction(ctx, func(tx *sql.Tx) error {
+		table, err := s.table(tx, *input.TableName)
+		if err != nil {
+			return err
+		}
+		query, err := storeutil.NewQuery(table, input)
+		if err != nil {
+			return err
+		}
+
+		rows, err := tx.QueryContext(ctx, fmt.Sprintf("SELECT %s FROM %s WHERE %s = ?", itemColumn, quote(table.Name), quote(query.HashKey())), query.HashKeyValue())
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		var items []dynamoexpr.Item
+		for rows.Next() {
+			var data string
+			if err = rows.Scan(&data); err != nil {
+				return err
+			}
+			item, err := unmarshalItem(data)
+			if err != nil {
+				return err
+			}
+			items = append(items, item)
+		}
+		if err = rows.Err(); err != nil {
+			return err
+		}
+
+		output, err = query.Run(items)
+		return err
+	})
+	return output, err
+}
+
+// ScanWithContext reads all the items of the table, the filter, the ordering
+// by the primary key and the limit are evaluated on the retrieved items
+func (s *Store) ScanWithContext(ctx aws.Context, input *dynamodb.ScanInput, _ ...request.Option) (*dynamodb.ScanOutput, error) {
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
+
+	var output *dynamodb.ScanOutput
+	err := s.inTransaction(ctx, func(tx *sql.Tx) error {
+		table, err := s.table(tx, *input.TableName)
+		if err != nil {
+			return err
+		}
+		scan, err := storeutil.NewScan(table, input)
+		if err != nil {
+			return err
+		}
+
+		rows, err := tx.QueryContext(ctx, fmt.Sprintf("SELECT %s FROM %s", itemColumn, quote(table.Name)))
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		var items []dynamoexpr.Item
+		for rows.Next() {
+			var data string
+			if err = rows.Scan(&data); err != nil {
+				return err
+			}
+			item, err := unmarshalItem(data)
+			if err != nil {
+				return err
+			}
+			items = append(items, item)
+		}
+		if err = rows.Err(); err != nil {
+			return err
+		}
+
+		output, err = scan.Run(items)
+		return err
+	})
+	return output, err
+}
+
+func (s *Store) BatchGetItemWithContext(ctx aws.Context, input *dynamodb.BatchGetItemInput, _ ...request.Option) (*dynamodb.BatchGetItemOutput, error) {
+	if err := storeutil.ValidateBatchGetItem(input); err != nil {
+		return nil, err
+	}
+
+	output := &dynamodb.BatchGetItemOutput{
+		Responses:       map[string][]map[string]*dynamodb.AttributeValue{},
+		UnprocessedKeys: map[string]*dynamodb.KeysAndAttributes{},
+	}
+	err := s.inTransaction(ctx, func(tx *sql.Tx) error {
+		for tableName, request := range input.RequestItems {
+			table, err := s.table(tx, tableName)
+			if err != nil {
+				return err
+			}
+			projection, err := storeutil.ParseProjection(request.ProjectionExpression, request.ExpressionAttributeNames)
+			if err != nil {
+				return err
+			}
+
+			requestedKeys := make(map[string]bool, len(request.Keys))
+			output.Responses[tableName] = []map[string]*dynamodb.AttributeValue{}
+			for _, requestKey := range request.Keys {
+				key, err := table.EncodeKey(requestKey)
+				if err != nil {
+					return err
+				}
+				if requestedKeys[key] {
+					return storeutil.ValidationError("Provided list of item keys contains duplicates")
+				}
+				requestedKeys[key] = true
+
+				item, err := getItem(tx, table, key)
+				if err != nil {
+					return err
+				}
+				if item != nil {
+					output.Responses[tableName] = append(output.Responses[tableName], projection.Apply(item))
+				}
+			}
+		}
+		return nil
+	})
+	return output, err
+}
+
+// BatchWriteItemWithContext applies all the requests in a single transaction,
+// i.e. no item is returned as unprocessed
+func (s *Store) BatchWriteItemWithContext(ctx aws.Context, input *dynamodb.BatchWriteItemInput, _ ...request.Option) (*dynamodb.BatchWriteItemOutput, error) {
+	if err := storeutil.ValidateBatchWriteItem(input); err != nil {
+		return nil, err
+	}
+
+	err := s.inTransaction(ctx, func(tx *sql.Tx) error {
+		writtenKeys := map[string]bool{}
+		for tableName, requests := range input.RequestItems {
+			table, err := s.table(tx, tableName)
+			if err != nil {
+				return err
+			}
+
+			for _, request := range requests {
+				var key string
+				if request.PutRequest != nil {
+					key, err = table.EncodeItemKey(request.PutRequest.Item)
+				} else {
+					key, err = table.EncodeKey(request.DeleteRequest.Key)
+				}
+				if err != nil {
+					return err
+				}
+				if writtenKeys[tableName+"\x00"+key] {
+					return storeutil.ValidationError("Provided list of item keys contains duplicates")
+				}
+				writtenKeys[tableName+"\x00"+key] = true
+
+				if request.PutRequest != nil {
+					err = putItem(tx, table, key, request.PutRequest.Item)
+				} else {
+					err = deleteItem(tx, table, key)
+				}
+				if err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &dynamodb.BatchWriteItemOutput{UnprocessedItems: map[string][]*dynamodb.WriteRequest{}}, nil
+}
+
+// TransactWriteItemsWithContext checks the conditions of all the operations
+// and applies the writes only if all of them are satisfied
+func (s *Store) TransactWriteItemsWithContext(ctx aws.Context, input *dynamodb.TransactWriteItemsInput, _ ...request.Option) (*dynamodb.TransactWriteItemsOutput, error) {
+	err := s.inTransaction(ctx, func(tx *sql.Tx) error {
+		writes, err := storeutil.PrepareTransactWrites(input,
+			func(name string) (*storeutil.Table, error) {
+				return s.table(tx, name)
+			},
+			func(table *storeutil.Table, key string) (dynamoexpr.Item, error) {
+				return getItem(tx, table, key)
+			})
+		if err != nil {
+			return err
+		}
+
+		for _, w := range writes {
+			if w.Item == nil {
+				err = deleteItem(tx, w.Table, w.Key)
+			} else {
+				err = putItem(tx, w.Table, w.Key, w.Item)
+			}
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &dynamodb.TransactWriteItemsOutput{}, nil
+}
+
+// inTransaction runs the operation in a database transaction,
+// which is rolled back if the context is done before it is committed
+func (s *Store) inTransaction(ctx context.Context, operation func(tx *sql.Tx) error) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if err = operation(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// table returns the definition of the table, reading it
+// from the database if it was created by another process
+func (s *Store) table(tx *sql.Tx, name string) (*storeutil.Table, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if table, found := s.tables[name]; found {
+		return table, nil
+	}
+
+	var definition string
+	err := tx.QueryRow(fmt.Sprintf("SELECT definition FROM %s WHERE name = ?", tablesTable), name).Scan(&definition)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, storeutil.ResourceNotFoundError(name)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	input := &dynamodb.CreateTableInput{}
+	if err = json.Unmarshal([]byte(definition), input); err != nil {
+		return nil, fmt.Errorf("invalid definition of table %s: %w", name, err)
+	}
+	table, err := storeutil.NewTable(input)
+	if err != nil {
+		return nil, err
+	}
+	s.tables[name] = table
+	return table, nil
+}
+
+func getItem(tx *sql.Tx, table *storeutil.Table, key string) (dynamoexpr.Item, error) {
+	var data string
+	err := tx.QueryRow(fmt.Sprintf("SELECT %s FROM %s WHERE %s = ?", itemColumn, quote(table.Name), keyColumn), key).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return unmarshalItem(data)
+}
+
+func putItem(tx *sql.Tx, table *storeutil.Table, key string, item dynamoexpr.Item) error {
+	data, err := marshalItem(item)
+	if err != nil {
+		return err
+	}
+
+	columns := []string{keyColumn, itemColumn}
+	values := []interface{}{key, data}
+	for _, attribute := range table.KeyAttributes() {
+		columns = append(columns, quote(attribute))
+		if value, indexed := table.IndexKeyValue(attribute, item); indexed {
+			values = append(values, value)
+		} else {
+			values = append(values, nil)
+		}
+	}
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(columns)), ", ")
+	_, err = tx.Exec(fmt.Sprintf("INSERT OR REPLACE INTO %s (%s) VALUES (%s)", quote(table.Name), strings.Join(columns, ", "), placeholders), values...)
+	return err
+}
+
+func deleteItem(tx *sql.Tx, table *storeutil.Table, key string) error {
+	_, err := tx.Exec(fmt.Sprintf("DELETE FROM %s WHERE %s = ?", quote(table.Name), keyColumn), key)
+	return err
+}
+
+func quote(identifier string) string {
+	return `"` + strings.ReplaceAll(identifier, `"`, `""`) + `"`
+}
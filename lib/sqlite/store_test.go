@@ -0,0 +1,459 @@
+package sqlite
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/Astenna/Nubes/lib/internal/dynamoexpr"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+func s(value string) *dynamodb.AttributeValue {
+	return &dynamodb.AttributeValue{S: aws.String(value)}
+}
+
+func n(value string) *dynamodb.AttributeValue {
+	return &dynamodb.AttributeValue{N: aws.String(value)}
+}
+
+func attributeDefinition(name, attributeType string) *dynamodb.AttributeDefinition {
+	return &dynamodb.AttributeDefinition{AttributeName: aws.String(name), AttributeType: aws.String(attributeType)}
+}
+
+func keySchema(hashKey, rangeKey string) []*dynamodb.KeySchemaElement {
+	schema := []*dynamodb.KeySchemaElement{{AttributeName: aws.String(hashKey), KeyType: aws.String(dynamodb.KeyTypeHash)}}
+	if rangeKey != "" {
+		schema = append(schema, &dynamodb.KeySchemaElement{AttributeName: aws.String(rangeKey), KeyType: aws.String(dynamodb.KeyTypeRange)})
+	}
+	return schema
+}
+
+func keysOnlyIndex(name, hashKey, rangeKey string) *dynamodb.GlobalSecondaryIndex {
+	return &dynamodb.GlobalSecondaryIndex{
+		IndexName:  aws.String(name),
+		KeySchema:  keySchema(hashKey, rangeKey),
+		Projection: &dynamodb.Projection{ProjectionType: aws.String(dynamodb.ProjectionTypeKeysOnly)},
+	}
+}
+
+// testTables returns the tables as created by the generator with the --dbInit flag:
+// a Nobject type table with an index of a reference and a sorted index,
+// and the join table of a many-to-many relationship
+func testTables() []*dynamodb.CreateTableInput {
+	return []*dynamodb.CreateTableInput{
+		{
+			BillingMode: aws.String(dynamodb.BillingModePayPerRequest),
+			TableName:   aws.String("Product"),
+			AttributeDefinitions: []*dynamodb.AttributeDefinition{
+				attributeDefinition("Id", dynamodb.ScalarAttributeTypeS),
+				attributeDefinition("SoldBy", dynamodb.ScalarAttributeTypeS),
+				attributeDefinition("Price", dynamodb.ScalarAttributeTypeN),
+			},
+			KeySchema: keySchema("Id", ""),
+			GlobalSecondaryIndexes: []*dynamodb.GlobalSecondaryIndex{
+				keysOnlyIndex("ProductSoldBy", "SoldBy", ""),
+				keysOnlyIndex("ProductSoldByPrice", "SoldBy", "Price"),
+			},
+		},
+		{
+			BillingMode: aws.String(dynamodb.BillingModePayPerRequest),
+			TableName:   aws.String("ShopProduct"),
+			AttributeDefinitions: []*dynamodb.AttributeDefinition{
+				attributeDefinition("Shop", dynamodb.ScalarAttributeTypeS),
+				attributeDefinition("Product", dynamodb.ScalarAttributeTypeS),
+			},
+			KeySchema:              keySchema("Shop", "Product"),
+			GlobalSecondaryIndexes: []*dynamodb.GlobalSecondaryIndex{keysOnlyIndex("ShopProductReversed", "Product", "")},
+		},
+	}
+}
+
+func openStore(t *testing.T, path string) *Store {
+	store, err := Open(path)
+	if err != nil {
+		t.Fatalf("failed to open the store: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+	return store
+}
+
+func newTestStore(t *testing.T) *Store {
+	store := openStore(t, filepath.Join(t.TempDir(), "nubes.db"))
+	for _, input := range testTables() {
+		if _, err := store.CreateTable(input); err != nil {
+			t.Fatalf("failed to create table %s: %v", *input.TableName, err)
+		}
+	}
+	return store
+}
+
+func product(id, soldBy, price string) dynamoexpr.Item {
+	item := dynamoexpr.Item{"Id": s(id), "Name": s("Product " + id)}
+	if soldBy != "" {
+		item["SoldBy"] = s(soldBy)
+	}
+	if price != "" {
+		item["Price"] = n(price)
+	}
+	return item
+}
+
+func putItems(t *testing.T, store *Store, tableName string, items ...dynamoexpr.Item) {
+	for _, item := range items {
+		if _, err := store.PutItemWithContext(context.Background(), &dynamodb.PutItemInput{TableName: aws.String(tableName), Item: item}); err != nil {
+			t.Fatalf("failed to put item %v: %v", item, err)
+		}
+	}
+}
+
+func getStoredItem(t *testing.T, store *Store, tableName string, key dynamoexpr.Item) dynamoexpr.Item {
+	output, err := store.GetItemWithContext(context.Background(), &dynamodb.GetItemInput{TableName: aws.String(tableName), Key: key})
+	if err != nil {
+		t.Fatalf("failed to get item %v: %v", key, err)
+	}
+	return output.Item
+}
+
+func ids(items []map[string]*dynamodb.AttributeValue, attribute string) []string {
+	result := []string{}
+	for _, item := range items {
+		result = append(result, aws.StringValue(item[attribute].S))
+	}
+	return result
+}
+
+func TestCreateTablesOfDbInitSchema(t *testing.T) {
+	// Arrange
+	path := filepath.Join(t.TempDir(), "nubes.db")
+	store := openStore(t, path)
+
+	// Act
+	for _, input := range testTables() {
+		if _, err := store.CreateTable(input); err != nil {
+			t.Fatalf("failed to create table %s: %v", *input.TableName, err)
+		}
+	}
+
+	// Assert
+	for _, input := range testTables() {
+		if _, err := store.CreateTable(input); err == nil {
+			t.Errorf("%s: expected ResourceInUseException, got no error", *input.TableName)
+		} else if _, ok := err.(*dynamodb.ResourceInUseException); !ok {
+			t.Errorf("%s: expected ResourceInUseException, got %v", *input.TableName, err)
+		}
+	}
+	putItems(t, store, "ShopProduct", dynamoexpr.Item{"Shop": s("shop-1"), "Product": s("product-1")})
+	store.Close()
+
+	// the definitions of the tables are read from the reopened database
+	reopened := openStore(t, path)
+	output, err := reopened.QueryWithContext(context.Background(), &dynamodb.QueryInput{
+		TableName:                 aws.String("ShopProduct"),
+		IndexName:                 aws.String("ShopProductReversed"),
+		KeyConditionExpression:    aws.String("Product = :product"),
+		ExpressionAttributeValues: dynamoexpr.Item{":product": s("product-1")},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if got := ids(output.Items, "Shop"); !reflect.DeepEqual(got, []string{"shop-1"}) {
+		t.Errorf("expected shop-1 to be found, got %v", got)
+	}
+	if _, err = reopened.GetItemWithContext(context.Background(), &dynamodb.GetItemInput{TableName: aws.String("Missing"), Key: dynamoexpr.Item{"Id": s("1")}}); err == nil {
+		t.Errorf("expected ResourceNotFoundException for the missing table, got no error")
+	} else if _, ok := err.(*dynamodb.ResourceNotFoundException); !ok {
+		t.Errorf("expected ResourceNotFoundException for the missing table, got %v", err)
+	}
+}
+
+func TestPutAndGetItemPreserveAttributeValues(t *testing.T) {
+	// Arrange
+	store := newTestStore(t)
+	item := dynamoexpr.Item{
+		"Id":       s("product-1"),
+		"Price":    n("12.50"),
+		"Tags":     {SS: aws.StringSlice([]string{"tea", "green"})},
+		"Sizes":    {NS: aws.StringSlice([]string{"1", "2.5"})},
+		"Image":    {B: []byte{0, 1, 2}},
+		"Archived": {BOOL: aws.Bool(false)},
+		"Removed":  {NULL: aws.Bool(true)},
+		"Variants": {L: []*dynamodb.AttributeValue{s("S"), n("2"), {M: dynamoexpr.Item{"Color": s("red")}}}},
+		"Shop":     {M: dynamoexpr.Item{"City": s("Warsaw"), "Ids": {L: []*dynamodb.AttributeValue{}}}},
+	}
+
+	// Act
+	putItems(t, store, "Product", item)
+	got := getStoredItem(t, store, "Product", dynamoexpr.Item{"Id": s("product-1")})
+	missing := getStoredItem(t, store, "Product", dynamoexpr.Item{"Id": s("product-2")})
+
+	// Assert
+	if !reflect.DeepEqual(got, item) {
+		t.Errorf("expected %v, got %v", item, got)
+	}
+	if missing != nil {
+		t.Errorf("expected no item, got %v", missing)
+	}
+}
+
+func TestWritesCheckConditions(t *testing.T) {
+	cases := []struct {
+		name     string
+		write    func(store *Store) error
+		expected dynamoexpr.Item
+		fails    bool
+	}{
+		{
+			name: "put of existing item",
+			write: func(store *Store) error {
+				_, err := store.PutItemWithContext(context.Background(), &dynamodb.PutItemInput{TableName: aws.String("Product"), Item: product("product-1", "", "1"),
+					ConditionExpression: aws.String("attribute_not_exists(Id)")})
+				return err
+			},
+			expected: product("product-1", "shop-1", "10"),
+			fails:    true,
+		},
+		{
+			name: "update with satisfied condition",
+			write: func(store *Store) error {
+				_, err := store.UpdateItemWithContext(context.Background(), &dynamodb.UpdateItemInput{TableName: aws.String("Product"), Key: dynamoexpr.Item{"Id": s("product-1")},
+					UpdateExpression: aws.String("SET Price = Price + :one REMOVE SoldBy"), ConditionExpression: aws.String("Price = :ten"),
+					ExpressionAttributeValues: dynamoexpr.Item{":one": n("1"), ":ten": n("10")}})
+				return err
+			},
+			expected: product("product-1", "", "11"),
+		},
+		{
+			name: "update with unsatisfied condition",
+			write: func(store *Store) error {
+				_, err := store.UpdateItemWithContext(context.Background(), &dynamodb.UpdateItemInput{TableName: aws.String("Product"), Key: dynamoexpr.Item{"Id": s("product-1")},
+					UpdateExpression: aws.String("SET Price = :one"), ConditionExpression: aws.String("Price > :ten"),
+					ExpressionAttributeValues: dynamoexpr.Item{":one": n("1"), ":ten": n("10")}})
+				return err
+			},
+			expected: product("product-1", "shop-1", "10"),
+			fails:    true,
+		},
+		{
+			name: "delete with unsatisfied condition",
+			write: func(store *Store) error {
+				_, err := store.DeleteItemWithContext(context.Background(), &dynamodb.DeleteItemInput{TableName: aws.String("Product"), Key: dynamoexpr.Item{"Id": s("product-1")},
+					ConditionExpression: aws.String("attribute_not_exists(SoldBy)")})
+				return err
+			},
+			expected: product("product-1", "shop-1", "10"),
+			fails:    true,
+		},
+		{
+			name: "delete",
+			write: func(store *Store) error {
+				_, err := store.DeleteItemWithContext(context.Background(), &dynamodb.DeleteItemInput{TableName: aws.String("Product"), Key: dynamoexpr.Item{"Id": s("product-1")}})
+				return err
+			},
+			expected: nil,
+		},
+	}
+
+	for _, c := range cases {
+		// Arrange
+		store := newTestStore(t)
+		putItems(t, store, "Product", product("product-1", "shop-1", "10"))
+
+		// Act
+		err := c.write(store)
+
+		// Assert
+		if _, failed := err.(*dynamodb.ConditionalCheckFailedException); failed != c.fails || (!c.fails && err != nil) {
+			t.Errorf("%s: unexpected error %v", c.name, err)
+		}
+		if got := getStoredItem(t, store, "Product", dynamoexpr.Item{"Id": s("product-1")}); !reflect.DeepEqual(got, c.expected) {
+			t.Errorf("%s: expected %v, got %v", c.name, c.expected, got)
+		}
+	}
+}
+
+func TestQueryOfSecondaryIndexes(t *testing.T) {
+	store := newTestStore(t)
+	putItems(t, store, "Product",
+		product("product-1", "shop-1", "30"),
+		product("product-2", "shop-1", "10"),
+		product("product-3", "shop-2", "20"),
+		product("product-4", "shop-1", ""),
+		product("product-5", "", "20"),
+		product("product-6", "shop-1", "20"),
+	)
+	cases := []struct {
+		name        string
+		input       *dynamodb.QueryInput
+		expectedIds []string
+	}{
+		{
+			name:        "index of reference",
+			input:       &dynamodb.QueryInput{IndexName: aws.String("ProductSoldBy"), KeyConditionExpression: aws.String("SoldBy = :shop")},
+			expectedIds: []string{"product-1", "product-2", "product-4", "product-6"},
+		},
+		{
+			name:        "sorted index",
+			input:       &dynamodb.QueryInput{IndexName: aws.String("ProductSoldByPrice"), KeyConditionExpression: aws.String("SoldBy = :shop")},
+			expectedIds: []string{"product-2", "product-6", "product-1"},
+		},
+		{
+			name: "sorted index in reverse order with range condition",
+			input: &dynamodb.QueryInput{IndexName: aws.String("ProductSoldByPrice"), KeyConditionExpression: aws.String("SoldBy = :shop AND Price >= :price"),
+				ScanIndexForward: aws.Bool(false)},
+			expectedIds: []string{"product-1", "product-6"},
+		},
+		{
+			name: "filter",
+			input: &dynamodb.QueryInput{IndexName: aws.String("ProductSoldBy"), KeyConditionExpression: aws.String("SoldBy = :shop"),
+				FilterExpression: aws.String("attribute_not_exists(Price)")},
+			expectedIds: []string{"product-4"},
+		},
+		{
+			name:        "primary key",
+			input:       &dynamodb.QueryInput{KeyConditionExpression: aws.String("Id = :id")},
+			expectedIds: []string{"product-5"},
+		},
+	}
+
+	for _, c := range cases {
+		// Arrange
+		c.input.TableName = aws.String("Product")
+		c.input.ExpressionAttributeValues = dynamoexpr.Item{":shop": s("shop-1"), ":price": n("20"), ":id": s("product-5")}
+
+		// Act
+		output, err := store.QueryWithContext(context.Background(), c.input)
+
+		// Assert
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", c.name, err)
+			continue
+		}
+		if got := ids(output.Items, "Id"); !reflect.DeepEqual(got, c.expectedIds) {
+			t.Errorf("%s: expected %v, got %v", c.name, c.expectedIds, got)
+		}
+	}
+}
+
+func TestQueryAndScanPaging(t *testing.T) {
+	store := newTestStore(t)
+	for _, id := range []string{"product-1", "product-2", "product-3", "product-4", "product-5"} {
+		putItems(t, store, "Product", product(id, "shop-1", "10"))
+	}
+	putItems(t, store, "Product", product("product-6", "shop-2", "10"))
+	cases := []struct {
+		name          string
+		page          func(startKey dynamoexpr.Item) ([]map[string]*dynamodb.AttributeValue, dynamoexpr.Item, error)
+		expectedPages [][]string
+	}{
+		{
+			name: "query",
+			page: func(startKey dynamoexpr.Item) ([]map[string]*dynamodb.AttributeValue, dynamoexpr.Item, error) {
+				output, err := store.QueryWithContext(context.Background(), &dynamodb.QueryInput{TableName: aws.String("Product"), IndexName: aws.String("ProductSoldByPrice"),
+					KeyConditionExpression: aws.String("SoldBy = :shop"), ExpressionAttributeValues: dynamoexpr.Item{":shop": s("shop-1")},
+					Limit: aws.Int64(2), ExclusiveStartKey: startKey})
+				if err != nil {
+					return nil, nil, err
+				}
+				return output.Items, output.LastEvaluatedKey, nil
+			},
+			expectedPages: [][]string{{"product-1", "product-2"}, {"product-3", "product-4"}, {"product-5"}},
+		},
+		{
+			name: "scan with filter",
+			page: func(startKey dynamoexpr.Item) ([]map[string]*dynamodb.AttributeValue, dynamoexpr.Item, error) {
+				output, err := store.ScanWithContext(context.Background(), &dynamodb.ScanInput{TableName: aws.String("Product"), FilterExpression: aws.String("Id <> :id"),
+					ExpressionAttributeValues: dynamoexpr.Item{":id": s("product-2")}, Limit: aws.Int64(3), ExclusiveStartKey: startKey})
+				if err != nil {
+					return nil, nil, err
+				}
+				return output.Items, output.LastEvaluatedKey, nil
+			},
+			expectedPages: [][]string{{"product-1", "product-3"}, {"product-4", "product-5", "product-6"}},
+		},
+	}
+
+	for _, c := range cases {
+		// Act
+		var pages [][]string
+		var startKey dynamoexpr.Item
+		for len(pages) <= len(c.expectedPages) {
+			items, lastKey, err := c.page(startKey)
+			if err != nil {
+				t.Fatalf("%s: unexpected error %v", c.name, err)
+			}
+			pages = append(pages, ids(items, "Id"))
+			if lastKey == nil {
+				break
+			}
+			startKey = lastKey
+		}
+
+		// Assert
+		if !reflect.DeepEqual(pages, c.expectedPages) {
+			t.Errorf("%s: expected pages %v, got %v", c.name, c.expectedPages, pages)
+		}
+	}
+}
+
+func TestCancelledTransactionLeavesNoPartialWrites(t *testing.T) {
+	// Arrange
+	store := newTestStore(t)
+	putItems(t, store, "Product", product("product-1", "shop-1", "10"), product("product-2", "shop-1", "20"))
+	input := &dynamodb.TransactWriteItemsInput{TransactItems: []*dynamodb.TransactWriteItem{
+		{Put: &dynamodb.Put{TableName: aws.String("ShopProduct"), Item: dynamoexpr.Item{"Shop": s("shop-1"), "Product": s("product-3")}}},
+		{Put: &dynamodb.Put{TableName: aws.String("Product"), Item: product("product-3", "shop-1", "30"),
+			ConditionExpression: aws.String("attribute_not_exists(Id)")}},
+		{Update: &dynamodb.Update{TableName: aws.String("Product"), Key: dynamoexpr.Item{"Id": s("product-1")},
+			UpdateExpression: aws.String("SET Price = :price"), ExpressionAttributeValues: dynamoexpr.Item{":price": n("15")}}},
+		{Delete: &dynamodb.Delete{TableName: aws.String("Product"), Key: dynamoexpr.Item{"Id": s("product-2")},
+			ConditionExpression: aws.String("Price > :price"), ExpressionAttributeValues: dynamoexpr.Item{":price": n("20")}}},
+	}}
+
+	// Act
+	_, err := store.TransactWriteItemsWithContext(context.Background(), input)
+
+	// Assert
+	canceled, ok := err.(*dynamodb.TransactionCanceledException)
+	if !ok {
+		t.Fatalf("expected TransactionCanceledException, got %v", err)
+	}
+	codes := []string{}
+	for _, reason := range canceled.CancellationReasons {
+		codes = append(codes, aws.StringValue(reason.Code))
+	}
+	if expected := []string{"None", "None", "None", "ConditionalCheckFailed"}; !reflect.DeepEqual(codes, expected) {
+		t.Errorf("expected cancellation reasons %v, got %v", expected, codes)
+	}
+	expected := map[string]dynamoexpr.Item{
+		"product-1": product("product-1", "shop-1", "10"),
+		"product-2": product("product-2", "shop-1", "20"),
+		"product-3": nil,
+	}
+	for id, item := range expected {
+		if got := getStoredItem(t, store, "Product", dynamoexpr.Item{"Id": s(id)}); !reflect.DeepEqual(got, item) {
+			t.Errorf("%s: expected %v, got %v", id, item, got)
+		}
+	}
+	if got := getStoredItem(t, store, "ShopProduct", dynamoexpr.Item{"Shop": s("shop-1"), "Product": s("product-3")}); got != nil {
+		t.Errorf("expected no join table item, got %v", got)
+	}
+
+	// the same transaction is applied entirely once the condition is satisfied
+	input.TransactItems[3].Delete.ExpressionAttributeValues[":price"] = n("10")
+	if _, err = store.TransactWriteItemsWithContext(context.Background(), input); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	expected = map[string]dynamoexpr.Item{
+		"product-1": product("product-1", "shop-1", "15"),
+		"product-2": nil,
+		"product-3": product("product-3", "shop-1", "30"),
+	}
+	for id, item := range expected {
+		if got := getStoredItem(t, store, "Product", dynamoexpr.Item{"Id": s(id)}); !reflect.DeepEqual(got, item) {
+			t.Errorf("%s: expected %v, got %v", id, item, got)
+		}
+	}
+}
@@ -0,0 +1,336 @@
+package lib
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/expression"
+)
+
+// TransactionWritesLimit is the maximum number of
+// writes and condition checks of a single transaction
+const TransactionWritesLimit = 100
+
+// the cancellation reason of the writes whose conditions are not satisfied
+const conditionalCheckFailedReason = "ConditionalCheckFailed"
+
+// Tx collects the writes of a transaction started with RunInTransaction
+type Tx struct {
+	store Store
+	// ctx is the context carrying the transaction, the operations
+	// invoked with it issue their writes in the transaction
+	ctx   context.Context
+	mu    sync.Mutex
+	items []*dynamodb.TransactWriteItem
+	// conditionErrors maps the indexes of the items to the errors returned
+	// instead of TransactionCancelledError if their conditions are not satisfied
+	conditionErrors map[int]error
+}
+
+// the key of the transaction in the contexts carrying it
+type txContextKey struct{}
+
+// RunInTransaction runs fn in a transaction. The writes issued by the library
+// operations invoked in fn, e.g. Export, Upsert, SetField, Delete or inserts to
+// many-to-many relationships, are collected and committed atomically when fn
+// returns nil. If fn returns an error, none of them is applied.
+// If any condition of the writes is not satisfied, no write is applied
+// and TransactionCancelledError is returned, or the error of the operation
+// whose condition failed if it has one, e.g. DuplicateValueError.
+//
+// The reads issued in fn do not see the writes of the transaction and each
+// object can be written at most once in a transaction.
+// The operations invoked without a context, e.g. the methods of the types, use
+// the invocation context, which carries the transaction until fn returns.
+// If the invocation context carries a transaction already, e.g. RunInTransaction
+// is invoked in fn, TransactionInProgressError is returned and fn is not run.
+// Use RunInTransactionWithContext to run the transactions independently of the
+// invocation context, e.g. in several goroutines at the same time.
+func RunInTransaction(fn func(tx *Tx) error) error {
+	invocationContextMu.Lock()
+	previous := invocationContext
+	ctx := previous
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if transactionFromContext(ctx) != nil {
+		invocationContextMu.Unlock()
+		return TransactionInProgressError{}
+	}
+	tx := newTransaction(ctx)
+	invocationContext = tx.ctx
+	invocationContextMu.Unlock()
+
+	err := func() error {
+		defer SetInvocationContext(previous)
+		return fn(tx)
+	}()
+	if err != nil {
+		return err
+	}
+	return tx.commit(ctx)
+}
+
+// RunInTransactionWithContext is the same as RunInTransaction, except that the transaction
+// is carried by the context returned by tx.Context() instead of the invocation context.
+// Only the operations invoked with that context, e.g. ExportWithContext(tx.Context(), ...),
+// issue their writes in the transaction. If ctx carries a transaction already,
+// TransactionInProgressError is returned and fn is not run.
+func RunInTransactionWithContext(ctx context.Context, fn func(tx *Tx) error) error {
+	if transactionFromContext(ctx) != nil {
+		return TransactionInProgressError{}
+	}
+	return runInTransaction(ctx, fn)
+}
+
+// runInTransaction runs fn in a new transaction, or in the one carried by ctx.
+// It's used by the operations of the library issuing several writes, so that
+// they join the transaction they are invoked in.
+func runInTransaction(ctx context.Context, fn func(tx *Tx) error) error {
+	if tx := transactionFromContext(ctx); tx != nil {
+		return fn(tx)
+	}
+
+	tx := newTransaction(ctx)
+	if err := fn(tx); err != nil {
+		return err
+	}
+	return tx.commit(ctx)
+}
+
+// Context returns the context carrying the transaction, which
+// is to be passed to the operations issuing writes in it
+func (tx *Tx) Context() context.Context {
+	return tx.ctx
+}
+
+// CheckCondition adds the check of the condition on the object with the given id
+// to the transaction. The transaction is cancelled if the condition is not satisfied.
+func (tx *Tx) CheckCondition(typeName, id string, condition expression.ConditionBuilder) error {
+	expr, err := expression.NewBuilder().WithCondition(condition).Build()
+	if err != nil {
+		return fmt.Errorf("error occurred when building dynamodb condition expression %w", err)
+	}
+
+	tx.add(&dynamodb.TransactWriteItem{ConditionCheck: &dynamodb.ConditionCheck{
+		TableName: aws.String(getTableName(typeName)),
+		Key: map[string]*dynamodb.AttributeValue{
+			"Id": {
+				S: aws.String(id),
+			},
+		},
+		ConditionExpression:       expr.Condition(),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+	}})
+	return nil
+}
+
+// CheckExists adds the check if the object with the given id exists to the transaction
+func (tx *Tx) CheckExists(typeName, id string) error {
+	return tx.CheckCondition(typeName, id, expression.AttributeExists(expression.Name("Id")))
+}
+
+// add appends the items to the transaction, the batch operations
+// of the library may issue the writes from several goroutines
+func (tx *Tx) add(items ...*dynamodb.TransactWriteItem) {
+	tx.mu.Lock()
+	defer tx.mu.Unlock()
+	tx.items = append(tx.items, items...)
+}
+
+// addWithConditionError appends the item to the transaction, the err is
+// returned on commit if the condition of the item is not satisfied
+func (tx *Tx) addWithConditionError(item *dynamodb.TransactWriteItem, err error) {
+	tx.mu.Lock()
+	defer tx.mu.Unlock()
+	tx.items = append(tx.items, item)
+	tx.setConditionErrorLocked(len(tx.items)-1, err)
+}
+
+// setConditionError sets the error returned on commit if
+// the condition of the item with the given index is not satisfied
+func (tx *Tx) setConditionError(index int, err error) {
+	tx.mu.Lock()
+	defer tx.mu.Unlock()
+	tx.setConditionErrorLocked(index, err)
+}
+
+func (tx *Tx) setConditionErrorLocked(index int, err error) {
+	if tx.conditionErrors == nil {
+		tx.conditionErrors = map[int]error{}
+	}
+	tx.conditionErrors[index] = err
+}
+
+// len returns the number of the items added to the transaction so far
+func (tx *Tx) len() int {
+	tx.mu.Lock()
+	defer tx.mu.Unlock()
+	return len(tx.items)
+}
+
+func (tx *Tx) commit(ctx context.Context) error {
+	if len(tx.items) == 0 {
+		return nil
+	}
+	if len(tx.items) > TransactionWritesLimit {
+		return fmt.Errorf("transaction consists of %d writes, the maximum number is %d", len(tx.items), TransactionWritesLimit)
+	}
+
+	_, err := tx.store.TransactWriteItemsWithContext(ctx, &dynamodb.TransactWriteItemsInput{TransactItems: tx.items})
+	if cancelled, ok := err.(*dynamodb.TransactionCanceledException); ok {
+		reasons := make([]string, len(cancelled.CancellationReasons))
+		for i, reason := range cancelled.CancellationReasons {
+			reasons[i] = aws.StringValue(reason.Code)
+		}
+		for i, reason := range reasons {
+			if conditionErr, exists := tx.conditionErrors[i]; exists && reason == conditionalCheckFailedReason {
+				return conditionErr
+			}
+		}
+		return TransactionCancelledError{Reasons: reasons}
+	}
+	return err
+}
+
+// commitSequentially issues the writes one by one in the order they were added,
+// hence they are not applied atomically. It's used when there are too many writes
+// to commit them in a transaction, the condition checks can not be issued this way.
+func (tx *Tx) commitSequentially(ctx context.Context) error {
+	for _, item := range tx.items {
+		var err error
+		switch {
+		case item.Put != nil:
+			_, err = tx.store.PutItemWithContext(ctx, &dynamodb.PutItemInput{
+				TableName:                 item.Put.TableName,
+				Item:                      item.Put.Item,
+				ConditionExpression:       item.Put.ConditionExpression,
+				ExpressionAttributeNames:  item.Put.ExpressionAttributeNames,
+				ExpressionAttributeValues: item.Put.ExpressionAttributeValues,
+			})
+		case item.Update != nil:
+			_, err = tx.store.UpdateItemWithContext(ctx, &dynamodb.UpdateItemInput{
+				TableName:                 item.Update.TableName,
+				Key:                       item.Update.Key,
+				UpdateExpression:          item.Update.UpdateExpression,
+				ConditionExpression:       item.Update.ConditionExpression,
+				ExpressionAttributeNames:  item.Update.ExpressionAttributeNames,
+				ExpressionAttributeValues: item.Update.ExpressionAttributeValues,
+			})
+		case item.Delete != nil:
+			_, err = tx.store.DeleteItemWithContext(ctx, &dynamodb.DeleteItemInput{
+				TableName:                 item.Delete.TableName,
+				Key:                       item.Delete.Key,
+				ConditionExpression:       item.Delete.ConditionExpression,
+				ExpressionAttributeNames:  item.Delete.ExpressionAttributeNames,
+				ExpressionAttributeValues: item.Delete.ExpressionAttributeValues,
+			})
+		default:
+			err = fmt.Errorf("condition checks can be issued only in a transaction")
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// isPut returns true if the item with the given id is put to the table in the transaction
+func (tx *Tx) isPut(tableName, id string) bool {
+	tx.mu.Lock()
+	defer tx.mu.Unlock()
+	for _, item := range tx.items {
+		if item.Put != nil && aws.StringValue(item.Put.TableName) == tableName {
+			if idAttribute := item.Put.Item["Id"]; idAttribute != nil && aws.StringValue(idAttribute.S) == id {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// transactionFromContext returns the transaction carried by the context, or nil
+func transactionFromContext(ctx context.Context) *Tx {
+	tx, _ := ctx.Value(txContextKey{}).(*Tx)
+	return tx
+}
+
+// newTransaction starts a transaction carried by a context derived from ctx
+func newTransaction(ctx context.Context) *Tx {
+	storeMu.Lock()
+	defer storeMu.Unlock()
+
+	tx := &Tx{store: currentStore()}
+	tx.ctx = context.WithValue(ctx, txContextKey{}, tx)
+	return tx
+}
+
+// transactionStore is the store used while the transaction is in progress,
+// the reads are passed to the underlying store and the writes are collected
+type transactionStore struct {
+	Store
+	tx *Tx
+}
+
+func (t transactionStore) PutItemWithContext(_ aws.Context, input *dynamodb.PutItemInput, _ ...request.Option) (*dynamodb.PutItemOutput, error) {
+	t.tx.add(&dynamodb.TransactWriteItem{Put: &dynamodb.Put{
+		TableName:                 input.TableName,
+		Item:                      input.Item,
+		ConditionExpression:       input.ConditionExpression,
+		ExpressionAttributeNames:  input.ExpressionAttributeNames,
+		ExpressionAttributeValues: input.ExpressionAttributeValues,
+	}})
+	return &dynamodb.PutItemOutput{}, nil
+}
+
+func (t transactionStore) UpdateItemWithContext(_ aws.Context, input *dynamodb.UpdateItemInput, _ ...request.Option) (*dynamodb.UpdateItemOutput, error) {
+	t.tx.add(&dynamodb.TransactWriteItem{Update: &dynamodb.Update{
+		TableName:                 input.TableName,
+		Key:                       input.Key,
+		UpdateExpression:          input.UpdateExpression,
+		ConditionExpression:       input.ConditionExpression,
+		ExpressionAttributeNames:  input.ExpressionAttributeNames,
+		ExpressionAttributeValues: input.ExpressionAttributeValues,
+	}})
+	return &dynamodb.UpdateItemOutput{}, nil
+}
+
+func (t transactionStore) DeleteItemWithContext(_ aws.Context, input *dynamodb.DeleteItemInput, _ ...request.Option) (*dynamodb.DeleteItemOutput, error) {
+	t.tx.add(&dynamodb.TransactWriteItem{Delete: &dynamodb.Delete{
+		TableName:                 input.TableName,
+		Key:                       input.Key,
+		ConditionExpression:       input.ConditionExpression,
+		ExpressionAttributeNames:  input.ExpressionAttributeNames,
+		ExpressionAttributeValues: input.ExpressionAttributeValues,
+	}})
+	return &dynamodb.DeleteItemOutput{}, nil
+}
+
+func (t transactionStore) BatchWriteItemWithContext(_ aws.Context, input *dynamodb.BatchWriteItemInput, _ ...request.Option) (*dynamodb.BatchWriteItemOutput, error) {
+	for tableName, requests := range input.RequestItems {
+		for _, request := range requests {
+			if request.PutRequest != nil {
+				t.tx.add(&dynamodb.TransactWriteItem{Put: &dynamodb.Put{
+					TableName: aws.String(tableName),
+					Item:      request.PutRequest.Item,
+				}})
+			} else if request.DeleteRequest != nil {
+				t.tx.add(&dynamodb.TransactWriteItem{Delete: &dynamodb.Delete{
+					TableName: aws.String(tableName),
+					Key:       request.DeleteRequest.Key,
+				}})
+			}
+		}
+	}
+	return &dynamodb.BatchWriteItemOutput{UnprocessedItems: map[string][]*dynamodb.WriteRequest{}}, nil
+}
+
+func (t transactionStore) TransactWriteItemsWithContext(_ aws.Context, input *dynamodb.TransactWriteItemsInput, _ ...request.Option) (*dynamodb.TransactWriteItemsOutput, error) {
+	t.tx.add(input.TransactItems...)
+	return &dynamodb.TransactWriteItemsOutput{}, nil
+}
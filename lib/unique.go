@@ -0,0 +1,345 @@
+package lib
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/aws/aws-sdk-go/service/dynamodb/expression"
+)
+
+// UniqueValuesTableName is the name of the table with the guard items of the values
+// of the fields tagged with `nubes:"unique"`. Each guard item is keyed by the value,
+// so that two objects can not hold the same one. The name is prefixed like the
+// names of the tables of the types.
+const UniqueValuesTableName = "NubesUniqueValues"
+
+// the option of nubes tag of the fields whose values are unique among all
+// instances of the type, or among the instances with the same value of
+// the scope field given after the hyphen, e.g. `nubes:"unique-City"`
+const uniqueTagOption = "unique"
+
+// the attribute of the guard item with the id of the object holding the value
+const uniqueValueOwnerAttributeName = "OwnerId"
+
+// DuplicateValueError is returned when the value of the field tagged
+// with `nubes:"unique"` is already held by another instance of the type
+type DuplicateValueError struct {
+	TypeName  string
+	FieldName string
+	Value     string
+}
+
+func (d DuplicateValueError) Error() string {
+	return fmt.Sprintf("value %s of field %s of %s is already held by another instance", d.Value, d.FieldName, d.TypeName)
+}
+
+type uniqueField struct {
+	fieldName     string
+	attributeName string
+	// the attribute of the scope field, empty if the value is unique among all instances
+	scopeAttributeName string
+}
+
+// the unique fields of the struct types, determined once per type
+var uniqueFieldsByType sync.Map
+
+// SetUniqueField is the same as SetField, but if the field of T is tagged with
+// `nubes:"unique"` or it's the scope field of such field, the guard items of
+// the values are updated in a transaction together with the field.
+// DuplicateValueError is returned if the new value is held by another instance.
+func SetUniqueField[T Nobject](param SetFieldParam) error {
+	return SetUniqueFieldWithContext[T](InvocationContext(), param)
+}
+
+// SetUniqueFieldWithContext is the same as SetUniqueField with the addition of the ability to pass a context
+func SetUniqueFieldWithContext[T Nobject](ctx context.Context, param SetFieldParam) error {
+	if err := param.Validate(); err != nil {
+		return err
+	}
+	return setUniqueField(ctx, reflect.TypeOf((*T)(nil)).Elem(), param)
+}
+
+// setUniqueField sets the field of the object of the given type
+// updating the guard items of the unique values it affects
+func setUniqueField(ctx context.Context, objType reflect.Type, param SetFieldParam) error {
+	var affected []uniqueField
+	for _, field := range getUniqueFields(objType) {
+		if field.attributeName == param.FieldName || field.scopeAttributeName == param.FieldName {
+			affected = append(affected, field)
+		}
+	}
+	if len(affected) == 0 {
+		return setField(ctx, param)
+	}
+
+	previous, err := getStoredUniqueAttributes(ctx, param.TypeName, param.Id, affected)
+	if err != nil {
+		return err
+	}
+	value, err := dynamodbattribute.Marshal(param.Value)
+	if err != nil {
+		return err
+	}
+	current := make(map[string]*dynamodb.AttributeValue, len(previous)+1)
+	for name, attribute := range previous {
+		current[name] = attribute
+	}
+	current[param.FieldName] = value
+
+	var conditionErr error
+	if param.ExpectedVersion != nil {
+		conditionErr = ConflictError{Id: param.Id, TypeName: param.TypeName, ExpectedVersion: *param.ExpectedVersion}
+	}
+	return writeGuardingUniqueValues(ctx, affected, param.TypeName, param.Id, previous, current, conditionErr, func(ctx context.Context) error {
+		return setField(ctx, param)
+	})
+}
+
+// writeGuardingUniqueValues issues the write of the object together with the writes of the
+// guard items of its unique values that changed, in a transaction or joining the one in progress.
+// The previous attributes are read from the DB if nil, they are empty for the new objects.
+// If the condition of the object write is not satisfied, conditionErr is returned, unless nil.
+func writeGuardingUniqueValues(ctx context.Context, fields []uniqueField, typeName, id string,
+	previous, current map[string]*dynamodb.AttributeValue, conditionErr error, write func(ctx context.Context) error) error {
+	if len(fields) == 0 {
+		return write(ctx)
+	}
+
+	if previous == nil {
+		var err error
+		if previous, err = getStoredUniqueAttributes(ctx, typeName, id, fields); err != nil {
+			return err
+		}
+	}
+
+	return runInTransaction(ctx, func(tx *Tx) error {
+		objectWriteIndex := tx.len()
+		if err := write(tx.Context()); err != nil {
+			return err
+		}
+		if conditionErr != nil {
+			tx.setConditionError(objectWriteIndex, conditionErr)
+		}
+
+		for _, field := range fields {
+			previousGuardId, hadValue := field.guardId(typeName, previous)
+			currentGuardId, hasValue := field.guardId(typeName, current)
+			if hadValue && hasValue && previousGuardId == currentGuardId {
+				continue
+			}
+			if hadValue {
+				item, err := getGuardDelete(previousGuardId, id)
+				if err != nil {
+					return err
+				}
+				tx.add(item)
+			}
+			if hasValue {
+				item, err := getGuardPut(currentGuardId, id)
+				if err != nil {
+					return err
+				}
+				value, _ := getUniqueAttributeValue(current[field.attributeName])
+				tx.addWithConditionError(item, DuplicateValueError{TypeName: typeName, FieldName: field.fieldName, Value: value})
+			}
+		}
+		return nil
+	})
+}
+
+// releaseUniqueValues adds the deletes of the guard items of the
+// unique values of the object to be deleted to the transaction
+func releaseUniqueValues(ctx context.Context, tx *Tx, objType reflect.Type, typeName, id string) error {
+	fields := getUniqueFields(objType)
+	if len(fields) == 0 {
+		return nil
+	}
+
+	stored, err := getStoredUniqueAttributes(ctx, typeName, id, fields)
+	if err != nil {
+		return err
+	}
+	for _, field := range fields {
+		if guardId, hasValue := field.guardId(typeName, stored); hasValue {
+			item, err := getGuardDelete(guardId, id)
+			if err != nil {
+				return err
+			}
+			tx.add(item)
+		}
+	}
+	return nil
+}
+
+// getStoredUniqueAttributes returns the attributes of the unique fields and their
+// scope fields stored in the DB, the result is empty if the object does not exist
+func getStoredUniqueAttributes(ctx context.Context, typeName, id string, fields []uniqueField) (map[string]*dynamodb.AttributeValue, error) {
+	var names []string
+	for _, field := range fields {
+		names = append(names, field.attributeName)
+		if field.scopeAttributeName != "" {
+			names = append(names, field.scopeAttributeName)
+		}
+	}
+	expr, err := expression.NewBuilder().WithProjection(getProjection(distinct(names))).Build()
+	if err != nil {
+		return nil, fmt.Errorf("error occurred when building dynamodb projection expression %w", err)
+	}
+
+	item, err := dbClient(ctx).GetItemWithContext(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(getTableName(typeName)),
+		Key: map[string]*dynamodb.AttributeValue{
+			"Id": {
+				S: aws.String(id),
+			},
+		},
+		ConsistentRead:           aws.Bool(true),
+		ProjectionExpression:     expr.Projection(),
+		ExpressionAttributeNames: expr.Names(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if item.Item == nil {
+		return map[string]*dynamodb.AttributeValue{}, nil
+	}
+	return item.Item, nil
+}
+
+// getGuardPut returns the put of the guard item, which succeeds
+// only if the value is not held or it's held by the same object
+func getGuardPut(guardId, ownerId string) (*dynamodb.TransactWriteItem, error) {
+	expr, err := expression.NewBuilder().WithCondition(getGuardOwnerCondition(ownerId)).Build()
+	if err != nil {
+		return nil, fmt.Errorf("error occurred when building dynamodb condition expression %w", err)
+	}
+
+	return &dynamodb.TransactWriteItem{Put: &dynamodb.Put{
+		TableName: aws.String(getTableName(UniqueValuesTableName)),
+		Item: map[string]*dynamodb.AttributeValue{
+			"Id": {
+				S: aws.String(guardId),
+			},
+			uniqueValueOwnerAttributeName: {
+				S: aws.String(ownerId),
+			},
+		},
+		ConditionExpression:       expr.Condition(),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+	}}, nil
+}
+
+// getGuardDelete returns the delete of the guard item, which
+// does not release the value held in the meantime by another object
+func getGuardDelete(guardId, ownerId string) (*dynamodb.TransactWriteItem, error) {
+	expr, err := expression.NewBuilder().WithCondition(getGuardOwnerCondition(ownerId)).Build()
+	if err != nil {
+		return nil, fmt.Errorf("error occurred when building dynamodb condition expression %w", err)
+	}
+
+	return &dynamodb.TransactWriteItem{Delete: &dynamodb.Delete{
+		TableName: aws.String(getTableName(UniqueValuesTableName)),
+		Key: map[string]*dynamodb.AttributeValue{
+			"Id": {
+				S: aws.String(guardId),
+			},
+		},
+		ConditionExpression:       expr.Condition(),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+	}}, nil
+}
+
+func getGuardOwnerCondition(ownerId string) expression.ConditionBuilder {
+	return expression.AttributeNotExists(expression.Name("Id")).
+		Or(expression.Name(uniqueValueOwnerAttributeName).Equal(expression.Value(ownerId)))
+}
+
+func getObjectUniqueFields(obj interface{}) []uniqueField {
+	return getUniqueFields(reflect.Indirect(reflect.ValueOf(obj)).Type())
+}
+
+func getUniqueFields(structType reflect.Type) []uniqueField {
+	if structType.Kind() != reflect.Struct {
+		return nil
+	}
+	if cached, ok := uniqueFieldsByType.Load(structType); ok {
+		return cached.([]uniqueField)
+	}
+
+	var result []uniqueField
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		attributeName, isStored := getAttributeName(field)
+		if !isStored {
+			continue
+		}
+		scopeFieldName, isUnique := parseUniqueTag(field.Tag.Get("nubes"))
+		if !isUnique {
+			continue
+		}
+
+		unique := uniqueField{fieldName: field.Name, attributeName: attributeName}
+		if scopeFieldName != "" {
+			unique.scopeAttributeName = scopeFieldName
+			if scopeField, found := structType.FieldByName(scopeFieldName); found {
+				if scopeAttributeName, isScopeStored := getAttributeName(scopeField); isScopeStored {
+					unique.scopeAttributeName = scopeAttributeName
+				}
+			}
+		}
+		result = append(result, unique)
+	}
+	uniqueFieldsByType.Store(structType, result)
+	return result
+}
+
+// parseUniqueTag returns true if the nubes tag has the unique option,
+// and the name of the scope field if given, e.g. unique-City
+func parseUniqueTag(tag string) (string, bool) {
+	for _, option := range strings.Split(tag, ",") {
+		name, scopeFieldName, _ := strings.Cut(strings.TrimSpace(option), "-")
+		if name == uniqueTagOption {
+			return scopeFieldName, true
+		}
+	}
+	return "", false
+}
+
+// guardId returns the id of the guard item of the value of the field,
+// the second return value is false if the field has no value
+func (u uniqueField) guardId(typeName string, attributes map[string]*dynamodb.AttributeValue) (string, bool) {
+	value, hasValue := getUniqueAttributeValue(attributes[u.attributeName])
+	if !hasValue {
+		return "", false
+	}
+	var scope string
+	if u.scopeAttributeName != "" {
+		scope, _ = getUniqueAttributeValue(attributes[u.scopeAttributeName])
+	}
+
+	guardId, _ := json.Marshal([]string{typeName, u.fieldName, scope, value})
+	return string(guardId), true
+}
+
+// getUniqueAttributeValue returns the value of the string or number
+// attribute, the missing attributes and empty strings have no value
+func getUniqueAttributeValue(attribute *dynamodb.AttributeValue) (string, bool) {
+	switch {
+	case attribute == nil:
+		return "", false
+	case attribute.S != nil:
+		return *attribute.S, *attribute.S != ""
+	case attribute.N != nil:
+		return *attribute.N, true
+	}
+	return "", false
+}
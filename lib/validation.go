@@ -0,0 +1,238 @@
+package lib
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// The validation rules that can be given in the nubes tags of the fields,
+// e.g. `nubes:"required,min=3,max=20"`. The options are separated with commas,
+// hence the regular expressions given with RegexRule can not contain commas.
+const (
+	// RequiredRule is satisfied by the non zero values
+	RequiredRule = "required"
+	// MinRule is the minimal value of numbers, or length of strings, slices and maps
+	MinRule = "min"
+	// MaxRule is the maximal value of numbers, or length of strings, slices and maps
+	MaxRule = "max"
+	// RegexRule is the regular expression the non empty strings must match
+	RegexRule = "regex"
+	// OneOfRule lists the allowed values separated with spaces, e.g. oneof=S M L
+	OneOfRule = "oneof"
+)
+
+// FieldValidationError describes the rule not satisfied by the value of the field
+type FieldValidationError struct {
+	FieldName string
+	// Rule as given in the tag, e.g. min=0
+	Rule string
+}
+
+// ValidationError is returned when the fields of an object do not satisfy
+// the rules of their nubes tags. It lists all the failing fields.
+type ValidationError struct {
+	TypeName string
+	Fields   []FieldValidationError
+}
+
+func (v ValidationError) Error() string {
+	failures := make([]string, 0, len(v.Fields))
+	for _, field := range v.Fields {
+		failures = append(failures, field.FieldName+" does not satisfy "+field.Rule)
+	}
+	return fmt.Sprintf("validation of %s failed: %s", v.TypeName, strings.Join(failures, ", "))
+}
+
+type validationRule struct {
+	name      string
+	parameter string
+	// expression is the compiled parameter of RegexRule, nil if it's invalid
+	expression *regexp.Regexp
+	// original is the rule as given in the tag
+	original string
+}
+
+type fieldValidationRules struct {
+	fieldIndex int
+	fieldName  string
+	rules      []validationRule
+}
+
+// the rules of the struct types, parsed once per type
+var validationRulesByType sync.Map
+
+// Validate checks the values of all the fields of the struct, or a pointer to it,
+// against the validation rules of their nubes tags. It returns ValidationError
+// listing every failing field, or nil.
+func Validate(obj interface{}) error {
+	value := reflect.Indirect(reflect.ValueOf(obj))
+	if value.Kind() != reflect.Struct {
+		return nil
+	}
+
+	var failed []FieldValidationError
+	for _, field := range getValidationRules(value.Type()) {
+		failed = append(failed, validateValue(field, value.Field(field.fieldIndex))...)
+	}
+	if len(failed) > 0 {
+		return ValidationError{TypeName: getObjectTypeName(obj, value.Type()), Fields: failed}
+	}
+	return nil
+}
+
+// ValidateField checks the value to be set in the field of the given
+// struct type against the validation rules of the field's nubes tag
+func ValidateField(obj interface{}, fieldName string, value interface{}) error {
+	objType := reflect.Indirect(reflect.ValueOf(obj)).Type()
+	if objType.Kind() != reflect.Struct {
+		return nil
+	}
+
+	for _, field := range getValidationRules(objType) {
+		if field.fieldName != fieldName {
+			continue
+		}
+		if failed := validateValue(field, reflect.ValueOf(value)); len(failed) > 0 {
+			return ValidationError{TypeName: getObjectTypeName(obj, objType), Fields: failed}
+		}
+		return nil
+	}
+	return nil
+}
+
+func getObjectTypeName(obj interface{}, objType reflect.Type) string {
+	if nobject, ok := obj.(Nobject); ok {
+		return nobject.GetTypeName()
+	}
+	return objType.Name()
+}
+
+func getValidationRules(structType reflect.Type) []fieldValidationRules {
+	if cached, ok := validationRulesByType.Load(structType); ok {
+		return cached.([]fieldValidationRules)
+	}
+
+	var result []fieldValidationRules
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		if rules := parseValidationRules(field.Tag.Get("nubes")); len(rules) > 0 {
+			result = append(result, fieldValidationRules{fieldIndex: i, fieldName: field.Name, rules: rules})
+		}
+	}
+	validationRulesByType.Store(structType, result)
+	return result
+}
+
+// parseValidationRules returns the validation rules from the value
+// of nubes tag, the other options, e.g. readonly, are skipped
+func parseValidationRules(tag string) []validationRule {
+	var rules []validationRule
+	for _, option := range strings.Split(tag, ",") {
+		option = strings.TrimSpace(option)
+		name, parameter := option, ""
+		if separator := strings.Index(option, "="); separator >= 0 {
+			name, parameter = option[:separator], option[separator+1:]
+		}
+
+		switch name {
+		case RequiredRule, MinRule, MaxRule, OneOfRule:
+			rules = append(rules, validationRule{name: name, parameter: parameter, original: option})
+		case RegexRule:
+			// the invalid expression is left nil, so the rule is not satisfied
+			expression, _ := regexp.Compile(parameter)
+			rules = append(rules, validationRule{name: name, parameter: parameter, expression: expression, original: option})
+		}
+	}
+	return rules
+}
+
+func validateValue(field fieldValidationRules, value reflect.Value) []FieldValidationError {
+	for value.IsValid() && (value.Kind() == reflect.Pointer || value.Kind() == reflect.Interface) {
+		if value.IsNil() {
+			value = reflect.Value{}
+			break
+		}
+		value = value.Elem()
+	}
+
+	var failed []FieldValidationError
+	for _, rule := range field.rules {
+		if !rule.isSatisfiedBy(value) {
+			failed = append(failed, FieldValidationError{FieldName: field.fieldName, Rule: rule.original})
+		}
+	}
+	return failed
+}
+
+// isSatisfiedBy returns true if the value satisfies the rule. The rules with
+// invalid parameters, e.g. min=abc, are not satisfied by any value.
+func (r validationRule) isSatisfiedBy(value reflect.Value) bool {
+	if r.name == RequiredRule {
+		return value.IsValid() && !value.IsZero()
+	}
+	if !value.IsValid() {
+		// the missing values are checked only by the required rule
+		return true
+	}
+
+	switch r.name {
+	case MinRule, MaxRule:
+		bound, err := strconv.ParseFloat(r.parameter, 64)
+		if err != nil {
+			return false
+		}
+		measured, ok := getMeasuredValue(value)
+		if !ok {
+			return true
+		}
+		if r.name == MinRule {
+			return measured >= bound
+		}
+		return measured <= bound
+
+	case RegexRule:
+		if r.expression == nil {
+			return false
+		}
+		if value.Kind() != reflect.String || value.Len() == 0 {
+			return true
+		}
+		return r.expression.MatchString(value.String())
+
+	case OneOfRule:
+		if value.IsZero() {
+			return true
+		}
+		formatted := fmt.Sprint(value.Interface())
+		for _, allowed := range strings.Fields(r.parameter) {
+			if formatted == allowed {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// getMeasuredValue returns the number compared with the bounds of min and max
+// rules, i.e. the value of numbers and the length of strings, slices and maps
+func getMeasuredValue(value reflect.Value) (float64, bool) {
+	switch value.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(value.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(value.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return value.Float(), true
+	case reflect.String:
+		return float64(utf8.RuneCountInString(value.String())), true
+	case reflect.Slice, reflect.Map, reflect.Array:
+		return float64(value.Len()), true
+	}
+	return 0, false
+}
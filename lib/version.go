@@ -0,0 +1,30 @@
+package lib
+
+import (
+	"github.com/aws/aws-sdk-go/service/dynamodb/expression"
+)
+
+// VersionAttributeName is the name of the attribute in which
+// the version of Nobjects implementing Versioned is stored
+const VersionAttributeName = "Version"
+
+// Versioned is implemented by Nobject types with a field tagged
+// with `nubes:"version"`. The methods are added by the generator.
+// The version is incremented each time the object's state is saved
+// with Upsert or SetField, the writes of Upsert succeed only if the
+// version stored in the DB is equal to the version of the object.
+type Versioned interface {
+	GetVersion() int
+	SetVersion(version int)
+}
+
+// getVersionCondition returns condition that holds if the stored
+// version equals the expected one. Version 0 matches also the objects
+// saved before the version field was added to the type.
+func getVersionCondition(expectedVersion int) expression.ConditionBuilder {
+	condition := expression.Name(VersionAttributeName).Equal(expression.Value(expectedVersion))
+	if expectedVersion == 0 {
+		condition = expression.Name(VersionAttributeName).AttributeNotExists().Or(condition)
+	}
+	return condition
+}